@@ -23,7 +23,8 @@ var rootCmd = &cobra.Command{
 	Use:   "license-audit",
 	Short: "A comprehensive license auditing tool for various package managers",
 	Long: `license-audit scans your project dependencies and generates detailed 
-license reports. It supports Node.js, Go, Docker, Python, Ruby, and Java projects.`,
+license reports. It supports Node.js, Go, Docker, Python, Ruby, Java, Rust, PHP, .NET, Swift, CocoaPods, Dart, Elixir, Erlang, C/C++ (Conan, vcpkg), Docker Compose, Kubernetes and Helm projects, as well as vendored third-party code
+and the packages installed in Debian/Ubuntu root filesystems and saved container images.`,
 	Run: run,
 }
 
@@ -5,10 +5,11 @@ go 1.24.2
 require (
 	github.com/BurntSushi/toml v1.5.0
 	github.com/spf13/cobra v1.9.1
+	golang.org/x/text v0.28.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
 	github.com/spf13/pflag v1.0.6 // indirect
-	golang.org/x/text v0.28.0 // indirect
 )
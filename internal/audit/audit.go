@@ -1,6 +1,7 @@
 package audit
 
 import (
+	"fmt"
 	"strings"
 
 	"license-audit/pkg/types"
@@ -19,7 +20,29 @@ func New(config *types.Config) *Auditor {
 func (a *Auditor) Audit(dependencies []types.Dependency) []types.AuditIssue {
 	var issues []types.AuditIssue
 
+	// Source files are checked against the license of the project they
+	// belong to, which the header scanner reports per scan path
+	projectLicenses := make(map[string]string)
 	for _, dep := range dependencies {
+		if dep.PackageType == "source" && dep.Scope == "project" {
+			projectLicenses[dep.FilePath] = dep.LicenseType
+		}
+	}
+
+	for _, dep := range dependencies {
+		if dep.PackageType == "source" {
+			if issue := a.auditSourceFiles(dep, projectLicenses[dep.FilePath]); issue != nil {
+				issues = append(issues, *issue)
+			}
+			continue
+		}
+
+		// The projects being scanned, as a gemspec or Cargo workspace
+		// member declares them, are not third-party code
+		if dep.Scope == "project" {
+			continue
+		}
+
 		// Check for dangerous licenses
 		if a.isDangerousLicense(dep.LicenseType) {
 			issue := types.AuditIssue{
@@ -72,6 +95,77 @@ func (a *Auditor) Audit(dependencies []types.Dependency) []types.AuditIssue {
 	return issues
 }
 
+// auditSourceFiles checks a group of first-party source files sharing a
+// license header. Files without a header, and files whose header names a
+// license other than the project's, such as code copied in from elsewhere,
+// are reported.
+func (a *Auditor) auditSourceFiles(dep types.Dependency, projectLicense string) *types.AuditIssue {
+	if dep.Scope == "project" {
+		return nil
+	}
+
+	if dep.LicenseType == "UNKNOWN" {
+		suggestion := "Add an SPDX-License-Identifier comment to the top of these files"
+		if projectLicense != "" && projectLicense != "UNKNOWN" {
+			suggestion = fmt.Sprintf("Add an SPDX-License-Identifier: %s comment to the top of these files", projectLicense)
+		}
+		return &types.AuditIssue{
+			Severity:   "warning",
+			Type:       "missing_license_header",
+			Message:    fmt.Sprintf("No SPDX-License-Identifier tag or license header in %s", sourceFileCount(len(dep.Files))),
+			Dependency: dep,
+			Suggestion: suggestion,
+		}
+	}
+
+	if projectLicense == "" || projectLicense == "UNKNOWN" || licensesMatch(dep.LicenseType, projectLicense) {
+		return nil
+	}
+
+	severity := "warning"
+	if a.isDangerousLicense(dep.LicenseType) || a.isDangerousLicense(baseLicense(dep.LicenseType)) {
+		severity = "error"
+	}
+	return &types.AuditIssue{
+		Severity:   severity,
+		Type:       "license_header_conflict",
+		Message:    fmt.Sprintf("%s licensed under %s, which differs from the project license %s", sourceFileCount(len(dep.Files)), dep.LicenseType, projectLicense),
+		Dependency: dep,
+		Suggestion: "Check where these files came from; keep their license notice and make sure it is compatible, or replace them",
+	}
+}
+
+func sourceFileCount(n int) string {
+	if n == 1 {
+		return "1 source file"
+	}
+	return fmt.Sprintf("%d source files", n)
+}
+
+// licensesMatch reports whether a file license agrees with the project
+// license. Either may be an OR expression, which matches if one of its
+// choices does.
+func licensesMatch(fileLicense, projectLicense string) bool {
+	for _, file := range strings.Split(fileLicense, " OR ") {
+		for _, project := range strings.Split(projectLicense, " OR ") {
+			if strings.EqualFold(baseLicense(file), baseLicense(project)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// baseLicense strips the -only and -or-later suffixes of GNU identifiers
+// and the parentheses of compound expressions, so that GPL-2.0-only and
+// GPL-2.0 compare equal.
+func baseLicense(license string) string {
+	license = strings.Trim(strings.TrimSpace(license), "()")
+	license = strings.TrimSuffix(license, "-only")
+	license = strings.TrimSuffix(license, "-or-later")
+	return strings.TrimSuffix(license, "+")
+}
+
 func (a *Auditor) GetIssueBreakdown(issues []types.AuditIssue) map[string]int {
 	breakdown := make(map[string]int)
 
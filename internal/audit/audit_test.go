@@ -54,6 +54,33 @@ func TestAuditDangerousLicenses(t *testing.T) {
 	}
 }
 
+func TestAuditSkipsProjects(t *testing.T) {
+	config := &types.Config{
+		DangerousLicenses: []string{"GPL-3.0"},
+		UnclearLicenses:   []string{"UNKNOWN"},
+	}
+
+	auditor := New(config)
+
+	dependencies := []types.Dependency{
+		{Name: "acme-widgets", LicenseType: "GPL-3.0", PackageType: "ruby", Scope: "project"},
+		{Name: "acme-core", LicenseType: "UNKNOWN", PackageType: "cargo", Scope: "project"},
+		{Name: "rack", LicenseType: "UNKNOWN", PackageType: "ruby"},
+	}
+
+	issues := auditor.Audit(dependencies)
+
+	// Only rack is third-party: 1 unclear license + 1 missing license
+	if len(issues) != 2 {
+		t.Errorf("Expected 2 issues, got %d", len(issues))
+	}
+	for _, issue := range issues {
+		if issue.Dependency.Name != "rack" {
+			t.Errorf("Unexpected %s issue for project %s", issue.Type, issue.Dependency.Name)
+		}
+	}
+}
+
 func TestIsDangerousLicense(t *testing.T) {
 	config := &types.Config{
 		DangerousLicenses: []string{"GPL-2.0", "GPL-3.0", "AGPL-3.0"},
@@ -181,3 +208,65 @@ func TestGetIssueBreakdown(t *testing.T) {
 		t.Errorf("Expected 2 error dangerous license issues, got %d", breakdown["error_dangerous_license"])
 	}
 }
+
+func TestAuditSourceFiles(t *testing.T) {
+	config := &types.Config{
+		DangerousLicenses: []string{"GPL-2.0", "GPL-3.0"},
+		UnclearLicenses:   []string{"UNKNOWN"},
+	}
+
+	auditor := New(config)
+
+	dependencies := []types.Dependency{
+		{Name: "app", LicenseType: "Apache-2.0", PackageType: "source", Scope: "project", FilePath: "."},
+		{Name: "Apache-2.0", LicenseType: "Apache-2.0", PackageType: "source", FilePath: ".", Files: []string{"main.go"}},
+		{Name: "MIT OR Apache-2.0", LicenseType: "MIT OR Apache-2.0", PackageType: "source", FilePath: ".", Files: []string{"itoa.rs"}},
+		{Name: "MIT", LicenseType: "MIT", PackageType: "source", FilePath: ".", Files: []string{"queue.js"}},
+		{Name: "GPL-2.0-or-later", LicenseType: "GPL-2.0-or-later", PackageType: "source", FilePath: ".", Files: []string{"crc32.c"}},
+		{Name: "UNKNOWN", LicenseType: "UNKNOWN", PackageType: "source", FilePath: ".", Files: []string{"a.ts", "b.ts"}},
+	}
+
+	issues := auditor.Audit(dependencies)
+
+	expected := map[string]string{
+		"MIT":              "warning_license_header_conflict",
+		"GPL-2.0-or-later": "error_license_header_conflict",
+		"UNKNOWN":          "warning_missing_license_header",
+	}
+
+	if len(issues) != len(expected) {
+		t.Errorf("Expected %d issues, got %d", len(expected), len(issues))
+	}
+
+	for _, issue := range issues {
+		want, ok := expected[issue.Dependency.Name]
+		if !ok {
+			t.Errorf("Unexpected issue for '%s': %s", issue.Dependency.Name, issue.Message)
+			continue
+		}
+		if got := issue.Severity + "_" + issue.Type; got != want {
+			t.Errorf("%s = %s, expected %s", issue.Dependency.Name, got, want)
+		}
+	}
+}
+
+func TestLicensesMatch(t *testing.T) {
+	testCases := []struct {
+		file, project string
+		expected      bool
+	}{
+		{"MIT", "MIT", true},
+		{"GPL-2.0-only", "GPL-2.0", true},
+		{"GPL-2.0-or-later", "GPL-2.0-only", true},
+		{"MIT OR Apache-2.0", "Apache-2.0", true},
+		{"Apache-2.0", "(MIT OR Apache-2.0)", true},
+		{"MIT", "Apache-2.0", false},
+		{"GPL-3.0-only", "GPL-2.0-only", false},
+	}
+
+	for _, tc := range testCases {
+		if result := licensesMatch(tc.file, tc.project); result != tc.expected {
+			t.Errorf("licensesMatch(%s, %s) = %v, expected %v", tc.file, tc.project, result, tc.expected)
+		}
+	}
+}
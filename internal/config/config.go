@@ -88,12 +88,31 @@ func getDefaultConfig() *types.Config {
 		},
 		EnableAudit: true,
 		Scanners: types.ScannerConfig{
-			NodeJS: true,
-			Go:     true,
-			Docker: true,
-			Python: true,
-			Ruby:   true,
-			Java:   true,
+			NodeJS:     true,
+			Go:         true,
+			Docker:     true,
+			Python:     true,
+			Ruby:       true,
+			Java:       true,
+			Rust:       true,
+			PHP:        true,
+			NuGet:      true,
+			Swift:      true,
+			CocoaPods:  true,
+			Pub:        true,
+			Elixir:     true,
+			Erlang:     true,
+			Conan:      true,
+			Vcpkg:      true,
+			Vendored:   true,
+			Compose:    true,
+			Kubernetes: true,
+			Helm:       true,
+			Deb:        true,
+
+			JavaArchives:    false,
+			SourceHeaders:   false,
+			ContainerImages: false,
 		},
 	}
 }
@@ -0,0 +1,81 @@
+package license
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// File is a license file of a package.
+type File struct {
+	Name string
+	Text string
+}
+
+// fileNames are the base names of license files.
+var fileNames = []string{"LICENSE", "LICENCE", "COPYING", "COPYRIGHT", "UNLICENSE", "MIT-LICENSE"}
+
+// codeExtensions mark files that are named like license files but hold
+// code or data, such as licenses.go.
+var codeExtensions = map[string]bool{
+	".c": true, ".h": true, ".cc": true, ".cpp": true, ".cxx": true, ".hh": true, ".hpp": true, ".hxx": true,
+	".m": true, ".mm": true, ".s": true, ".asm": true,
+	".go": true, ".rs": true, ".zig": true, ".java": true, ".kt": true, ".scala": true, ".swift": true,
+	".py": true, ".rb": true, ".php": true, ".pl": true, ".lua": true, ".cs": true, ".ex": true, ".exs": true,
+	".erl": true, ".dart": true, ".js": true, ".mjs": true, ".cjs": true, ".ts": true, ".css": true,
+	".sh": true, ".json": true, ".yaml": true, ".yml": true, ".toml": true, ".xml": true,
+}
+
+// IsFile reports whether name is a license file: one of the fileNames,
+// alone or followed by a suffix such as -MIT or .LIB or a document
+// extension, in any case.
+func IsFile(name string) bool {
+	if codeExtensions[strings.ToLower(filepath.Ext(name))] {
+		return false
+	}
+
+	upper := strings.ToUpper(name)
+	for _, base := range fileNames {
+		if rest, ok := strings.CutPrefix(upper, base); ok {
+			if rest == "" || strings.ContainsAny(rest[:1], "-_.") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ReadFiles returns the license files at the top of dir, such as LICENSE,
+// LICENSE-MIT, COPYING.LIB or UNLICENSE, in name order.
+func ReadFiles(dir string) []File {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var files []File
+	for _, entry := range entries {
+		if entry.IsDir() || !IsFile(entry.Name()) {
+			continue
+		}
+		if data, err := os.ReadFile(filepath.Join(dir, entry.Name())); err == nil {
+			files = append(files, File{Name: entry.Name(), Text: string(data)})
+		}
+	}
+	return files
+}
+
+// ReadFile returns the text of the license file in dir, or "" if it has
+// none. LICENSE is preferred over COPYING, and COPYING over COPYRIGHT,
+// which is often only a notice.
+func ReadFile(dir string) string {
+	files := ReadFiles(dir)
+	for _, base := range fileNames {
+		for _, file := range files {
+			if strings.HasPrefix(strings.ToUpper(file.Name), base) {
+				return file.Text
+			}
+		}
+	}
+	return ""
+}
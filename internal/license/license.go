@@ -0,0 +1,121 @@
+// Package license recognises licenses from the text of license files, and
+// finds those files in the directories packages are installed to. Scanners
+// classify the license files they read here, so that the same text is
+// reported as the same license whichever ecosystem ships it.
+package license
+
+import (
+	"regexp"
+	"strings"
+)
+
+// patterns are tried in order. Licenses that mention others in their text
+// come first: the MPL 2.0 names the GNU licenses as secondary licenses.
+var patterns = []struct {
+	pattern string
+	license string
+}{
+	{"mit license", "MIT"},
+	{"permission is hereby granted, free of charge", "MIT"},
+	{"apache license, version 2.0", "Apache-2.0"},
+	{"apache license version 2.0", "Apache-2.0"},
+	{"apache software license", "Apache-2.0"},
+	{"boost software license", "BSL-1.0"},
+	{"eclipse public license - v 2.0", "EPL-2.0"},
+	{"eclipse public license v2.0", "EPL-2.0"},
+	{"eclipse public license - v 1.0", "EPL-1.0"},
+	{"common development and distribution license", "CDDL-1.0"},
+	{"microsoft public license", "MS-PL"},
+	{"the php license", "PHP-3.01"},
+	{"python software foundation license", "PSF-2.0"},
+	{"ruby is copyrighted free software", "Ruby"},
+	{"mozilla public license version 1.1", "MPL-1.1"},
+	{"mozilla public license", "MPL-2.0"},
+	{"bsd 3-clause", "BSD-3-Clause"},
+	{"neither the name of", "BSD-3-Clause"},
+	{"bsd 2-clause", "BSD-2-Clause"},
+	{"redistribution and use in source and binary forms", "BSD-2-Clause"},
+	{"isc license", "ISC"},
+	{"permission to use, copy, modify, and/or distribute this software", "ISC"},
+	{"zlib license", "Zlib"},
+	{"this software is provided 'as-is', without any express or implied", "Zlib"},
+	{"this is free and unencumbered software released into the public domain", "Unlicense"},
+}
+
+// gnuLicenses are the titles of the GNU licenses. Each names the others
+// in its text, so the one whose title comes first is the license.
+var gnuLicenses = []struct {
+	title    string
+	family   string
+	versions map[string]bool
+	fallback string
+}{
+	{"gnu affero general public license", "AGPL", map[string]bool{"1.0": true, "3.0": true}, "3.0"},
+	{"gnu lesser general public license", "LGPL", map[string]bool{"2.1": true, "3.0": true}, "3.0"},
+	{"gnu library general public license", "LGPL", map[string]bool{"2.0": true}, "2.0"},
+	{"gnu general public license", "GPL", map[string]bool{"1.0": true, "2.0": true, "3.0": true}, "3.0"},
+}
+
+var (
+	gnuVersionPattern = regexp.MustCompile(`version (\d(?:\.\d)?)\b`)
+	unlicensePattern  = regexp.MustCompile(`\bunlicense\b`)
+)
+
+// Detect returns the SPDX identifier of the license a license file holds,
+// or UNKNOWN.
+func Detect(licenseText string) string {
+	// License files are often hard-wrapped or indented
+	text := strings.Join(strings.Fields(strings.ToLower(licenseText)), " ")
+
+	for _, p := range patterns {
+		if strings.Contains(text, p.pattern) {
+			return p.license
+		}
+	}
+
+	if license := detectGNU(text); license != "" {
+		return license
+	}
+	if unlicensePattern.MatchString(text) {
+		return "Unlicense"
+	}
+
+	return "UNKNOWN"
+}
+
+// detectGNU returns the GNU license of a normalised text, with the version
+// stated after its title, as in "Version 2.1, February 1999" or "either
+// version 2 of the License". The GPL with the Classpath exception is told
+// apart from the GPL.
+func detectGNU(text string) string {
+	first := -1
+	for i, gnu := range gnuLicenses {
+		index := strings.Index(text, gnu.title)
+		if index >= 0 && (first < 0 || index < strings.Index(text, gnuLicenses[first].title)) {
+			first = i
+		}
+	}
+	if first < 0 {
+		return ""
+	}
+	gnu := gnuLicenses[first]
+
+	if gnu.family == "GPL" && (strings.Contains(text, "classpath exception") || strings.Contains(text, `"classpath" exception`)) {
+		return "GPL-2.0-with-classpath-exception"
+	}
+
+	version := gnu.fallback
+	title := strings.Index(text, gnu.title) + len(gnu.title)
+	window := text[title:min(len(text), title+300)]
+	if match := gnuVersionPattern.FindStringSubmatch(window); match != nil {
+		stated := match[1]
+		if !strings.Contains(stated, ".") {
+			stated += ".0"
+		}
+		if gnu.versions[stated] {
+			version = stated
+		}
+	}
+
+	return gnu.family + "-" + version
+}
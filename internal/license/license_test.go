@@ -0,0 +1,100 @@
+package license
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	testCases := []struct {
+		text     string
+		expected string
+	}{
+		{"MIT License\n\nCopyright (c) 2024 Example", "MIT"},
+		{"Permission is hereby granted, free of charge, to any person", "MIT"},
+		{"Apache License\n   Version 2.0, January 2004", "Apache-2.0"},
+		{"Licensed under the Apache License, Version 2.0 (the \"License\")", "Apache-2.0"},
+		{"Boost Software License - Version 1.0 - August 17th, 2003", "BSL-1.0"},
+		{"Redistribution and use in source and binary forms, with or without\nmodification, are permitted. Neither the name of", "BSD-3-Clause"},
+		{"Redistribution and use in source and binary forms, with or without modification", "BSD-2-Clause"},
+		{"ISC License\n\nPermission to use, copy, modify, and/or distribute this software", "ISC"},
+		{"This software is provided 'as-is', without any express or implied\nwarranty.", "Zlib"},
+		{"This is free and unencumbered software released into the public domain.", "Unlicense"},
+		{"Ruby is copyrighted free software by Yukihiro Matsumoto.\nRedistribution and use in source and binary forms", "Ruby"},
+		{"                 GNU GENERAL PUBLIC LICENSE\n                    Version 2, June 1991", "GPL-2.0"},
+		// The GPL 3 recommends the LGPL near its end
+		{"GNU GENERAL PUBLIC LICENSE Version 3, 29 June 2007 ... consider it more useful to permit linking proprietary applications with the library. If this is what you want to do, use the GNU Lesser General Public License instead of this License.", "GPL-3.0"},
+		{"GNU LESSER GENERAL PUBLIC LICENSE\nVersion 2.1, February 1999", "LGPL-2.1"},
+		{"GNU LESSER GENERAL PUBLIC LICENSE\nVersion 3, 29 June 2007\n\nThis version of the GNU Lesser General Public License incorporates version 3 of the GNU General Public License", "LGPL-3.0"},
+		{"GNU LIBRARY GENERAL PUBLIC LICENSE\nVersion 2, June 1991", "LGPL-2.0"},
+		{"GNU AFFERO GENERAL PUBLIC LICENSE\nVersion 3, 19 November 2007", "AGPL-3.0"},
+		{"GNU General Public License, version 2, with the Classpath Exception", "GPL-2.0-with-classpath-exception"},
+		{"GNU General Public License", "GPL-3.0"},
+		// The MPL 2.0 names the GNU licenses as secondary licenses
+		{"Mozilla Public License Version 2.0\n... the GNU General Public License, Version 2.0, the GNU Lesser General Public License, Version 2.1", "MPL-2.0"},
+		{"MOZILLA PUBLIC LICENSE\nVersion 1.1", "MPL-1.1"},
+		{"Licensed under the Unlicense", "Unlicense"},
+		{"Unlicensed copying is prohibited.", "UNKNOWN"},
+		{"All rights reserved.", "UNKNOWN"},
+		{"", "UNKNOWN"},
+	}
+
+	for _, tc := range testCases {
+		if result := Detect(tc.text); result != tc.expected {
+			t.Errorf("Detect(%q) = %s, expected %s", tc.text, result, tc.expected)
+		}
+	}
+}
+
+func TestIsFile(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected bool
+	}{
+		{"LICENSE", true},
+		{"license.md", true},
+		{"LICENSE-MIT", true},
+		{"LICENSE_APACHE.txt", true},
+		{"COPYING.LIB", true},
+		{"UNLICENSE", true},
+		{"Licence.rst", true},
+		{"MIT-LICENSE", true},
+		{"COPYRIGHT", true},
+		{"licenses.go", false},
+		{"license.py", false},
+		{"license.json", false},
+		{"LICENSES", false},
+		{"COPYINGS.txt", false},
+		{"README.md", false},
+	}
+
+	for _, tc := range testCases {
+		if result := IsFile(tc.name); result != tc.expected {
+			t.Errorf("IsFile(%s) = %v, expected %v", tc.name, result, tc.expected)
+		}
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	dir := t.TempDir()
+	for name, text := range map[string]string{
+		"COPYRIGHT":   "Copyright 2024 Example",
+		"LICENSE.txt": "MIT License",
+		"license.go":  "package license",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if text := ReadFile(dir); text != "MIT License" {
+		t.Errorf("ReadFile = %q, expected the LICENSE.txt text", text)
+	}
+	if files := ReadFiles(dir); len(files) != 2 || files[0].Name != "COPYRIGHT" || files[1].Name != "LICENSE.txt" {
+		t.Errorf("ReadFiles = %+v, expected COPYRIGHT and LICENSE.txt", files)
+	}
+	if text := ReadFile(filepath.Join(dir, "missing")); text != "" {
+		t.Errorf("ReadFile of a missing directory = %q, expected none", text)
+	}
+}
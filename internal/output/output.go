@@ -137,6 +137,10 @@ func (f *MarkdownFormatter) writeIssues(sb *strings.Builder, issues []types.Audi
 			issue.Dependency.Name, issue.Dependency.Version, issue.Dependency.PackageType))
 		sb.WriteString(fmt.Sprintf("- **File:** %s\n", issue.Dependency.FilePath))
 
+		if len(issue.Dependency.Files) > 0 {
+			sb.WriteString(fmt.Sprintf("- **Files:** %s\n", strings.Join(issue.Dependency.Files, ", ")))
+		}
+
 		if issue.Suggestion != "" {
 			sb.WriteString(fmt.Sprintf("- **Suggestion:** %s\n", issue.Suggestion))
 		}
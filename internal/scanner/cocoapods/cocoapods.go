@@ -0,0 +1,224 @@
+package cocoapods
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"license-audit/pkg/types"
+)
+
+type Scanner struct {
+	// Home is the CocoaPods home holding the spec repos. It defaults to
+	// $CP_HOME_DIR, then ~/.cocoapods.
+	Home string
+
+	podspecCache map[string]*Podspec
+}
+
+// PodfileLock is a Podfile.lock file.
+type PodfileLock struct {
+	Pods            []interface{}                `yaml:"PODS"` // "Name (version)" or {"Name (version)": [requirements]}
+	Dependencies    []string                     `yaml:"DEPENDENCIES"`
+	SpecRepos       map[string][]string          `yaml:"SPEC REPOS"`
+	ExternalSources map[string]map[string]string `yaml:"EXTERNAL SOURCES"`
+	CheckoutOptions map[string]map[string]string `yaml:"CHECKOUT OPTIONS"`
+	SpecChecksums   map[string]string            `yaml:"SPEC CHECKSUMS"`
+}
+
+// trunkRepos are the names and URLs of the public spec repo, whose pods
+// need no source annotation.
+var trunkRepos = map[string]bool{
+	"trunk":                                  true,
+	"https://github.com/CocoaPods/Specs.git": true,
+	"https://cdn.cocoapods.org/":             true,
+}
+
+// podPattern matches "Name (version)" and "Name/Subspec (requirement)".
+var podPattern = regexp.MustCompile(`^\s*([^\s(]+)(?:\s+\(([^)]*)\))?\s*$`)
+
+func NewScanner() *Scanner {
+	return &Scanner{}
+}
+
+func (s *Scanner) Name() string {
+	return "cocoapods"
+}
+
+func (s *Scanner) Detect(path string) bool {
+	return filepath.Base(path) == "Podfile.lock"
+}
+
+func (s *Scanner) Scan(path string) ([]types.Dependency, error) {
+	dependencies, err := s.scanPodfileLock(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s.applyPodLicenses(filepath.Dir(path), dependencies)
+	return dependencies, nil
+}
+
+// scanPodfileLock reports each locked pod once. Subspecs such as
+// Firebase/Analytics belong to their root pod, which carries the license.
+func (s *Scanner) scanPodfileLock(path string) ([]types.Dependency, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Podfile.lock: %w", err)
+	}
+
+	var lockFile PodfileLock
+	if err := yaml.Unmarshal(data, &lockFile); err != nil {
+		return nil, fmt.Errorf("failed to parse Podfile.lock: %w", err)
+	}
+
+	direct := make(map[string]bool)
+	for _, requirement := range lockFile.Dependencies {
+		name, _ := parsePod(requirement)
+		direct[rootName(name)] = true
+	}
+
+	specRepos := make(map[string]string)
+	for repo, pods := range lockFile.SpecRepos {
+		for _, pod := range pods {
+			specRepos[pod] = repo
+		}
+	}
+
+	var dependencies []types.Dependency
+	index := make(map[string]int)
+
+	for _, entry := range lockFile.Pods {
+		spec, requirements := podEntry(entry)
+		name, version := parsePod(spec)
+		if name == "" {
+			continue
+		}
+		root := rootName(name)
+
+		i, ok := index[root]
+		if !ok {
+			dep := s.newDependency(root, version, path)
+			dep.Indirect = !direct[root]
+			if checksum := lockFile.SpecChecksums[root]; checksum != "" {
+				dep.Hashes = []string{"sha1:" + checksum}
+			}
+			if repo := specRepos[root]; repo != "" && !trunkRepos[repo] {
+				dep.Source = "registry"
+				dep.SourceURL = repo
+			}
+			applyExternalSource(&dep, lockFile.ExternalSources[root], lockFile.CheckoutOptions[root])
+
+			i = len(dependencies)
+			index[root] = i
+			dependencies = append(dependencies, dep)
+		}
+
+		for _, requirement := range requirements {
+			required, _ := parsePod(requirement)
+			if required = rootName(required); required != "" && required != root && !containsString(dependencies[i].Requires, required) {
+				dependencies[i].Requires = append(dependencies[i].Requires, required)
+			}
+		}
+	}
+
+	for i := range dependencies {
+		sort.Strings(dependencies[i].Requires)
+	}
+
+	return dependencies, nil
+}
+
+// applyExternalSource records pods installed from a path or a git
+// repository instead of a spec repo.
+func applyExternalSource(dep *types.Dependency, source, checkout map[string]string) {
+	switch {
+	case source[":path"] != "":
+		dep.Source = "path"
+		dep.SourceURL = source[":path"]
+	case source[":git"] != "":
+		dep.Source = "git"
+		dep.SourceURL = source[":git"]
+		for _, options := range []map[string]string{checkout, source} {
+			if ref := first(options[":commit"], options[":tag"], options[":branch"]); ref != "" {
+				dep.SourceURL += "#" + ref
+				break
+			}
+		}
+	case source[":podspec"] != "":
+		dep.Source = "podspec"
+		dep.SourceURL = source[":podspec"]
+	}
+}
+
+// podEntry splits a PODS entry into the pod and its requirements.
+func podEntry(entry interface{}) (string, []string) {
+	switch v := entry.(type) {
+	case string:
+		return v, nil
+	case map[string]interface{}:
+		for spec, value := range v {
+			var requirements []string
+			if list, ok := value.([]interface{}); ok {
+				for _, item := range list {
+					if requirement, ok := item.(string); ok {
+						requirements = append(requirements, requirement)
+					}
+				}
+			}
+			return spec, requirements
+		}
+	}
+	return "", nil
+}
+
+// parsePod splits "Name (version)" into its parts. DEPENDENCIES entries
+// may carry a requirement such as "~> 5.8" or "from `../Local`".
+func parsePod(spec string) (string, string) {
+	match := podPattern.FindStringSubmatch(spec)
+	if match == nil {
+		return "", ""
+	}
+	return match[1], match[2]
+}
+
+func rootName(name string) string {
+	root, _, _ := strings.Cut(name, "/")
+	return root
+}
+
+func (s *Scanner) newDependency(name, version, filePath string) types.Dependency {
+	if version == "" {
+		version = "UNKNOWN"
+	}
+
+	return types.Dependency{
+		Name:        name,
+		Version:     version,
+		LicenseType: "UNKNOWN",
+		PackageType: "cocoapods",
+		FilePath:    filePath,
+	}
+}
+
+func first(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
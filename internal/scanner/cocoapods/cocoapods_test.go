@@ -0,0 +1,108 @@
+package cocoapods
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+const fixturesDir = "../../../test/fixtures/cocoapods"
+
+func TestDetect(t *testing.T) {
+	scanner := NewScanner()
+
+	testCases := []struct {
+		path     string
+		expected bool
+	}{
+		{"Podfile.lock", true},
+		{"ios/Podfile.lock", true},
+		{"Podfile", false},
+		{"Package.resolved", false},
+		{"", false},
+	}
+
+	for _, tc := range testCases {
+		result := scanner.Detect(tc.path)
+		if result != tc.expected {
+			t.Errorf("Detect(%s) = %v, expected %v", tc.path, result, tc.expected)
+		}
+	}
+}
+
+func TestScanPodfileLock(t *testing.T) {
+	scanner := NewScanner()
+	scanner.Home = filepath.Join(fixturesDir, "cocoapods-home")
+
+	dependencies, err := scanner.Scan(filepath.Join(fixturesDir, "Podfile.lock"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	type expectation struct {
+		version  string
+		source   string
+		indirect bool
+		requires int
+		license  string
+	}
+
+	expected := map[string]expectation{
+		"AcmeCore":          {"1.2.0", "registry", true, 0, "MIT"},     // Ruby podspec in a private spec repo
+		"AcmeUI":            {"0.4.0", "path", false, 1, "Apache-2.0"}, // Pods/Local Podspecs
+		"Alamofire":         {"5.8.1", "", false, 0, "MIT"},            // LICENSE under Pods/
+		"Firebase":          {"10.15.0", "", false, 2, "Apache-2.0"},   // trunk podspec
+		"FirebaseAnalytics": {"10.15.0", "", true, 0, "UNKNOWN"},
+		"FirebaseCore":      {"10.15.0", "", true, 0, "UNKNOWN"},
+		"SwiftyJSON":        {"5.0.1", "git", false, 0, "UNKNOWN"},
+	}
+
+	if len(dependencies) != len(expected) {
+		t.Errorf("Expected %d dependencies, got %d", len(expected), len(dependencies))
+	}
+
+	for _, dep := range dependencies {
+		want, ok := expected[dep.Name]
+		if !ok {
+			t.Errorf("Unexpected dependency '%s'", dep.Name)
+			continue
+		}
+		got := expectation{dep.Version, dep.Source, dep.Indirect, len(dep.Requires), dep.LicenseType}
+		if got != want {
+			t.Errorf("%s = %+v, expected %+v", dep.Name, got, want)
+		}
+		if len(dep.Hashes) != 1 {
+			t.Errorf("Expected a spec checksum for %s, got %v", dep.Name, dep.Hashes)
+		}
+
+		switch dep.Name {
+		case "AcmeUI":
+			if dep.LicenseText == "" {
+				t.Errorf("Expected the license file of AcmeUI from its path")
+			}
+		case "SwiftyJSON":
+			if dep.SourceURL != "https://github.com/SwiftyJSON/SwiftyJSON.git#5.0.1" {
+				t.Errorf("Unexpected source URL for SwiftyJSON: %s", dep.SourceURL)
+			}
+		}
+	}
+}
+
+func TestParsePod(t *testing.T) {
+	testCases := []struct {
+		spec            string
+		expectedName    string
+		expectedVersion string
+	}{
+		{"Alamofire (5.8.1)", "Alamofire", "5.8.1"},
+		{"Firebase/Analytics", "Firebase/Analytics", ""},
+		{"FirebaseAnalytics (~> 10.15.0)", "FirebaseAnalytics", "~> 10.15.0"},
+		{"AcmeUI (from `LocalPods/AcmeUI`)", "AcmeUI", "from `LocalPods/AcmeUI`"},
+	}
+
+	for _, tc := range testCases {
+		name, version := parsePod(tc.spec)
+		if name != tc.expectedName || version != tc.expectedVersion {
+			t.Errorf("parsePod(%s) = (%s, %s), expected (%s, %s)", tc.spec, name, version, tc.expectedName, tc.expectedVersion)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package cocoapods
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"license-audit/internal/license"
+	"license-audit/pkg/types"
+)
+
+// Podspec is the license metadata of a pod, read from a .podspec.json or
+// statically from a Ruby .podspec.
+type Podspec struct {
+	Name     string      `json:"name"`
+	Version  string      `json:"version"`
+	License  interface{} `json:"license"` // a name or {type, file, text}
+	Homepage string      `json:"homepage"`
+}
+
+var (
+	podspecLicenseTypePattern = regexp.MustCompile(`\.license\s*=\s*\{[^}]*?(?::type\s*=>|\btype:)\s*['"]([^'"]+)['"]`)
+	podspecLicenseFilePattern = regexp.MustCompile(`\.license\s*=\s*\{[^}]*?(?::file\s*=>|\bfile:)\s*['"]([^'"]+)['"]`)
+	podspecLicensePattern     = regexp.MustCompile(`\.license\s*=\s*['"]([^'"]+)['"]`)
+	podspecHomepagePattern    = regexp.MustCompile(`\.homepage\s*=\s*['"]([^'"]+)['"]`)
+)
+
+// licenseNames maps license names common in podspecs to SPDX identifiers.
+var licenseNames = map[string]string{
+	"mit":                         "MIT",
+	"apache":                      "Apache-2.0",
+	"apache 2":                    "Apache-2.0",
+	"apache 2.0":                  "Apache-2.0",
+	"apache-2":                    "Apache-2.0",
+	"apache license, version 2.0": "Apache-2.0",
+	"apache license 2.0":          "Apache-2.0",
+	"bsd":                         "BSD-3-Clause",
+	"new bsd":                     "BSD-3-Clause",
+	"bsd 3-clause":                "BSD-3-Clause",
+	"bsd 2-clause":                "BSD-2-Clause",
+	"zlib":                        "Zlib",
+}
+
+// applyPodLicenses fills in licenses from the podspecs of the locked pods:
+// those CocoaPods stored for external sources under Pods/Local Podspecs,
+// then those in the spec repos, and finally the license files of the
+// installed pods under Pods/.
+func (s *Scanner) applyPodLicenses(dir string, dependencies []types.Dependency) {
+	podsDir := filepath.Join(dir, "Pods")
+
+	for i := range dependencies {
+		dep := &dependencies[i]
+		if dep.LicenseType != "UNKNOWN" && dep.LicenseType != "" {
+			continue
+		}
+
+		installDir := filepath.Join(podsDir, dep.Name)
+		if dep.Source == "path" {
+			installDir = filepath.Join(dir, filepath.FromSlash(dep.SourceURL))
+		}
+
+		if podspec := s.findPodspec(podsDir, dep.Name, dep.Version); podspec != nil {
+			licenseType, licenseFile, licenseText := podspec.license()
+			dep.LicenseType = licenseType
+			if licenseText == "" && licenseFile != "" {
+				if data, err := os.ReadFile(filepath.Join(installDir, filepath.FromSlash(licenseFile))); err == nil {
+					licenseText = string(data)
+				}
+			}
+			dep.LicenseText = licenseText
+			if dep.Homepage == "" {
+				dep.Homepage = podspec.Homepage
+			}
+		}
+
+		if dep.LicenseText == "" {
+			dep.LicenseText = license.ReadFile(installDir)
+		}
+		if dep.LicenseType == "UNKNOWN" && dep.LicenseText != "" {
+			dep.LicenseType = license.Detect(dep.LicenseText)
+		}
+	}
+}
+
+func (s *Scanner) findPodspec(podsDir, name, version string) *Podspec {
+	candidates := []string{
+		filepath.Join(podsDir, "Local Podspecs", name+".podspec.json"),
+	}
+
+	if home := s.home(); home != "" {
+		// The trunk repo shards specs by the first characters of the MD5
+		// of the pod name; private repos usually keep them flat
+		sum := md5.Sum([]byte(name))
+		shard := hex.EncodeToString(sum[:])
+		for _, specs := range []string{
+			filepath.Join(home, "repos", "*", "Specs", shard[0:1], shard[1:2], shard[2:3], name, version),
+			filepath.Join(home, "repos", "*", "Specs", name, version),
+			filepath.Join(home, "repos", "*", name, version),
+		} {
+			for _, ext := range []string{".podspec.json", ".podspec"} {
+				matches, _ := filepath.Glob(filepath.Join(specs, name+ext))
+				candidates = append(candidates, matches...)
+			}
+		}
+	}
+
+	for _, candidate := range candidates {
+		if podspec := s.readPodspec(candidate); podspec != nil && podspec.Name == name {
+			return podspec
+		}
+	}
+	return nil
+}
+
+func (s *Scanner) home() string {
+	if s.Home != "" {
+		return s.Home
+	}
+	if home := os.Getenv("CP_HOME_DIR"); home != "" {
+		return home
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".cocoapods")
+}
+
+func (s *Scanner) readPodspec(path string) *Podspec {
+	if cached, ok := s.podspecCache[path]; ok {
+		return cached
+	}
+
+	var podspec *Podspec
+	if data, err := os.ReadFile(path); err == nil {
+		if strings.HasSuffix(path, ".json") {
+			var spec Podspec
+			if json.Unmarshal(data, &spec) == nil {
+				podspec = &spec
+			}
+		} else {
+			podspec = parseRubyPodspec(path, string(data))
+		}
+	}
+
+	if s.podspecCache == nil {
+		s.podspecCache = make(map[string]*Podspec)
+	}
+	s.podspecCache[path] = podspec
+	return podspec
+}
+
+// parseRubyPodspec reads the license and homepage of a Ruby podspec
+// without evaluating it. The pod is named after the file.
+func parseRubyPodspec(path, source string) *Podspec {
+	spec := &Podspec{Name: strings.TrimSuffix(filepath.Base(path), ".podspec")}
+
+	license := make(map[string]interface{})
+	if match := podspecLicenseTypePattern.FindStringSubmatch(source); match != nil {
+		license["type"] = match[1]
+	} else if match := podspecLicensePattern.FindStringSubmatch(source); match != nil {
+		license["type"] = match[1]
+	}
+	if match := podspecLicenseFilePattern.FindStringSubmatch(source); match != nil {
+		license["file"] = match[1]
+	}
+	if len(license) > 0 {
+		spec.License = license
+	}
+
+	if match := podspecHomepagePattern.FindStringSubmatch(source); match != nil {
+		spec.Homepage = match[1]
+	}
+	return spec
+}
+
+// license returns the license type, license file and inline license text
+// of a podspec.
+func (p *Podspec) license() (string, string, string) {
+	var name, file, text string
+
+	switch v := p.License.(type) {
+	case string:
+		name = v
+	case map[string]interface{}:
+		name, _ = v["type"].(string)
+		file, _ = v["file"].(string)
+		text, _ = v["text"].(string)
+	}
+
+	licenseType := "UNKNOWN"
+	if name = strings.TrimSpace(name); name != "" {
+		licenseType = name
+		if spdx, ok := licenseNames[strings.ToLower(name)]; ok {
+			licenseType = spdx
+		}
+	} else if text != "" {
+		licenseType = license.Detect(text)
+	}
+
+	return licenseType, file, text
+}
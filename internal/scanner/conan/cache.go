@@ -0,0 +1,213 @@
+package conan
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"license-audit/internal/license"
+	"license-audit/pkg/types"
+)
+
+// Recipe is the license of a package found in a Conan cache: the license
+// attribute of its exported recipe, and the files its package() step
+// copied to licenses/.
+type Recipe struct {
+	LicenseType string
+	LicenseText string
+	Homepage    string
+	Repository  string
+}
+
+// ConanData is the conandata.yml exported with a recipe. Recipes that
+// build several versions, as ConanCenter's do, key their sources by
+// version instead of setting a version attribute.
+type ConanData struct {
+	Sources map[string]interface{} `yaml:"sources"`
+}
+
+// applyCacheLicenses fills in licenses from the recipes and packages in
+// the local Conan caches.
+func (s *Scanner) applyCacheLicenses(dependencies []types.Dependency) {
+	for i := range dependencies {
+		dep := &dependencies[i]
+		if dep.Scope == "project" {
+			continue
+		}
+
+		recipe := s.findRecipe(dep.Name, dep.Version)
+		if recipe == nil {
+			continue
+		}
+		if dep.LicenseType == "UNKNOWN" || dep.LicenseType == "" {
+			dep.LicenseType = recipe.LicenseType
+		}
+		if dep.LicenseText == "" {
+			dep.LicenseText = recipe.LicenseText
+		}
+		if dep.Homepage == "" {
+			dep.Homepage = recipe.Homepage
+		}
+		if dep.Repository == "" {
+			dep.Repository = recipe.Repository
+		}
+	}
+}
+
+// findRecipe looks name/version up in each cache. A Conan 1 cache stores
+// packages under data/<name>/<version>/<user>/<channel>; a Conan 2 cache
+// under hashed folders in p/, found through the recipes' name attributes.
+func (s *Scanner) findRecipe(name, version string) *Recipe {
+	for _, home := range s.homes() {
+		matches, _ := filepath.Glob(filepath.Join(home, "data", name, version, "*", "*"))
+		for _, match := range matches {
+			if recipe := s.readRecipe(filepath.Join(match, "export"), filepath.Join(match, "package", "*", "licenses")); recipe != nil {
+				return recipe
+			}
+		}
+
+		for _, export := range s.conan2Exports(home, name, version) {
+			if recipe := s.readRecipe(export, ""); recipe != nil {
+				return recipe
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Scanner) homes() []string {
+	if s.Home != "" {
+		return []string{s.Home}
+	}
+
+	var homes []string
+	home, _ := os.UserHomeDir()
+	if conanHome := os.Getenv("CONAN_HOME"); conanHome != "" {
+		homes = append(homes, conanHome)
+	} else if home != "" {
+		homes = append(homes, filepath.Join(home, ".conan2"))
+	}
+	if userHome := os.Getenv("CONAN_USER_HOME"); userHome != "" {
+		homes = append(homes, filepath.Join(userHome, ".conan"))
+	} else if home != "" {
+		homes = append(homes, filepath.Join(home, ".conan"))
+	}
+	return homes
+}
+
+// conan2Exports returns the export folders of a Conan 2 cache holding the
+// recipe for name/version.
+func (s *Scanner) conan2Exports(home, name, version string) []string {
+	if !s.indexedHomes[home] {
+		if s.indexedHomes == nil {
+			s.indexedHomes = make(map[string]bool)
+			s.conan2Index = make(map[string][]string)
+		}
+		s.indexedHomes[home] = true
+
+		matches, _ := filepath.Glob(filepath.Join(home, "p", "*", "e", "conanfile.py"))
+		for _, match := range matches {
+			if conanfile, err := readConanfilePy(match); err == nil && conanfile.Name != "" {
+				key := home + "\x00" + conanfile.Name
+				s.conan2Index[key] = append(s.conan2Index[key], filepath.Dir(match))
+			}
+		}
+	}
+
+	candidates := s.conan2Index[home+"\x00"+name]
+	var exports []string
+	for _, export := range candidates {
+		if exportVersions(export)[version] {
+			exports = append(exports, export)
+		}
+	}
+
+	// A lone recipe that does not say which versions it builds is taken
+	// as the one
+	if len(exports) == 0 && len(candidates) == 1 && len(exportVersions(candidates[0])) == 0 {
+		return candidates
+	}
+	return exports
+}
+
+// exportVersions returns the versions an exported recipe declares, by its
+// version attribute or its conandata.yml sources.
+func exportVersions(export string) map[string]bool {
+	versions := make(map[string]bool)
+
+	if conanfile, err := readConanfilePy(filepath.Join(export, "conanfile.py")); err == nil && conanfile.Version != "" {
+		versions[conanfile.Version] = true
+	}
+
+	if data, err := os.ReadFile(filepath.Join(export, "conandata.yml")); err == nil {
+		var conanData ConanData
+		if yaml.Unmarshal(data, &conanData) == nil {
+			for version := range conanData.Sources {
+				versions[version] = true
+			}
+		}
+	}
+
+	return versions
+}
+
+// readRecipe reads the conanfile.py in an export folder and the first file
+// in the license folders matching licensesPattern, if any.
+func (s *Scanner) readRecipe(export, licensesPattern string) *Recipe {
+	key := export + "\x00" + licensesPattern
+	if recipe, ok := s.recipeCache[key]; ok {
+		return recipe
+	}
+
+	var recipe *Recipe
+	conanfile, err := readConanfilePy(filepath.Join(export, "conanfile.py"))
+	if err == nil {
+		recipe = &Recipe{
+			LicenseType: "UNKNOWN",
+			Homepage:    conanfile.Homepage,
+			Repository:  conanfile.URL,
+		}
+
+		if licensesPattern != "" {
+			licenseDirs, _ := filepath.Glob(licensesPattern)
+			for _, licenseDir := range licenseDirs {
+				if recipe.LicenseText = readLicenseDir(licenseDir); recipe.LicenseText != "" {
+					break
+				}
+			}
+		}
+
+		switch {
+		case len(conanfile.Licenses) > 0:
+			recipe.LicenseType = strings.Join(conanfile.Licenses, " OR ")
+		case recipe.LicenseText != "":
+			recipe.LicenseType = license.Detect(recipe.LicenseText)
+		}
+	}
+
+	if s.recipeCache == nil {
+		s.recipeCache = make(map[string]*Recipe)
+	}
+	s.recipeCache[key] = recipe
+	return recipe
+}
+
+// readLicenseDir returns the text of the first file in a package's
+// licenses/ folder. Recipes copy license files there under their
+// original names, sometimes in subfolders.
+func readLicenseDir(licenseDir string) string {
+	var text string
+	filepath.WalkDir(licenseDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return nil
+		}
+		if data, err := os.ReadFile(path); err == nil && len(data) > 0 {
+			text = string(data)
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return text
+}
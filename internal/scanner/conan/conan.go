@@ -0,0 +1,111 @@
+package conan
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"license-audit/pkg/types"
+)
+
+type Scanner struct {
+	// Home is the Conan cache to read recipe licenses from. It defaults to
+	// $CONAN_HOME or ~/.conan2 for Conan 2, and $CONAN_USER_HOME/.conan or
+	// ~/.conan for Conan 1; both are searched.
+	Home string
+
+	recipeCache  map[string]*Recipe
+	conan2Index  map[string][]string // home and recipe name -> export folders in a Conan 2 cache
+	indexedHomes map[string]bool
+}
+
+// Reference is a Conan reference: name/version[@user/channel][#revision].
+type Reference struct {
+	Name     string
+	Version  string
+	User     string
+	Channel  string
+	Revision string
+}
+
+func NewScanner() *Scanner {
+	return &Scanner{}
+}
+
+func (s *Scanner) Name() string {
+	return "conan"
+}
+
+func (s *Scanner) Detect(path string) bool {
+	fileName := filepath.Base(path)
+	return fileName == "conanfile.txt" || fileName == "conanfile.py" || fileName == "conan.lock"
+}
+
+func (s *Scanner) Scan(path string) ([]types.Dependency, error) {
+	dependencies, err := s.scanFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s.applyCacheLicenses(dependencies)
+	return dependencies, nil
+}
+
+func (s *Scanner) scanFile(path string) ([]types.Dependency, error) {
+	fileName := filepath.Base(path)
+
+	switch fileName {
+	case "conanfile.txt":
+		return s.scanConanfileTxt(path)
+	case "conanfile.py":
+		return s.scanConanfilePy(path)
+	case "conan.lock":
+		return s.scanConanLock(path)
+	default:
+		return nil, fmt.Errorf("unsupported conan file: %s", fileName)
+	}
+}
+
+func (s *Scanner) newDependency(ref Reference, filePath string) types.Dependency {
+	version := ref.Version
+	if version == "" {
+		version = "UNKNOWN"
+	}
+
+	return types.Dependency{
+		Name:        ref.Name,
+		Version:     version,
+		LicenseType: "UNKNOWN",
+		PackageType: "conan",
+		FilePath:    filePath,
+	}
+}
+
+// parseReference parses a reference as written in conanfiles and
+// lockfiles. Lockfiles append a %timestamp to the revision, and version
+// ranges are written in brackets: zlib/[>=1.2 <2].
+func parseReference(text string) (Reference, bool) {
+	text = strings.TrimSpace(text)
+	if i := strings.Index(text, "%"); i >= 0 {
+		text = text[:i]
+	}
+
+	var ref Reference
+	if i := strings.Index(text, "#"); i >= 0 {
+		ref.Revision = text[i+1:]
+		text = text[:i]
+	}
+	if i := strings.Index(text, "@"); i >= 0 {
+		ref.User, ref.Channel, _ = strings.Cut(text[i+1:], "/")
+		text = text[:i]
+	}
+
+	name, version, ok := strings.Cut(text, "/")
+	if !ok || name == "" {
+		return Reference{}, false
+	}
+	ref.Name = name
+	ref.Version = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(version, "["), "]"))
+
+	return ref, true
+}
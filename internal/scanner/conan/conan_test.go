@@ -0,0 +1,237 @@
+package conan
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const fixturesDir = "../../../test/fixtures/conan"
+
+func newTestScanner() *Scanner {
+	scanner := NewScanner()
+	scanner.Home = filepath.Join(fixturesDir, "conan-home")
+	return scanner
+}
+
+func TestDetect(t *testing.T) {
+	scanner := NewScanner()
+
+	testCases := []struct {
+		path     string
+		expected bool
+	}{
+		{"conanfile.txt", true},
+		{"conanfile.py", true},
+		{"conan.lock", true},
+		{"libs/core/conanfile.py", true},
+		{"conandata.yml", false},
+		{"conanprofile.txt", false},
+		{"", false},
+	}
+
+	for _, tc := range testCases {
+		result := scanner.Detect(tc.path)
+		if result != tc.expected {
+			t.Errorf("Detect(%s) = %v, expected %v", tc.path, result, tc.expected)
+		}
+	}
+}
+
+func TestParseReference(t *testing.T) {
+	testCases := []struct {
+		text     string
+		expected Reference
+	}{
+		{"zlib/1.2.13", Reference{Name: "zlib", Version: "1.2.13"}},
+		{"openssl/[>=3.0 <4]", Reference{Name: "openssl", Version: ">=3.0 <4"}},
+		{"mylib/2.0.0@acme/stable", Reference{Name: "mylib", Version: "2.0.0", User: "acme", Channel: "stable"}},
+		{"zlib/1.2.13#97d5730b529b4224045fe7090592d4c1%1692672717.68", Reference{Name: "zlib", Version: "1.2.13", Revision: "97d5730b529b4224045fe7090592d4c1"}},
+	}
+
+	for _, tc := range testCases {
+		ref, ok := parseReference(tc.text)
+		if !ok || ref != tc.expected {
+			t.Errorf("parseReference(%s) = %+v, expected %+v", tc.text, ref, tc.expected)
+		}
+	}
+
+	if _, ok := parseReference("CMakeDeps"); ok {
+		t.Errorf("Expected a generator name not to parse as a reference")
+	}
+}
+
+func TestScanConanfileTxt(t *testing.T) {
+	scanner := newTestScanner()
+
+	dependencies, err := scanner.Scan(filepath.Join(fixturesDir, "txt", "conanfile.txt"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	type expectation struct {
+		version string
+		scope   string
+		license string
+	}
+
+	expected := map[string]expectation{
+		"zlib":    {"1.2.13", "", "Zlib"},
+		"fmt":     {"10.1.1", "", "MIT"}, // Conan 2 cache
+		"openssl": {">=3.0 <4", "", "UNKNOWN"},
+		"mylib":   {"2.0.0", "", "Proprietary"}, // Conan 1 cache, user/channel
+		"cmake":   {"3.27.1", "build", "UNKNOWN"},
+		"gtest":   {"1.14.0", "test", "UNKNOWN"},
+	}
+
+	if len(dependencies) != len(expected) {
+		t.Errorf("Expected %d dependencies, got %d", len(expected), len(dependencies))
+	}
+
+	for _, dep := range dependencies {
+		want, ok := expected[dep.Name]
+		if !ok {
+			t.Errorf("Unexpected dependency '%s'", dep.Name)
+			continue
+		}
+		got := expectation{dep.Version, dep.Scope, dep.LicenseType}
+		if got != want {
+			t.Errorf("%s = %+v, expected %+v", dep.Name, got, want)
+		}
+
+		switch dep.Name {
+		case "zlib":
+			if dep.LicenseText == "" {
+				t.Errorf("Expected the license text from the zlib package's licenses folder")
+			}
+			if dep.Homepage != "https://zlib.net" {
+				t.Errorf("Expected the homepage from the zlib recipe, got %s", dep.Homepage)
+			}
+		case "fmt":
+			if dep.Homepage != "https://github.com/fmtlib/fmt" {
+				t.Errorf("Expected the homepage from the fmt recipe, got %s", dep.Homepage)
+			}
+		}
+	}
+}
+
+func TestScanConanfilePy(t *testing.T) {
+	scanner := newTestScanner()
+
+	dependencies, err := scanner.Scan(filepath.Join(fixturesDir, "recipe", "conanfile.py"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	type expectation struct {
+		version string
+		scope   string
+		license string
+	}
+
+	expected := map[string]expectation{
+		"acme-engine": {"3.2.0", "project", "BSL-1.0 OR MIT"},
+		"zlib":        {"1.2.13", "", "Zlib"},
+		"fmt":         {"10.1.1", "", "MIT"},
+		"ninja":       {"1.11.1", "build", "UNKNOWN"},
+		"spdlog":      {"1.12.0", "", "UNKNOWN"},
+		"winreg":      {"0.3.0", "", "UNKNOWN"},
+		"catch2":      {"3.4.0", "test", "UNKNOWN"},
+	}
+
+	if len(dependencies) != len(expected) {
+		t.Errorf("Expected %d dependencies, got %d", len(expected), len(dependencies))
+	}
+
+	for _, dep := range dependencies {
+		want, ok := expected[dep.Name]
+		if !ok {
+			t.Errorf("Unexpected dependency '%s'", dep.Name)
+			continue
+		}
+		got := expectation{dep.Version, dep.Scope, dep.LicenseType}
+		if got != want {
+			t.Errorf("%s = %+v, expected %+v", dep.Name, got, want)
+		}
+
+		if dep.Name == "acme-engine" && dep.Repository != "https://github.com/acme/engine" {
+			t.Errorf("Unexpected repository for acme-engine: %s", dep.Repository)
+		}
+	}
+}
+
+func TestScanConanLock(t *testing.T) {
+	scanner := newTestScanner()
+
+	type expectation struct {
+		version  string
+		scope    string
+		indirect bool
+	}
+
+	testCases := []struct {
+		name     string
+		path     string
+		expected map[string]expectation
+	}{
+		{
+			name: "Conan 2",
+			path: filepath.Join(fixturesDir, "txt", "conan.lock"),
+			expected: map[string]expectation{
+				"zlib":    {"1.2.13", "", false},
+				"openssl": {"3.1.4", "", false},
+				"mylib":   {"2.0.0", "", false},
+				"gtest":   {"1.14.0", "test", false},
+				"fmt":     {"10.1.1", "", false},
+				"bzip2":   {"1.0.8", "", true},
+				"cmake":   {"3.27.1", "build", false},
+			},
+		},
+		{
+			name: "Conan 1 graph lock",
+			path: filepath.Join(fixturesDir, "v1", "conan.lock"),
+			expected: map[string]expectation{
+				"zlib":    {"1.2.13", "", false},
+				"libpng":  {"1.6.40", "", false},
+				"cmake":   {"3.27.1", "build", false},
+				"openssl": {"3.1.4", "build", true},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dependencies, err := scanner.Scan(tc.path)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if len(dependencies) != len(tc.expected) {
+				t.Errorf("Expected %d dependencies, got %d", len(tc.expected), len(dependencies))
+			}
+
+			for _, dep := range dependencies {
+				want, ok := tc.expected[dep.Name]
+				if !ok {
+					t.Errorf("Unexpected dependency '%s'", dep.Name)
+					continue
+				}
+				got := expectation{dep.Version, dep.Scope, dep.Indirect}
+				if got != want {
+					t.Errorf("%s = %+v, expected %+v", dep.Name, got, want)
+				}
+
+				switch dep.Name {
+				case "zlib":
+					if dep.LicenseType != "Zlib" {
+						t.Errorf("Expected the zlib license from the cache, got %s", dep.LicenseType)
+					}
+				case "libpng":
+					if !reflect.DeepEqual(dep.Requires, []string{"zlib"}) {
+						t.Errorf("Unexpected requirements for libpng: %v", dep.Requires)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package conan
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"license-audit/pkg/types"
+)
+
+// Conanfile is what a conanfile.txt or a static reading of a conanfile.py
+// declares.
+type Conanfile struct {
+	Name         string
+	Version      string
+	Licenses     []string
+	Homepage     string
+	URL          string
+	Requirements []Requirement
+}
+
+// Requirement is one requires, tool_requires or test_requires entry.
+type Requirement struct {
+	Reference Reference
+	Scope     string
+}
+
+// requirementScopes maps requirement kinds, as conanfile.txt sections or
+// conanfile.py attributes and methods, to scopes.
+var requirementScopes = map[string]string{
+	"requires":       "",
+	"tool_requires":  "build",
+	"build_requires": "build",
+	"test_requires":  "test",
+}
+
+var (
+	recipeAttributePattern  = regexp.MustCompile(`(?m)^[ \t]+(name|version|license|homepage|url|requires|tool_requires|build_requires|test_requires)[ \t]*=[ \t]*`)
+	requirementCallPattern  = regexp.MustCompile(`self\.(requires|tool_requires|build_requires|test_requires)\(\s*f?["']([^"']+)["']`)
+	quotedStringPattern     = regexp.MustCompile(`["']([^"']*)["']`)
+	conanfileSectionPattern = regexp.MustCompile(`^\[(\w+)\]$`)
+)
+
+func readConanfile(path string) (*Conanfile, error) {
+	switch filepath.Base(path) {
+	case "conanfile.py":
+		return readConanfilePy(path)
+	default:
+		return readConanfileTxt(path)
+	}
+}
+
+// readConanfileTxt reads the [requires], [tool_requires] and
+// [test_requires] sections of a conanfile.txt.
+func readConanfileTxt(path string) (*Conanfile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read conanfile.txt: %w", err)
+	}
+	defer file.Close()
+
+	conanfile := &Conanfile{}
+	section := ""
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if match := conanfileSectionPattern.FindStringSubmatch(line); match != nil {
+			section = match[1]
+			continue
+		}
+
+		scope, ok := requirementScopes[section]
+		if !ok {
+			continue
+		}
+		if ref, ok := parseReference(line); ok {
+			conanfile.Requirements = append(conanfile.Requirements, Requirement{Reference: ref, Scope: scope})
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read conanfile.txt: %w", err)
+	}
+
+	return conanfile, nil
+}
+
+// readConanfilePy reads a conanfile.py without running it: the literal
+// class attributes and the self.requires("...") style calls. References
+// built at run time, such as f-strings, are skipped.
+func readConanfilePy(path string) (*Conanfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read conanfile.py: %w", err)
+	}
+	content := string(data)
+
+	conanfile := &Conanfile{}
+	addRequirement := func(kind, text string) {
+		if strings.Contains(text, "{") {
+			return
+		}
+		if ref, ok := parseReference(text); ok {
+			conanfile.Requirements = append(conanfile.Requirements, Requirement{Reference: ref, Scope: requirementScopes[kind]})
+		}
+	}
+
+	for _, match := range recipeAttributePattern.FindAllStringSubmatchIndex(content, -1) {
+		attribute := content[match[2]:match[3]]
+		var values []string
+		for _, quoted := range quotedStringPattern.FindAllStringSubmatch(statementValue(content, match[1]), -1) {
+			values = append(values, quoted[1])
+		}
+		if len(values) == 0 {
+			continue
+		}
+
+		switch attribute {
+		case "name":
+			conanfile.Name = values[0]
+		case "version":
+			conanfile.Version = values[0]
+		case "license":
+			conanfile.Licenses = values
+		case "homepage":
+			conanfile.Homepage = values[0]
+		case "url":
+			conanfile.URL = values[0]
+		default:
+			for _, value := range values {
+				addRequirement(attribute, value)
+			}
+		}
+	}
+
+	for _, match := range requirementCallPattern.FindAllStringSubmatch(content, -1) {
+		addRequirement(match[1], match[2])
+	}
+
+	return conanfile, nil
+}
+
+// statementValue returns the right-hand side of an assignment starting at
+// offset: a bracketed tuple or list, which may span lines, or the rest of
+// the line.
+func statementValue(content string, offset int) string {
+	if offset >= len(content) {
+		return ""
+	}
+
+	if open := content[offset]; open == '(' || open == '[' {
+		depth := 0
+		for i := offset; i < len(content); i++ {
+			switch content[i] {
+			case '(', '[':
+				depth++
+			case ')', ']':
+				depth--
+				if depth == 0 {
+					return content[offset : i+1]
+				}
+			}
+		}
+		return content[offset:]
+	}
+
+	end := strings.IndexByte(content[offset:], '\n')
+	if end < 0 {
+		return content[offset:]
+	}
+	value := content[offset : offset+end]
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = value[:i]
+	}
+	return value
+}
+
+func (s *Scanner) scanConanfileTxt(path string) ([]types.Dependency, error) {
+	conanfile, err := readConanfileTxt(path)
+	if err != nil {
+		return nil, err
+	}
+	return s.conanfileDependencies(conanfile, path), nil
+}
+
+// scanConanfilePy reports the recipe itself when it is named, followed by
+// its requirements.
+func (s *Scanner) scanConanfilePy(path string) ([]types.Dependency, error) {
+	conanfile, err := readConanfilePy(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var dependencies []types.Dependency
+	if conanfile.Name != "" {
+		project := s.newDependency(Reference{Name: conanfile.Name, Version: conanfile.Version}, path)
+		project.Scope = "project"
+		project.Homepage = conanfile.Homepage
+		project.Repository = conanfile.URL
+		if len(conanfile.Licenses) > 0 {
+			project.LicenseType = strings.Join(conanfile.Licenses, " OR ")
+		}
+		dependencies = append(dependencies, project)
+	}
+
+	return append(dependencies, s.conanfileDependencies(conanfile, path)...), nil
+}
+
+func (s *Scanner) conanfileDependencies(conanfile *Conanfile, path string) []types.Dependency {
+	var dependencies []types.Dependency
+	seen := make(map[string]bool)
+
+	for _, requirement := range conanfile.Requirements {
+		if seen[requirement.Reference.Name] {
+			continue
+		}
+		seen[requirement.Reference.Name] = true
+
+		dep := s.newDependency(requirement.Reference, path)
+		dep.Scope = requirement.Scope
+		dependencies = append(dependencies, dep)
+	}
+
+	return dependencies
+}
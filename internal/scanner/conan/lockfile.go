@@ -0,0 +1,146 @@
+package conan
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"license-audit/pkg/types"
+)
+
+// ConanLock is a conan.lock file. Conan 2 lists references by kind;
+// Conan 1 records the whole graph under graph_lock.
+type ConanLock struct {
+	Version        string    `json:"version"`
+	Requires       []string  `json:"requires"`
+	BuildRequires  []string  `json:"build_requires"`
+	PythonRequires []string  `json:"python_requires"`
+	GraphLock      GraphLock `json:"graph_lock"`
+}
+
+type GraphLock struct {
+	Nodes map[string]GraphNode `json:"nodes"`
+}
+
+type GraphNode struct {
+	Ref           string   `json:"ref"`
+	Path          string   `json:"path"`
+	Context       string   `json:"context"` // host or build
+	Requires      []string `json:"requires"`
+	BuildRequires []string `json:"build_requires"`
+}
+
+func (s *Scanner) scanConanLock(path string) ([]types.Dependency, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read conan.lock: %w", err)
+	}
+
+	var lockFile ConanLock
+	if err := json.Unmarshal(data, &lockFile); err != nil {
+		return nil, fmt.Errorf("failed to parse conan.lock: %w", err)
+	}
+
+	if len(lockFile.GraphLock.Nodes) > 0 {
+		return s.scanGraphLock(lockFile.GraphLock, path), nil
+	}
+
+	// A Conan 2 lockfile does not say which requirements are direct; the
+	// conanfile next to it does, and with which scope, as test
+	// requirements are locked with the rest
+	var direct map[string]string
+	for _, name := range []string{"conanfile.py", "conanfile.txt"} {
+		if conanfile, err := readConanfile(filepath.Join(filepath.Dir(path), name)); err == nil {
+			direct = make(map[string]string)
+			for _, requirement := range conanfile.Requirements {
+				direct[requirement.Reference.Name] = requirement.Scope
+			}
+			break
+		}
+	}
+
+	var dependencies []types.Dependency
+	seen := make(map[string]bool)
+	for _, group := range []struct {
+		refs  []string
+		scope string
+	}{
+		{lockFile.Requires, ""},
+		{lockFile.BuildRequires, "build"},
+		{lockFile.PythonRequires, "build"},
+	} {
+		for _, text := range group.refs {
+			ref, ok := parseReference(text)
+			if !ok || seen[ref.Name+"/"+ref.Version] {
+				continue
+			}
+			seen[ref.Name+"/"+ref.Version] = true
+
+			dep := s.newDependency(ref, path)
+			dep.Scope = group.scope
+			if scope, ok := direct[ref.Name]; ok {
+				if dep.Scope == "" {
+					dep.Scope = scope
+				}
+			} else {
+				dep.Indirect = direct != nil
+			}
+			dependencies = append(dependencies, dep)
+		}
+	}
+
+	return dependencies, nil
+}
+
+// scanGraphLock reports the nodes of a Conan 1 graph lock. Node 0 is the
+// consumer; what it requires is direct, and nodes built for the build
+// context or reached through build_requires are build tools.
+func (s *Scanner) scanGraphLock(graph GraphLock, path string) []types.Dependency {
+	root := graph.Nodes["0"]
+	direct := make(map[string]bool)
+	for _, id := range append(root.Requires, root.BuildRequires...) {
+		direct[id] = true
+	}
+
+	build := make(map[string]bool)
+	for _, node := range graph.Nodes {
+		for _, id := range node.BuildRequires {
+			build[id] = true
+		}
+	}
+
+	ids := make([]string, 0, len(graph.Nodes))
+	for id := range graph.Nodes {
+		if id != "0" {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	var dependencies []types.Dependency
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		node := graph.Nodes[id]
+		ref, ok := parseReference(node.Ref)
+		if !ok || seen[ref.Name+"/"+ref.Version] {
+			continue
+		}
+		seen[ref.Name+"/"+ref.Version] = true
+
+		dep := s.newDependency(ref, path)
+		if node.Context == "build" || build[id] {
+			dep.Scope = "build"
+		}
+		dep.Indirect = !direct[id]
+		for _, required := range node.Requires {
+			if requiredRef, ok := parseReference(graph.Nodes[required].Ref); ok {
+				dep.Requires = append(dep.Requires, requiredRef.Name)
+			}
+		}
+		dependencies = append(dependencies, dep)
+	}
+
+	return dependencies
+}
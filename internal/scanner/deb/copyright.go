@@ -0,0 +1,172 @@
+package deb
+
+import (
+	"regexp"
+	"strings"
+
+	"license-audit/internal/license"
+)
+
+// Copyright is a package's copyright file. Machine-readable (DEP-5) files
+// are split into their Files stanzas; others are kept as text.
+type Copyright struct {
+	MachineReadable bool
+	Files           []FilesStanza
+	Licenses        map[string]string // texts of the standalone License stanzas, by short name
+	Text            string
+}
+
+// FilesStanza gives the copyright and license of the files its patterns
+// match.
+type FilesStanza struct {
+	Files     []string // glob patterns, e.g. * or src/compat/*
+	Copyright string
+	License   string // SPDX expression
+}
+
+// debianLicenses maps the short names of machine-readable Debian
+// copyright files that differ from their SPDX identifiers.
+var debianLicenses = map[string]string{
+	"expat":         "MIT",
+	"bsd-2-clause":  "BSD-2-Clause",
+	"bsd-3-clause":  "BSD-3-Clause",
+	"bsd-4-clause":  "BSD-4-Clause",
+	"apache-2.0":    "Apache-2.0",
+	"artistic":      "Artistic-1.0",
+	"artistic-2.0":  "Artistic-2.0",
+	"mpl-1.1":       "MPL-1.1",
+	"mpl-2.0":       "MPL-2.0",
+	"isc":           "ISC",
+	"zlib":          "Zlib",
+	"cc0-1.0":       "CC0-1.0",
+	"public-domain": "LicenseRef-public-domain",
+}
+
+var (
+	gnuShortNamePattern  = regexp.MustCompile(`^(a?gpl|lgpl|gfdl)-?(\d(?:\.\d)?)(\+?)$`)
+	commonLicensePattern = regexp.MustCompile(`/usr/share/common-licenses/([A-Za-z0-9.+-]+)`)
+)
+
+// ParseCopyright reads a copyright file. A file is machine-readable when
+// its first stanza has a Format field, or Format-Specification in drafts
+// of the format.
+func ParseCopyright(data []byte) *Copyright {
+	copyright := &Copyright{Licenses: make(map[string]string), Text: string(data)}
+
+	paragraphs := ParseControl(data)
+	if len(paragraphs) == 0 || (paragraphs[0]["format"] == "" && paragraphs[0]["format-specification"] == "") {
+		return copyright
+	}
+	copyright.MachineReadable = true
+
+	for _, paragraph := range paragraphs[1:] {
+		// The first line of License is the short name, any others the text
+		shortName, text, _ := strings.Cut(paragraph["license"], "\n")
+		files, ok := paragraph["files"]
+		switch {
+		case ok:
+			copyright.Files = append(copyright.Files, FilesStanza{
+				Files:     strings.Fields(files),
+				Copyright: paragraph["copyright"],
+				License:   licenseExpression(shortName),
+			})
+		case shortName != "":
+			copyright.Licenses[shortName] = text
+		}
+	}
+
+	return copyright
+}
+
+// License returns the SPDX expression for the files a package ships: the
+// licenses of its Files stanzas, joined with AND, leaving out the stanzas
+// that only cover the debian/ packaging. Copyright files that are not
+// machine-readable usually point at a text in /usr/share/common-licenses.
+func (c *Copyright) License() string {
+	if !c.MachineReadable {
+		if match := commonLicensePattern.FindStringSubmatch(c.Text); match != nil {
+			return licenseExpression(strings.TrimSuffix(match[1], "."))
+		}
+		return license.Detect(c.Text)
+	}
+
+	var licenses []string
+	seen := make(map[string]bool)
+	for _, stanza := range c.Files {
+		if stanza.License == "" || seen[stanza.License] || isPackagingStanza(stanza) {
+			continue
+		}
+		seen[stanza.License] = true
+		licenses = append(licenses, stanza.License)
+	}
+
+	switch len(licenses) {
+	case 0:
+		return "UNKNOWN"
+	case 1:
+		return licenses[0]
+	}
+	for i, license := range licenses {
+		if strings.Contains(license, " OR ") {
+			licenses[i] = "(" + license + ")"
+		}
+	}
+	return strings.Join(licenses, " AND ")
+}
+
+// isPackagingStanza reports whether a Files stanza only covers the
+// debian/ directory, which binary packages do not ship.
+func isPackagingStanza(stanza FilesStanza) bool {
+	for _, pattern := range stanza.Files {
+		if !strings.HasPrefix(pattern, "debian/") {
+			return false
+		}
+	}
+	return len(stanza.Files) > 0
+}
+
+// licenseExpression converts the short names of a Debian License field,
+// such as "GPL-2+ or Artistic" or "GPL-2+ with OpenSSL exception", to an
+// SPDX expression.
+func licenseExpression(field string) string {
+	words := strings.Fields(strings.ReplaceAll(field, ",", " "))
+
+	var expression []string
+	for i := 0; i < len(words); i++ {
+		switch strings.ToLower(words[i]) {
+		case "or", "and":
+			expression = append(expression, strings.ToUpper(words[i]))
+		case "with":
+			// with <name> exception
+			var exception []string
+			for i++; i < len(words) && !strings.EqualFold(words[i], "exception"); i++ {
+				exception = append(exception, words[i])
+			}
+			expression = append(expression, "WITH", strings.Join(exception, "-")+"-exception")
+		default:
+			expression = append(expression, shortNameLicense(words[i]))
+		}
+	}
+	return strings.Join(expression, " ")
+}
+
+func shortNameLicense(name string) string {
+	lower := strings.ToLower(name)
+	if spdx, ok := debianLicenses[lower]; ok {
+		return spdx
+	}
+
+	if match := gnuShortNamePattern.FindStringSubmatch(lower); match != nil {
+		version := match[2]
+		if !strings.Contains(version, ".") {
+			version += ".0"
+		}
+		identifier := strings.ToUpper(match[1]) + "-" + version
+		if match[3] == "+" {
+			return identifier + "-or-later"
+		}
+		return identifier + "-only"
+	}
+
+	return name
+}
@@ -0,0 +1,128 @@
+package deb
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"license-audit/pkg/types"
+)
+
+// maxSymlinkHops bounds how many symbolic links are followed when reading
+// a file of a root filesystem.
+const maxSymlinkHops = 16
+
+// Scanner reports the packages installed in a Debian or Ubuntu root
+// filesystem, such as an unpacked VM image or rootfs build, from its dpkg
+// database and the copyright files in usr/share/doc.
+type Scanner struct{}
+
+func NewScanner() *Scanner {
+	return &Scanner{}
+}
+
+func (s *Scanner) Name() string {
+	return "deb"
+}
+
+// Detect accepts var/lib/dpkg/status, and the per-package status files
+// distroless images keep in var/lib/dpkg/status.d.
+func (s *Scanner) Detect(path string) bool {
+	_, ok := rootfs(path)
+	return ok
+}
+
+func (s *Scanner) Scan(path string) ([]types.Dependency, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dpkg status: %w", err)
+	}
+	root, _ := rootfs(path)
+
+	var dependencies []types.Dependency
+	for _, pkg := range ParseStatus(data) {
+		var copyright *Copyright
+		if text, err := ReadRootFile(root, CopyrightPath(pkg.Name)); err == nil {
+			copyright = ParseCopyright(text)
+		}
+		dependencies = append(dependencies, pkg.Dependency(path, copyright))
+	}
+
+	return dependencies, nil
+}
+
+// rootfs returns the root filesystem a dpkg status file belongs to.
+func rootfs(statusPath string) (string, bool) {
+	slashed := filepath.ToSlash(statusPath)
+	if root, ok := strings.CutSuffix(slashed, "/"+StatusFile); ok {
+		return filepath.FromSlash(root), true
+	}
+	if slashed == StatusFile {
+		return ".", true
+	}
+
+	// status.d entries sit next to their .md5sums and .list files
+	dir, name := path.Split(slashed)
+	if strings.HasSuffix(dir, StatusDir+"/") && !strings.Contains(name, ".") {
+		root := strings.TrimSuffix(strings.TrimSuffix(dir, StatusDir+"/"), "/")
+		if root == "" {
+			root = "."
+		}
+		return filepath.FromSlash(root), true
+	}
+
+	return "", false
+}
+
+// ReadRootFile reads a file of the root filesystem at root, resolving
+// symbolic links, including absolute ones, inside that root rather than
+// on the host.
+func ReadRootFile(root, name string) ([]byte, error) {
+	parts := strings.Split(name, "/")
+	resolved := ""
+
+	for hops := 0; len(parts) > 0; {
+		part := parts[0]
+		parts = parts[1:]
+
+		switch part {
+		case "", ".":
+			continue
+		case "..":
+			// The parent of the root is the root itself
+			if resolved = path.Dir(resolved); resolved == "." {
+				resolved = ""
+			}
+			continue
+		}
+
+		current := path.Join(resolved, part)
+		info, err := os.Lstat(filepath.Join(root, filepath.FromSlash(current)))
+		if err != nil {
+			return nil, err
+		}
+		if info.Mode()&os.ModeSymlink == 0 {
+			resolved = current
+			continue
+		}
+
+		if hops++; hops > maxSymlinkHops {
+			return nil, errors.New("too many levels of symbolic links")
+		}
+		target, err := os.Readlink(filepath.Join(root, filepath.FromSlash(current)))
+		if err != nil {
+			return nil, err
+		}
+		target = filepath.ToSlash(target)
+		if strings.HasPrefix(target, "/") {
+			resolved = ""
+		}
+		// Resolve the target's components in turn, from the link's directory
+		parts = append(strings.Split(target, "/"), parts...)
+	}
+
+	return os.ReadFile(filepath.Join(root, filepath.FromSlash(resolved)))
+}
@@ -0,0 +1,153 @@
+package deb
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const fixturesDir = "../../../test/fixtures/deb"
+
+func TestDetect(t *testing.T) {
+	scanner := NewScanner()
+
+	testCases := []struct {
+		path     string
+		expected bool
+	}{
+		{"rootfs/var/lib/dpkg/status", true},
+		{"var/lib/dpkg/status", true},
+		{"rootfs/var/lib/dpkg/status.d/libc6", true},
+		{"rootfs/var/lib/dpkg/status.d/libc6.md5sums", false},
+		{"rootfs/var/lib/dpkg/status-old", false},
+		{"rootfs/var/lib/dpkg/available", false},
+		{"status", false},
+	}
+
+	for _, tc := range testCases {
+		result := scanner.Detect(filepath.FromSlash(tc.path))
+		if result != tc.expected {
+			t.Errorf("Detect(%s) = %v, expected %v", tc.path, result, tc.expected)
+		}
+	}
+}
+
+func TestScan(t *testing.T) {
+	dependencies, err := NewScanner().Scan(filepath.Join(fixturesDir, "rootfs", "var", "lib", "dpkg", "status"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	type expectation struct {
+		version  string
+		license  string
+		platform string
+	}
+
+	expected := map[string]expectation{
+		"base-files":  {"12.4+deb12u5", "GPL", "amd64"},                                   // not machine-readable
+		"bash":        {"5.2.15-2+b2", "GPL-3.0-or-later AND GFDL-1.3-or-later", "amd64"}, // debian/* left out
+		"libssl3":     {"3.0.11-1~deb12u2", "Apache-2.0", "amd64"},                        // continued Copyright field
+		"libssl-dev":  {"3.0.11-1~deb12u2", "Apache-2.0", "amd64"},                        // absolute symlink inside the rootfs
+		"perl-base":   {"5.36.0-7+deb12u1", "(GPL-1.0-or-later OR Artistic-1.0) AND Zlib", "amd64"},
+		"libgcc-s1":   {"12.2.0-14", "GPL-3.0-or-later WITH GCC-Runtime-Library-exception", "amd64"}, // relative symlink
+		"gcc-12-base": {"12.2.0-14", "GPL-3.0-or-later WITH GCC-Runtime-Library-exception", "amd64"},
+		"tzdata":      {"2024a-0+deb12u1", "UNKNOWN", ""}, // no copyright file
+	}
+
+	for _, dep := range dependencies {
+		want, ok := expected[dep.Name]
+		if !ok {
+			t.Errorf("Unexpected dependency '%s'", dep.Name)
+			continue
+		}
+		got := expectation{dep.Version, dep.LicenseType, dep.Platform}
+		if got != want {
+			t.Errorf("%s = %+v, expected %+v", dep.Name, got, want)
+		}
+		if dep.PackageType != "deb" {
+			t.Errorf("%s has package type %s, expected deb", dep.Name, dep.PackageType)
+		}
+		if dep.Name == "bash" {
+			requires := []string{"libc6", "libtinfo6", "base-files", "debianutils"}
+			if !reflect.DeepEqual(dep.Requires, requires) {
+				t.Errorf("bash requires %v, expected %v", dep.Requires, requires)
+			}
+		}
+	}
+	if len(dependencies) != len(expected) {
+		t.Errorf("Expected %d dependencies, got %d", len(expected), len(dependencies))
+	}
+}
+
+func TestScanDistroless(t *testing.T) {
+	dependencies, err := NewScanner().Scan(filepath.Join(fixturesDir, "distroless", "var", "lib", "dpkg", "status.d", "libc6"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(dependencies) != 1 {
+		t.Fatalf("Expected 1 dependency, got %d", len(dependencies))
+	}
+	if dep := dependencies[0]; dep.Name != "libc6" || dep.Version != "2.36-9+deb12u4" || dep.LicenseType != "LGPL-2.1-or-later" {
+		t.Errorf("Unexpected dependency: %+v", dep)
+	}
+}
+
+func TestParseCopyright(t *testing.T) {
+	copyright := ParseCopyright([]byte(`Format: https://www.debian.org/doc/packaging-manuals/copyright-format/1.0/
+Upstream-Name: example
+
+Files: *
+Copyright: 2020 Acme
+ 2021 Example Corp
+License: GPL-2+ or Artistic
+
+# Bundled code
+Files: src/compat/* src/getopt.c
+Copyright: 1999 Someone
+License: BSD-3-clause
+
+License: BSD-3-clause
+ Redistribution and use in source and binary forms, with or without
+ modification, are permitted.
+`))
+
+	if !copyright.MachineReadable {
+		t.Fatalf("Expected a machine-readable copyright file")
+	}
+
+	expected := []FilesStanza{
+		{Files: []string{"*"}, Copyright: "2020 Acme\n2021 Example Corp", License: "GPL-2.0-or-later OR Artistic-1.0"},
+		{Files: []string{"src/compat/*", "src/getopt.c"}, Copyright: "1999 Someone", License: "BSD-3-Clause"},
+	}
+	if !reflect.DeepEqual(copyright.Files, expected) {
+		t.Errorf("Files = %+v, expected %+v", copyright.Files, expected)
+	}
+	if _, ok := copyright.Licenses["BSD-3-clause"]; !ok {
+		t.Errorf("Expected the BSD-3-clause license text")
+	}
+	if license := copyright.License(); license != "(GPL-2.0-or-later OR Artistic-1.0) AND BSD-3-Clause" {
+		t.Errorf("License() = %s", license)
+	}
+}
+
+func TestCopyrightLicense(t *testing.T) {
+	testCases := []struct {
+		copyright string
+		expected  string
+	}{
+		{"Format: dep5\n\nFiles: *\nLicense: LGPL-2.1\n\nFiles: src/*\nLicense: Expat\n", "LGPL-2.1-only AND MIT"},
+		{"Format-Specification: dep5\n\nFiles: *\nLicense: public-domain\n", "LicenseRef-public-domain"},
+		{"Format: dep5\n\nFiles: debian/*\nLicense: GPL-2+\n", "UNKNOWN"},
+		{"See /usr/share/common-licenses/LGPL-3.\n", "LGPL-3.0-only"},
+		{"Permission is hereby granted, free of charge, to any person\n", "MIT"},
+		{"All rights reserved.\n", "UNKNOWN"},
+	}
+
+	for _, tc := range testCases {
+		if result := ParseCopyright([]byte(tc.copyright)).License(); result != tc.expected {
+			t.Errorf("License(%q) = %s, expected %s", tc.copyright, result, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package deb
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+
+	"license-audit/pkg/types"
+)
+
+const (
+	// StatusFile is the dpkg database, relative to the root filesystem.
+	StatusFile = "var/lib/dpkg/status"
+	// StatusDir holds one status file per package in distroless images.
+	StatusDir = "var/lib/dpkg/status.d"
+)
+
+// Package is a package recorded in the dpkg database.
+type Package struct {
+	Name         string
+	Version      string
+	Architecture string
+	Source       string // source package, when named differently
+	Homepage     string
+	Status       string
+	Depends      []string // package names of Pre-Depends and Depends
+}
+
+// CopyrightPath returns where a package's copyright file is installed,
+// relative to the root filesystem.
+func CopyrightPath(name string) string {
+	return "usr/share/doc/" + name + "/copyright"
+}
+
+// ParseControl splits a Debian control file, such as the dpkg database or
+// a copyright file, into its paragraphs. Field names are lower-cased, and
+// continuation lines are appended to their field's value on their own
+// line.
+func ParseControl(data []byte) []map[string]string {
+	var paragraphs []map[string]string
+	paragraph := make(map[string]string)
+	field := ""
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.TrimSpace(line) == "":
+			if len(paragraph) > 0 {
+				paragraphs = append(paragraphs, paragraph)
+				paragraph = make(map[string]string)
+			}
+			field = ""
+		case line[0] == ' ' || line[0] == '\t':
+			if field != "" {
+				paragraph[field] += "\n" + strings.TrimSpace(line)
+			}
+		case line[0] == '#':
+			// Comments are allowed in copyright files
+		default:
+			name, value, ok := strings.Cut(line, ":")
+			if !ok {
+				continue
+			}
+			field = strings.ToLower(strings.TrimSpace(name))
+			paragraph[field] = strings.TrimSpace(value)
+		}
+	}
+	if len(paragraph) > 0 {
+		paragraphs = append(paragraphs, paragraph)
+	}
+
+	return paragraphs
+}
+
+// ParseStatus returns the packages installed according to a dpkg status
+// file. Removed packages stay in the database as config-files or
+// not-installed and are left out.
+func ParseStatus(data []byte) []Package {
+	var packages []Package
+	for _, paragraph := range ParseControl(data) {
+		name := paragraph["package"]
+		status := paragraph["status"]
+		if name == "" || (status != "" && !strings.HasSuffix(status, " installed")) {
+			continue
+		}
+
+		source, _, _ := strings.Cut(paragraph["source"], " ")
+		packages = append(packages, Package{
+			Name:         name,
+			Version:      paragraph["version"],
+			Architecture: paragraph["architecture"],
+			Source:       source,
+			Homepage:     paragraph["homepage"],
+			Status:       status,
+			Depends:      relationNames(paragraph["pre-depends"], paragraph["depends"]),
+		})
+	}
+	return packages
+}
+
+// relationNames returns the package names of relationship fields, taking
+// the first of each set of alternatives.
+func relationNames(fields ...string) []string {
+	var names []string
+	for _, field := range fields {
+		for _, relation := range strings.Split(field, ",") {
+			alternative, _, _ := strings.Cut(relation, "|")
+			words := strings.Fields(alternative)
+			if len(words) == 0 {
+				continue
+			}
+			name, _, _ := strings.Cut(words[0], ":")
+			name, _, _ = strings.Cut(name, "(")
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Dependency describes an installed package, with the license its
+// copyright file declares when there is one.
+func (p Package) Dependency(filePath string, copyright *Copyright) types.Dependency {
+	dep := types.Dependency{
+		Name:        p.Name,
+		Version:     p.Version,
+		LicenseType: "UNKNOWN",
+		Homepage:    p.Homepage,
+		PackageType: "deb",
+		FilePath:    filePath,
+		Requires:    p.Depends,
+	}
+	if dep.Version == "" {
+		dep.Version = "UNKNOWN"
+	}
+	if p.Architecture != "" && p.Architecture != "all" {
+		dep.Platform = p.Architecture
+	}
+	if copyright != nil {
+		dep.LicenseType = copyright.License()
+	}
+	return dep
+}
@@ -0,0 +1,143 @@
+package docker
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"license-audit/pkg/types"
+)
+
+// ComposeScanner reports the images the services of a Docker Compose file
+// run.
+type ComposeScanner struct{}
+
+type ComposeFile struct {
+	Services map[string]ComposeService `yaml:"services"`
+}
+
+type ComposeService struct {
+	Image string      `yaml:"image"`
+	Build interface{} `yaml:"build"`
+}
+
+// composeVariablePattern matches ${VAR}, ${VAR:-default}, ${VAR-default},
+// ${VAR:?error} and $VAR.
+var composeVariablePattern = regexp.MustCompile(`\$\{(\w+)(?:(:?[-?])([^}]*))?\}|\$(\w+)`)
+
+func NewComposeScanner() *ComposeScanner {
+	return &ComposeScanner{}
+}
+
+func (s *ComposeScanner) Name() string {
+	return "compose"
+}
+
+func (s *ComposeScanner) Detect(path string) bool {
+	return isComposeFile(filepath.Base(path))
+}
+
+// isComposeFile matches compose.yaml, docker-compose.yml and override
+// files such as docker-compose.prod.yml.
+func isComposeFile(fileName string) bool {
+	ext := filepath.Ext(fileName)
+	if ext != ".yml" && ext != ".yaml" {
+		return false
+	}
+	base := strings.TrimSuffix(fileName, ext)
+	for _, prefix := range []string{"docker-compose", "compose"} {
+		if base == prefix || strings.HasPrefix(base, prefix+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// Scan reports the image of each service, in service name order. Services
+// with a build section run an image built from the project, which the
+// Dockerfile scanner covers.
+func (s *ComposeScanner) Scan(path string) ([]types.Dependency, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read compose file: %w", err)
+	}
+
+	var compose ComposeFile
+	if err := yaml.Unmarshal(data, &compose); err != nil {
+		return nil, fmt.Errorf("failed to parse compose file: %w", err)
+	}
+
+	names := make([]string, 0, len(compose.Services))
+	for name := range compose.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	env := readDotEnv(filepath.Join(filepath.Dir(path), ".env"))
+	images := &imageCollector{filePath: path}
+	for _, name := range names {
+		service := compose.Services[name]
+		if service.Build != nil {
+			continue
+		}
+		images.add(interpolate(service.Image, env))
+	}
+
+	return images.dependencies, nil
+}
+
+// interpolate substitutes variables the way Compose does, from the .env
+// file next to the compose file, then the environment, then the default
+// given in the reference. Variables without a value are left in place.
+func interpolate(value string, env map[string]string) string {
+	return composeVariablePattern.ReplaceAllStringFunc(value, func(match string) string {
+		parts := composeVariablePattern.FindStringSubmatch(match)
+		name, operator, fallback := parts[1], parts[2], parts[3]
+		if name == "" {
+			name = parts[4]
+		}
+
+		resolved, ok := env[name]
+		if !ok {
+			resolved, ok = os.LookupEnv(name)
+		}
+		// ${VAR:-default} also applies the default to an empty value
+		if (!ok || (resolved == "" && strings.HasPrefix(operator, ":"))) && strings.HasSuffix(operator, "-") {
+			return fallback
+		}
+		if !ok {
+			return match
+		}
+		return resolved
+	})
+}
+
+// readDotEnv reads the KEY=value lines of a .env file.
+func readDotEnv(path string) map[string]string {
+	env := make(map[string]string)
+
+	file, err := os.Open(path)
+	if err != nil {
+		return env
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		if !ok {
+			continue
+		}
+		env[strings.TrimSpace(key)] = strings.Trim(strings.TrimSpace(value), `"'`)
+	}
+	return env
+}
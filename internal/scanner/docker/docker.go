@@ -1,11 +1,11 @@
 package docker
 
 import (
-	"bufio"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
-	"regexp"
+	"strconv"
 	"strings"
 
 	"license-audit/pkg/types"
@@ -13,6 +13,14 @@ import (
 
 type Scanner struct{}
 
+// stage is a build stage of a multi-stage Dockerfile.
+type stage struct {
+	name   string
+	parent int               // index of the stage this one is built FROM, or -1
+	args   map[string]string // ARG values in scope
+	env    map[string]string // ENV values, inherited by stages built on this one
+}
+
 func NewScanner() *Scanner {
 	return &Scanner{}
 }
@@ -27,6 +35,9 @@ func (s *Scanner) Detect(path string) bool {
 		strings.HasSuffix(fileName, ".dockerfile")
 }
 
+// Scan reports the base images of a Dockerfile, the images COPY --from
+// reads, and the packages RUN commands install. Everything a stage that
+// the final image is not built on brings in is a build dependency.
 func (s *Scanner) Scan(path string) ([]types.Dependency, error) {
 	file, err := os.Open(path)
 	if err != nil {
@@ -34,106 +45,262 @@ func (s *Scanner) Scan(path string) ([]types.Dependency, error) {
 	}
 	defer file.Close()
 
+	instructions, err := parseDockerfile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Dockerfile: %w", err)
+	}
+
+	var stages []*stage
+	var stageOf []int // the stage each dependency was found in
 	var dependencies []types.Dependency
-	scanner := bufio.NewScanner(file)
+	globalArgs := make(map[string]string)
 
-	// Regex patterns for different package managers in Docker
-	patterns := map[string]*regexp.Regexp{
-		"apt": regexp.MustCompile(`(?i)apt-get\s+install.*?(\S+)`),
-		"yum": regexp.MustCompile(`(?i)yum\s+install.*?(\S+)`),
-		"apk": regexp.MustCompile(`(?i)apk\s+add.*?(\S+)`),
-		"npm": regexp.MustCompile(`(?i)npm\s+install\s+(?:.*?)?(\S+)`),
-		"pip": regexp.MustCompile(`(?i)pip\s+install.*?(\S+)`),
-		"gem": regexp.MustCompile(`(?i)gem\s+install.*?(\S+)`),
+	add := func(dep types.Dependency) {
+		dependencies = append(dependencies, dep)
+		stageOf = append(stageOf, len(stages)-1)
 	}
 
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
+	for _, instruction := range instructions {
+		if instruction.Command == "FROM" {
+			current, image, platform := newStage(instruction.Args, globalArgs, stages)
+			stages = append(stages, current)
+			if image != "" {
+				add(s.imageDependency(image, platform, path))
+			}
+			continue
+		}
 
-		// Skip comments and empty lines
-		if line == "" || strings.HasPrefix(line, "#") {
+		if len(stages) == 0 {
+			// Only ARG may come before the first FROM
+			if instruction.Command == "ARG" {
+				declareArgs(instruction.Args, globalArgs, globalArgs, nil)
+			}
 			continue
 		}
 
-		// Look for RUN commands
-		if strings.HasPrefix(strings.ToUpper(line), "RUN ") {
-			lineDeps := s.parseRunCommand(line, patterns, path)
-			dependencies = append(dependencies, lineDeps...)
+		current := stages[len(stages)-1]
+		switch instruction.Command {
+		case "ARG":
+			declareArgs(instruction.Args, current.args, globalArgs, current.vars())
+		case "ENV":
+			setEnv(instruction.Args, current.env, current.vars())
+		case "RUN":
+			for _, pkg := range runPackages(instruction, current.vars()) {
+				add(newPackageDependency(pkg, path))
+			}
+		case "COPY", "ADD":
+			flags, _ := instructionFlags(instruction.Args)
+			from, ok := flags["from"]
+			if !ok || stageIndex(from, stages) >= 0 {
+				continue
+			}
+			if image, resolved := expandVariables(from, current.vars()); image != "" {
+				dep := s.imageDependency(image, "", path)
+				if !resolved {
+					dep.Version = "UNKNOWN"
+				}
+				add(dep)
+			}
 		}
+	}
 
-		// Look for FROM commands (base images)
-		if strings.HasPrefix(strings.ToUpper(line), "FROM ") {
-			if dep := s.parseFromCommand(line, path); dep.Name != "" {
-				dependencies = append(dependencies, dep)
+	// The final image is made of the last stage and those it is built on
+	runtime := make(map[int]bool)
+	for i := len(stages) - 1; i >= 0; i = stages[i].parent {
+		runtime[i] = true
+	}
+
+	var result []types.Dependency
+	seen := make(map[string]int)
+	for i, dep := range dependencies {
+		if !runtime[stageOf[i]] {
+			dep.Scope = "build"
+		}
+		key := dep.PackageType + "|" + dep.Name + "|" + dep.Version
+		if j, ok := seen[key]; ok {
+			if dep.Scope == "" {
+				result[j].Scope = ""
 			}
+			continue
 		}
+		seen[key] = len(result)
+		result = append(result, dep)
 	}
 
-	return dependencies, scanner.Err()
+	return result, nil
 }
 
-func (s *Scanner) parseRunCommand(line string, patterns map[string]*regexp.Regexp, filePath string) []types.Dependency {
-	var dependencies []types.Dependency
+// newStage starts the stage a FROM instruction opens, and returns the
+// external image it is based on, if any, with its platform. FROM may only
+// use the ARGs declared before the first FROM.
+func newStage(args string, globalArgs map[string]string, stages []*stage) (*stage, string, string) {
+	flags, args := instructionFlags(args)
+	fields := strings.Fields(args)
 
-	// Remove RUN prefix
-	command := strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(line, "RUN"), "run"))
+	current := &stage{
+		parent: -1,
+		args:   make(map[string]string),
+		env:    make(map[string]string),
+	}
+	if len(fields) >= 3 && strings.EqualFold(fields[len(fields)-2], "AS") {
+		current.name = strings.ToLower(fields[len(fields)-1])
+	}
+	if len(fields) == 0 {
+		return current, "", ""
+	}
 
-	for pkgManager, pattern := range patterns {
-		matches := pattern.FindAllStringSubmatch(command, -1)
-		for _, match := range matches {
-			if len(match) > 1 {
-				packageName := strings.TrimSpace(match[1])
+	image, resolved := expandVariables(fields[0], globalArgs)
+	if parent := stageIndex(image, stages); parent >= 0 {
+		current.parent = parent
+		for key, value := range stages[parent].env {
+			current.env[key] = value
+		}
+		return current, "", ""
+	}
+	if image == "scratch" || image == "" {
+		return current, "", ""
+	}
+	if !resolved {
+		// The tag or digest depends on a build argument without a default
+		image = parseImageReference(image).Name + ":UNKNOWN"
+	}
 
-				// Clean up package name
-				packageName = strings.Trim(packageName, "\"'")
-				if packageName == "" || strings.Contains(packageName, " ") {
-					continue
-				}
+	// Platforms set from automatic arguments such as $BUILDPLATFORM are
+	// only known at build time
+	platform, ok := expandVariables(flags["platform"], globalArgs)
+	if !ok {
+		platform = ""
+	}
+	return current, image, platform
+}
 
-				dep := types.Dependency{
-					Name:        packageName,
-					Version:     "UNKNOWN",
-					LicenseType: "UNKNOWN",
-					PackageType: pkgManager,
-					FilePath:    filePath,
-				}
+// stageIndex returns the index of the stage a FROM or --from= names, by
+// its AS name or its number, or -1 for an image.
+func stageIndex(name string, stages []*stage) int {
+	if i, err := strconv.Atoi(name); err == nil && i >= 0 && i < len(stages) {
+		return i
+	}
+	for i, stage := range stages {
+		if stage.name != "" && stage.name == strings.ToLower(name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// vars returns the variables in scope in a stage; ENV overrides ARG.
+func (st *stage) vars() map[string]string {
+	vars := make(map[string]string, len(st.args)+len(st.env))
+	for key, value := range st.args {
+		vars[key] = value
+	}
+	for key, value := range st.env {
+		vars[key] = value
+	}
+	return vars
+}
 
-				dependencies = append(dependencies, dep)
+// declareArgs records ARG NAME[=default] declarations. A stage ARG without
+// a default takes the value declared before the first FROM.
+func declareArgs(args string, scope, globalArgs, vars map[string]string) {
+	for _, words := range splitCommands(args, nil) {
+		for _, word := range words {
+			name, value, hasDefault := strings.Cut(word, "=")
+			if hasDefault {
+				if vars != nil {
+					value, _ = expandVariables(value, vars)
+				}
+				scope[name] = value
+			} else if global, ok := globalArgs[name]; ok {
+				scope[name] = global
 			}
 		}
 	}
+}
+
+// setEnv records ENV KEY=value pairs, or the legacy ENV KEY value form.
+func setEnv(args string, env, vars map[string]string) {
+	key, rest, _ := strings.Cut(args, " ")
+	if !strings.Contains(key, "=") {
+		value, _ := expandVariables(strings.TrimSpace(rest), vars)
+		env[key] = value
+		return
+	}
 
-	return dependencies
+	// Each value may use the variables set before it
+	scope := vars
+	for _, command := range splitCommands(strings.ReplaceAll(args, "$", "\\$"), nil) {
+		for _, word := range command {
+			name, value, ok := strings.Cut(word, "=")
+			if !ok {
+				continue
+			}
+			value, _ = expandVariables(value, scope)
+			env[name] = value
+			scope[name] = value
+		}
+	}
 }
 
-func (s *Scanner) parseFromCommand(line string, filePath string) types.Dependency {
-	parts := strings.Fields(line)
-	if len(parts) < 2 {
-		return types.Dependency{}
+// runPackages returns the packages a RUN instruction installs. The script
+// is the command line in shell form, the argument list in exec form, or
+// the heredoc body when the command line is empty or just a shell.
+func runPackages(instruction Instruction, vars map[string]string) []InstalledPackage {
+	_, args := instructionFlags(instruction.Args)
+
+	var commands [][]string
+	if strings.HasPrefix(args, "[") {
+		var words []string
+		if err := json.Unmarshal([]byte(args), &words); err == nil {
+			commands = [][]string{words}
+		}
+	}
+
+	if commands == nil {
+		script := args
+		if len(instruction.Heredocs) > 0 {
+			commandLine := strings.Fields(stripHeredocMarkers(args))
+			if len(commandLine) == 0 || isShell(commandLine[0]) {
+				script = strings.Join(instruction.Heredocs, "\n")
+			} else {
+				script = strings.Join(commandLine, " ")
+			}
+		}
+		commands = splitCommands(script, vars)
 	}
 
-	image := parts[1]
+	var packages []InstalledPackage
+	for _, command := range commands {
+		packages = append(packages, extractPackages(command)...)
+	}
+	return packages
+}
 
-	// Skip scratch and empty images
-	if image == "scratch" || image == "" {
-		return types.Dependency{}
+func isShell(command string) bool {
+	switch filepath.Base(command) {
+	case "sh", "bash", "ash", "dash", "zsh":
+		return true
 	}
+	return false
+}
 
-	name := image
-	version := "latest"
+func (s *Scanner) imageDependency(image, platform, filePath string) types.Dependency {
+	dep := newImageDependency(image, filePath)
+	dep.Platform = platform
+	return dep
+}
 
-	// Parse image:tag format
-	if strings.Contains(image, ":") {
-		parts := strings.SplitN(image, ":", 2)
-		name = parts[0]
-		version = parts[1]
+func newPackageDependency(pkg InstalledPackage, filePath string) types.Dependency {
+	version := pkg.Version
+	if version == "" {
+		version = "UNKNOWN"
 	}
 
 	return types.Dependency{
-		Name:        name,
+		Name:        pkg.Name,
 		Version:     version,
 		LicenseType: "UNKNOWN",
-		PackageType: "docker-image",
+		PackageType: pkg.Manager,
 		FilePath:    filePath,
 	}
 }
@@ -0,0 +1,428 @@
+package docker
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"license-audit/pkg/types"
+)
+
+const fixturesDir = "../../../test/fixtures/docker"
+
+type imageExpectation struct {
+	version string
+	hashes  []string
+}
+
+func checkImages(t *testing.T, dependencies []types.Dependency, expected map[string]imageExpectation) {
+	t.Helper()
+
+	images := 0
+	for _, dep := range dependencies {
+		if dep.PackageType != "docker-image" {
+			continue
+		}
+		images++
+
+		want, ok := expected[dep.Name]
+		if !ok {
+			t.Errorf("Unexpected image '%s'", dep.Name)
+			continue
+		}
+		if dep.Version != want.version || !reflect.DeepEqual(dep.Hashes, want.hashes) {
+			t.Errorf("%s = %s %v, expected %s %v", dep.Name, dep.Version, dep.Hashes, want.version, want.hashes)
+		}
+	}
+
+	if images != len(expected) {
+		t.Errorf("Expected %d images, got %d", len(expected), images)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	testCases := []struct {
+		scanner  interface{ Detect(string) bool }
+		path     string
+		expected bool
+	}{
+		{NewScanner(), "Dockerfile", true},
+		{NewScanner(), "build/api.dockerfile", true},
+		{NewComposeScanner(), "docker-compose.yml", true},
+		{NewComposeScanner(), "deploy/compose.yaml", true},
+		{NewComposeScanner(), "docker-compose.prod.yml", true},
+		{NewComposeScanner(), "composer.yaml", false},
+		{NewComposeScanner(), "docker-compose.json", false},
+		{NewKubernetesScanner(), "deploy/app.yaml", true},
+		{NewKubernetesScanner(), "deploy/app.yml", true},
+		{NewKubernetesScanner(), "docker-compose.yml", false},
+		{NewKubernetesScanner(), "pubspec.yaml", false},
+		{NewKubernetesScanner(), filepath.Join(fixturesDir, "chart", "shop", "templates", "deployment.yaml"), false},
+		{NewKubernetesScanner(), "deploy/app.json", false},
+		{NewHelmScanner(), "charts/shop/Chart.yaml", true},
+		{NewHelmScanner(), "charts/shop/Chart.lock", false},
+		{NewHelmScanner(), "", false},
+	}
+
+	for _, tc := range testCases {
+		result := tc.scanner.Detect(tc.path)
+		if result != tc.expected {
+			t.Errorf("%T.Detect(%s) = %v, expected %v", tc.scanner, tc.path, result, tc.expected)
+		}
+	}
+}
+
+func TestParseImageReference(t *testing.T) {
+	testCases := []struct {
+		image    string
+		expected ImageReference
+	}{
+		{"nginx", ImageReference{Name: "nginx"}},
+		{"nginx:1.25", ImageReference{Name: "nginx", Tag: "1.25"}},
+		{"localhost:5000/app", ImageReference{Name: "localhost:5000/app"}},
+		{"localhost:5000/app:2.0", ImageReference{Name: "localhost:5000/app", Tag: "2.0"}},
+		{"ghcr.io/acme/api:1.4.2@sha256:abc", ImageReference{Name: "ghcr.io/acme/api", Tag: "1.4.2", Digest: "sha256:abc"}},
+		{"redis@sha256:abc", ImageReference{Name: "redis", Digest: "sha256:abc"}},
+	}
+
+	for _, tc := range testCases {
+		if result := parseImageReference(tc.image); result != tc.expected {
+			t.Errorf("parseImageReference(%s) = %+v, expected %+v", tc.image, result, tc.expected)
+		}
+	}
+}
+
+func TestScanCompose(t *testing.T) {
+	dependencies, err := NewComposeScanner().Scan(filepath.Join(fixturesDir, "compose", "docker-compose.yml"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	digest := "sha256:3134997edb04277814aa51a4175a588d45eb4299272f8eff2307bbf8b39e4d43"
+	checkImages(t, dependencies, map[string]imageExpectation{
+		"postgres":                {"16.2-alpine", nil},
+		"redis":                   {digest, []string{digest}},
+		"docker.io/library/nginx": {"1.25.4", nil}, // default and .env
+		"localhost:5000/rabbitmq": {"3.13-management", nil},
+	})
+}
+
+func TestInterpolate(t *testing.T) {
+	env := map[string]string{"TAG": "1.0", "EMPTY": ""}
+
+	testCases := []struct {
+		value    string
+		expected string
+	}{
+		{"app:${TAG}", "app:1.0"},
+		{"app:$TAG", "app:1.0"},
+		{"app:${EMPTY:-2.0}", "app:2.0"},
+		{"app:${EMPTY-2.0}", "app:"},
+		{"app:${LICENSE_AUDIT_UNSET:-3.0}", "app:3.0"},
+		{"app:${LICENSE_AUDIT_UNSET}", "app:${LICENSE_AUDIT_UNSET}"},
+	}
+
+	for _, tc := range testCases {
+		if result := interpolate(tc.value, env); result != tc.expected {
+			t.Errorf("interpolate(%s) = %s, expected %s", tc.value, result, tc.expected)
+		}
+	}
+}
+
+func TestScanKubernetes(t *testing.T) {
+	scanner := NewKubernetesScanner()
+
+	dependencies, err := scanner.Scan(filepath.Join(fixturesDir, "k8s", "app.yaml"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	checkImages(t, dependencies, map[string]imageExpectation{
+		"ghcr.io/acme/migrate": {"2.0.1", nil}, // init container
+		"ghcr.io/acme/api":     {"1.4.2", nil},
+		"envoyproxy/envoy":     {"v1.29.1", nil},
+		"ghcr.io/acme/backup":  {"0.9.0", nil}, // CronJob
+	})
+
+	dependencies, err = scanner.Scan(filepath.Join(fixturesDir, "k8s", "list.yaml"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	checkImages(t, dependencies, map[string]imageExpectation{
+		"busybox":          {"latest", nil},
+		"ghcr.io/acme/api": {"1.4.2", nil},
+	})
+
+	// YAML files that are not workloads report nothing
+	dependencies, err = scanner.Scan(filepath.Join(fixturesDir, "k8s", "settings.yaml"))
+	if err != nil || len(dependencies) != 0 {
+		t.Errorf("Expected no dependencies from settings.yaml, got %d (%v)", len(dependencies), err)
+	}
+}
+
+func TestScanHelm(t *testing.T) {
+	dependencies, err := NewHelmScanner().Scan(filepath.Join(fixturesDir, "chart", "shop", "Chart.yaml"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	type expectation struct {
+		version string
+		scope   string
+		source  string
+		license string
+	}
+
+	expected := map[string]expectation{
+		"shop":                  {"1.2.0", "project", "", "Apache-2.0"},
+		"redis":                 {"17.3.14", "optional", "", "Apache-2.0"}, // from the packaged chart
+		"common":                {"0.1.0", "", "path", "MIT"},
+		"kube-prometheus-stack": {"55.5.0", "", "", "UNKNOWN"},
+	}
+
+	charts := 0
+	for _, dep := range dependencies {
+		if dep.PackageType != "helm-chart" {
+			continue
+		}
+		charts++
+
+		want, ok := expected[dep.Name]
+		if !ok {
+			t.Errorf("Unexpected chart '%s'", dep.Name)
+			continue
+		}
+		got := expectation{dep.Version, dep.Scope, dep.Source, dep.LicenseType}
+		if got != want {
+			t.Errorf("%s = %+v, expected %+v", dep.Name, got, want)
+		}
+
+		switch dep.Name {
+		case "shop":
+			if dep.Homepage != "https://shop.example.com" || dep.Repository != "https://github.com/acme/shop" {
+				t.Errorf("Unexpected shop links: %s, %s", dep.Homepage, dep.Repository)
+			}
+		case "kube-prometheus-stack":
+			if dep.Repository != "oci://ghcr.io/prometheus-community/charts" {
+				t.Errorf("Unexpected repository for kube-prometheus-stack: %s", dep.Repository)
+			}
+		}
+	}
+	if charts != len(expected) {
+		t.Errorf("Expected %d charts, got %d", len(expected), charts)
+	}
+
+	digest := "sha256:1b4f0e7f5b8c4c1c7d5a0c9e2e3f4a5b6c7d8e9f0a1b2c3d4e5f6a7b8c9d0e1f"
+	checkImages(t, dependencies, map[string]imageExpectation{
+		"ghcr.io/acme/shop":        {"3.4.1", nil}, // appVersion
+		"ghcr.io/acme/shop-worker": {"3.4.0", nil},
+		"prom/statsd-exporter":     {"v0.26.0", []string{digest}},
+	})
+}
+
+func TestParseDockerfile(t *testing.T) {
+	dockerfile := "# escape=`\n" +
+		"FROM alpine:3.19\n" +
+		"RUN apk add `\n" +
+		"    # a comment between continued lines\n" +
+		"    curl\n" +
+		"\n" +
+		"copy <<-EOT /etc/motd\n" +
+		"\thello\n" +
+		"\tEOT\n" +
+		"CMD [\"sh\"]\n"
+
+	instructions, err := parseDockerfile(strings.NewReader(dockerfile))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []Instruction{
+		{Command: "FROM", Args: "alpine:3.19", Line: 2},
+		{Command: "RUN", Args: "apk add     curl", Line: 3},
+		{Command: "COPY", Args: "<<-EOT /etc/motd", Heredocs: []string{"hello"}, Line: 7},
+		{Command: "CMD", Args: "[\"sh\"]", Line: 10},
+	}
+	if !reflect.DeepEqual(instructions, expected) {
+		t.Errorf("parseDockerfile() = %+v, expected %+v", instructions, expected)
+	}
+}
+
+func TestSplitCommands(t *testing.T) {
+	vars := map[string]string{"VERSION": "1.2"}
+
+	testCases := []struct {
+		script   string
+		expected [][]string
+	}{
+		{"apt-get update && apt-get install -y curl", [][]string{{"apt-get", "update"}, {"apt-get", "install", "-y", "curl"}}},
+		{"pip install \"app==$VERSION\" 'lib==$VERSION'", [][]string{{"pip", "install", "app==1.2", "lib==$VERSION"}}},
+		{"make install 2>/dev/null; echo ${MISSING:-done} > log", [][]string{{"make", "install"}, {"echo", "done"}}},
+		{"cd /src \\\n  && go install ./... # build", [][]string{{"cd", "/src"}, {"go", "install", "./..."}}},
+		{"apk add $(cat packages.txt) | tee out", [][]string{{"apk", "add", "$(cat packages.txt)"}, {"tee", "out"}}},
+	}
+
+	for _, tc := range testCases {
+		if result := splitCommands(tc.script, vars); !reflect.DeepEqual(result, tc.expected) {
+			t.Errorf("splitCommands(%q) = %q, expected %q", tc.script, result, tc.expected)
+		}
+	}
+}
+
+func TestExtractPackages(t *testing.T) {
+	testCases := []struct {
+		command  string
+		expected []InstalledPackage
+	}{
+		{"sudo apt-get install -y -o Dpkg::Options::=--force-confold nginx=1.22.1-9 libssl3/bookworm-backports",
+			[]InstalledPackage{{"apt", "nginx", "1.22.1-9"}, {"apt", "libssl3", ""}}},
+		{"apk add --virtual .build-deps gcc~13 musl-dev@edge",
+			[]InstalledPackage{{"apk", "gcc", "~13"}, {"apk", "musl-dev", ""}}},
+		{"dnf install -y --setopt=tsflags=nodocs httpd-2.4.57 @development",
+			[]InstalledPackage{{"dnf", "httpd", "2.4.57"}}},
+		{"python3 -m pip install -r requirements.txt Django~=5.0 ./local",
+			[]InstalledPackage{{"python", "Django", "~=5.0"}}},
+		{"pnpm add -g @scope/tool@1.0.0 acme/repo left-pad",
+			[]InstalledPackage{{"npm", "@scope/tool", "1.0.0"}, {"npm", "left-pad", ""}}},
+		{"gem install rails:7.1.3 --no-document",
+			[]InstalledPackage{{"ruby", "rails", "7.1.3"}}},
+		{"sh -c 'apk add jq && go install example.com/tool@v1.0.0'",
+			[]InstalledPackage{{"apk", "jq", ""}, {"go", "example.com/tool", "v1.0.0"}}},
+		{"apt-get update", nil},
+		{"npm ci", nil},
+	}
+
+	for _, tc := range testCases {
+		var result []InstalledPackage
+		for _, command := range splitCommands(tc.command, nil) {
+			result = append(result, extractPackages(command)...)
+		}
+		if !reflect.DeepEqual(result, tc.expected) {
+			t.Errorf("extractPackages(%s) = %+v, expected %+v", tc.command, result, tc.expected)
+		}
+	}
+}
+
+func TestScanDockerfile(t *testing.T) {
+	dependencies, err := NewScanner().Scan(filepath.Join(fixturesDir, "dockerfile", "app.dockerfile"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	type expectation struct {
+		packageType string
+		version     string
+		scope       string
+	}
+
+	expected := map[string]expectation{
+		// builder stage: the platform comes from a build-time argument
+		"golang":                          {"docker-image", "1.22.1-alpine", "build"},
+		"git":                             {"apk", "2.43.0-r0", "build"},
+		"build-base":                      {"apk", "UNKNOWN", "build"},
+		"ca-certificates":                 {"apk", "UNKNOWN", "build"},
+		"github.com/swaggo/swag/cmd/swag": {"go", "v1.16.3", "build"},
+		"golang.org/x/tools/cmd/stringer": {"go", "latest", "build"},
+		// assets stage
+		"node":               {"docker-image", "20.11-bookworm-slim", "build"},
+		"yarn":               {"npm", "1.22.19", "build"},
+		"@angular/cli":       {"npm", "17.2.0", "build"},
+		"typescript":         {"npm", "5.3.3", "build"},
+		"ghcr.io/acme/fonts": {"docker-image", "2.1", "build"},
+		// base stage, which the final stage is built on
+		"python":   {"docker-image", "3.12-slim", ""},
+		"libpq5":   {"apt", "15.6-0+deb12u1", ""},
+		"curl":     {"apt", "UNKNOWN", ""},
+		"pip":      {"python", "24.0", ""},
+		"gunicorn": {"python", "21.2.0", ""},
+		"uvicorn":  {"python", ">=0.27", ""},
+		"bundler":  {"ruby", "2.5.6", ""},
+		// final stage
+		"alpine":          {"docker-image", "3.19", ""},
+		"flask":           {"python", "3.0.2", ""},
+		"requests":        {"python", "UNKNOWN", ""},
+		"nodejs":          {"yum", "18.19.0", ""},
+		"java-17-openjdk": {"yum", "UNKNOWN", ""},
+	}
+
+	for _, dep := range dependencies {
+		want, ok := expected[dep.Name]
+		if !ok {
+			t.Errorf("Unexpected dependency '%s'", dep.Name)
+			continue
+		}
+		got := expectation{dep.PackageType, dep.Version, dep.Scope}
+		if got != want {
+			t.Errorf("%s = %+v, expected %+v", dep.Name, got, want)
+		}
+
+		switch dep.Name {
+		case "golang":
+			if dep.Platform != "" {
+				t.Errorf("Expected no platform for golang, got %s", dep.Platform)
+			}
+		case "python":
+			if dep.Platform != "linux/amd64" {
+				t.Errorf("Expected platform linux/amd64 for python, got %s", dep.Platform)
+			}
+		}
+	}
+	if len(dependencies) != len(expected) {
+		t.Errorf("Expected %d dependencies, got %d", len(expected), len(dependencies))
+	}
+}
+
+func TestScanDockerfileHeredocMarkers(t *testing.T) {
+	dependencies, err := NewScanner().Scan(filepath.Join(fixturesDir, "dockerfile", "heredoc.dockerfile"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	type expectation struct {
+		packageType string
+		version     string
+		scope       string
+	}
+
+	expected := map[string]expectation{
+		"debian":  {"docker-image", "12", "build"},
+		"curl":    {"apk", "UNKNOWN", "build"},
+		"git":     {"apk", "UNKNOWN", "build"},
+		"alpine":  {"docker-image", "3.19", ""},
+		"busybox": {"docker-image", "1.36", ""},
+	}
+
+	for _, dep := range dependencies {
+		want, ok := expected[dep.Name]
+		if !ok {
+			t.Errorf("Unexpected dependency '%s'", dep.Name)
+			continue
+		}
+		got := expectation{dep.PackageType, dep.Version, dep.Scope}
+		if got != want {
+			t.Errorf("%s = %+v, expected %+v", dep.Name, got, want)
+		}
+	}
+	if len(dependencies) != len(expected) {
+		t.Errorf("Expected %d dependencies, got %d", len(expected), len(dependencies))
+	}
+}
+
+func TestHeredocMarkers(t *testing.T) {
+	testCases := []struct {
+		args     string
+		expected string
+	}{
+		{"<<EOF /etc/motd", " /etc/motd"},
+		{"cat <<-'EOT' > /tmp/x", "cat  > /tmp/x"},
+		{"echo $((1<<SHIFT)) && apt-get update", "echo $((1<<SHIFT)) && apt-get update"},
+		{"echo \"see <<EOF in docs\" && apk add curl", "echo \"see <<EOF in docs\" && apk add curl"},
+		{"echo 'a <<EOF' <<<word", "echo 'a <<EOF' <<<word"},
+	}
+
+	for _, tc := range testCases {
+		if result := stripHeredocMarkers(tc.args); result != tc.expected {
+			t.Errorf("stripHeredocMarkers(%q) = %q, expected %q", tc.args, result, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package docker
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Instruction is one logical Dockerfile instruction, with its line
+// continuations joined and its heredocs read.
+type Instruction struct {
+	Command  string   // upper-cased, e.g. RUN
+	Args     string   // everything after the command
+	Heredocs []string // bodies of the <<EOF heredocs the instruction opens, in order
+	Line     int
+}
+
+var (
+	directivePattern = regexp.MustCompile(`^#\s*([a-zA-Z]+)\s*=\s*(\S+)\s*$`)
+	heredocPattern   = regexp.MustCompile(`^<<(-?)(["']?)([A-Za-z_][A-Za-z0-9_]*)(["']?)`)
+)
+
+// parseDockerfile reads the instructions of a Dockerfile. It honours the
+// escape parser directive, skips comments, including those between
+// continued lines, and reads the heredocs of RUN, COPY and ADD.
+func parseDockerfile(r io.Reader) ([]Instruction, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, strings.TrimSuffix(scanner.Text(), "\r"))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	escape := "\\"
+	start := 0
+	// Parser directives are only recognised before anything else
+	for ; start < len(lines); start++ {
+		match := directivePattern.FindStringSubmatch(strings.TrimSpace(lines[start]))
+		if match == nil {
+			break
+		}
+		if strings.EqualFold(match[1], "escape") && (match[2] == "`" || match[2] == "\\") {
+			escape = match[2]
+		}
+	}
+
+	var instructions []Instruction
+	var logical strings.Builder
+	continued := false
+	firstLine := 0
+
+	for i := start; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if !continued {
+			firstLine = i + 1
+		}
+
+		line := strings.TrimRight(lines[i], " \t")
+		if strings.HasSuffix(line, escape) {
+			logical.WriteString(strings.TrimSuffix(line, escape))
+			continued = true
+			continue
+		}
+		logical.WriteString(line)
+		continued = false
+
+		command, args, _ := strings.Cut(strings.TrimSpace(logical.String()), " ")
+		logical.Reset()
+		instruction := Instruction{
+			Command: strings.ToUpper(command),
+			Args:    strings.TrimSpace(args),
+			Line:    firstLine,
+		}
+
+		if instruction.Command == "RUN" || instruction.Command == "COPY" || instruction.Command == "ADD" {
+			for _, marker := range heredocMarkers(instruction.Args) {
+				stripTabs, delimiter := marker[3] > marker[2], instruction.Args[marker[6]:marker[7]]
+				var body []string
+				end := i + 1
+				for ; end < len(lines); end++ {
+					line := lines[end]
+					if stripTabs {
+						line = strings.TrimLeft(line, "\t")
+					}
+					if line == delimiter {
+						break
+					}
+					body = append(body, line)
+				}
+				// Without its delimiter the heredoc would swallow the rest of
+				// the file, so it is dropped instead
+				if end == len(lines) {
+					continue
+				}
+				i = end
+				instruction.Heredocs = append(instruction.Heredocs, strings.Join(body, "\n"))
+			}
+		}
+
+		instructions = append(instructions, instruction)
+	}
+
+	return instructions, nil
+}
+
+// heredocMarkers returns the submatch indexes of the <<EOF markers in the
+// arguments of an instruction, in order. A << inside quotes or a $( )
+// substitution, such as the shift in $((1<<SHIFT)), does not open a
+// heredoc, and neither does a <<< here-string.
+func heredocMarkers(args string) [][]int {
+	var markers [][]int
+	var discard strings.Builder
+
+	for i := 0; i < len(args); i++ {
+		switch c := args[i]; {
+		case c == '\\':
+			i++
+		case c == '\'':
+			end := strings.IndexByte(args[i+1:], '\'')
+			if end < 0 {
+				return markers
+			}
+			i += end + 1
+		case c == '"':
+			i = readDoubleQuoted(args, i+1, nil, &discard)
+		case c == '$':
+			_, next := expandAt(args, i, nil)
+			i = next - 1
+		case strings.HasPrefix(args[i:], "<<<"):
+			i += 2
+		case c == '<':
+			if match := heredocPattern.FindStringSubmatchIndex(args[i:]); match != nil {
+				for j := range match {
+					match[j] += i
+				}
+				markers = append(markers, match)
+				i = match[1] - 1
+			}
+		}
+	}
+
+	return markers
+}
+
+// stripHeredocMarkers removes the <<EOF markers from the arguments of an
+// instruction, leaving its command line.
+func stripHeredocMarkers(args string) string {
+	var sb strings.Builder
+	last := 0
+	for _, marker := range heredocMarkers(args) {
+		sb.WriteString(args[last:marker[0]])
+		last = marker[1]
+	}
+	sb.WriteString(args[last:])
+	return sb.String()
+}
+
+// instructionFlags splits the leading --name=value flags off the
+// arguments of an instruction, as in FROM --platform=linux/amd64 or
+// RUN --mount=type=cache,target=/root/.cache.
+func instructionFlags(args string) (map[string]string, string) {
+	flags := make(map[string]string)
+	for strings.HasPrefix(args, "--") {
+		flag, rest, _ := strings.Cut(args, " ")
+		name, value, _ := strings.Cut(strings.TrimPrefix(flag, "--"), "=")
+		flags[strings.ToLower(name)] = value
+		args = strings.TrimSpace(rest)
+	}
+	return flags, args
+}
@@ -0,0 +1,341 @@
+package docker
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"license-audit/internal/license"
+	"license-audit/pkg/types"
+)
+
+// HelmScanner reports a Helm chart, the charts it depends on and the
+// images its values.yaml configures.
+type HelmScanner struct{}
+
+// Chart is a Chart.yaml file.
+type Chart struct {
+	APIVersion   string            `yaml:"apiVersion"`
+	Name         string            `yaml:"name"`
+	Version      string            `yaml:"version"`
+	AppVersion   string            `yaml:"appVersion"`
+	Home         string            `yaml:"home"`
+	Sources      []string          `yaml:"sources"`
+	Annotations  map[string]string `yaml:"annotations"`
+	Dependencies []ChartDependency `yaml:"dependencies"`
+}
+
+type ChartDependency struct {
+	Name       string   `yaml:"name"`
+	Version    string   `yaml:"version"`
+	Repository string   `yaml:"repository"`
+	Condition  string   `yaml:"condition"`
+	Tags       []string `yaml:"tags"`
+	Alias      string   `yaml:"alias"`
+}
+
+// ChartLock is a Chart.lock, or a requirements.lock of an apiVersion v1
+// chart.
+type ChartLock struct {
+	Dependencies []ChartDependency `yaml:"dependencies"`
+	Digest       string            `yaml:"digest"`
+}
+
+func NewHelmScanner() *HelmScanner {
+	return &HelmScanner{}
+}
+
+func (s *HelmScanner) Name() string {
+	return "helm"
+}
+
+// Detect matches Chart.yaml, except those of dependencies unpacked in the
+// charts/ directory of another chart, which that chart reports.
+func (s *HelmScanner) Detect(path string) bool {
+	if filepath.Base(path) != "Chart.yaml" {
+		return false
+	}
+	chartsDir := filepath.Dir(filepath.Dir(path))
+	if filepath.Base(chartsDir) == "charts" {
+		if _, err := os.Stat(filepath.Join(filepath.Dir(chartsDir), "Chart.yaml")); err == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Scan reports the chart itself, its dependencies at the versions
+// Chart.lock pins, and the images of values.yaml. Licenses come from the
+// artifacthub.io/license annotation or the LICENSE file of each chart,
+// read from the charts/ directory for dependencies.
+func (s *HelmScanner) Scan(path string) ([]types.Dependency, error) {
+	dir := filepath.Dir(path)
+
+	chart, err := readChart(path)
+	if err != nil {
+		return nil, err
+	}
+
+	project := newChartDependency(chart.Name, chart.Version, path)
+	project.Scope = "project"
+	project.Homepage = chart.Home
+	if len(chart.Sources) > 0 {
+		project.Repository = chart.Sources[0]
+	}
+	project.LicenseType, project.LicenseText = chartLicense(chart, license.ReadFile(dir))
+	dependencies := []types.Dependency{project}
+
+	// Charts of apiVersion v1 list their dependencies in requirements.yaml
+	requirements := chart.Dependencies
+	lockName := "Chart.lock"
+	if chart.APIVersion == "v1" {
+		lockName = "requirements.lock"
+		var legacy struct {
+			Dependencies []ChartDependency `yaml:"dependencies"`
+		}
+		if data, err := os.ReadFile(filepath.Join(dir, "requirements.yaml")); err == nil {
+			if err := yaml.Unmarshal(data, &legacy); err != nil {
+				return nil, fmt.Errorf("failed to parse requirements.yaml: %w", err)
+			}
+			requirements = append(requirements, legacy.Dependencies...)
+		}
+	}
+
+	locked := make(map[string]string)
+	if data, err := os.ReadFile(filepath.Join(dir, lockName)); err == nil {
+		var lock ChartLock
+		if err := yaml.Unmarshal(data, &lock); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", lockName, err)
+		}
+		for _, dep := range lock.Dependencies {
+			locked[dep.Name] = dep.Version
+		}
+	}
+
+	for _, requirement := range requirements {
+		version := requirement.Version
+		if v, ok := locked[requirement.Name]; ok {
+			version = v
+		}
+
+		dep := newChartDependency(requirement.Name, version, path)
+		dep.Repository = requirement.Repository
+		if requirement.Condition != "" || len(requirement.Tags) > 0 {
+			dep.Scope = "optional"
+		}
+		if local, ok := strings.CutPrefix(requirement.Repository, "file://"); ok {
+			dep.Source = "path"
+			dep.SourceURL = local
+			dep.Repository = ""
+		}
+
+		if subchart, licenseText := readSubchart(dir, requirement, version); subchart != nil {
+			dep.LicenseType, dep.LicenseText = chartLicense(subchart, licenseText)
+			dep.Homepage = subchart.Home
+			if dep.Version == "" || dep.Source == "path" {
+				dep.Version = subchart.Version
+			}
+		}
+		if dep.Version == "" {
+			dep.Version = "UNKNOWN"
+		}
+
+		dependencies = append(dependencies, dep)
+	}
+
+	images := &imageCollector{filePath: path}
+	if data, err := os.ReadFile(filepath.Join(dir, "values.yaml")); err == nil {
+		var values interface{}
+		if err := yaml.Unmarshal(data, &values); err != nil {
+			return nil, fmt.Errorf("failed to parse values.yaml: %w", err)
+		}
+		collectValueImages(values, chart.AppVersion, images)
+	}
+	dependencies = append(dependencies, images.dependencies...)
+
+	return dependencies, nil
+}
+
+func newChartDependency(name, version, filePath string) types.Dependency {
+	if version == "" {
+		version = "UNKNOWN"
+	}
+
+	return types.Dependency{
+		Name:        name,
+		Version:     version,
+		LicenseType: "UNKNOWN",
+		PackageType: "helm-chart",
+		FilePath:    filePath,
+	}
+}
+
+func readChart(path string) (*Chart, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Chart.yaml: %w", err)
+	}
+
+	var chart Chart
+	if err := yaml.Unmarshal(data, &chart); err != nil {
+		return nil, fmt.Errorf("failed to parse Chart.yaml: %w", err)
+	}
+	return &chart, nil
+}
+
+// chartLicense returns the license a chart declares in its
+// artifacthub.io/license annotation, or else the one its license text
+// reads as.
+func chartLicense(chart *Chart, licenseText string) (string, string) {
+	if annotation := chart.Annotations["artifacthub.io/license"]; annotation != "" {
+		return annotation, licenseText
+	}
+	if licenseText != "" {
+		return license.Detect(licenseText), licenseText
+	}
+	return "UNKNOWN", ""
+}
+
+// readSubchart reads a dependency as helm dependency build left it: an
+// archive such as charts/postgresql-12.1.9.tgz or an unpacked
+// charts/postgresql directory, or the chart a file:// repository points to.
+func readSubchart(dir string, requirement ChartDependency, version string) (*Chart, string) {
+	chartDirs := []string{filepath.Join(dir, "charts", requirement.Name)}
+	if local, ok := strings.CutPrefix(requirement.Repository, "file://"); ok {
+		chartDirs = append([]string{filepath.Join(dir, filepath.FromSlash(local))}, chartDirs...)
+	}
+	for _, chartDir := range chartDirs {
+		if chart, err := readChart(filepath.Join(chartDir, "Chart.yaml")); err == nil {
+			return chart, license.ReadFile(chartDir)
+		}
+	}
+
+	archive := filepath.Join(dir, "charts", requirement.Name+"-"+version+".tgz")
+	if chart, licenseText, err := readChartArchive(archive, requirement.Name); err == nil {
+		return chart, licenseText
+	}
+	return nil, ""
+}
+
+// readChartArchive reads Chart.yaml and LICENSE from a packaged chart,
+// whose files sit in a directory named after the chart.
+func readChartArchive(path, name string) (*Chart, string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, "", err
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read chart archive: %w", err)
+	}
+	defer gz.Close()
+
+	var chart *Chart
+	var licenseText string
+	reader := tar.NewReader(gz)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read chart archive: %w", err)
+		}
+
+		switch header.Name {
+		case name + "/Chart.yaml":
+			data, err := io.ReadAll(reader)
+			if err != nil {
+				return nil, "", fmt.Errorf("failed to read chart archive: %w", err)
+			}
+			chart = &Chart{}
+			if err := yaml.Unmarshal(data, chart); err != nil {
+				return nil, "", fmt.Errorf("failed to parse Chart.yaml: %w", err)
+			}
+		case name + "/LICENSE", name + "/LICENSE.md", name + "/LICENSE.txt":
+			data, err := io.ReadAll(reader)
+			if err != nil {
+				return nil, "", fmt.Errorf("failed to read chart archive: %w", err)
+			}
+			licenseText = string(data)
+		}
+	}
+
+	if chart == nil {
+		return nil, "", fmt.Errorf("no Chart.yaml in %s", path)
+	}
+	return chart, licenseText, nil
+}
+
+// collectValueImages finds the images a values.yaml configures, either as
+// image: nginx:1.25 or in the common image: {registry, repository, tag,
+// digest} form. An empty tag defaults to the chart's appVersion, as chart
+// templates usually do.
+func collectValueImages(value interface{}, appVersion string, images *imageCollector) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			child := v[key]
+			if key != "image" {
+				collectValueImages(child, appVersion, images)
+				continue
+			}
+			switch image := child.(type) {
+			case string:
+				images.add(image)
+			case map[string]interface{}:
+				if ref := valueImageReference(image, appVersion); ref != "" {
+					images.add(ref)
+				}
+			}
+		}
+	case []interface{}:
+		for _, child := range v {
+			collectValueImages(child, appVersion, images)
+		}
+	}
+}
+
+func valueImageReference(image map[string]interface{}, appVersion string) string {
+	field := func(key string) string {
+		if value, ok := image[key]; ok && value != nil {
+			return fmt.Sprint(value)
+		}
+		return ""
+	}
+
+	repository := field("repository")
+	if repository == "" {
+		return ""
+	}
+	if registry := field("registry"); registry != "" {
+		repository = registry + "/" + repository
+	}
+
+	ref := repository
+	tag := field("tag")
+	if tag == "" {
+		tag = appVersion
+	}
+	if tag != "" {
+		ref += ":" + tag
+	}
+	if digest := field("digest"); digest != "" {
+		ref += "@" + digest
+	}
+	return ref
+}
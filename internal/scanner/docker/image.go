@@ -0,0 +1,83 @@
+package docker
+
+import (
+	"strings"
+
+	"license-audit/pkg/types"
+)
+
+// ImageReference is a parsed image reference such as
+// ghcr.io/acme/api:1.4.2@sha256:....
+type ImageReference struct {
+	Name   string // repository, including the registry if one is given
+	Tag    string
+	Digest string
+}
+
+// parseImageReference splits an image reference into repository, tag and
+// digest. A colon before the last slash belongs to a registry port, as in
+// localhost:5000/app, not to a tag.
+func parseImageReference(image string) ImageReference {
+	var ref ImageReference
+
+	image = strings.TrimSpace(image)
+	if name, digest, ok := strings.Cut(image, "@"); ok {
+		image = name
+		ref.Digest = digest
+	}
+
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		ref.Tag = image[i+1:]
+		image = image[:i]
+	}
+	ref.Name = image
+
+	return ref
+}
+
+// newImageDependency returns a docker-image dependency for an image
+// reference. Images without a tag are versioned by their digest, or else
+// by the latest tag Docker pulls.
+func newImageDependency(image, filePath string) types.Dependency {
+	ref := parseImageReference(image)
+
+	version := ref.Tag
+	if version == "" {
+		version = "latest"
+		if ref.Digest != "" {
+			version = ref.Digest
+		}
+	}
+
+	dep := types.Dependency{
+		Name:        ref.Name,
+		Version:     version,
+		LicenseType: "UNKNOWN",
+		PackageType: "docker-image",
+		FilePath:    filePath,
+	}
+	if ref.Digest != "" {
+		dep.Hashes = []string{ref.Digest}
+	}
+	return dep
+}
+
+// imageCollector gathers the images of a file, each reported once.
+type imageCollector struct {
+	filePath     string
+	seen         map[string]bool
+	dependencies []types.Dependency
+}
+
+func (c *imageCollector) add(image string) {
+	image = strings.TrimSpace(image)
+	// Unresolved templates and variables are not images
+	if image == "" || strings.ContainsAny(image, "{}$ ") || c.seen[image] {
+		return
+	}
+	if c.seen == nil {
+		c.seen = make(map[string]bool)
+	}
+	c.seen[image] = true
+	c.dependencies = append(c.dependencies, newImageDependency(image, c.filePath))
+}
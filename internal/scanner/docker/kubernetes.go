@@ -0,0 +1,133 @@
+package docker
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"license-audit/pkg/types"
+)
+
+// KubernetesScanner reports the container images of Kubernetes workloads.
+// Manifests have no fixed file name, so every YAML file is read and the
+// documents that are not workloads are passed over.
+type KubernetesScanner struct{}
+
+// KubernetesObject is a Kubernetes manifest document. The pod spec is
+// found in a different place for each kind of workload.
+type KubernetesObject struct {
+	APIVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+	Spec       struct {
+		PodSpec     `yaml:",inline"`
+		Template    PodTemplate `yaml:"template"`
+		JobTemplate struct {
+			Spec struct {
+				Template PodTemplate `yaml:"template"`
+			} `yaml:"spec"`
+		} `yaml:"jobTemplate"`
+	} `yaml:"spec"`
+	Items []KubernetesObject `yaml:"items"`
+}
+
+type PodTemplate struct {
+	Spec PodSpec `yaml:"spec"`
+}
+
+type PodSpec struct {
+	InitContainers      []Container `yaml:"initContainers"`
+	Containers          []Container `yaml:"containers"`
+	EphemeralContainers []Container `yaml:"ephemeralContainers"`
+}
+
+type Container struct {
+	Name  string `yaml:"name"`
+	Image string `yaml:"image"`
+}
+
+func NewKubernetesScanner() *KubernetesScanner {
+	return &KubernetesScanner{}
+}
+
+func (s *KubernetesScanner) Name() string {
+	return "kubernetes"
+}
+
+// Detect matches YAML files other than those another scanner owns, and
+// other than Helm chart templates, which are not YAML until rendered.
+func (s *KubernetesScanner) Detect(path string) bool {
+	fileName := filepath.Base(path)
+	ext := filepath.Ext(fileName)
+	if ext != ".yml" && ext != ".yaml" {
+		return false
+	}
+	if isComposeFile(fileName) || fileName == "Chart.yaml" || fileName == "Chart.lock" ||
+		fileName == "requirements.yaml" || fileName == "pubspec.yaml" || fileName == "pnpm-lock.yaml" {
+		return false
+	}
+
+	// Helm chart templates
+	dir := filepath.Dir(path)
+	if filepath.Base(dir) == "templates" {
+		if _, err := os.Stat(filepath.Join(filepath.Dir(dir), "Chart.yaml")); err == nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *KubernetesScanner) Scan(path string) ([]types.Dependency, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Kubernetes manifest: %w", err)
+	}
+	if !bytes.Contains(data, []byte("apiVersion")) || !bytes.Contains(data, []byte("kind")) {
+		return nil, nil
+	}
+
+	images := &imageCollector{filePath: path}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var object KubernetesObject
+		// A YAML file that does not parse is not a manifest; keep what
+		// the documents before it held
+		if err := decoder.Decode(&object); err != nil {
+			break
+		}
+		collectWorkloadImages(object, images)
+	}
+
+	return images.dependencies, nil
+}
+
+func collectWorkloadImages(object KubernetesObject, images *imageCollector) {
+	if object.APIVersion == "" {
+		return
+	}
+
+	var spec PodSpec
+	switch object.Kind {
+	case "List":
+		for _, item := range object.Items {
+			collectWorkloadImages(item, images)
+		}
+		return
+	case "Pod":
+		spec = object.Spec.PodSpec
+	case "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "ReplicationController", "Job":
+		spec = object.Spec.Template.Spec
+	case "CronJob":
+		spec = object.Spec.JobTemplate.Spec.Template.Spec
+	default:
+		return
+	}
+
+	for _, containers := range [][]Container{spec.InitContainers, spec.Containers, spec.EphemeralContainers} {
+		for _, container := range containers {
+			images.add(strings.Trim(container.Image, `"'`))
+		}
+	}
+}
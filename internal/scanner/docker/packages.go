@@ -0,0 +1,296 @@
+package docker
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// InstalledPackage is a package a RUN command installs.
+type InstalledPackage struct {
+	Manager string // the dependency's package type: apt, apk, yum, dnf, python, npm, ruby or go
+	Name    string
+	Version string // the pinned version or constraint, empty if none
+}
+
+// Options that take a value as the next word, per package manager
+var (
+	aptValueOptions = optionSet("-o", "-c", "-t", "--option", "--config-file", "--target-release", "--default-release")
+	apkValueOptions = optionSet("-X", "--repository", "-t", "--virtual", "-p", "--root", "--arch", "--cache-dir", "--keys-dir", "--repositories-file")
+	rpmValueOptions = optionSet("-c", "--config", "-x", "--exclude", "--enablerepo", "--disablerepo", "--repo", "--repofrompath",
+		"--setopt", "--releasever", "--installroot", "--forcearch")
+	pipValueOptions = optionSet("-r", "--requirement", "-c", "--constraint", "-e", "--editable", "-i", "--index-url",
+		"--extra-index-url", "-f", "--find-links", "-t", "--target", "--prefix", "--root", "--trusted-host", "--platform",
+		"--python-version", "--implementation", "--abi", "--src", "--cache-dir", "--log", "--progress-bar", "--python")
+	npmValueOptions = optionSet("--prefix", "--registry", "-w", "--workspace", "--cache", "--tag", "--omit", "--include")
+	gemValueOptions = optionSet("-i", "--install-dir", "-n", "--bindir", "-s", "--source", "-P", "--trust-policy", "--platform", "--document")
+	goValueOptions  = optionSet("-o", "-C", "-p", "-ldflags", "-gcflags", "-asmflags", "-gccgoflags", "-tags", "-mod", "-modfile",
+		"-pkgdir", "-overlay", "-pgo", "-toolexec", "-compiler", "-installsuffix")
+)
+
+// Words that may come before a command without being the command
+var commandPrefixes = map[string]bool{
+	"then": true, "do": true, "else": true, "!": true, "{": true, "time": true, "exec": true, "nohup": true, "command": true,
+}
+
+var (
+	pipRequirementPattern = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)(\[[^\]]*\])?\s*(.*)$`)
+	rpmVersionPattern     = regexp.MustCompile(`^(.+?)-(\d+\.[\w.+~^:]*(?:-[\w.+~^]+)?)$`)
+	envAssignmentPattern  = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*=`)
+)
+
+func optionSet(options ...string) map[string]bool {
+	set := make(map[string]bool, len(options))
+	for _, option := range options {
+		set[option] = true
+	}
+	return set
+}
+
+// operands returns the words of a command line that are not options or
+// option values. Options of the form --name=value carry their own value.
+func operands(args []string, valueOptions map[string]bool) []string {
+	var result []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			return append(result, args[i+1:]...)
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			if valueOptions[arg] {
+				i++
+			}
+		default:
+			result = append(result, arg)
+		}
+	}
+	return result
+}
+
+// extractPackages returns the packages a simple command installs, looking
+// through sudo, env, variable assignments and sh -c.
+func extractPackages(words []string) []InstalledPackage {
+	for len(words) > 0 {
+		switch {
+		case commandPrefixes[words[0]], envAssignmentPattern.MatchString(words[0]):
+			words = words[1:]
+		case words[0] == "sudo" || words[0] == "env":
+			words = words[1:]
+			for len(words) > 0 && strings.HasPrefix(words[0], "-") {
+				words = words[1:]
+			}
+		default:
+			return commandPackages(words)
+		}
+	}
+	return nil
+}
+
+func commandPackages(words []string) []InstalledPackage {
+	command := path.Base(words[0])
+	args := words[1:]
+
+	switch {
+	case command == "sh" || command == "bash" || command == "ash" || command == "dash" || command == "zsh":
+		for i, arg := range args {
+			if strings.HasPrefix(arg, "-") && strings.Contains(arg, "c") && i+1 < len(args) {
+				var packages []InstalledPackage
+				for _, command := range splitCommands(args[i+1], nil) {
+					packages = append(packages, extractPackages(command)...)
+				}
+				return packages
+			}
+		}
+	case command == "apt-get" || command == "apt" || command == "aptitude":
+		return aptPackages(args)
+	case command == "apk":
+		return apkPackages(args)
+	case command == "yum":
+		return rpmPackages("yum", args)
+	case command == "dnf" || command == "microdnf" || command == "tdnf":
+		return rpmPackages("dnf", args)
+	case strings.HasPrefix(command, "pip"):
+		return pipPackages(args)
+	case strings.HasPrefix(command, "python") && len(args) > 1 && args[0] == "-m" && strings.HasPrefix(args[1], "pip"):
+		return pipPackages(args[2:])
+	case command == "uv" && len(args) > 0 && args[0] == "pip":
+		return pipPackages(args[1:])
+	case command == "npm" || command == "pnpm" || command == "yarn":
+		return npmPackages(command, args)
+	case command == "gem":
+		return gemPackages(args)
+	case command == "go":
+		return goPackages(args)
+	}
+	return nil
+}
+
+// installOperands returns the operands after the install subcommand, or
+// nil if the command does not install anything.
+func installOperands(args []string, valueOptions map[string]bool, subcommands ...string) []string {
+	words := operands(args, valueOptions)
+	if len(words) == 0 {
+		return nil
+	}
+	for _, subcommand := range subcommands {
+		if words[0] == subcommand {
+			return words[1:]
+		}
+	}
+	return nil
+}
+
+// isLiteral reports whether a package argument is a plain name rather than
+// a file, URL or unevaluated command substitution.
+func isLiteral(arg string) bool {
+	return arg != "" && !strings.ContainsAny(arg, "$`*") && !strings.Contains(arg, "://") &&
+		!strings.HasPrefix(arg, ".") && !strings.HasPrefix(arg, "/")
+}
+
+// aptPackages handles apt-get install name, name=version and
+// name/release.
+func aptPackages(args []string) []InstalledPackage {
+	var packages []InstalledPackage
+	for _, arg := range installOperands(args, aptValueOptions, "install") {
+		if !isLiteral(arg) || strings.HasSuffix(arg, ".deb") {
+			continue
+		}
+		name, version, _ := strings.Cut(arg, "=")
+		name, _, _ = strings.Cut(name, "/")
+		packages = append(packages, InstalledPackage{Manager: "apt", Name: name, Version: version})
+	}
+	return packages
+}
+
+// apkPackages handles apk add name, name=version, name~version,
+// name>version and name@repository.
+func apkPackages(args []string) []InstalledPackage {
+	var packages []InstalledPackage
+	for _, arg := range installOperands(args, apkValueOptions, "add") {
+		if !isLiteral(arg) || strings.HasSuffix(arg, ".apk") {
+			continue
+		}
+		name, version := arg, ""
+		if i := strings.IndexAny(arg, "=~<>"); i > 0 {
+			name, version = arg[:i], arg[i:]
+			version = strings.TrimPrefix(version, "=")
+		}
+		name, _, _ = strings.Cut(name, "@")
+		packages = append(packages, InstalledPackage{Manager: "apk", Name: name, Version: version})
+	}
+	return packages
+}
+
+// rpmPackages handles yum and dnf install name and name-version, where
+// the version is dotted so that names such as java-17-openjdk stay whole.
+// Groups (@name) and local .rpm files are passed over.
+func rpmPackages(manager string, args []string) []InstalledPackage {
+	var packages []InstalledPackage
+	for _, arg := range installOperands(args, rpmValueOptions, "install") {
+		if !isLiteral(arg) || strings.HasPrefix(arg, "@") || strings.HasSuffix(arg, ".rpm") {
+			continue
+		}
+		name, version := arg, ""
+		if match := rpmVersionPattern.FindStringSubmatch(arg); match != nil {
+			name, version = match[1], match[2]
+		}
+		packages = append(packages, InstalledPackage{Manager: manager, Name: name, Version: version})
+	}
+	return packages
+}
+
+// pipPackages handles pip install requirement specifiers. An exact pin
+// (==) gives the version; any other specifier is kept as the version
+// constraint.
+func pipPackages(args []string) []InstalledPackage {
+	var packages []InstalledPackage
+	for _, arg := range installOperands(args, pipValueOptions, "install") {
+		if !isLiteral(arg) || strings.Contains(arg, "/") {
+			continue
+		}
+		match := pipRequirementPattern.FindStringSubmatch(arg)
+		if match == nil {
+			continue
+		}
+		specifier, _, _ := strings.Cut(match[3], ";")
+		specifier = strings.TrimSpace(specifier)
+		if exact, ok := strings.CutPrefix(specifier, "=="); ok && !strings.Contains(exact, ",") {
+			specifier = strings.TrimSpace(exact)
+		}
+		packages = append(packages, InstalledPackage{Manager: "python", Name: match[1], Version: specifier})
+	}
+	return packages
+}
+
+// npmPackages handles npm install, pnpm add and yarn (global) add with
+// name, name@version and @scope/name@version.
+func npmPackages(command string, args []string) []InstalledPackage {
+	subcommands := []string{"install", "i", "add"}
+	if command == "yarn" {
+		args = operands(args, npmValueOptions)
+		if len(args) > 0 && args[0] == "global" {
+			args = args[1:]
+		}
+		subcommands = []string{"add"}
+	}
+
+	var packages []InstalledPackage
+	for _, arg := range installOperands(args, npmValueOptions, subcommands...) {
+		if !isLiteral(arg) || strings.Contains(arg, ":") || strings.HasSuffix(arg, ".tgz") {
+			continue
+		}
+		// A slash outside a scope is a GitHub shorthand
+		if strings.Contains(arg, "/") && !strings.HasPrefix(arg, "@") {
+			continue
+		}
+		name, version := arg, ""
+		if i := strings.LastIndex(arg, "@"); i > 0 {
+			name, version = arg[:i], arg[i+1:]
+		}
+		packages = append(packages, InstalledPackage{Manager: "npm", Name: name, Version: version})
+	}
+	return packages
+}
+
+// gemPackages handles gem install name, name:version and -v version.
+func gemPackages(args []string) []InstalledPackage {
+	version := ""
+	for i, arg := range args {
+		if (arg == "-v" || arg == "--version") && i+1 < len(args) {
+			version = args[i+1]
+		} else if v, ok := strings.CutPrefix(arg, "--version="); ok {
+			version = v
+		}
+	}
+
+	valueOptions := optionSet("-v", "--version")
+	for option := range gemValueOptions {
+		valueOptions[option] = true
+	}
+
+	var packages []InstalledPackage
+	for _, arg := range installOperands(args, valueOptions, "install", "i") {
+		if !isLiteral(arg) || strings.HasSuffix(arg, ".gem") {
+			continue
+		}
+		name, pinned, ok := strings.Cut(arg, ":")
+		if !ok {
+			pinned = version
+		}
+		packages = append(packages, InstalledPackage{Manager: "ruby", Name: name, Version: pinned})
+	}
+	return packages
+}
+
+// goPackages handles go install path@version.
+func goPackages(args []string) []InstalledPackage {
+	var packages []InstalledPackage
+	for _, arg := range installOperands(args, goValueOptions, "install") {
+		if !isLiteral(arg) {
+			continue
+		}
+		name, version, _ := strings.Cut(arg, "@")
+		packages = append(packages, InstalledPackage{Manager: "go", Name: name, Version: version})
+	}
+	return packages
+}
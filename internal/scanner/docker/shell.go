@@ -0,0 +1,234 @@
+package docker
+
+import (
+	"strings"
+)
+
+// splitCommands splits a shell script into its simple commands, each a
+// list of words with quotes removed and variables expanded from vars.
+// Control operators (;, &&, ||, |, &, newlines and parentheses) separate
+// commands, and redirections are dropped. Command substitutions are kept
+// as written, so that callers can recognise and skip them.
+func splitCommands(script string, vars map[string]string) [][]string {
+	var commands [][]string
+	var words []string
+	var word strings.Builder
+	inWord := false
+	skipWord := false // the next word is a redirection target
+
+	endWord := func() {
+		if inWord {
+			if skipWord {
+				skipWord = false
+			} else {
+				words = append(words, word.String())
+			}
+		}
+		word.Reset()
+		inWord = false
+	}
+	endCommand := func() {
+		endWord()
+		if len(words) > 0 {
+			commands = append(commands, words)
+		}
+		words = nil
+	}
+
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+		switch {
+		case c == '\\':
+			if i+1 < len(script) {
+				i++
+				// A backslash before a newline continues the line
+				if script[i] != '\n' {
+					word.WriteByte(script[i])
+					inWord = true
+				}
+			}
+		case c == '\'':
+			end := strings.IndexByte(script[i+1:], '\'')
+			if end < 0 {
+				end = len(script) - i - 1
+			}
+			word.WriteString(script[i+1 : i+1+end])
+			i += end + 1
+			inWord = true
+		case c == '"':
+			i = readDoubleQuoted(script, i+1, vars, &word)
+			inWord = true
+		case c == '$':
+			value, next := expandAt(script, i, vars)
+			word.WriteString(value)
+			i = next - 1
+			inWord = true
+		case c == ' ' || c == '\t' || c == '\r':
+			endWord()
+		case c == '#' && !inWord:
+			// Comment to the end of the line
+			for i+1 < len(script) && script[i+1] != '\n' {
+				i++
+			}
+		case c == '>' || c == '<':
+			// A file descriptor number belongs to the redirection
+			if inWord && strings.Trim(word.String(), "0123456789") == "" {
+				word.Reset()
+				inWord = false
+			}
+			endWord()
+			for i+1 < len(script) && strings.IndexByte("<>&-0123456789", script[i+1]) >= 0 {
+				i++
+			}
+			skipWord = true
+		case strings.IndexByte("\n;&|()", c) >= 0:
+			endCommand()
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+	endCommand()
+
+	return commands
+}
+
+// readDoubleQuoted reads a double-quoted string starting after its opening
+// quote into word, expanding variables, and returns the index of the
+// closing quote.
+func readDoubleQuoted(script string, i int, vars map[string]string, word *strings.Builder) int {
+	for ; i < len(script); i++ {
+		c := script[i]
+		switch {
+		case c == '"':
+			return i
+		case c == '\\' && i+1 < len(script) && strings.IndexByte("$`\"\\\n", script[i+1]) >= 0:
+			i++
+			if script[i] != '\n' {
+				word.WriteByte(script[i])
+			}
+		case c == '$':
+			value, next := expandAt(script, i, vars)
+			word.WriteString(value)
+			i = next - 1
+		default:
+			word.WriteByte(c)
+		}
+	}
+	return i
+}
+
+// expandAt expands the variable reference or command substitution at
+// script[i], which is a $, and returns its value and the index after it.
+func expandAt(script string, i int, vars map[string]string) (string, int) {
+	if i+1 >= len(script) {
+		return "$", i + 1
+	}
+
+	switch next := script[i+1]; {
+	case next == '(':
+		// Command substitutions cannot be evaluated; keep them verbatim
+		depth := 0
+		for j := i + 1; j < len(script); j++ {
+			switch script[j] {
+			case '(':
+				depth++
+			case ')':
+				depth--
+				if depth == 0 {
+					return script[i : j+1], j + 1
+				}
+			}
+		}
+		return script[i:], len(script)
+	case next == '{':
+		end := strings.IndexByte(script[i+2:], '}')
+		if end < 0 {
+			return script[i:], len(script)
+		}
+		value, _ := expandParameter(script[i+2:i+2+end], vars)
+		return value, i + 2 + end + 1
+	case isNameByte(next, true):
+		j := i + 1
+		for j < len(script) && isNameByte(script[j], false) {
+			j++
+		}
+		return vars[script[i+1:j]], j
+	default:
+		return "$", i + 1
+	}
+}
+
+// expandVariables expands the $NAME and ${NAME} references of a
+// Dockerfile instruction argument. It reports whether every variable had
+// a value.
+func expandVariables(value string, vars map[string]string) (string, bool) {
+	var result strings.Builder
+	resolved := true
+
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c == '\\' && i+1 < len(value) && value[i+1] == '$':
+			result.WriteByte('$')
+			i++
+		case c == '$' && i+1 < len(value) && value[i+1] == '{':
+			end := strings.IndexByte(value[i+2:], '}')
+			if end < 0 {
+				result.WriteString(value[i:])
+				return result.String(), resolved
+			}
+			expanded, ok := expandParameter(value[i+2:i+2+end], vars)
+			result.WriteString(expanded)
+			resolved = resolved && ok
+			i += 2 + end
+		case c == '$' && i+1 < len(value) && isNameByte(value[i+1], true):
+			j := i + 1
+			for j < len(value) && isNameByte(value[j], false) {
+				j++
+			}
+			expanded, ok := vars[value[i+1:j]]
+			result.WriteString(expanded)
+			resolved = resolved && ok
+			i = j - 1
+		default:
+			result.WriteByte(c)
+		}
+	}
+
+	return result.String(), resolved
+}
+
+// expandParameter expands the inside of ${...}: NAME, NAME:-default,
+// NAME-default, NAME:+alternative and NAME+alternative.
+func expandParameter(expression string, vars map[string]string) (string, bool) {
+	j := 0
+	for j < len(expression) && isNameByte(expression[j], j == 0) {
+		j++
+	}
+	name, rest := expression[:j], expression[j:]
+	value, ok := vars[name]
+
+	colon := strings.HasPrefix(rest, ":")
+	rest = strings.TrimPrefix(rest, ":")
+	set := ok && (!colon || value != "")
+
+	switch {
+	case strings.HasPrefix(rest, "-"):
+		if !set {
+			fallback, _ := expandVariables(rest[1:], vars)
+			return fallback, true
+		}
+	case strings.HasPrefix(rest, "+"):
+		if set {
+			alternative, _ := expandVariables(rest[1:], vars)
+			return alternative, true
+		}
+		return "", true
+	}
+	return value, ok
+}
+
+func isNameByte(c byte, first bool) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (!first && c >= '0' && c <= '9')
+}
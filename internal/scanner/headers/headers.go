@@ -0,0 +1,206 @@
+package headers
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"license-audit/internal/license"
+	"license-audit/pkg/types"
+)
+
+// DefaultLines is how far into a file the license header is looked for
+// when HeaderLines is not set.
+const DefaultLines = 30
+
+// Scanner checks the license headers of first-party source files. Scan
+// reads the header of each file the walk passes it, and ScanTree groups
+// the files of a scan path by license once the walk is done.
+type Scanner struct {
+	// ProjectLicense is the license the project declares. When empty it is
+	// detected from the LICENSE or COPYING file at the scan path.
+	ProjectLicense string
+	// HeaderLines is the number of lines read from the top of each file,
+	// DefaultLines if zero.
+	HeaderLines int
+
+	files []sourceFile
+}
+
+type sourceFile struct {
+	path    string
+	license string
+}
+
+// skippedDirs hold code that is not first-party, or not source: vendored
+// components, package manager installs and build output.
+var skippedDirs = map[string]bool{
+	".git":             true,
+	".hg":              true,
+	".svn":             true,
+	"node_modules":     true,
+	"bower_components": true,
+	"vendor":           true,
+	"third_party":      true,
+	"third-party":      true,
+	"thirdparty":       true,
+	"3rdparty":         true,
+	"external":         true,
+	"extern":           true,
+	"deps":             true,
+	"_build":           true,
+	"build":            true,
+	"dist":             true,
+	"target":           true,
+	"Pods":             true,
+	".build":           true,
+	"vcpkg_installed":  true,
+	"site-packages":    true,
+	".venv":            true,
+	"venv":             true,
+	"__pycache__":      true,
+}
+
+// sourceExtensions identify the source files whose headers are checked.
+var sourceExtensions = map[string]bool{
+	".c": true, ".h": true, ".cc": true, ".cpp": true, ".cxx": true, ".hh": true, ".hpp": true, ".hxx": true,
+	".m": true, ".mm": true, ".s": true, ".asm": true,
+	".go": true, ".rs": true, ".zig": true, ".java": true, ".kt": true, ".kts": true, ".scala": true, ".groovy": true,
+	".swift": true, ".dart": true, ".cs": true, ".fs": true, ".vb": true,
+	".py": true, ".rb": true, ".php": true, ".pl": true, ".pm": true, ".lua": true, ".r": true, ".jl": true,
+	".ex": true, ".exs": true, ".erl": true, ".hrl": true, ".hs": true, ".ml": true, ".clj": true,
+	".js": true, ".mjs": true, ".cjs": true, ".jsx": true, ".ts": true, ".tsx": true, ".vue": true, ".svelte": true,
+	".css": true, ".scss": true, ".html": true,
+	".sh": true, ".bash": true, ".zsh": true, ".ps1": true, ".sql": true, ".proto": true, ".cmake": true,
+}
+
+func NewScanner() *Scanner {
+	return &Scanner{}
+}
+
+func (s *Scanner) Name() string {
+	return "source-headers"
+}
+
+func (s *Scanner) Detect(path string) bool {
+	if !sourceExtensions[strings.ToLower(filepath.Ext(path))] {
+		return false
+	}
+	for _, part := range strings.Split(filepath.ToSlash(filepath.Dir(path)), "/") {
+		if skippedDirs[part] {
+			return false
+		}
+	}
+	return true
+}
+
+// Scan records the license header of a source file. It reports nothing
+// itself; the files are reported by ScanTree.
+func (s *Scanner) Scan(path string) ([]types.Dependency, error) {
+	license, err := s.readHeader(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s.files = append(s.files, sourceFile{path: path, license: license})
+	return nil, nil
+}
+
+// ScanTree reports the source files recorded since the last call, grouped
+// by the license their headers declare, together with the license the
+// project under root declares. Files without a header are grouped under
+// UNKNOWN.
+func (s *Scanner) ScanTree(root string) ([]types.Dependency, error) {
+	files := s.files
+	s.files = nil
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	projectLicense := s.ProjectLicense
+	if projectLicense == "" {
+		projectLicense = readProjectLicense(root)
+	}
+
+	name := filepath.Base(root)
+	if abs, err := filepath.Abs(root); err == nil {
+		name = filepath.Base(abs)
+	}
+
+	dependencies := []types.Dependency{{
+		Name:        name,
+		Version:     "UNKNOWN",
+		LicenseType: projectLicense,
+		PackageType: "source",
+		FilePath:    root,
+		Scope:       "project",
+	}}
+
+	groups := make(map[string][]string)
+	for _, file := range files {
+		rel, err := filepath.Rel(root, file.path)
+		if err != nil {
+			rel = file.path
+		}
+		groups[file.license] = append(groups[file.license], filepath.ToSlash(rel))
+	}
+
+	licenses := make([]string, 0, len(groups))
+	for license := range groups {
+		licenses = append(licenses, license)
+	}
+	sort.Strings(licenses)
+
+	for _, license := range licenses {
+		dependencies = append(dependencies, types.Dependency{
+			Name:        license,
+			Version:     "UNKNOWN",
+			LicenseType: license,
+			PackageType: "source",
+			FilePath:    root,
+			Files:       groups[license],
+		})
+	}
+
+	return dependencies, nil
+}
+
+// readHeader returns the license the top of a source file declares, by an
+// SPDX-License-Identifier tag or by license boilerplate, or UNKNOWN.
+func (s *Scanner) readHeader(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	limit := s.HeaderLines
+	if limit <= 0 {
+		limit = DefaultLines
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for len(lines) < limit && scanner.Scan() {
+		line := scanner.Text()
+		if license := spdxIdentifier(line); license != "" {
+			return license, nil
+		}
+		lines = append(lines, stripComment(line))
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	return detectHeaderLicense(strings.Join(lines, " ")), nil
+}
+
+func readProjectLicense(root string) string {
+	if text := license.ReadFile(root); text != "" {
+		return license.Detect(text)
+	}
+	return "UNKNOWN"
+}
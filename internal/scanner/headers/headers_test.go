@@ -0,0 +1,177 @@
+package headers
+
+import (
+	"io/fs"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const fixturesDir = "../../../test/fixtures/headers"
+
+func TestDetect(t *testing.T) {
+	scanner := NewScanner()
+
+	testCases := []struct {
+		path     string
+		expected bool
+	}{
+		{"main.go", true},
+		{"src/lib/crc32.c", true},
+		{"scripts/release.sh", true},
+		{"web/App.TSX", true},
+		{"README.md", false},
+		{"LICENSE", false},
+		{"node_modules/left-pad/index.js", false},
+		{"third_party/zlib/inflate.c", false},
+		{"src/vendor/github.com/pkg/errors/errors.go", false},
+		{"", false},
+	}
+
+	for _, tc := range testCases {
+		result := scanner.Detect(tc.path)
+		if result != tc.expected {
+			t.Errorf("Detect(%s) = %v, expected %v", tc.path, result, tc.expected)
+		}
+	}
+}
+
+func TestScanProject(t *testing.T) {
+	scanner := NewScanner()
+	root := filepath.Join(fixturesDir, "project")
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !scanner.Detect(path) {
+			return err
+		}
+		deps, err := scanner.Scan(path)
+		if len(deps) != 0 {
+			t.Errorf("Expected Scan(%s) to report nothing, got %d dependencies", path, len(deps))
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	dependencies, err := scanner.ScanTree(root)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := map[string][]string{
+		"Apache-2.0":        {"scripts/release.sh", "src/lib/config.py", "src/main.go"},
+		"GPL-2.0-or-later":  {"src/lib/crc32.c"},
+		"MIT":               {"src/lib/queue.js"},
+		"MIT OR Apache-2.0": {"src/lib/itoa.rs"},
+		"UNKNOWN":           {"src/lib/helpers.ts"},
+	}
+
+	if len(dependencies) != len(expected)+1 {
+		t.Errorf("Expected %d dependencies, got %d", len(expected)+1, len(dependencies))
+	}
+
+	for _, dep := range dependencies {
+		if dep.PackageType != "source" || dep.FilePath != root {
+			t.Errorf("Unexpected package type or path for %s: %s, %s", dep.Name, dep.PackageType, dep.FilePath)
+		}
+		if dep.Scope == "project" {
+			if dep.Name != "project" || dep.LicenseType != "Apache-2.0" {
+				t.Errorf("Expected the project license Apache-2.0, got %s: %s", dep.Name, dep.LicenseType)
+			}
+			continue
+		}
+
+		want, ok := expected[dep.LicenseType]
+		if !ok {
+			t.Errorf("Unexpected license group '%s'", dep.LicenseType)
+			continue
+		}
+		if !reflect.DeepEqual(dep.Files, want) {
+			t.Errorf("%s = %v, expected %v", dep.LicenseType, dep.Files, want)
+		}
+	}
+
+	// The recorded files are reported once
+	if dependencies, _ := scanner.ScanTree(root); len(dependencies) != 0 {
+		t.Errorf("Expected no dependencies from a second ScanTree, got %d", len(dependencies))
+	}
+}
+
+func TestProjectLicense(t *testing.T) {
+	scanner := NewScanner()
+	scanner.ProjectLicense = "MIT"
+
+	path := filepath.Join(fixturesDir, "project", "src", "main.go")
+	if _, err := scanner.Scan(path); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	dependencies, _ := scanner.ScanTree(filepath.Join(fixturesDir, "project"))
+	if len(dependencies) == 0 || dependencies[0].LicenseType != "MIT" {
+		t.Errorf("Expected the configured project license to be reported, got %+v", dependencies)
+	}
+}
+
+func TestHeaderLines(t *testing.T) {
+	scanner := NewScanner()
+	scanner.HeaderLines = 2
+
+	// The Apache notice in config.py starts on line 3
+	license, err := scanner.readHeader(filepath.Join(fixturesDir, "project", "src", "lib", "config.py"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if license != "UNKNOWN" {
+		t.Errorf("Expected UNKNOWN within two lines, got %s", license)
+	}
+}
+
+func TestSPDXIdentifier(t *testing.T) {
+	testCases := []struct {
+		line     string
+		expected string
+	}{
+		{"// SPDX-License-Identifier: MIT", "MIT"},
+		{"# SPDX-License-Identifier: GPL-2.0-only", "GPL-2.0-only"},
+		{"/* SPDX-License-Identifier: (MIT OR Apache-2.0) */", "(MIT OR Apache-2.0)"},
+		{"<!-- SPDX-License-Identifier: CC-BY-4.0 -->", "CC-BY-4.0"},
+		{"-- SPDX-License-Identifier:   BSD-3-Clause  ", "BSD-3-Clause"},
+		{" * SPDX-License-Identifier: Apache-2.0 WITH LLVM-exception", "Apache-2.0 WITH LLVM-exception"},
+		{"// SPDX-License-Identifier: LicenseRef-Proprietary", "LicenseRef-Proprietary"},
+		{"// Copyright 2024 Example Corp.", ""},
+		// Tags outside a comment, or that are not valid expressions
+		{`var spdxPattern = regexp.MustCompile(` + "`" + `SPDX-License-Identifier:\s*(.+)` + "`" + `)`, ""},
+		{`	header := "SPDX-License-Identifier: MIT"`, ""},
+		{"SPDX-License-Identifier: MIT", ""},
+		{"// SPDX-License-Identifier: (MIT OR", ""},
+		{"// SPDX-License-Identifier: MIT AND", ""},
+		{"// SPDX-License-Identifier: see LICENSE file", ""},
+	}
+
+	for _, tc := range testCases {
+		if result := spdxIdentifier(tc.line); result != tc.expected {
+			t.Errorf("spdxIdentifier(%q) = %q, expected %q", tc.line, result, tc.expected)
+		}
+	}
+}
+
+func TestDetectHeaderLicense(t *testing.T) {
+	testCases := []struct {
+		header   string
+		expected string
+	}{
+		{"Use of this source code is governed by a BSD-style license that can be found in the LICENSE file.", "BSD-3-Clause"},
+		{"under the terms of the GNU Lesser General Public License as published by the Free Software Foundation; either version 2.1 of the License, or (at your option) any later version.", "LGPL-2.1-or-later"},
+		{"under the terms of the GNU General Public License version 3, as published by", "GPL-3.0-only"},
+		{"GNU Affero General Public License", "AGPL"},
+		{"This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0.", "MPL-2.0"},
+		{"Copyright 2024 Example Corp. All rights reserved.", "UNKNOWN"},
+	}
+
+	for _, tc := range testCases {
+		if result := detectHeaderLicense(tc.header); result != tc.expected {
+			t.Errorf("detectHeaderLicense(%q) = %s, expected %s", tc.header, result, tc.expected)
+		}
+	}
+}
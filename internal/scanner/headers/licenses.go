@@ -0,0 +1,189 @@
+package headers
+
+import (
+	"regexp"
+	"strings"
+)
+
+// spdxPattern matches an SPDX-License-Identifier tag at the start of a
+// comment: after //, #, /*, <!--, --, ;, %, (*, {-, ', REM, .. or the *
+// continuing a block comment. Tags elsewhere, as in string literals, are
+// not the file's license.
+var spdxPattern = regexp.MustCompile(`^\s*(?://+|#+|/\*+|<!--|--+|;+|%+|\(\*|\{-|'|(?i:rem)\s|\.\.|\*+)\s*SPDX-License-Identifier:\s*(.+)`)
+
+// spdxIDPattern matches a license or exception identifier of an SPDX
+// expression, including LicenseRef- and DocumentRef- references.
+var spdxIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9.:-]*\+?$`)
+
+// commentClosers end an SPDX tag written on a single comment line, as in
+// /* SPDX-License-Identifier: MIT */ or <!-- ... -->.
+var commentClosers = []string{"*/", "-->", "*)", "-}", "%>", "#}"}
+
+// spdxIdentifier returns the license expression of an
+// SPDX-License-Identifier tag on line, if it has one and the expression
+// is valid.
+func spdxIdentifier(line string) string {
+	match := spdxPattern.FindStringSubmatch(line)
+	if match == nil {
+		return ""
+	}
+
+	expression := match[1]
+	for _, closer := range commentClosers {
+		if i := strings.Index(expression, closer); i >= 0 {
+			expression = expression[:i]
+		}
+	}
+	expression = strings.Join(strings.Fields(expression), " ")
+	if !isSPDXExpression(expression) {
+		return ""
+	}
+	return expression
+}
+
+// isSPDXExpression reports whether expression is a valid SPDX license
+// expression: identifiers, optionally WITH an exception, combined with
+// AND and OR and grouped by parentheses.
+func isSPDXExpression(expression string) bool {
+	tokens := strings.Fields(strings.NewReplacer("(", " ( ", ")", " ) ").Replace(expression))
+	if len(tokens) == 0 {
+		return false
+	}
+
+	rest, ok := parseSPDXExpression(tokens)
+	return ok && len(rest) == 0
+}
+
+// parseSPDXExpression consumes an expression from the start of tokens and
+// returns the tokens that follow it.
+func parseSPDXExpression(tokens []string) ([]string, bool) {
+	for {
+		var ok bool
+		if tokens, ok = parseSPDXTerm(tokens); !ok {
+			return nil, false
+		}
+		if len(tokens) == 0 || (tokens[0] != "AND" && tokens[0] != "OR") {
+			return tokens, true
+		}
+		tokens = tokens[1:]
+	}
+}
+
+// parseSPDXTerm consumes a parenthesized expression, or an identifier
+// with an optional exception.
+func parseSPDXTerm(tokens []string) ([]string, bool) {
+	if len(tokens) == 0 {
+		return nil, false
+	}
+
+	if tokens[0] == "(" {
+		rest, ok := parseSPDXExpression(tokens[1:])
+		if !ok || len(rest) == 0 || rest[0] != ")" {
+			return nil, false
+		}
+		return rest[1:], true
+	}
+
+	if !isSPDXID(tokens[0]) {
+		return nil, false
+	}
+	tokens = tokens[1:]
+	if len(tokens) > 0 && tokens[0] == "WITH" {
+		if len(tokens) < 2 || !isSPDXID(tokens[1]) {
+			return nil, false
+		}
+		tokens = tokens[2:]
+	}
+	return tokens, true
+}
+
+func isSPDXID(token string) bool {
+	switch token {
+	case "AND", "OR", "WITH":
+		return false
+	}
+	return spdxIDPattern.MatchString(token)
+}
+
+// stripComment removes the comment markers around a header line, so that
+// boilerplate reads the same in every language.
+func stripComment(line string) string {
+	line = strings.TrimSpace(line)
+	for _, marker := range []string{"/*", "<!--", "(*", "{-", "//", "--", "#", ";", "%", "'", "*"} {
+		line = strings.TrimLeft(strings.TrimPrefix(line, marker), "*/#;% ")
+	}
+	for _, closer := range commentClosers {
+		line = strings.TrimSuffix(line, closer)
+	}
+	return line
+}
+
+// detectHeaderLicense recognises the license notices that are placed at
+// the top of source files instead of an SPDX tag. Only notices stating
+// the version are mapped to a versioned identifier.
+func detectHeaderLicense(header string) string {
+	// Notices are hard-wrapped differently in every file
+	text := strings.Join(strings.Fields(strings.ToLower(header)), " ")
+
+	switch {
+	case strings.Contains(text, "gnu affero general public license"):
+		return gnuLicense(text, "AGPL")
+	case strings.Contains(text, "gnu lesser general public license"), strings.Contains(text, "gnu library general public license"):
+		return gnuLicense(text, "LGPL")
+	case strings.Contains(text, "gnu general public license"):
+		return gnuLicense(text, "GPL")
+	}
+
+	patterns := []struct {
+		pattern string
+		license string
+	}{
+		{"licensed under the apache license, version 2.0", "Apache-2.0"},
+		{"apache license, version 2.0", "Apache-2.0"},
+		{"mozilla public license, v. 2.0", "MPL-2.0"},
+		{"mozilla public license version 2.0", "MPL-2.0"},
+		{"eclipse public license v2.0", "EPL-2.0"},
+		{"eclipse public license - v 2.0", "EPL-2.0"},
+		{"governed by a bsd-style license", "BSD-3-Clause"},
+		{"governed by an mit-style license", "MIT"},
+		{"licensed under the mit license", "MIT"},
+		{"permission is hereby granted, free of charge", "MIT"},
+		{"neither the name of", "BSD-3-Clause"},
+		{"redistribution and use in source and binary forms", "BSD-2-Clause"},
+		{"permission to use, copy, modify, and/or distribute this software", "ISC"},
+		{"boost software license", "BSL-1.0"},
+		{"this is free and unencumbered software released into the public domain", "Unlicense"},
+	}
+
+	for _, p := range patterns {
+		if strings.Contains(text, p.pattern) {
+			return p.license
+		}
+	}
+
+	return "UNKNOWN"
+}
+
+// gnuLicense returns the SPDX identifier of a GNU license notice, such as
+// GPL-2.0-or-later for "either version 2 of the License, or (at your
+// option) any later version".
+func gnuLicense(text, family string) string {
+	version := ""
+	for _, v := range []string{"3", "2.1", "2"} {
+		if strings.Contains(text, "version "+v+" of the license") || strings.Contains(text, "version "+v+",") {
+			version = v
+			break
+		}
+	}
+	if version == "" {
+		return family
+	}
+	if !strings.Contains(version, ".") {
+		version += ".0"
+	}
+
+	if strings.Contains(text, "any later version") {
+		return family + "-" + version + "-or-later"
+	}
+	return family + "-" + version + "-only"
+}
@@ -0,0 +1,109 @@
+package hex
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"license-audit/internal/license"
+	"license-audit/pkg/types"
+)
+
+// FetchedPackage is the license of a dependency whose sources have been
+// fetched, read from its hex_metadata.config or .app.src and license file.
+type FetchedPackage struct {
+	LicenseType string
+	LicenseText string
+	Homepage    string
+	Repository  string
+}
+
+// packageReader caches the fetched packages read by both scanners of this
+// package, keyed by directory.
+type packageReader struct {
+	licenseCache map[string]*FetchedPackage
+}
+
+func newDependency(name, version, filePath string) types.Dependency {
+	if version == "" {
+		version = "UNKNOWN"
+	}
+
+	return types.Dependency{
+		Name:        name,
+		Version:     version,
+		LicenseType: "UNKNOWN",
+		PackageType: "hex",
+		FilePath:    filePath,
+	}
+}
+
+// applyFetchedLicenses fills in licenses from the first of each
+// dependency's candidate directories that holds the fetched package.
+func (r *packageReader) applyFetchedLicenses(dependencies []types.Dependency, candidates func(dep *types.Dependency) []string) {
+	for i := range dependencies {
+		dep := &dependencies[i]
+		if dep.Scope == "project" {
+			continue
+		}
+
+		for _, candidate := range candidates(dep) {
+			fetched := r.readPackage(candidate)
+			if fetched == nil {
+				continue
+			}
+			if dep.LicenseType == "UNKNOWN" || dep.LicenseType == "" {
+				dep.LicenseType = fetched.LicenseType
+			}
+			if dep.LicenseText == "" {
+				dep.LicenseText = fetched.LicenseText
+			}
+			if dep.Homepage == "" {
+				dep.Homepage = fetched.Homepage
+			}
+			if dep.Repository == "" {
+				dep.Repository = fetched.Repository
+			}
+			break
+		}
+	}
+}
+
+// readPackage reads a fetched package. Hex packages carry their declared
+// licenses in hex_metadata.config; git and path dependencies only have
+// the licenses of their .app.src, if any, and a license file.
+func (r *packageReader) readPackage(packageDir string) *FetchedPackage {
+	if fetched, ok := r.licenseCache[packageDir]; ok {
+		return fetched
+	}
+
+	var fetched *FetchedPackage
+	if info, err := os.Stat(packageDir); err == nil && info.IsDir() {
+		metadata := readHexMetadata(filepath.Join(packageDir, "hex_metadata.config"))
+		if metadata == nil {
+			metadata = readAppSrc(packageDir)
+		}
+		if metadata == nil {
+			metadata = &AppMetadata{}
+		}
+
+		fetched = &FetchedPackage{
+			LicenseType: "UNKNOWN",
+			LicenseText: license.ReadFile(packageDir),
+			Homepage:    metadata.Homepage,
+			Repository:  metadata.Repository,
+		}
+		switch {
+		case len(metadata.Licenses) > 0:
+			fetched.LicenseType = strings.Join(metadata.Licenses, " OR ")
+		case fetched.LicenseText != "":
+			fetched.LicenseType = license.Detect(fetched.LicenseText)
+		}
+	}
+
+	if r.licenseCache == nil {
+		r.licenseCache = make(map[string]*FetchedPackage)
+	}
+	r.licenseCache[packageDir] = fetched
+	return fetched
+}
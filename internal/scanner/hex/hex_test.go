@@ -0,0 +1,334 @@
+package hex
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const (
+	elixirFixturesDir = "../../../test/fixtures/elixir"
+	erlangFixturesDir = "../../../test/fixtures/erlang"
+)
+
+func TestDetect(t *testing.T) {
+	mix := NewScanner()
+	rebar := NewRebarScanner()
+
+	testCases := []struct {
+		path  string
+		mix   bool
+		rebar bool
+	}{
+		{"mix.exs", true, false},
+		{"mix.lock", true, false},
+		{"apps/web/mix.exs", true, false},
+		{"rebar.config", false, true},
+		{"rebar.lock", false, true},
+		{"rebar.config.script", false, false},
+		{"config/config.exs", false, false},
+		{"", false, false},
+	}
+
+	for _, tc := range testCases {
+		if result := mix.Detect(tc.path); result != tc.mix {
+			t.Errorf("mix Detect(%s) = %v, expected %v", tc.path, result, tc.mix)
+		}
+		if result := rebar.Detect(tc.path); result != tc.rebar {
+			t.Errorf("rebar Detect(%s) = %v, expected %v", tc.path, result, tc.rebar)
+		}
+	}
+}
+
+func TestParseTerms(t *testing.T) {
+	terms, err := parseTerms(`
+%% comment
+{deps, [cowboy, {jsx, "3.1.0"}, {'quoted-atom', <<"bin">>, <<>>}]}.
+[1, -2.5].
+`)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []interface{}{
+		Tuple{Atom("deps"), List{Atom("cowboy"), Tuple{Atom("jsx"), "3.1.0"}, Tuple{Atom("quoted-atom"), "bin", ""}}},
+		List{"1", "-2.5"},
+	}
+	if !reflect.DeepEqual(terms, expected) {
+		t.Errorf("parseTerms = %#v, expected %#v", terms, expected)
+	}
+
+	// Trailing keyword pairs of a tuple form a keyword list
+	term, err := parseTermAt(`{:credo, "~> 1.7", only: [:dev, :test], runtime: false}`, 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	want := Tuple{Atom("credo"), "~> 1.7", List{
+		Tuple{Atom("only"), List{Atom("dev"), Atom("test")}},
+		Tuple{Atom("runtime"), Atom("false")},
+	}}
+	if !reflect.DeepEqual(term, want) {
+		t.Errorf("parseTermAt = %#v, expected %#v", term, want)
+	}
+}
+
+func TestScanMixExs(t *testing.T) {
+	scanner := NewScanner()
+
+	dependencies, err := scanner.Scan(filepath.Join(elixirFixturesDir, "app", "mix.exs"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	type expectation struct {
+		version string
+		scope   string
+		source  string
+		license string
+	}
+
+	expected := map[string]expectation{
+		"acme_web":      {"1.4.0", "project", "", "Apache-2.0"},
+		"jason":         {"~> 1.4", "", "", "Apache-2.0"},
+		"plug_crypto":   {"~> 2.0", "optional", "", "Apache-2.0 OR MIT"},
+		"ecto_extras":   {"UNKNOWN", "", "git", "MIT"},
+		"local_helpers": {"UNKNOWN", "", "path", "Apache-2.0"},
+		"billing":       {"~> 0.2", "", "registry", "UNKNOWN"},
+		"credo":         {"~> 1.7", "dev", "", "UNKNOWN"},
+		"mox":           {"~> 1.1", "test", "", "UNKNOWN"},
+		"telemetry":     {"~> 1.2", "", "", "UNKNOWN"}, // only: Mix.env()
+	}
+
+	if len(dependencies) != len(expected) {
+		t.Errorf("Expected %d dependencies, got %d", len(expected), len(dependencies))
+	}
+
+	for _, dep := range dependencies {
+		want, ok := expected[dep.Name]
+		if !ok {
+			t.Errorf("Unexpected dependency '%s'", dep.Name)
+			continue
+		}
+		got := expectation{dep.Version, dep.Scope, dep.Source, dep.LicenseType}
+		if got != want {
+			t.Errorf("%s = %+v, expected %+v", dep.Name, got, want)
+		}
+
+		switch dep.Name {
+		case "acme_web":
+			if dep.Repository != "https://github.com/acme/acme_web" {
+				t.Errorf("Unexpected repository for acme_web: %s", dep.Repository)
+			}
+		case "ecto_extras":
+			if dep.SourceURL != "https://github.com/acme/ecto_extras.git#v0.3.1" {
+				t.Errorf("Unexpected source URL for ecto_extras: %s", dep.SourceURL)
+			}
+		case "billing":
+			if dep.SourceURL != "hexpm:acme" {
+				t.Errorf("Unexpected source URL for billing: %s", dep.SourceURL)
+			}
+		case "jason":
+			if dep.Repository != "https://github.com/michalmuskala/jason" {
+				t.Errorf("Expected the repository from hex_metadata.config, got %s", dep.Repository)
+			}
+		}
+	}
+}
+
+func TestScanMixLock(t *testing.T) {
+	scanner := NewScanner()
+
+	dependencies, err := scanner.Scan(filepath.Join(elixirFixturesDir, "app", "mix.lock"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	type expectation struct {
+		version  string
+		scope    string
+		source   string
+		indirect bool
+		license  string
+	}
+
+	expected := map[string]expectation{
+		"billing":     {"0.2.4", "", "registry", false, "UNKNOWN"},
+		"bunt":        {"1.0.0", "", "", true, "UNKNOWN"},
+		"credo":       {"1.7.1", "dev", "", false, "UNKNOWN"},
+		"ecto_extras": {"v0.3.1", "", "git", false, "MIT"},
+		"jason":       {"1.4.1", "", "", false, "Apache-2.0"},
+		"mox":         {"1.1.0", "test", "", false, "UNKNOWN"},
+		"plug_crypto": {"2.0.0", "optional", "", false, "Apache-2.0 OR MIT"},
+		"telemetry":   {"1.2.1", "", "", false, "UNKNOWN"},
+	}
+
+	if len(dependencies) != len(expected) {
+		t.Errorf("Expected %d dependencies, got %d", len(expected), len(dependencies))
+	}
+
+	for _, dep := range dependencies {
+		want, ok := expected[dep.Name]
+		if !ok {
+			t.Errorf("Unexpected dependency '%s'", dep.Name)
+			continue
+		}
+		got := expectation{dep.Version, dep.Scope, dep.Source, dep.Indirect, dep.LicenseType}
+		if got != want {
+			t.Errorf("%s = %+v, expected %+v", dep.Name, got, want)
+		}
+
+		switch dep.Name {
+		case "jason":
+			if len(dep.Hashes) != 1 || dep.Hashes[0] != "sha256:fbb01ecdfd565b56261302f7e1fcc27c4fb8f32d56eab74db621fc154604a7a1" {
+				t.Errorf("Expected the outer checksum of jason, got %v", dep.Hashes)
+			}
+		case "credo":
+			if !reflect.DeepEqual(dep.Requires, []string{"bunt", "jason"}) {
+				t.Errorf("Unexpected requirements for credo: %v", dep.Requires)
+			}
+		case "ecto_extras":
+			if dep.SourceURL != "https://github.com/acme/ecto_extras.git#4f2d6c9b1e0a7d3c5b8e9f0a1b2c3d4e5f6a7b8c" {
+				t.Errorf("Unexpected source URL for ecto_extras: %s", dep.SourceURL)
+			}
+		case "billing":
+			if dep.SourceURL != "hexpm:acme" {
+				t.Errorf("Unexpected source URL for billing: %s", dep.SourceURL)
+			}
+		}
+	}
+}
+
+func TestScanRebarConfig(t *testing.T) {
+	scanner := NewRebarScanner()
+
+	dependencies, err := scanner.Scan(filepath.Join(erlangFixturesDir, "rebar.config"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	type expectation struct {
+		version string
+		scope   string
+		source  string
+		license string
+	}
+
+	expected := map[string]expectation{
+		"acme_svc": {"1.0.0", "project", "", "Apache-2.0"},
+		"cowboy":   {"2.10.0", "", "", "ISC"},
+		"lager":    {"UNKNOWN", "", "", "UNKNOWN"},
+		"recon":    {"2.5.3", "", "git", "BSD-3-Clause"}, // from its .app.src
+		"jsx":      {"UNKNOWN", "", "", "UNKNOWN"},
+		"legacy":   {"master", "", "git", "UNKNOWN"},
+		"meck":     {"UNKNOWN", "test", "", "UNKNOWN"},
+		"proper":   {"1.4.0", "test", "", "UNKNOWN"},
+		"edown":    {"0.8.4", "dev", "", "UNKNOWN"},
+	}
+
+	if len(dependencies) != len(expected) {
+		t.Errorf("Expected %d dependencies, got %d", len(expected), len(dependencies))
+	}
+
+	for _, dep := range dependencies {
+		want, ok := expected[dep.Name]
+		if !ok {
+			t.Errorf("Unexpected dependency '%s'", dep.Name)
+			continue
+		}
+		got := expectation{dep.Version, dep.Scope, dep.Source, dep.LicenseType}
+		if got != want {
+			t.Errorf("%s = %+v, expected %+v", dep.Name, got, want)
+		}
+
+		switch dep.Name {
+		case "cowboy":
+			if dep.Repository != "https://github.com/ninenines/cowboy" {
+				t.Errorf("Expected the repository from hex_metadata.config, got %s", dep.Repository)
+			}
+		case "recon":
+			if dep.SourceURL != "https://github.com/ferd/recon.git#2.5.3" {
+				t.Errorf("Unexpected source URL for recon: %s", dep.SourceURL)
+			}
+		}
+	}
+}
+
+func TestScanRebarLock(t *testing.T) {
+	scanner := NewRebarScanner()
+
+	testCases := []struct {
+		name     string
+		path     string
+		expected map[string]bool // name -> indirect
+	}{
+		{
+			name: "rebar3 1.2.0 format",
+			path: filepath.Join(erlangFixturesDir, "rebar.lock"),
+			expected: map[string]bool{
+				"cowboy": false,
+				"cowlib": true,
+				"jsx":    false,
+				"lager":  false,
+				"ranch":  true,
+				"recon":  false,
+			},
+		},
+		{
+			name: "legacy list format",
+			path: filepath.Join(erlangFixturesDir, "v1", "rebar.lock"),
+			expected: map[string]bool{
+				"goldrush": true,
+				"lager":    false,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dependencies, err := scanner.Scan(tc.path)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if len(dependencies) != len(tc.expected) {
+				t.Errorf("Expected %d dependencies, got %d", len(tc.expected), len(dependencies))
+			}
+
+			for _, dep := range dependencies {
+				indirect, ok := tc.expected[dep.Name]
+				if !ok {
+					t.Errorf("Unexpected dependency '%s'", dep.Name)
+					continue
+				}
+				if dep.Indirect != indirect {
+					t.Errorf("%s indirect = %v, expected %v", dep.Name, dep.Indirect, indirect)
+				}
+			}
+		})
+	}
+
+	dependencies, err := scanner.Scan(filepath.Join(erlangFixturesDir, "rebar.lock"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for _, dep := range dependencies {
+		switch dep.Name {
+		case "cowboy":
+			if dep.Version != "2.10.0" || dep.LicenseType != "ISC" {
+				t.Errorf("cowboy = %s %s, expected 2.10.0 ISC", dep.Version, dep.LicenseType)
+			}
+			if len(dep.Hashes) != 1 || dep.Hashes[0] != "sha256:3afdccb7183cc6f143cb14d3cf51fa00e53db9ec80cdcd525482f5e99bc41d6b" {
+				t.Errorf("Expected the pkg_hash_ext of cowboy, got %v", dep.Hashes)
+			}
+		case "cowlib":
+			if dep.LicenseType != "ISC" {
+				t.Errorf("Expected ISC from the cowlib license file, got %s", dep.LicenseType)
+			}
+		case "recon":
+			if dep.Source != "git" || dep.Version != "c2a76855be3a226a3148c0dfc21ce000b6186ef8" {
+				t.Errorf("recon = %s %s, expected a git source locked to its ref", dep.Source, dep.Version)
+			}
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package hex
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// AppMetadata is what an Erlang application says about itself, in the
+// hex_metadata.config of a Hex package or in its .app.src.
+type AppMetadata struct {
+	Name       string
+	Version    string
+	Licenses   []string
+	Homepage   string
+	Repository string
+}
+
+// readHexMetadata reads the hex_metadata.config that Hex writes into each
+// fetched package: a file of {<<"key">>, Value} terms.
+func readHexMetadata(path string) *AppMetadata {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	terms, err := parseTerms(string(data))
+	if err != nil {
+		return nil
+	}
+
+	var properties List
+	for _, term := range terms {
+		if pair, ok := term.(Tuple); ok && len(pair) == 2 {
+			properties = append(properties, pair)
+		}
+	}
+
+	return appMetadata(properties)
+}
+
+// readAppSrc reads the application resource file of an Erlang project:
+// {application, Name, [{vsn, "1.0.0"}, {licenses, ["MIT"]}, ...]}.
+func readAppSrc(dir string) *AppMetadata {
+	matches, _ := filepath.Glob(filepath.Join(dir, "src", "*.app.src"))
+	if len(matches) == 0 {
+		return nil
+	}
+
+	data, err := os.ReadFile(matches[0])
+	if err != nil {
+		return nil
+	}
+
+	terms, err := parseTerms(string(data))
+	if err != nil || len(terms) == 0 {
+		return nil
+	}
+
+	application, ok := terms[0].(Tuple)
+	if !ok || len(application) != 3 || termString(application[0]) != "application" {
+		return nil
+	}
+
+	metadata := appMetadata(application[2])
+	if metadata.Name == "" {
+		metadata.Name = termString(application[1])
+	}
+	return metadata
+}
+
+func appMetadata(properties interface{}) *AppMetadata {
+	metadata := &AppMetadata{}
+
+	if name, ok := keyword(properties, "name"); ok {
+		metadata.Name = termString(name)
+	}
+	for _, key := range []string{"version", "vsn"} {
+		if version, ok := keyword(properties, key); ok && metadata.Version == "" {
+			// vsn may be the atom git, computed at build time
+			if _, isAtom := version.(Atom); !isAtom {
+				metadata.Version = termString(version)
+			}
+		}
+	}
+
+	if licenses, ok := keyword(properties, "licenses"); ok {
+		if list, ok := licenses.(List); ok {
+			for _, license := range list {
+				if name := termString(license); name != "" {
+					metadata.Licenses = append(metadata.Licenses, name)
+				}
+			}
+		}
+	}
+
+	if links, ok := keyword(properties, "links"); ok {
+		if list, ok := links.(List); ok {
+			for _, link := range list {
+				pair, ok := link.(Tuple)
+				if !ok || len(pair) != 2 {
+					continue
+				}
+				label := strings.ToLower(termString(pair[0]))
+				url := termString(pair[1])
+				switch {
+				case label == "github" || label == "gitlab" || label == "source" || label == "repository":
+					if metadata.Repository == "" {
+						metadata.Repository = url
+					}
+				case label == "homepage" || label == "website":
+					if metadata.Homepage == "" {
+						metadata.Homepage = url
+					}
+				}
+			}
+		}
+	}
+
+	return metadata
+}
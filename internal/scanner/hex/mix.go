@@ -0,0 +1,391 @@
+package hex
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"license-audit/internal/license"
+	"license-audit/pkg/types"
+)
+
+// Scanner reports the dependencies of Elixir projects from mix.exs and
+// mix.lock.
+type Scanner struct {
+	packageReader
+}
+
+// MixProject is the static part of a mix.exs that names the project and
+// its dependencies.
+type MixProject struct {
+	App          string
+	Version      string
+	Licenses     []string
+	Homepage     string
+	Repository   string
+	DepsPath     string
+	Dependencies []MixDependency
+}
+
+// MixDependency is one {:name, requirement, options} entry of deps/0.
+type MixDependency struct {
+	Name        string
+	Requirement string
+	Scope       string
+	Source      string
+	SourceURL   string
+}
+
+var (
+	mixDepsPattern      = regexp.MustCompile(`defp?\s+deps(?:\(\s*\))?\s*(?:do|,\s*do:)\s*\[`)
+	mixAppPattern       = regexp.MustCompile(`\bapp:\s*:(\w+)`)
+	mixVersionPattern   = regexp.MustCompile(`\bversion:\s*(?:"([^"]+)"|@(\w+))`)
+	mixLicensesPattern  = regexp.MustCompile(`\blicenses:\s*\[([^\]]*)\]`)
+	mixSourcePattern    = regexp.MustCompile(`\bsource_url:\s*"([^"]+)"`)
+	mixHomepagePattern  = regexp.MustCompile(`\bhomepage_url:\s*"([^"]+)"`)
+	mixDepsPathPattern  = regexp.MustCompile(`\bdeps_path:\s*"([^"]+)"`)
+	quotedStringPattern = regexp.MustCompile(`"([^"]*)"`)
+)
+
+func NewScanner() *Scanner {
+	return &Scanner{}
+}
+
+func (s *Scanner) Name() string {
+	return "mix"
+}
+
+func (s *Scanner) Detect(path string) bool {
+	fileName := filepath.Base(path)
+	return fileName == "mix.exs" || fileName == "mix.lock"
+}
+
+func (s *Scanner) Scan(path string) ([]types.Dependency, error) {
+	dependencies, err := s.scanFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Dir(path)
+	depsPath := "deps"
+	if project, err := readMixProject(filepath.Join(dir, "mix.exs")); err == nil && project.DepsPath != "" {
+		depsPath = project.DepsPath
+	}
+
+	s.applyFetchedLicenses(dependencies, func(dep *types.Dependency) []string {
+		if dep.Source == "path" {
+			return []string{filepath.Join(dir, filepath.FromSlash(dep.SourceURL))}
+		}
+		return []string{filepath.Join(dir, filepath.FromSlash(depsPath), dep.Name)}
+	})
+	return dependencies, nil
+}
+
+func (s *Scanner) scanFile(path string) ([]types.Dependency, error) {
+	fileName := filepath.Base(path)
+
+	switch fileName {
+	case "mix.exs":
+		return s.scanMixExs(path)
+	case "mix.lock":
+		return s.scanMixLock(path)
+	default:
+		return nil, fmt.Errorf("unsupported mix file: %s", fileName)
+	}
+}
+
+// readMixProject extracts the project and dependencies from a mix.exs
+// without evaluating it. The deps list must be a literal; entries that
+// use function calls, such as only: Mix.env(), keep what can be read.
+func readMixProject(path string) (*MixProject, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mix.exs: %w", err)
+	}
+	content := string(data)
+
+	project := &MixProject{}
+	if match := mixAppPattern.FindStringSubmatch(content); match != nil {
+		project.App = match[1]
+	}
+	if match := mixVersionPattern.FindStringSubmatch(content); match != nil {
+		project.Version = match[1]
+		if match[2] != "" {
+			// version: @version refers to a module attribute
+			attribute := regexp.MustCompile(`@` + regexp.QuoteMeta(match[2]) + `\s+"([^"]+)"`)
+			if value := attribute.FindStringSubmatch(content); value != nil {
+				project.Version = value[1]
+			}
+		}
+	}
+	if match := mixLicensesPattern.FindStringSubmatch(content); match != nil {
+		for _, license := range quotedStringPattern.FindAllStringSubmatch(match[1], -1) {
+			project.Licenses = append(project.Licenses, license[1])
+		}
+	}
+	if match := mixSourcePattern.FindStringSubmatch(content); match != nil {
+		project.Repository = match[1]
+	}
+	if match := mixHomepagePattern.FindStringSubmatch(content); match != nil {
+		project.Homepage = match[1]
+	}
+	if match := mixDepsPathPattern.FindStringSubmatch(content); match != nil {
+		project.DepsPath = match[1]
+	}
+
+	location := mixDepsPattern.FindStringIndex(content)
+	if location == nil {
+		return project, nil
+	}
+
+	term, err := parseTermAt(content, location[1]-1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse mix.exs deps: %w", err)
+	}
+
+	list, _ := term.(List)
+	for _, item := range list {
+		if entry, ok := item.(Tuple); ok {
+			if dep, ok := mixDependency(entry); ok {
+				project.Dependencies = append(project.Dependencies, dep)
+			}
+		}
+	}
+
+	return project, nil
+}
+
+// mixDependency reads {:name, "~> 1.0", opts}, {:name, opts} or {:name,
+// "~> 1.0"}.
+func mixDependency(entry Tuple) (MixDependency, bool) {
+	name, ok := entry[0].(Atom)
+	if !ok || len(entry) < 2 {
+		return MixDependency{}, false
+	}
+
+	dep := MixDependency{Name: string(name)}
+
+	var options interface{}
+	for _, element := range entry[1:] {
+		switch v := element.(type) {
+		case string:
+			dep.Requirement = v
+		case List:
+			options = v
+		}
+	}
+
+	if only, ok := keyword(options, "only"); ok {
+		dep.Scope = onlyScope(atoms(only))
+	}
+	if optional, ok := keyword(options, "optional"); ok && termString(optional) == "true" && dep.Scope == "" {
+		dep.Scope = "optional"
+	}
+
+	if path, ok := keyword(options, "path"); ok {
+		dep.Source = "path"
+		dep.SourceURL = termString(path)
+	} else if umbrella, ok := keyword(options, "in_umbrella"); ok && termString(umbrella) == "true" {
+		// Umbrella children are siblings under apps/
+		dep.Source = "path"
+		dep.SourceURL = "../" + dep.Name
+	} else if git, ok := keyword(options, "git"); ok {
+		dep.Source = "git"
+		dep.SourceURL = termString(git)
+	} else if github, ok := keyword(options, "github"); ok {
+		dep.Source = "git"
+		dep.SourceURL = "https://github.com/" + termString(github) + ".git"
+	} else if repo, ok := keyword(options, "repo"); ok && termString(repo) != "hexpm" {
+		dep.Source = "registry"
+		dep.SourceURL = termString(repo)
+	} else if organization, ok := keyword(options, "organization"); ok {
+		dep.Source = "registry"
+		dep.SourceURL = "hexpm:" + termString(organization)
+	}
+
+	if dep.Source == "git" {
+		for _, key := range []string{"ref", "tag", "branch"} {
+			if ref, ok := keyword(options, key); ok {
+				dep.SourceURL += "#" + termString(ref)
+				break
+			}
+		}
+	}
+
+	return dep, true
+}
+
+// onlyScope maps the environments of only: to a scope. Dependencies that
+// reach :prod are runtime dependencies.
+func onlyScope(environments []string) string {
+	if len(environments) == 0 {
+		return ""
+	}
+
+	scope := "test"
+	for _, environment := range environments {
+		switch environment {
+		case "prod":
+			return ""
+		case "test":
+		default:
+			if strings.ContainsAny(environment, ".(") {
+				// A computed environment such as Mix.env()
+				return ""
+			}
+			scope = "dev"
+		}
+	}
+	return scope
+}
+
+func (s *Scanner) scanMixExs(path string) ([]types.Dependency, error) {
+	project, err := readMixProject(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var dependencies []types.Dependency
+
+	if project.App != "" {
+		dep := newDependency(project.App, project.Version, path)
+		dep.Scope = "project"
+		dep.Homepage = project.Homepage
+		dep.Repository = project.Repository
+		dep.LicenseText = license.ReadFile(filepath.Dir(path))
+		switch {
+		case len(project.Licenses) > 0:
+			dep.LicenseType = strings.Join(project.Licenses, " OR ")
+		case dep.LicenseText != "":
+			dep.LicenseType = license.Detect(dep.LicenseText)
+		}
+		dependencies = append(dependencies, dep)
+	}
+
+	for _, declared := range project.Dependencies {
+		dep := newDependency(declared.Name, declared.Requirement, path)
+		dep.Scope = declared.Scope
+		dep.Source = declared.Source
+		dep.SourceURL = declared.SourceURL
+		dependencies = append(dependencies, dep)
+	}
+
+	return dependencies, nil
+}
+
+// scanMixLock reads the %{"name" => {:hex | :git | :path, ...}} map of a
+// mix.lock. Dependencies declared in the neighbouring mix.exs are direct
+// and take their scope from it; the others are transitive.
+func (s *Scanner) scanMixLock(path string) ([]types.Dependency, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mix.lock: %w", err)
+	}
+
+	terms, err := parseTerms(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse mix.lock: %w", err)
+	}
+	if len(terms) == 0 {
+		return nil, nil
+	}
+	entries, ok := terms[0].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("failed to parse mix.lock: expected a map")
+	}
+
+	var direct map[string]MixDependency
+	if project, err := readMixProject(filepath.Join(filepath.Dir(path), "mix.exs")); err == nil {
+		direct = make(map[string]MixDependency)
+		for _, dep := range project.Dependencies {
+			direct[dep.Name] = dep
+		}
+	}
+
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var dependencies []types.Dependency
+	for _, name := range names {
+		entry, ok := entries[name].(Tuple)
+		if !ok || len(entry) < 2 {
+			continue
+		}
+
+		dep := newDependency(name, "", path)
+		switch termString(entry[0]) {
+		case "hex":
+			lockedHexPackage(&dep, entry)
+		case "git":
+			dep.Source = "git"
+			dep.SourceURL = termString(entry[1])
+			if len(entry) > 2 {
+				revision := termString(entry[2])
+				dep.SourceURL += "#" + revision
+				dep.Version = revision
+			}
+			if len(entry) > 3 {
+				if tag, ok := keyword(entry[3], "tag"); ok {
+					dep.Version = termString(tag)
+				}
+			}
+		case "path":
+			dep.Source = "path"
+			dep.SourceURL = termString(entry[1])
+		default:
+			continue
+		}
+
+		if direct != nil {
+			if declared, ok := direct[name]; ok {
+				dep.Scope = declared.Scope
+			} else {
+				dep.Indirect = true
+			}
+		}
+
+		dependencies = append(dependencies, dep)
+	}
+
+	return dependencies, nil
+}
+
+// lockedHexPackage reads {:hex, :package, "version", "inner checksum",
+// managers, deps, "repo", "outer checksum"}. Older locks stop after the
+// inner checksum or the deps.
+func lockedHexPackage(dep *types.Dependency, entry Tuple) {
+	if len(entry) > 2 {
+		dep.Version = termString(entry[2])
+	}
+
+	if len(entry) > 5 {
+		if requirements, ok := entry[5].(List); ok {
+			for _, requirement := range requirements {
+				if tuple, ok := requirement.(Tuple); ok && len(tuple) > 0 {
+					dep.Requires = append(dep.Requires, termString(tuple[0]))
+				}
+			}
+		}
+	}
+
+	if len(entry) > 6 {
+		if repo := termString(entry[6]); repo != "" && repo != "hexpm" {
+			dep.Source = "registry"
+			dep.SourceURL = repo
+		}
+	}
+
+	// The outer checksum is the SHA-256 of the package tarball
+	switch {
+	case len(entry) > 7 && termString(entry[7]) != "":
+		dep.Hashes = []string{"sha256:" + strings.ToLower(termString(entry[7]))}
+	case len(entry) > 3 && termString(entry[3]) != "":
+		dep.Hashes = []string{"sha256:" + strings.ToLower(termString(entry[3]))}
+	}
+}
@@ -0,0 +1,255 @@
+package hex
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"license-audit/internal/license"
+	"license-audit/pkg/types"
+)
+
+// RebarScanner reports the dependencies of Erlang projects from
+// rebar.config and rebar.lock.
+type RebarScanner struct {
+	packageReader
+}
+
+func NewRebarScanner() *RebarScanner {
+	return &RebarScanner{}
+}
+
+func (s *RebarScanner) Name() string {
+	return "rebar"
+}
+
+func (s *RebarScanner) Detect(path string) bool {
+	fileName := filepath.Base(path)
+	return fileName == "rebar.config" || fileName == "rebar.lock"
+}
+
+func (s *RebarScanner) Scan(path string) ([]types.Dependency, error) {
+	dependencies, err := s.scanFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// rebar3 fetches into _build/default/lib; _checkouts overrides it
+	dir := filepath.Dir(path)
+	s.applyFetchedLicenses(dependencies, func(dep *types.Dependency) []string {
+		return []string{
+			filepath.Join(dir, "_checkouts", dep.Name),
+			filepath.Join(dir, "_build", "default", "lib", dep.Name),
+			filepath.Join(dir, "deps", dep.Name),
+		}
+	})
+	return dependencies, nil
+}
+
+func (s *RebarScanner) scanFile(path string) ([]types.Dependency, error) {
+	fileName := filepath.Base(path)
+
+	switch fileName {
+	case "rebar.config":
+		return s.scanRebarConfig(path)
+	case "rebar.lock":
+		return s.scanRebarLock(path)
+	default:
+		return nil, fmt.Errorf("unsupported rebar file: %s", fileName)
+	}
+}
+
+// scanRebarConfig reports the application described by src/*.app.src,
+// followed by the deps of rebar.config and those added by its profiles.
+func (s *RebarScanner) scanRebarConfig(path string) ([]types.Dependency, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rebar.config: %w", err)
+	}
+
+	terms, err := parseTerms(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse rebar.config: %w", err)
+	}
+
+	var config List
+	for _, term := range terms {
+		if tuple, ok := term.(Tuple); ok && len(tuple) == 2 {
+			config = append(config, tuple)
+		}
+	}
+
+	var dependencies []types.Dependency
+
+	dir := filepath.Dir(path)
+	if app := readAppSrc(dir); app != nil && app.Name != "" {
+		dep := newDependency(app.Name, app.Version, path)
+		dep.Scope = "project"
+		dep.Homepage = app.Homepage
+		dep.Repository = app.Repository
+		dep.LicenseText = license.ReadFile(dir)
+		switch {
+		case len(app.Licenses) > 0:
+			dep.LicenseType = strings.Join(app.Licenses, " OR ")
+		case dep.LicenseText != "":
+			dep.LicenseType = license.Detect(dep.LicenseText)
+		}
+		dependencies = append(dependencies, dep)
+	}
+
+	seen := make(map[string]bool)
+	addDeps := func(deps interface{}, scope string) {
+		list, _ := deps.(List)
+		for _, item := range list {
+			dep, ok := rebarDependency(item, path)
+			if !ok || seen[dep.Name] {
+				continue
+			}
+			seen[dep.Name] = true
+			dep.Scope = scope
+			dependencies = append(dependencies, dep)
+		}
+	}
+
+	if deps, ok := keyword(config, "deps"); ok {
+		addDeps(deps, "")
+	}
+	if profiles, ok := keyword(config, "profiles"); ok {
+		list, _ := profiles.(List)
+		for _, item := range list {
+			profile, ok := item.(Tuple)
+			if !ok || len(profile) != 2 {
+				continue
+			}
+			if deps, ok := keyword(profile[1], "deps"); ok {
+				scope := "dev"
+				if termString(profile[0]) == "test" {
+					scope = "test"
+				}
+				addDeps(deps, scope)
+			}
+		}
+	}
+
+	return dependencies, nil
+}
+
+// rebarDependency reads a deps entry: name, {name, "vsn"}, {name, Source}
+// or the rebar2 form {name, "vsn", Source}.
+func rebarDependency(item interface{}, path string) (types.Dependency, bool) {
+	if name, ok := item.(Atom); ok {
+		return newDependency(string(name), "", path), true
+	}
+
+	entry, ok := item.(Tuple)
+	if !ok || len(entry) < 2 {
+		return types.Dependency{}, false
+	}
+	name := termString(entry[0])
+	if name == "" {
+		return types.Dependency{}, false
+	}
+
+	dep := newDependency(name, "", path)
+	for _, element := range entry[1:] {
+		switch v := element.(type) {
+		case string:
+			dep.Version = v
+		case Tuple:
+			applyRebarSource(&dep, v)
+		}
+	}
+
+	return dep, true
+}
+
+// applyRebarSource reads {pkg, Name[, Vsn]}, {git, Url, {Ref, Value}} and
+// {git_subdir, Url, {Ref, Value}, Dir}. Hg sources are read as git ones.
+func applyRebarSource(dep *types.Dependency, source Tuple) {
+	if len(source) < 2 {
+		return
+	}
+
+	switch termString(source[0]) {
+	case "pkg":
+		if len(source) > 2 {
+			dep.Version = termString(source[2])
+		}
+	case "git", "git_subdir", "hg":
+		dep.Source = "git"
+		dep.SourceURL = termString(source[1])
+		if len(source) > 2 {
+			if ref, ok := source[2].(Tuple); ok && len(ref) == 2 {
+				// The ref pins the version; a rebar2 version is only a regex
+				dep.SourceURL += "#" + termString(ref[1])
+				dep.Version = termString(ref[1])
+			}
+		}
+	}
+}
+
+// scanRebarLock reads a rebar.lock: {"1.2.0", [Locks]}. [Hashes]. in
+// current rebar3, or a bare [Locks]. in older ones. Each lock is {Name,
+// Source, Level}, where level 0 is a direct dependency.
+func (s *RebarScanner) scanRebarLock(path string) ([]types.Dependency, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rebar.lock: %w", err)
+	}
+
+	terms, err := parseTerms(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse rebar.lock: %w", err)
+	}
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	var locks List
+	switch v := terms[0].(type) {
+	case List:
+		locks = v
+	case Tuple:
+		if len(v) == 2 {
+			locks, _ = v[1].(List)
+		}
+	}
+
+	// pkg_hash_ext is the SHA-256 of the tarball, pkg_hash that of its
+	// contents; prefer the former
+	hashes := make(map[string]string)
+	if len(terms) > 1 {
+		for _, key := range []string{"pkg_hash", "pkg_hash_ext"} {
+			list, _ := keyword(terms[1], key)
+			entries, _ := list.(List)
+			for _, entry := range entries {
+				if pair, ok := entry.(Tuple); ok && len(pair) == 2 {
+					hashes[termString(pair[0])] = strings.ToLower(termString(pair[1]))
+				}
+			}
+		}
+	}
+
+	var dependencies []types.Dependency
+	for _, item := range locks {
+		lock, ok := item.(Tuple)
+		if !ok || len(lock) != 3 {
+			continue
+		}
+
+		name := termString(lock[0])
+		dep := newDependency(name, "", path)
+		if source, ok := lock[1].(Tuple); ok {
+			applyRebarSource(&dep, source)
+		}
+		dep.Indirect = termString(lock[2]) != "0"
+		if hash := hashes[name]; hash != "" {
+			dep.Hashes = []string{"sha256:" + hash}
+		}
+
+		dependencies = append(dependencies, dep)
+	}
+
+	return dependencies, nil
+}
@@ -0,0 +1,398 @@
+package hex
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Term values produced by parseTerms: Atom, string (for both "strings" and
+// <<"binaries">>), Tuple, List and map[string]interface{}. Numbers are
+// kept as their source text.
+type (
+	Atom  string
+	Tuple []interface{}
+	List  []interface{}
+)
+
+// termParser reads the literal subset of Erlang and Elixir that lock and
+// config files use: tuples, lists, keyword lists, maps, atoms, strings and
+// binaries. Anything else, such as a function call, becomes an Atom of its
+// source text so the surrounding structure still parses.
+type termParser struct {
+	src string
+	pos int
+}
+
+// parseTerms reads a file of Erlang terms, each ended by a period, as
+// file:consult/1 does. An Elixir file holding a single term parses too.
+func parseTerms(src string) ([]interface{}, error) {
+	p := &termParser{src: src}
+
+	var terms []interface{}
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			return terms, nil
+		}
+
+		term, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+
+		p.skipSpace()
+		if p.peek() == '.' {
+			p.pos++
+		}
+	}
+}
+
+// parseTermAt reads the single term starting at offset in src.
+func parseTermAt(src string, offset int) (interface{}, error) {
+	p := &termParser{src: src, pos: offset}
+	return p.parseTerm()
+}
+
+func (p *termParser) peek() byte {
+	if p.pos < len(p.src) {
+		return p.src[p.pos]
+	}
+	return 0
+}
+
+func (p *termParser) errorf(format string, args ...interface{}) error {
+	line := strings.Count(p.src[:min(p.pos, len(p.src))], "\n") + 1
+	return fmt.Errorf("line %d: %s", line, fmt.Sprintf(format, args...))
+}
+
+// skipSpace skips whitespace and comments: % in Erlang, # in Elixir.
+func (p *termParser) skipSpace() {
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			p.pos++
+		case c == '#' || (c == '%' && !strings.HasPrefix(p.src[p.pos:], "%{")):
+			for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (p *termParser) parseTerm() (interface{}, error) {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return nil, p.errorf("unexpected end of input")
+	}
+
+	c := p.src[p.pos]
+	switch {
+	case c == '{':
+		p.pos++
+		elements, err := p.parseSequence('}')
+		return Tuple(elements), err
+	case c == '[':
+		p.pos++
+		elements, err := p.parseSequence(']')
+		return List(elements), err
+	case strings.HasPrefix(p.src[p.pos:], "%{"):
+		p.pos += 2
+		return p.parseMap()
+	case strings.HasPrefix(p.src[p.pos:], "<<"):
+		return p.parseBinary()
+	case c == '"':
+		return p.parseString('"')
+	case c == '\'':
+		value, err := p.parseString('\'')
+		return Atom(value), err
+	case c == ':' && p.pos+1 < len(p.src) && p.src[p.pos+1] == '"':
+		p.pos++
+		value, err := p.parseString('"')
+		return Atom(value), err
+	case c == ':':
+		p.pos++
+		return Atom(p.parseIdentifier()), nil
+	case c == '-' || (c >= '0' && c <= '9'):
+		start := p.pos
+		p.pos++
+		for p.pos < len(p.src) && strings.IndexByte("0123456789._eExXabcdefABCDEF#+-", p.src[p.pos]) >= 0 {
+			p.pos++
+		}
+		return p.src[start:p.pos], nil
+	case isIdentifierStart(c):
+		return p.parseExpression(), nil
+	}
+
+	return nil, p.errorf("unexpected %q", c)
+}
+
+// parseSequence reads comma-separated elements up to end. Elixir keyword
+// pairs (key: value) become {key, value} tuples; trailing keyword pairs
+// in a tuple are collected into a list, as Elixir does.
+func (p *termParser) parseSequence(end byte) ([]interface{}, error) {
+	var elements []interface{}
+	var keywords List
+
+	for {
+		p.skipSpace()
+		if p.peek() == end {
+			p.pos++
+			break
+		}
+
+		if key, ok := p.parseKeywordKey(); ok {
+			value, err := p.parseTerm()
+			if err != nil {
+				return nil, err
+			}
+			keywords = append(keywords, Tuple{Atom(key), value})
+		} else {
+			term, err := p.parseTerm()
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, term)
+		}
+
+		p.skipSpace()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case end:
+		case '|':
+			// [Head | Tail] only occurs in code, not in data
+			return nil, p.errorf("unsupported list tail")
+		default:
+			return nil, p.errorf("expected ',' or %q", end)
+		}
+	}
+
+	if len(keywords) > 0 {
+		if end == ']' {
+			elements = append(elements, keywords...)
+		} else {
+			elements = append(elements, keywords)
+		}
+	}
+	return elements, nil
+}
+
+// parseKeywordKey consumes "key: " if the next element is a keyword pair.
+func (p *termParser) parseKeywordKey() (string, bool) {
+	start := p.pos
+	if p.pos < len(p.src) && p.src[p.pos] == '"' {
+		key, err := p.parseString('"')
+		if err == nil && p.peek() == ':' && p.pos+1 < len(p.src) && unicode.IsSpace(rune(p.src[p.pos+1])) {
+			p.pos++
+			return key, true
+		}
+		p.pos = start
+		return "", false
+	}
+
+	if !isIdentifierStart(p.peek()) {
+		return "", false
+	}
+	key := p.parseIdentifier()
+	if p.peek() == ':' && p.pos+1 < len(p.src) && unicode.IsSpace(rune(p.src[p.pos+1])) {
+		p.pos++
+		return key, true
+	}
+	p.pos = start
+	return "", false
+}
+
+// parseMap reads the rest of %{key => value, key: value}.
+func (p *termParser) parseMap() (interface{}, error) {
+	result := make(map[string]interface{})
+
+	for {
+		p.skipSpace()
+		if p.peek() == '}' {
+			p.pos++
+			return result, nil
+		}
+
+		var key string
+		if keyword, ok := p.parseKeywordKey(); ok {
+			key = keyword
+		} else {
+			term, err := p.parseTerm()
+			if err != nil {
+				return nil, err
+			}
+			key = termString(term)
+
+			p.skipSpace()
+			if !strings.HasPrefix(p.src[p.pos:], "=>") {
+				return nil, p.errorf("expected '=>'")
+			}
+			p.pos += 2
+		}
+
+		value, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		result[key] = value
+
+		p.skipSpace()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case '}':
+		default:
+			return nil, p.errorf("expected ',' or '}'")
+		}
+	}
+}
+
+// parseBinary reads <<"text">>; the empty binary is <<>>.
+func (p *termParser) parseBinary() (interface{}, error) {
+	p.pos += 2
+	p.skipSpace()
+
+	var value string
+	if p.peek() == '"' {
+		var err error
+		if value, err = p.parseString('"'); err != nil {
+			return nil, err
+		}
+		// Drop a type specifier such as /utf8
+		for p.pos < len(p.src) && p.src[p.pos] != '>' {
+			p.pos++
+		}
+	}
+
+	if !strings.HasPrefix(p.src[p.pos:], ">>") {
+		return nil, p.errorf("expected '>>'")
+	}
+	p.pos += 2
+	return value, nil
+}
+
+func (p *termParser) parseString(quote byte) (string, error) {
+	p.pos++
+
+	var b strings.Builder
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == '\\' && p.pos+1 < len(p.src):
+			p.pos++
+			switch p.src[p.pos] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			default:
+				b.WriteByte(p.src[p.pos])
+			}
+		case c == quote:
+			p.pos++
+			return b.String(), nil
+		default:
+			b.WriteByte(c)
+		}
+		p.pos++
+	}
+
+	return "", p.errorf("unterminated string")
+}
+
+func (p *termParser) parseIdentifier() string {
+	start := p.pos
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		if !(isIdentifierStart(c) || (c >= '0' && c <= '9') || c == '@' || c == '?' || c == '!') {
+			break
+		}
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+// parseExpression reads a bare atom, or skips over a call such as
+// Mix.env() or System.get_env("X"), returning its source text.
+func (p *termParser) parseExpression() interface{} {
+	start := p.pos
+	for {
+		p.parseIdentifier()
+		if p.peek() == '.' && p.pos+1 < len(p.src) && isIdentifierStart(p.src[p.pos+1]) {
+			p.pos++
+			continue
+		}
+		break
+	}
+
+	if p.peek() == '(' {
+		depth := 0
+		for p.pos < len(p.src) {
+			switch p.src[p.pos] {
+			case '(':
+				depth++
+			case ')':
+				depth--
+			}
+			p.pos++
+			if depth == 0 {
+				break
+			}
+		}
+	}
+
+	return Atom(p.src[start:p.pos])
+}
+
+func isIdentifierStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// termString returns the text of an atom, string or number term.
+func termString(term interface{}) string {
+	switch v := term.(type) {
+	case Atom:
+		return string(v)
+	case string:
+		return v
+	}
+	return ""
+}
+
+// keyword looks up key in a keyword list or proplist of {key, value}
+// tuples.
+func keyword(list interface{}, key string) (interface{}, bool) {
+	items, ok := list.(List)
+	if !ok {
+		return nil, false
+	}
+	for _, item := range items {
+		if pair, ok := item.(Tuple); ok && len(pair) == 2 && termString(pair[0]) == key {
+			return pair[1], true
+		}
+	}
+	return nil, false
+}
+
+// atoms returns the atoms of a term that is either one atom or a list of
+// them, such as only: :test and only: [:dev, :test].
+func atoms(term interface{}) []string {
+	switch v := term.(type) {
+	case Atom:
+		return []string{string(v)}
+	case List:
+		var names []string
+		for _, item := range v {
+			if name := termString(item); name != "" {
+				names = append(names, name)
+			}
+		}
+		return names
+	}
+	return nil
+}
@@ -0,0 +1,295 @@
+package image
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"license-audit/pkg/types"
+)
+
+// Scanner reports the packages installed in container images saved to
+// disk: docker save tarballs, OCI archives and OCI image layout
+// directories. It applies the image's layers, with their whiteouts, and
+// reads the dpkg and apk databases and the metadata of Python, npm and
+// Ruby packages found in the resulting filesystem.
+type Scanner struct{}
+
+const (
+	mediaTypeOCIIndex    = "application/vnd.oci.image.index.v1+json"
+	mediaTypeDockerIndex = "application/vnd.docker.distribution.manifest.list.v2+json"
+)
+
+// descriptor points at a blob of an OCI image layout.
+type descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Annotations map[string]string `json:"annotations"`
+	Platform    *struct {
+		Architecture string `json:"architecture"`
+		OS           string `json:"os"`
+	} `json:"platform"`
+}
+
+// imageConfig is the part of an image configuration blob the scanner uses.
+type imageConfig struct {
+	Architecture string `json:"architecture"`
+	OS           string `json:"os"`
+	Config       struct {
+		Labels map[string]string `json:"Labels"`
+	} `json:"config"`
+}
+
+// manifest describes the image found in a layout: its reference, the
+// digest identifying it, its configuration and its layers, bottom first.
+type manifest struct {
+	reference string
+	digest    string
+	config    imageConfig
+	layers    []string
+}
+
+func NewScanner() *Scanner {
+	return &Scanner{}
+}
+
+func (s *Scanner) Name() string {
+	return "container-image"
+}
+
+// Detect accepts the oci-layout marker of an OCI image layout directory,
+// and .tar or .tar.gz files holding a saved image.
+func (s *Scanner) Detect(path string) bool {
+	fileName := strings.ToLower(filepath.Base(path))
+	if fileName == "oci-layout" {
+		return true
+	}
+	if !strings.HasSuffix(fileName, ".tar") && !strings.HasSuffix(fileName, ".tar.gz") {
+		return false
+	}
+	return isImageTarball(path)
+}
+
+func (s *Scanner) Scan(path string) ([]types.Dependency, error) {
+	var l layout
+	if filepath.Base(path) == "oci-layout" {
+		l = &dirLayout{dir: filepath.Dir(path)}
+	} else {
+		tarball, err := openTarLayout(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read image tarball: %w", err)
+		}
+		l = tarball
+	}
+
+	image, err := readManifest(l)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image manifest: %w", err)
+	}
+
+	layers := make(map[string]*layer, len(image.layers))
+	err = l.readLayers(image.layers, func(name string, r io.Reader) error {
+		changes, err := readLayer(r, isPackageMetadata)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		layers[name] = changes
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image layers: %w", err)
+	}
+
+	fs := newFilesystem()
+	for _, name := range image.layers {
+		changes, ok := layers[name]
+		if !ok {
+			return nil, fmt.Errorf("failed to read image layers: %s is missing", name)
+		}
+		fs.apply(changes)
+	}
+
+	dependencies := []types.Dependency{imageDependency(image, path)}
+	dependencies = append(dependencies, dpkgPackages(fs, path)...)
+	dependencies = append(dependencies, apkPackages(fs, path)...)
+
+	// The same language package can be installed in several places
+	seen := make(map[string]bool)
+	for _, packages := range [][]types.Dependency{
+		pythonPackages(fs, path),
+		npmPackages(fs, path),
+		gemPackages(fs, path),
+	} {
+		for _, dep := range packages {
+			key := dep.PackageType + "|" + dep.Name + "|" + dep.Version
+			if !seen[key] {
+				seen[key] = true
+				dependencies = append(dependencies, dep)
+			}
+		}
+	}
+
+	return dependencies, nil
+}
+
+// readManifest finds the image of a layout from the manifest.json that
+// docker save writes, or else from the OCI index.json.
+func readManifest(l layout) (*manifest, error) {
+	if data, err := l.readFile("manifest.json"); err == nil {
+		return readDockerManifest(l, data)
+	}
+
+	data, err := l.readFile("index.json")
+	if err != nil {
+		return nil, errors.New("neither manifest.json nor index.json found")
+	}
+	return readOCIIndex(l, data, "")
+}
+
+func readDockerManifest(l layout, data []byte) (*manifest, error) {
+	var entries []struct {
+		Config   string   `json:"Config"`
+		RepoTags []string `json:"RepoTags"`
+		Layers   []string `json:"Layers"`
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, errors.New("manifest.json lists no images")
+	}
+
+	entry := entries[0]
+	image := &manifest{layers: entry.Layers}
+	if len(entry.RepoTags) > 0 {
+		image.reference = entry.RepoTags[0]
+	}
+
+	// The configuration is blobs/sha256/<hex>, or <hex>.json in older
+	// versions, and its digest is the image ID
+	hex := strings.TrimSuffix(filepath.Base(entry.Config), ".json")
+	image.digest = "sha256:" + hex
+	if config, err := l.readFile(entry.Config); err == nil {
+		json.Unmarshal(config, &image.config)
+	}
+
+	return image, nil
+}
+
+// readOCIIndex follows an OCI index to the image manifest it lists,
+// choosing the first entry for a real platform in multi-platform indexes.
+func readOCIIndex(l layout, data []byte, reference string) (*manifest, error) {
+	var index struct {
+		Manifests []descriptor `json:"manifests"`
+	}
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, err
+	}
+
+	for _, desc := range index.Manifests {
+		// Attestations are listed with an unknown platform
+		if desc.Platform != nil && desc.Platform.OS == "unknown" {
+			continue
+		}
+
+		if ref := imageReference(desc.Annotations); ref != "" {
+			reference = ref
+		}
+
+		blob, err := l.readFile(blobPath(desc.Digest))
+		if err != nil {
+			return nil, err
+		}
+		if desc.MediaType == mediaTypeOCIIndex || desc.MediaType == mediaTypeDockerIndex {
+			return readOCIIndex(l, blob, reference)
+		}
+
+		var imageManifest struct {
+			Config descriptor   `json:"config"`
+			Layers []descriptor `json:"layers"`
+		}
+		if err := json.Unmarshal(blob, &imageManifest); err != nil {
+			return nil, err
+		}
+
+		image := &manifest{reference: reference, digest: desc.Digest}
+		for _, layer := range imageManifest.Layers {
+			image.layers = append(image.layers, blobPath(layer.Digest))
+		}
+		if config, err := l.readFile(blobPath(imageManifest.Config.Digest)); err == nil {
+			json.Unmarshal(config, &image.config)
+		}
+		return image, nil
+	}
+
+	return nil, errors.New("index.json lists no image manifests")
+}
+
+// imageReference returns the image name an index entry is annotated with.
+// The OCI ref.name annotation is often only the tag.
+func imageReference(annotations map[string]string) string {
+	if name := annotations["io.containerd.image.name"]; name != "" {
+		return name
+	}
+	return annotations["org.opencontainers.image.ref.name"]
+}
+
+func blobPath(digest string) string {
+	algorithm, hex, _ := strings.Cut(digest, ":")
+	return "blobs/" + algorithm + "/" + hex
+}
+
+// imageDependency describes the scanned image itself, with the license
+// its org.opencontainers.image.licenses label declares.
+func imageDependency(image *manifest, filePath string) types.Dependency {
+	name, version := splitReference(image.reference)
+	if name == "" {
+		name = strings.TrimSuffix(strings.TrimSuffix(filepath.Base(filePath), ".gz"), ".tar")
+		if name == "oci-layout" {
+			name = filepath.Base(filepath.Dir(filePath))
+		}
+	}
+	if version == "" {
+		version = "latest"
+	}
+
+	labels := image.config.Config.Labels
+	dep := types.Dependency{
+		Name:        name,
+		Version:     version,
+		LicenseType: "UNKNOWN",
+		PackageType: "docker-image",
+		FilePath:    filePath,
+		Scope:       "project",
+		Repository:  labels["org.opencontainers.image.source"],
+		Homepage:    labels["org.opencontainers.image.url"],
+	}
+	if license := labels["org.opencontainers.image.licenses"]; license != "" {
+		dep.LicenseType = license
+	}
+	if strings.HasPrefix(image.digest, "sha256:") {
+		dep.Hashes = []string{image.digest}
+	}
+	if image.config.OS != "" && image.config.Architecture != "" {
+		dep.Platform = image.config.OS + "/" + image.config.Architecture
+	}
+
+	return dep
+}
+
+// splitReference splits an image reference into its name and tag. A
+// reference without a slash or colon is taken as a bare tag.
+func splitReference(reference string) (string, string) {
+	reference, _, _ = strings.Cut(reference, "@")
+	slash := strings.LastIndex(reference, "/")
+	if colon := strings.LastIndex(reference, ":"); colon > slash {
+		return reference[:colon], reference[colon+1:]
+	}
+	if slash < 0 && reference != "" && strings.ContainsAny(reference[:1], "0123456789") {
+		return "", reference
+	}
+	return reference, ""
+}
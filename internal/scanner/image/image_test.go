@@ -0,0 +1,166 @@
+package image
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"license-audit/pkg/types"
+)
+
+const fixturesDir = "../../../test/fixtures/image"
+
+type expectation struct {
+	packageType string
+	version     string
+	license     string
+	platform    string
+}
+
+func checkDependencies(t *testing.T, dependencies []types.Dependency, expected map[string]expectation) {
+	t.Helper()
+
+	for _, dep := range dependencies {
+		want, ok := expected[dep.Name]
+		if !ok {
+			t.Errorf("Unexpected dependency '%s'", dep.Name)
+			continue
+		}
+		got := expectation{dep.PackageType, dep.Version, dep.LicenseType, dep.Platform}
+		if got != want {
+			t.Errorf("%s = %+v, expected %+v", dep.Name, got, want)
+		}
+	}
+
+	if len(dependencies) != len(expected) {
+		t.Errorf("Expected %d dependencies, got %d", len(expected), len(dependencies))
+	}
+}
+
+func TestDetect(t *testing.T) {
+	scanner := NewScanner()
+
+	testCases := []struct {
+		path     string
+		expected bool
+	}{
+		{filepath.Join(fixturesDir, "api.tar"), true},
+		{filepath.Join(fixturesDir, "alpine-oci", "oci-layout"), true},
+		{filepath.Join(fixturesDir, "alpine-oci", "index.json"), false},
+		{filepath.Join(fixturesDir, "missing.tar"), false},
+		{"image.zip", false},
+	}
+
+	for _, tc := range testCases {
+		result := scanner.Detect(tc.path)
+		if result != tc.expected {
+			t.Errorf("Detect(%s) = %v, expected %v", tc.path, result, tc.expected)
+		}
+	}
+}
+
+func TestScanDockerArchive(t *testing.T) {
+	dependencies, err := NewScanner().Scan(filepath.Join(fixturesDir, "api.tar"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	checkDependencies(t, dependencies, map[string]expectation{
+		"ghcr.io/acme/api": {"docker-image", "1.4.2", "Apache-2.0", "linux/amd64"},
+		// dpkg status rewritten by the second layer; vim-tiny was removed
+		"base-files": {"deb", "12.4+deb12u5", "GPL", "amd64"}, // common-licenses reference
+		"libssl3":    {"deb", "3.0.11-1~deb12u2", "Apache-2.0", "amd64"},
+		"libssl-dev": {"deb", "3.0.11-1~deb12u2", "Apache-2.0", "amd64"}, // doc directory is a symlink
+		"zlib1g":     {"deb", "1:1.2.13.dfsg-1", "GPL-2.0-or-later AND Zlib", "amd64"},
+		"tzdata":     {"deb", "2024a-0+deb12u1", "LicenseRef-public-domain", ""},
+		"curl":       {"deb", "7.88.1-10+deb12u5", "curl", "amd64"},
+		// setuptools 65.5.1 was whited out
+		"six":              {"python", "1.16.0", "MIT", ""},
+		"setuptools":       {"python", "69.1.1", "MIT", ""},
+		"npm":              {"npm", "10.2.4", "Artistic-2.0", ""},
+		"@npmcli/arborist": {"npm", "7.2.1", "ISC", ""},
+		"old-lib":          {"npm", "0.1.0", "MIT", ""},
+		"rack":             {"ruby", "3.0.9", "MIT", ""},
+	})
+
+	for _, dep := range dependencies {
+		switch dep.Name {
+		case "ghcr.io/acme/api":
+			if dep.Scope != "project" || dep.Repository != "https://github.com/acme/api" || len(dep.Hashes) != 1 {
+				t.Errorf("Unexpected image dependency: %+v", dep)
+			}
+		case "libssl3":
+			if !reflect.DeepEqual(dep.Requires, []string{"libc6", "zlib1g"}) {
+				t.Errorf("libssl3 requires %v, expected [libc6 zlib1g]", dep.Requires)
+			}
+		}
+	}
+}
+
+func TestScanOCILayout(t *testing.T) {
+	dependencies, err := NewScanner().Scan(filepath.Join(fixturesDir, "alpine-oci", "oci-layout"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// The index lists an attestation before the image, and the second
+	// layer hides the pip installed by the first
+	checkDependencies(t, dependencies, map[string]expectation{
+		"docker.io/acme/worker": {"docker-image", "2.0.0", "UNKNOWN", "linux/amd64"},
+		"musl":                  {"apk", "1.2.4_git20230717-r4", "MIT", "x86_64"},
+		"busybox":               {"apk", "1.36.1-r15", "GPL-2.0-only", "x86_64"},
+		"ssl_client":            {"apk", "1.36.1-r15", "GPL-2.0-only", "x86_64"},
+		"requests":              {"python", "2.31.0", "Apache-2.0", ""},
+	})
+
+	for _, dep := range dependencies {
+		if dep.Name == "ssl_client" && !reflect.DeepEqual(dep.Requires, []string{"busybox"}) {
+			t.Errorf("ssl_client requires %v, expected [busybox]", dep.Requires)
+		}
+	}
+}
+
+func TestCopyrightLicense(t *testing.T) {
+	testCases := []struct {
+		copyright string
+		expected  string
+	}{
+		{"Files: *\nCopyright: 2020 Acme\nLicense: GPL-2+ or Artistic\n", "GPL-2.0-or-later OR Artistic-1.0"},
+		{"Files: *\nLicense: LGPL-2.1\n\nFiles: src/*\nLicense: Expat\n", "LGPL-2.1-only"},
+		{"Files: src/*\nLicense: BSD-3-clause\n\nFiles: doc/*\nLicense: GFDL-1.3+\n", "BSD-3-Clause AND GFDL-1.3-or-later"},
+		{"See /usr/share/common-licenses/LGPL-3.\n", "LGPL-3.0-only"},
+		{"Permission is hereby granted, free of charge, to any person\n", "MIT"},
+		{"All rights reserved.\n", "UNKNOWN"},
+	}
+
+	for _, tc := range testCases {
+		if result := copyrightLicense(tc.copyright); result != tc.expected {
+			t.Errorf("copyrightLicense(%q) = %s, expected %s", tc.copyright, result, tc.expected)
+		}
+	}
+}
+
+func TestFilesystemApply(t *testing.T) {
+	fs := newFilesystem()
+	fs.apply(&layer{
+		files: map[string][]byte{
+			"etc/a/one":   []byte("1"),
+			"etc/a/two":   []byte("2"),
+			"etc/b/three": []byte("3"),
+		},
+		links: map[string]string{"etc/c": "/etc/b"},
+	})
+	fs.apply(&layer{
+		files:     map[string][]byte{"etc/a/four": []byte("4")},
+		links:     map[string]string{},
+		whiteouts: []string{"etc/b"},
+		opaque:    []string{"etc/a"},
+	})
+
+	if names := fs.glob(func(string) bool { return true }); !reflect.DeepEqual(names, []string{"etc/a/four"}) {
+		t.Errorf("Files = %v, expected [etc/a/four]", names)
+	}
+	if _, ok := fs.readFile("etc/c/three"); ok {
+		t.Errorf("Expected etc/c/three to be gone with etc/b")
+	}
+}
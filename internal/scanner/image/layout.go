@@ -0,0 +1,328 @@
+package image
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// maxMetadataSize bounds the image metadata files (manifests, configs)
+	// kept in memory while reading an image tarball.
+	maxMetadataSize = 4 << 20
+	// maxPackageFileSize bounds the package database and metadata files
+	// read from a layer.
+	maxPackageFileSize = 64 << 20
+)
+
+// layout gives access to the files of an image, whether a docker save or
+// OCI tarball or an OCI image layout directory.
+type layout interface {
+	// readFile returns a metadata file, such as manifest.json or a blob.
+	readFile(name string) ([]byte, error)
+	// readLayers calls fn with the content of each named layer blob, in
+	// the order they are stored rather than the order they apply.
+	readLayers(names []string, fn func(name string, r io.Reader) error) error
+}
+
+// dirLayout is an OCI image layout directory.
+type dirLayout struct {
+	dir string
+}
+
+func (l *dirLayout) readFile(name string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(l.dir, filepath.FromSlash(name)))
+}
+
+func (l *dirLayout) readLayers(names []string, fn func(name string, r io.Reader) error) error {
+	for _, name := range names {
+		file, err := os.Open(filepath.Join(l.dir, filepath.FromSlash(name)))
+		if err != nil {
+			return err
+		}
+		err = fn(name, file)
+		file.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tarLayout is an image saved as a tarball, possibly gzipped. Tarballs
+// can only be read in order, so the small metadata files are kept from a
+// first pass and the layers are read in a second one.
+type tarLayout struct {
+	path  string
+	files map[string][]byte
+}
+
+func openTarLayout(tarball string) (*tarLayout, error) {
+	l := &tarLayout{path: tarball, files: make(map[string][]byte)}
+
+	err := l.walk(func(name string, header *tar.Header, r io.Reader) error {
+		if header.Size > maxMetadataSize {
+			return nil
+		}
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		l.files[name] = data
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *tarLayout) readFile(name string) ([]byte, error) {
+	data, ok := l.files[path.Clean(name)]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", name, os.ErrNotExist)
+	}
+	return data, nil
+}
+
+func (l *tarLayout) readLayers(names []string, fn func(name string, r io.Reader) error) error {
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[path.Clean(name)] = true
+	}
+
+	return l.walk(func(name string, header *tar.Header, r io.Reader) error {
+		if !wanted[name] {
+			return nil
+		}
+		return fn(name, r)
+	})
+}
+
+// walk calls fn with every regular file of the tarball.
+func (l *tarLayout) walk(fn func(name string, header *tar.Header, r io.Reader) error) error {
+	file, err := os.Open(l.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	r, err := decompress(file)
+	if err != nil {
+		return err
+	}
+
+	reader := tar.NewReader(r)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := fn(path.Clean(header.Name), header, reader); err != nil {
+			return err
+		}
+	}
+}
+
+// isImageTarball reports whether a tarball holds a saved image, from the
+// manifest.json of docker save or the oci-layout marker of an OCI archive.
+func isImageTarball(tarball string) bool {
+	file, err := os.Open(tarball)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	r, err := decompress(file)
+	if err != nil {
+		return false
+	}
+
+	reader := tar.NewReader(r)
+	for {
+		header, err := reader.Next()
+		if err != nil {
+			return false
+		}
+		switch path.Clean(header.Name) {
+		case "manifest.json", "oci-layout":
+			return true
+		}
+	}
+}
+
+// decompress returns a reader of r's content, gunzipping it if needed.
+// Layers compressed with zstd are not supported.
+func decompress(r io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(r)
+	magic, _ := buffered.Peek(4)
+
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		return gzip.NewReader(buffered)
+	case bytes.Equal(magic, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		return nil, errors.New("zstd compressed layers are not supported")
+	}
+	return buffered, nil
+}
+
+// layer is what one layer changes in the image filesystem, limited to the
+// package metadata files the scanner reads.
+type layer struct {
+	files     map[string][]byte
+	links     map[string]string // symbolic links, to their target
+	whiteouts []string          // paths the layer deletes
+	opaque    []string          // directories whose lower content the layer hides
+}
+
+// readLayer reads a layer tarball, keeping the files wanted reports as
+// package metadata, and the whiteouts and symbolic links that affect them.
+func readLayer(r io.Reader, wanted func(name string) bool) (*layer, error) {
+	r, err := decompress(r)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &layer{files: make(map[string][]byte), links: make(map[string]string)}
+	reader := tar.NewReader(r)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return l, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		name := strings.TrimPrefix(path.Clean("/"+header.Name), "/")
+		dir, base := path.Split(name)
+		dir = strings.TrimSuffix(dir, "/")
+
+		switch {
+		case base == ".wh..wh..opq":
+			l.opaque = append(l.opaque, dir)
+		case strings.HasPrefix(base, ".wh."):
+			l.whiteouts = append(l.whiteouts, path.Join(dir, strings.TrimPrefix(base, ".wh.")))
+		case header.Typeflag == tar.TypeSymlink:
+			l.links[name] = header.Linkname
+		case header.Typeflag == tar.TypeLink && wanted(name):
+			target := strings.TrimPrefix(path.Clean("/"+header.Linkname), "/")
+			if data, ok := l.files[target]; ok {
+				l.files[name] = data
+			}
+		case header.Typeflag == tar.TypeReg && wanted(name) && header.Size <= maxPackageFileSize:
+			data, err := io.ReadAll(reader)
+			if err != nil {
+				return nil, err
+			}
+			l.files[name] = data
+		}
+	}
+}
+
+// filesystem is the union of the layers applied so far.
+type filesystem struct {
+	files map[string][]byte
+	links map[string]string
+}
+
+func newFilesystem() *filesystem {
+	return &filesystem{files: make(map[string][]byte), links: make(map[string]string)}
+}
+
+// apply adds a layer on top: its whiteouts and opaque directories hide
+// what the layers below hold, then its own files are added.
+func (fs *filesystem) apply(l *layer) {
+	for _, dir := range l.opaque {
+		fs.remove(dir, false)
+	}
+	for _, name := range l.whiteouts {
+		fs.remove(name, true)
+	}
+
+	for name, target := range l.links {
+		fs.links[name] = target
+		delete(fs.files, name)
+	}
+	for name, data := range l.files {
+		fs.files[name] = data
+		delete(fs.links, name)
+	}
+}
+
+// remove deletes everything below name, and name itself if self is set.
+func (fs *filesystem) remove(name string, self bool) {
+	prefix := name + "/"
+	if name == "" {
+		prefix = ""
+	}
+	for file := range fs.files {
+		if (self && file == name) || strings.HasPrefix(file, prefix) {
+			delete(fs.files, file)
+		}
+	}
+	for link := range fs.links {
+		if (self && link == name) || strings.HasPrefix(link, prefix) {
+			delete(fs.links, link)
+		}
+	}
+}
+
+// readFile returns the content of a file, following symbolic links in any
+// component of its path.
+func (fs *filesystem) readFile(name string) ([]byte, bool) {
+	for hops := 0; hops < 16; hops++ {
+		if data, ok := fs.files[name]; ok {
+			return data, true
+		}
+
+		resolved := false
+		parts := strings.Split(name, "/")
+		for i := len(parts); i > 0; i-- {
+			prefix := strings.Join(parts[:i], "/")
+			target, ok := fs.links[prefix]
+			if !ok {
+				continue
+			}
+			if strings.HasPrefix(target, "/") {
+				target = strings.TrimPrefix(path.Clean(target), "/")
+			} else {
+				target = path.Join(path.Dir(prefix), target)
+			}
+			name = path.Join(append([]string{target}, parts[i:]...)...)
+			resolved = true
+			break
+		}
+		if !resolved {
+			return nil, false
+		}
+	}
+	return nil, false
+}
+
+// glob returns the files match accepts, sorted.
+func (fs *filesystem) glob(match func(name string) bool) []string {
+	var names []string
+	for name := range fs.files {
+		if match(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,118 @@
+package image
+
+import (
+	"regexp"
+	"strings"
+
+	"license-audit/internal/license"
+)
+
+// troveLicenses maps the OSI Approved license classifiers of Python
+// packages to SPDX identifiers.
+var troveLicenses = map[string]string{
+	"MIT License":                                         "MIT",
+	"Apache Software License":                             "Apache-2.0",
+	"BSD License":                                         "BSD-3-Clause",
+	"ISC License (ISCL)":                                  "ISC",
+	"Mozilla Public License 2.0 (MPL 2.0)":                "MPL-2.0",
+	"Python Software Foundation License":                  "PSF-2.0",
+	"GNU General Public License v2 (GPLv2)":               "GPL-2.0-only",
+	"GNU General Public License v3 (GPLv3)":               "GPL-3.0-only",
+	"GNU Lesser General Public License v3 (LGPLv3)":       "LGPL-3.0-only",
+	"GNU Library or Lesser General Public License (LGPL)": "LGPL-2.0-or-later",
+	"The Unlicense (Unlicense)":                           "Unlicense",
+}
+
+// debianLicenses maps the short names of machine-readable Debian
+// copyright files that differ from their SPDX identifiers.
+var debianLicenses = map[string]string{
+	"expat":         "MIT",
+	"bsd-2-clause":  "BSD-2-Clause",
+	"bsd-3-clause":  "BSD-3-Clause",
+	"bsd-4-clause":  "BSD-4-Clause",
+	"apache-2.0":    "Apache-2.0",
+	"artistic":      "Artistic-1.0",
+	"artistic-2.0":  "Artistic-2.0",
+	"mpl-1.1":       "MPL-1.1",
+	"mpl-2.0":       "MPL-2.0",
+	"isc":           "ISC",
+	"zlib":          "Zlib",
+	"cc0-1.0":       "CC0-1.0",
+	"public-domain": "LicenseRef-public-domain",
+}
+
+var (
+	gnuShortNamePattern  = regexp.MustCompile(`^(a?gpl|lgpl|gfdl)-?(\d(?:\.\d)?)(\+?)$`)
+	commonLicensePattern = regexp.MustCompile(`/usr/share/common-licenses/([A-Za-z0-9.+-]+)`)
+)
+
+// copyrightLicense returns the license of a Debian package from its
+// copyright file. Machine-readable files give it in the License field of
+// their Files: * paragraph, or of every paragraph when there is none;
+// others usually point at a text in /usr/share/common-licenses.
+func copyrightLicense(copyright string) string {
+	stanzas := parseStanzas([]byte(copyright), true)
+
+	var licenses []string
+	seen := make(map[string]bool)
+	for _, stanza := range stanzas {
+		files, ok := stanza["files"]
+		field := stanza["license"]
+		if !ok || field == "" {
+			continue
+		}
+		expression := debianLicenseExpression(strings.SplitN(field, "\n", 2)[0])
+		if strings.TrimSpace(files) == "*" {
+			return expression
+		}
+		if !seen[expression] {
+			seen[expression] = true
+			licenses = append(licenses, expression)
+		}
+	}
+	if len(licenses) > 0 {
+		return strings.Join(licenses, " AND ")
+	}
+
+	if match := commonLicensePattern.FindStringSubmatch(copyright); match != nil {
+		return debianLicenseExpression(strings.TrimSuffix(match[1], "."))
+	}
+
+	return license.Detect(copyright)
+}
+
+// debianLicenseExpression converts the short names of a Debian License
+// field, such as "GPL-2+ or Artistic", to SPDX identifiers.
+func debianLicenseExpression(field string) string {
+	words := strings.Fields(field)
+	for i, word := range words {
+		switch strings.ToLower(word) {
+		case "or", "and":
+			words[i] = strings.ToUpper(word)
+		default:
+			words[i] = debianLicense(strings.Trim(word, ","))
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+func debianLicense(name string) string {
+	lower := strings.ToLower(name)
+	if spdx, ok := debianLicenses[lower]; ok {
+		return spdx
+	}
+
+	if match := gnuShortNamePattern.FindStringSubmatch(lower); match != nil {
+		version := match[2]
+		if !strings.Contains(version, ".") {
+			version += ".0"
+		}
+		identifier := strings.ToUpper(match[1]) + "-" + version
+		if match[3] == "+" {
+			return identifier + "-or-later"
+		}
+		return identifier + "-only"
+	}
+
+	return name
+}
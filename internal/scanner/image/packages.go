@@ -0,0 +1,367 @@
+package image
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"license-audit/pkg/types"
+)
+
+const (
+	dpkgStatusFile   = "var/lib/dpkg/status"
+	dpkgStatusDir    = "var/lib/dpkg/status.d/"
+	apkInstalledFile = "lib/apk/db/installed"
+)
+
+var (
+	copyrightPattern   = regexp.MustCompile(`^usr/share/doc/[^/]+/copyright$`)
+	pythonMetadataPath = regexp.MustCompile(`(^|/)(site|dist)-packages/[^/]+\.(dist-info/METADATA|egg-info/PKG-INFO)$`)
+	npmPackagePath     = regexp.MustCompile(`(^|/)node_modules/(@[^/]+/)?[^/@.][^/]*/package\.json$`)
+	gemspecPath        = regexp.MustCompile(`(^|/)specifications/[^/]+\.gemspec$`)
+
+	gemspecNamePattern     = regexp.MustCompile(`\.name\s*=\s*"([^"]+)"`)
+	gemspecVersionPattern  = regexp.MustCompile(`\.version\s*=\s*"([^"]+)"`)
+	gemspecLicensesPattern = regexp.MustCompile(`\.licenses?\s*=\s*\[?([^\n\]]*)`)
+	gemspecHomepagePattern = regexp.MustCompile(`\.homepage\s*=\s*"([^"]+)"`)
+	quotedPattern          = regexp.MustCompile(`"([^"]+)"`)
+)
+
+// isPackageMetadata reports whether a file in an image is one the scanner
+// reads: an OS package database, a Debian copyright file, or the metadata
+// of an installed Python distribution, npm package or gem.
+func isPackageMetadata(name string) bool {
+	switch {
+	case name == dpkgStatusFile, name == apkInstalledFile:
+		return true
+	case strings.HasPrefix(name, dpkgStatusDir):
+		// Distroless images have one status file per package, next to
+		// their .md5sums
+		return !strings.Contains(path.Base(name), ".")
+	}
+	return copyrightPattern.MatchString(name) || pythonMetadataPath.MatchString(name) ||
+		npmPackagePath.MatchString(name) || gemspecPath.MatchString(name)
+}
+
+// parseStanzas splits a Debian control file into its paragraphs of
+// Field: value pairs. Continuation lines are appended to the field's value
+// on their own line. Debian field names are case-insensitive and are
+// lower-cased when foldCase is set; those of the apk database are not.
+func parseStanzas(data []byte, foldCase bool) []map[string]string {
+	var stanzas []map[string]string
+	stanza := make(map[string]string)
+	field := ""
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.TrimSpace(line) == "":
+			if len(stanza) > 0 {
+				stanzas = append(stanzas, stanza)
+				stanza = make(map[string]string)
+			}
+			field = ""
+		case line[0] == ' ' || line[0] == '\t':
+			if field != "" {
+				stanza[field] += "\n" + strings.TrimSpace(line)
+			}
+		default:
+			name, value, ok := strings.Cut(line, ":")
+			if !ok {
+				continue
+			}
+			field = strings.TrimSpace(name)
+			if foldCase {
+				field = strings.ToLower(field)
+			}
+			stanza[field] = strings.TrimSpace(value)
+		}
+	}
+	if len(stanza) > 0 {
+		stanzas = append(stanzas, stanza)
+	}
+
+	return stanzas
+}
+
+// dpkgPackages reads the Debian packages installed in the image from the
+// dpkg status database, with the licenses their copyright files declare.
+func dpkgPackages(fs *filesystem, filePath string) []types.Dependency {
+	var stanzas []map[string]string
+	if data, ok := fs.readFile(dpkgStatusFile); ok {
+		stanzas = append(stanzas, parseStanzas(data, true)...)
+	}
+	for _, name := range fs.glob(func(name string) bool { return strings.HasPrefix(name, dpkgStatusDir) }) {
+		data, _ := fs.readFile(name)
+		stanzas = append(stanzas, parseStanzas(data, true)...)
+	}
+
+	var dependencies []types.Dependency
+	for _, stanza := range stanzas {
+		name := stanza["package"]
+		// Removed packages stay in the database as config-files or
+		// not-installed
+		if name == "" || (stanza["status"] != "" && !strings.HasSuffix(stanza["status"], " installed")) {
+			continue
+		}
+
+		dep := types.Dependency{
+			Name:        name,
+			Version:     stanza["version"],
+			LicenseType: "UNKNOWN",
+			Homepage:    stanza["homepage"],
+			PackageType: "deb",
+			FilePath:    filePath,
+			Requires:    dpkgRequires(stanza["pre-depends"], stanza["depends"]),
+		}
+		if dep.Version == "" {
+			dep.Version = "UNKNOWN"
+		}
+		if arch := stanza["architecture"]; arch != "" && arch != "all" {
+			dep.Platform = arch
+		}
+
+		if copyright, ok := fs.readFile("usr/share/doc/" + name + "/copyright"); ok {
+			dep.LicenseType = copyrightLicense(string(copyright))
+		}
+
+		dependencies = append(dependencies, dep)
+	}
+
+	return dependencies
+}
+
+// dpkgRequires returns the package names of Depends fields, taking the
+// first of each set of alternatives.
+func dpkgRequires(fields ...string) []string {
+	var requires []string
+	for _, field := range fields {
+		for _, relation := range strings.Split(field, ",") {
+			alternative, _, _ := strings.Cut(relation, "|")
+			name := strings.Fields(alternative)
+			if len(name) == 0 {
+				continue
+			}
+			pkg, _, _ := strings.Cut(name[0], ":")
+			pkg, _, _ = strings.Cut(pkg, "(")
+			requires = append(requires, pkg)
+		}
+	}
+	return requires
+}
+
+// apkPackages reads the Alpine packages installed in the image. The
+// database is a list of paragraphs of single-letter fields.
+func apkPackages(fs *filesystem, filePath string) []types.Dependency {
+	data, ok := fs.readFile(apkInstalledFile)
+	if !ok {
+		return nil
+	}
+
+	var dependencies []types.Dependency
+	for _, stanza := range parseStanzas(data, false) {
+		name := stanza["P"]
+		if name == "" {
+			continue
+		}
+
+		dep := types.Dependency{
+			Name:        name,
+			Version:     stanza["V"],
+			LicenseType: stanza["L"],
+			Homepage:    stanza["U"],
+			PackageType: "apk",
+			FilePath:    filePath,
+			Platform:    stanza["A"],
+		}
+		if dep.Version == "" {
+			dep.Version = "UNKNOWN"
+		}
+		if dep.LicenseType == "" {
+			dep.LicenseType = "UNKNOWN"
+		}
+
+		// so:, cmd: and pc: entries name what a package provides, not
+		// a package
+		for _, require := range strings.Fields(stanza["D"]) {
+			if strings.HasPrefix(require, "!") || strings.Contains(require, ":") {
+				continue
+			}
+			if i := strings.IndexAny(require, "=<>~"); i > 0 {
+				require = require[:i]
+			}
+			dep.Requires = append(dep.Requires, require)
+		}
+
+		dependencies = append(dependencies, dep)
+	}
+
+	return dependencies
+}
+
+// pythonPackages reads the distributions installed in the image's
+// site-packages and dist-packages directories.
+func pythonPackages(fs *filesystem, filePath string) []types.Dependency {
+	var dependencies []types.Dependency
+	for _, name := range fs.glob(pythonMetadataPath.MatchString) {
+		data, _ := fs.readFile(name)
+
+		// Core metadata headers end at the first blank line
+		header, _, _ := bytes.Cut(data, []byte("\n\n"))
+		var classifiers []string
+		fields := make(map[string]string)
+		for _, line := range strings.Split(string(header), "\n") {
+			key, value, ok := strings.Cut(line, ":")
+			if !ok || strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+				continue
+			}
+			key, value = strings.ToLower(key), strings.TrimSpace(value)
+			if key == "classifier" {
+				if license, ok := strings.CutPrefix(value, "License :: OSI Approved :: "); ok {
+					classifiers = append(classifiers, license)
+				}
+				continue
+			}
+			if _, exists := fields[key]; !exists {
+				fields[key] = value
+			}
+		}
+		if fields["name"] == "" {
+			continue
+		}
+
+		dep := types.Dependency{
+			Name:        fields["name"],
+			Version:     fields["version"],
+			LicenseType: "UNKNOWN",
+			Homepage:    fields["home-page"],
+			PackageType: "python",
+			FilePath:    filePath,
+		}
+		switch {
+		case fields["license-expression"] != "":
+			dep.LicenseType = fields["license-expression"]
+		case len(classifiers) > 0:
+			var licenses []string
+			for _, classifier := range classifiers {
+				if license, ok := troveLicenses[classifier]; ok {
+					licenses = append(licenses, license)
+				}
+			}
+			if len(licenses) > 0 {
+				sort.Strings(licenses)
+				dep.LicenseType = strings.Join(licenses, " OR ")
+			}
+		case fields["license"] != "" && len(fields["license"]) <= 64:
+			dep.LicenseType = fields["license"]
+		}
+		if dep.Version == "" {
+			dep.Version = "UNKNOWN"
+		}
+
+		dependencies = append(dependencies, dep)
+	}
+
+	return dependencies
+}
+
+// npmPackages reads the packages installed in the image's node_modules
+// directories.
+func npmPackages(fs *filesystem, filePath string) []types.Dependency {
+	var dependencies []types.Dependency
+	for _, name := range fs.glob(npmPackagePath.MatchString) {
+		data, _ := fs.readFile(name)
+
+		var manifest struct {
+			Name     string          `json:"name"`
+			Version  string          `json:"version"`
+			License  json.RawMessage `json:"license"`
+			Homepage string          `json:"homepage"`
+		}
+		if err := json.Unmarshal(data, &manifest); err != nil || manifest.Name == "" {
+			continue
+		}
+
+		dep := types.Dependency{
+			Name:        manifest.Name,
+			Version:     manifest.Version,
+			LicenseType: npmLicense(manifest.License),
+			Homepage:    manifest.Homepage,
+			PackageType: "npm",
+			FilePath:    filePath,
+		}
+		if dep.Version == "" {
+			dep.Version = "UNKNOWN"
+		}
+
+		dependencies = append(dependencies, dep)
+	}
+
+	return dependencies
+}
+
+// npmLicense reads the license field of a package.json, which is an SPDX
+// expression or, in old packages, a {"type": ...} object.
+func npmLicense(raw json.RawMessage) string {
+	var license string
+	if err := json.Unmarshal(raw, &license); err == nil && license != "" {
+		return license
+	}
+
+	var object struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(raw, &object); err == nil && object.Type != "" {
+		return object.Type
+	}
+
+	return "UNKNOWN"
+}
+
+// gemPackages reads the gems installed in the image from the
+// specifications RubyGems writes for them.
+func gemPackages(fs *filesystem, filePath string) []types.Dependency {
+	var dependencies []types.Dependency
+	for _, name := range fs.glob(gemspecPath.MatchString) {
+		data, _ := fs.readFile(name)
+		spec := string(data)
+
+		match := gemspecNamePattern.FindStringSubmatch(spec)
+		if match == nil {
+			continue
+		}
+
+		dep := types.Dependency{
+			Name:        match[1],
+			Version:     "UNKNOWN",
+			LicenseType: "UNKNOWN",
+			PackageType: "ruby",
+			FilePath:    filePath,
+		}
+		if match := gemspecVersionPattern.FindStringSubmatch(spec); match != nil {
+			dep.Version = match[1]
+		}
+		if match := gemspecHomepagePattern.FindStringSubmatch(spec); match != nil {
+			dep.Homepage = match[1]
+		}
+		if match := gemspecLicensesPattern.FindStringSubmatch(spec); match != nil {
+			var licenses []string
+			for _, quoted := range quotedPattern.FindAllStringSubmatch(match[1], -1) {
+				licenses = append(licenses, quoted[1])
+			}
+			if len(licenses) > 0 {
+				dep.LicenseType = strings.Join(licenses, " OR ")
+			}
+		}
+
+		dependencies = append(dependencies, dep)
+	}
+
+	return dependencies
+}
@@ -0,0 +1,474 @@
+package java
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"license-audit/internal/license"
+	"license-audit/pkg/types"
+)
+
+const (
+	// maxArchiveDepth bounds how deeply nested archives are opened.
+	maxArchiveDepth = 4
+	// maxNestedArchiveSize bounds the size of a nested archive, which is
+	// read into memory to be opened.
+	maxNestedArchiveSize = 256 << 20
+)
+
+// ArchiveScanner reports the artifacts packaged in JAR, WAR and EAR files,
+// including jars nested in fat jars (BOOT-INF/lib, WEB-INF/lib, lib/) and
+// artifacts shaded into a single jar.
+type ArchiveScanner struct{}
+
+// JarMetadata is what a JAR exposes about itself and its contents.
+type JarMetadata struct {
+	LicenseText string
+	NoticeText  string
+	Manifest    map[string]string  // main attributes of META-INF/MANIFEST.MF
+	Artifacts   []EmbeddedArtifact // one per META-INF/maven/<groupId>/<artifactId>/
+}
+
+// EmbeddedArtifact is a Maven artifact described under META-INF/maven.
+type EmbeddedArtifact struct {
+	GroupID    string
+	ArtifactID string
+	Version    string
+	POM        *POM
+}
+
+var archiveFileNamePattern = regexp.MustCompile(`^(.+?)-(\d[^-]*(?:-[A-Za-z0-9.]+)*)$`)
+
+func NewArchiveScanner() *ArchiveScanner {
+	return &ArchiveScanner{}
+}
+
+func (s *ArchiveScanner) Name() string {
+	return "java-archive"
+}
+
+func (s *ArchiveScanner) Detect(path string) bool {
+	fileName := strings.ToLower(filepath.Base(path))
+
+	// Source and javadoc jars do not ship
+	if strings.HasSuffix(fileName, "-sources.jar") || strings.HasSuffix(fileName, "-javadoc.jar") {
+		return false
+	}
+	return isArchiveName(fileName)
+}
+
+func (s *ArchiveScanner) Scan(path string) ([]types.Dependency, error) {
+	reader, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", filepath.Base(path), err)
+	}
+	defer reader.Close()
+
+	return s.scanArchive(&reader.Reader, filepath.Base(path), "", 0)
+}
+
+// scanArchive reports the archive's own artifacts and recurses into the
+// archives it contains. location is the "!/"-separated path of a nested
+// archive inside the scanned file, empty for the scanned file itself.
+func (s *ArchiveScanner) scanArchive(reader *zip.Reader, fileName, location string, depth int) ([]types.Dependency, error) {
+	jar, err := ReadJar(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", fileName, err)
+	}
+
+	dependencies := archiveDependencies(jar, fileName)
+	identity := archiveIdentity(jar, fileName)
+
+	for i := range dependencies {
+		switch {
+		case location != "":
+			dependencies[i].Indirect = true
+			dependencies[i].Source = "archive"
+			dependencies[i].SourceURL = location
+		case i == identity:
+			dependencies[i].Scope = "project"
+		default:
+			// Shaded into the scanned archive
+			dependencies[i].Indirect = true
+		}
+	}
+
+	if depth >= maxArchiveDepth {
+		return dependencies, nil
+	}
+
+	for _, file := range reader.File {
+		if file.FileInfo().IsDir() || !isArchiveName(strings.ToLower(file.Name)) {
+			continue
+		}
+
+		data, err := readNestedArchive(file, maxNestedArchiveSize)
+		if err != nil {
+			continue
+		}
+		nested, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			continue
+		}
+
+		nestedLocation := file.Name
+		if location != "" {
+			nestedLocation = location + "!/" + file.Name
+		}
+
+		nestedDeps, err := s.scanArchive(nested, path.Base(file.Name), nestedLocation, depth+1)
+		if err != nil {
+			continue
+		}
+		dependencies = append(dependencies, nestedDeps...)
+	}
+
+	return dependencies, nil
+}
+
+func isArchiveName(fileName string) bool {
+	switch path.Ext(fileName) {
+	case ".jar", ".war", ".ear":
+		return true
+	}
+	return false
+}
+
+// archiveDependencies identifies the artifacts of one archive from its
+// embedded Maven metadata, falling back to the manifest and file name.
+func archiveDependencies(jar *JarMetadata, fileName string) []types.Dependency {
+	identity := archiveIdentity(jar, fileName)
+
+	var dependencies []types.Dependency
+	for i, artifact := range jar.Artifacts {
+		dep := archiveDependency(artifact.GroupID+":"+artifact.ArtifactID, artifact.Version)
+		if artifact.POM != nil && len(artifact.POM.Licenses) > 0 {
+			dep.LicenseType = pomLicenseType(artifact.POM.Licenses)
+			dep.LicenseURL = strings.TrimSpace(artifact.POM.Licenses[0].URL)
+		}
+		if artifact.POM != nil {
+			dep.Homepage = strings.TrimSpace(artifact.POM.URL)
+		}
+
+		// The manifest and bundled license files describe the archive
+		// itself, not what was shaded into it
+		if i == identity {
+			applyArchiveLicense(jar, &dep)
+		}
+		dependencies = append(dependencies, dep)
+	}
+
+	if len(dependencies) > 0 {
+		return dependencies
+	}
+
+	name, version := archiveFileName(fileName)
+	for _, key := range []string{"Bundle-SymbolicName", "Automatic-Module-Name", "Implementation-Title"} {
+		if value := jar.Manifest[key]; value != "" {
+			// Bundle-SymbolicName may carry directives: name;singleton:=true
+			name = strings.TrimSpace(strings.SplitN(value, ";", 2)[0])
+			break
+		}
+	}
+	for _, key := range []string{"Bundle-Version", "Implementation-Version"} {
+		if value := jar.Manifest[key]; value != "" {
+			version = value
+			break
+		}
+	}
+
+	dep := archiveDependency(name, version)
+	dep.Homepage = jar.Manifest["Bundle-DocURL"]
+	applyArchiveLicense(jar, &dep)
+	return []types.Dependency{dep}
+}
+
+func archiveDependency(name, version string) types.Dependency {
+	if version == "" {
+		version = "UNKNOWN"
+	}
+
+	return types.Dependency{
+		Name:        name,
+		Version:     version,
+		LicenseType: "UNKNOWN",
+		PackageType: "maven",
+	}
+}
+
+// archiveIdentity returns the index of the artifact that is the archive
+// itself: the only one, or the one named like the file. Shaded jars list
+// several, and -1 means none could be told apart.
+func archiveIdentity(jar *JarMetadata, fileName string) int {
+	if len(jar.Artifacts) <= 1 {
+		return 0
+	}
+
+	name, _ := archiveFileName(fileName)
+	for i, artifact := range jar.Artifacts {
+		if artifact.ArtifactID == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// archiveFileName splits "name-1.2.3.jar" into its name and version.
+func archiveFileName(fileName string) (string, string) {
+	base := strings.TrimSuffix(fileName, path.Ext(fileName))
+	if match := archiveFileNamePattern.FindStringSubmatch(base); match != nil {
+		return match[1], match[2]
+	}
+	return base, ""
+}
+
+// applyArchiveLicense fills in what the embedded POM left unknown from
+// the manifest's Bundle-License and the bundled license files.
+func applyArchiveLicense(jar *JarMetadata, dep *types.Dependency) {
+	if dep.LicenseType == "UNKNOWN" {
+		if value := jar.Manifest["Bundle-License"]; value != "" {
+			dep.LicenseType = bundleLicense(value)
+		}
+	}
+
+	text := jar.LicenseText
+	if text == "" {
+		text = jar.NoticeText
+	}
+	dep.LicenseText = text
+	if dep.LicenseType == "UNKNOWN" && text != "" {
+		dep.LicenseType = license.Detect(text)
+	}
+}
+
+// bundleLicense maps an OSGi Bundle-License header, a comma-separated
+// list of names or URLs with optional ;link= and ;description= attributes,
+// to an SPDX expression.
+func bundleLicense(value string) string {
+	var licenses []License
+
+	for _, clause := range splitManifestList(value) {
+		parts := strings.Split(clause, ";")
+		name := strings.Trim(strings.TrimSpace(parts[0]), `"`)
+		license := License{Name: name}
+
+		if strings.Contains(name, "://") {
+			license = License{URL: name}
+		}
+		for _, attribute := range parts[1:] {
+			if link, ok := strings.CutPrefix(strings.TrimSpace(attribute), "link="); ok {
+				license.URL = strings.Trim(link, `"`)
+			}
+		}
+		licenses = append(licenses, license)
+	}
+
+	return pomLicenseType(licenses)
+}
+
+// splitManifestList splits a manifest header on commas outside quotes.
+func splitManifestList(value string) []string {
+	var parts []string
+	var current strings.Builder
+	quoted := false
+
+	for _, r := range value {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == ',' && !quoted:
+			parts = append(parts, current.String())
+			current.Reset()
+			continue
+		}
+		current.WriteRune(r)
+	}
+
+	return append(parts, current.String())
+}
+
+// ReadJarFile reads the metadata of the JAR at path.
+func ReadJarFile(path string) (*JarMetadata, error) {
+	reader, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return ReadJar(&reader.Reader)
+}
+
+// ReadJar reads the manifest, Maven metadata and license files of an
+// opened JAR. Nested archives are not opened.
+func ReadJar(reader *zip.Reader) (*JarMetadata, error) {
+	jar := &JarMetadata{Manifest: make(map[string]string)}
+	artifacts := make(map[string]*EmbeddedArtifact)
+
+	for _, file := range reader.File {
+		name := file.Name
+		dir := path.Dir(name)
+		upper := strings.ToUpper(path.Base(name))
+
+		isMaven := strings.HasPrefix(name, "META-INF/maven/")
+		isLicenseDir := dir == "META-INF" || dir == "."
+
+		switch {
+		case strings.EqualFold(name, "META-INF/MANIFEST.MF"):
+		case isMaven && (strings.HasSuffix(name, "/pom.xml") || strings.HasSuffix(name, "/pom.properties")):
+		case isLicenseDir && license.IsFile(path.Base(name)) && jar.LicenseText == "":
+		case isLicenseDir && strings.HasPrefix(upper, "NOTICE") && jar.NoticeText == "":
+		default:
+			continue
+		}
+
+		data, err := readZipFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case strings.EqualFold(name, "META-INF/MANIFEST.MF"):
+			jar.Manifest = parseManifest(data)
+		case isMaven:
+			artifact, ok := artifacts[dir]
+			if !ok {
+				artifact = &EmbeddedArtifact{}
+				artifacts[dir] = artifact
+			}
+
+			if strings.HasSuffix(name, "/pom.properties") {
+				properties := parseProperties(data)
+				artifact.GroupID = properties["groupId"]
+				artifact.ArtifactID = properties["artifactId"]
+				artifact.Version = properties["version"]
+			} else {
+				var pom POM
+				if xml.Unmarshal(data, &pom) == nil {
+					artifact.POM = &pom
+				}
+			}
+		case license.IsFile(path.Base(name)):
+			jar.LicenseText = string(data)
+		default:
+			jar.NoticeText = string(data)
+		}
+	}
+
+	for _, dir := range sortedKeys(artifacts) {
+		artifact := artifacts[dir]
+
+		// Without pom.properties the coordinates come from the pom.xml,
+		// or failing that from the META-INF/maven/<groupId>/<artifactId> path
+		if pom := artifact.POM; pom != nil {
+			if artifact.GroupID == "" {
+				artifact.GroupID = pom.GroupID
+				if artifact.GroupID == "" && pom.Parent != nil {
+					artifact.GroupID = pom.Parent.GroupID
+				}
+			}
+			if artifact.ArtifactID == "" {
+				artifact.ArtifactID = pom.ArtifactID
+			}
+			if artifact.Version == "" {
+				artifact.Version = pom.Version
+				if artifact.Version == "" && pom.Parent != nil {
+					artifact.Version = pom.Parent.Version
+				}
+			}
+		}
+		if artifact.GroupID == "" || artifact.ArtifactID == "" {
+			artifact.GroupID = path.Base(path.Dir(dir))
+			artifact.ArtifactID = path.Base(dir)
+		}
+		// Versions still holding ${...} were not filtered at build time
+		if strings.Contains(artifact.Version, "${") {
+			artifact.Version = ""
+		}
+
+		jar.Artifacts = append(jar.Artifacts, *artifact)
+	}
+
+	return jar, nil
+}
+
+func readZipFile(file *zip.File) ([]byte, error) {
+	rc, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	return io.ReadAll(rc)
+}
+
+// readNestedArchive reads an archive stored in another, failing when it is
+// larger than limit bytes.
+func readNestedArchive(file *zip.File, limit int64) ([]byte, error) {
+	if file.UncompressedSize64 > uint64(limit) {
+		return nil, fmt.Errorf("%s is larger than %d bytes", file.Name, limit)
+	}
+
+	rc, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	// The size in the header is not trusted
+	data, err := io.ReadAll(io.LimitReader(rc, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%s is larger than %d bytes", file.Name, limit)
+	}
+	return data, nil
+}
+
+// parseManifest reads the main section of a MANIFEST.MF, joining
+// continuation lines (which start with a single space).
+func parseManifest(data []byte) map[string]string {
+	attributes := make(map[string]string)
+	var lastKey string
+
+	for _, line := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		if line == "" {
+			// The main section ends at the first blank line
+			break
+		}
+		if strings.HasPrefix(line, " ") && lastKey != "" {
+			attributes[lastKey] += line[1:]
+			continue
+		}
+		if key, value, ok := strings.Cut(line, ":"); ok {
+			lastKey = strings.TrimSpace(key)
+			attributes[lastKey] = strings.TrimSpace(value)
+		}
+	}
+
+	return attributes
+}
+
+// parseProperties reads a Java .properties file of key=value lines.
+func parseProperties(data []byte) map[string]string {
+	properties := make(map[string]string)
+
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+		if key, value, ok := strings.Cut(line, "="); ok {
+			properties[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+
+	return properties
+}
@@ -0,0 +1,616 @@
+package java
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"license-audit/pkg/types"
+)
+
+// VersionCatalog is a gradle/libs.versions.toml file.
+type VersionCatalog struct {
+	Versions  map[string]interface{} `toml:"versions"`
+	Libraries map[string]interface{} `toml:"libraries"`
+}
+
+// CatalogLibrary is a resolved entry of a version catalog.
+type CatalogLibrary struct {
+	Alias   string
+	Group   string
+	Name    string
+	Version string
+}
+
+var (
+	gradleStringPattern    = regexp.MustCompile(`"([^"\\]*(?:\\.[^"\\]*)*)"|'([^'\\]*(?:\\.[^'\\]*)*)'`)
+	gradleNamedArgPattern  = regexp.MustCompile(`\b(group|name|version)\s*[:=]\s*(?:"([^"]*)"|'([^']*)')`)
+	gradleStatementPattern = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)\s*(?:\(\s*(.*)\s*\)|\s(.*))$`)
+	gradleCatalogPattern   = regexp.MustCompile(`\blibs\.([A-Za-z0-9_.]+?)(?:\.get\(\))?(?:[\s,)]|$)`)
+	gradleVariablePattern  = regexp.MustCompile(`\$\{?([A-Za-z_][A-Za-z0-9_.]*)\}?`)
+	gradleAssignPattern    = regexp.MustCompile(`^(?:(?:def|val|var)\s+|ext\.|extra\[")?([A-Za-z_][A-Za-z0-9_]*)"?\]?\s*(?::\s*String\s*)?=\s*(?:"([^"$]*)"|'([^']*)')\s*$`)
+	gradleKotlinPattern    = regexp.MustCompile(`^kotlin\(\s*"([^"]+)"(?:\s*,\s*"([^"]+)")?\s*\)$`)
+)
+
+// gradleScope maps a Gradle configuration name to a dependency scope.
+func gradleScope(configuration string) string {
+	lower := strings.ToLower(configuration)
+
+	switch {
+	case strings.HasPrefix(lower, "test") || strings.Contains(lower, "androidtest") ||
+		strings.Contains(lower, "testfixtures") || strings.Contains(lower, "unittest"):
+		return "test"
+	case strings.HasPrefix(lower, "compileonly"):
+		return "provided"
+	case lower == "annotationprocessor" || strings.HasPrefix(lower, "kapt") ||
+		strings.HasPrefix(lower, "ksp") || lower == "classpath" || strings.HasSuffix(lower, "plugin"):
+		return "build"
+	default:
+		return ""
+	}
+}
+
+// scanGradleLockfile parses gradle.lockfile and buildscript-gradle.lockfile,
+// where each line is group:artifact:version=configuration,...
+func (s *Scanner) scanGradleLockfile(path string) ([]types.Dependency, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", filepath.Base(path), err)
+	}
+	defer file.Close()
+
+	buildscript := strings.HasPrefix(filepath.Base(path), "buildscript-")
+
+	var dependencies []types.Dependency
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		coordinates, configurations, _ := strings.Cut(line, "=")
+		if coordinates == "empty" {
+			continue
+		}
+
+		parts := strings.Split(coordinates, ":")
+		if len(parts) < 3 {
+			continue
+		}
+
+		scope := "build"
+		if !buildscript {
+			scope = lockfileScope(strings.Split(configurations, ","))
+		}
+
+		dependencies = append(dependencies, s.newDependency(parts[0]+":"+parts[1], parts[2], scope, path))
+	}
+
+	return dependencies, scanner.Err()
+}
+
+// lockfileScope picks the widest scope among the configurations that
+// resolved a locked module: runtime beats provided, test and build. A module
+// only on a compile classpath is not shipped, so it counts as provided.
+func lockfileScope(configurations []string) string {
+	rank := map[string]int{"": 0, "provided": 1, "test": 2, "build": 3}
+	best := "build"
+
+	for _, configuration := range configurations {
+		configuration = strings.TrimSpace(configuration)
+		if configuration == "" {
+			continue
+		}
+
+		scope := gradleScope(configuration)
+		if scope == "" && strings.HasSuffix(strings.ToLower(configuration), "compileclasspath") {
+			scope = "provided"
+		}
+		if rank[scope] < rank[best] {
+			best = scope
+		}
+	}
+
+	return best
+}
+
+// scanVersionCatalog reports the libraries of a version catalog that no
+// build script of its project references. Referenced libraries are reported
+// from the build scripts, with the scope of their configuration.
+func (s *Scanner) scanVersionCatalog(path string) ([]types.Dependency, error) {
+	libraries, err := ParseVersionCatalog(path)
+	if err != nil {
+		return nil, err
+	}
+
+	referenced := catalogReferences(filepath.Dir(filepath.Dir(path)))
+
+	var dependencies []types.Dependency
+	for _, alias := range sortedKeys(libraries) {
+		if referenced[catalogAccessor(alias)] {
+			continue
+		}
+		library := libraries[alias]
+		dependencies = append(dependencies, s.newDependency(library.Group+":"+library.Name, library.Version, "", path))
+	}
+
+	return dependencies, nil
+}
+
+// catalogReferences collects the catalog accessors used in the dependencies
+// blocks of the build scripts that belong to the Gradle build rooted at root.
+func catalogReferences(root string) map[string]bool {
+	referenced := make(map[string]bool)
+
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path != root && (strings.HasPrefix(d.Name(), ".") || d.Name() == "build") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() != "build.gradle" && d.Name() != "build.gradle.kts" {
+			return nil
+		}
+		// Builds with their own settings have their own catalog
+		if gradleSettingsRoot(filepath.Dir(path)) != root {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		for _, block := range gradleDependencyBlocks(stripGradleComments(string(data))) {
+			for _, statement := range gradleStatements(block.body) {
+				if match := gradleCatalogPattern.FindStringSubmatch(statement); match != nil {
+					referenced[match[1]] = true
+				}
+			}
+		}
+		return nil
+	})
+
+	return referenced
+}
+
+// ParseVersionCatalog reads the [libraries] of a libs.versions.toml file,
+// resolving version.ref against [versions]. Libraries are keyed by alias.
+func ParseVersionCatalog(path string) (map[string]CatalogLibrary, error) {
+	var catalog VersionCatalog
+	if _, err := toml.DecodeFile(path, &catalog); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Base(path), err)
+	}
+
+	libraries := make(map[string]CatalogLibrary)
+	for alias, entry := range catalog.Libraries {
+		library := CatalogLibrary{Alias: alias, Version: "UNKNOWN"}
+
+		switch v := entry.(type) {
+		case string:
+			// "group:name:version" shorthand
+			parts := strings.Split(v, ":")
+			if len(parts) < 2 {
+				continue
+			}
+			library.Group, library.Name = parts[0], parts[1]
+			if len(parts) > 2 {
+				library.Version = parts[2]
+			}
+		case map[string]interface{}:
+			if module, ok := v["module"].(string); ok {
+				library.Group, library.Name, _ = strings.Cut(module, ":")
+			} else {
+				library.Group, _ = v["group"].(string)
+				library.Name, _ = v["name"].(string)
+			}
+			if version := catalogVersion(v["version"], catalog.Versions); version != "" {
+				library.Version = version
+			}
+		default:
+			continue
+		}
+
+		if library.Group == "" || library.Name == "" {
+			continue
+		}
+		libraries[alias] = library
+	}
+
+	return libraries, nil
+}
+
+// catalogVersion resolves a version declaration, which may be a string, a
+// {ref = ...} table (as written by version.ref) or a rich version table.
+func catalogVersion(version interface{}, versions map[string]interface{}) string {
+	switch v := version.(type) {
+	case string:
+		return v
+	case map[string]interface{}:
+		if ref, ok := v["ref"].(string); ok {
+			return catalogVersion(versions[ref], versions)
+		}
+		for _, key := range []string{"strictly", "require", "prefer"} {
+			if rich, ok := v[key].(string); ok {
+				return rich
+			}
+		}
+	}
+	return ""
+}
+
+// catalogAccessor converts a catalog alias into the accessor used in build
+// scripts: "spring-boot_starter" becomes "spring.boot.starter".
+func catalogAccessor(alias string) string {
+	return strings.NewReplacer("-", ".", "_", ".").Replace(alias)
+}
+
+// findVersionCatalog returns the gradle/libs.versions.toml of the build a
+// build file in dir belongs to, or "" when that build has none.
+func findVersionCatalog(dir string) string {
+	candidate := filepath.Join(gradleSettingsRoot(dir), "gradle", "libs.versions.toml")
+	if _, err := os.Stat(candidate); err != nil {
+		return ""
+	}
+	return candidate
+}
+
+// gradleSettingsRoot returns the nearest directory at or above dir holding
+// settings.gradle or settings.gradle.kts. A build without a settings file is
+// rooted at dir itself.
+func gradleSettingsRoot(dir string) string {
+	for current := dir; ; {
+		for _, name := range []string{"settings.gradle", "settings.gradle.kts"} {
+			if _, err := os.Stat(filepath.Join(current, name)); err == nil {
+				return current
+			}
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return dir
+		}
+		current = parent
+	}
+}
+
+// scanGradleBuild extracts dependency declarations from the dependencies
+// blocks of build.gradle (Groovy DSL) and build.gradle.kts (Kotlin DSL).
+func (s *Scanner) scanGradleBuild(path string) ([]types.Dependency, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
+	}
+
+	source := stripGradleComments(string(data))
+	dir := filepath.Dir(path)
+
+	variables := gradleProperties(filepath.Join(dir, "gradle.properties"))
+	for _, line := range strings.Split(source, "\n") {
+		if match := gradleAssignPattern.FindStringSubmatch(strings.TrimSpace(line)); match != nil {
+			variables[match[1]] = match[2] + match[3]
+		}
+	}
+
+	catalog := make(map[string]CatalogLibrary)
+	if catalogPath := findVersionCatalog(dir); catalogPath != "" {
+		if libraries, err := ParseVersionCatalog(catalogPath); err == nil {
+			for alias, library := range libraries {
+				catalog[catalogAccessor(alias)] = library
+			}
+		}
+	}
+
+	var dependencies []types.Dependency
+	for _, block := range gradleDependencyBlocks(source) {
+		for _, statement := range gradleStatements(block.body) {
+			dep, ok := s.parseGradleStatement(statement, variables, catalog, path)
+			if !ok {
+				continue
+			}
+			if block.buildscript {
+				dep.Scope = "build"
+			}
+			dependencies = append(dependencies, dep)
+		}
+	}
+
+	return dependencies, nil
+}
+
+func (s *Scanner) parseGradleStatement(statement string, variables map[string]string, catalog map[string]CatalogLibrary, path string) (types.Dependency, bool) {
+	match := gradleStatementPattern.FindStringSubmatch(statement)
+	if match == nil {
+		return types.Dependency{}, false
+	}
+
+	configuration := match[1]
+	args := strings.TrimSpace(match[2] + match[3])
+	scope := gradleScope(configuration)
+
+	// Local modules and files are not third-party dependencies
+	if args == "" || strings.HasPrefix(args, "project(") || strings.HasPrefix(args, "files(") ||
+		strings.HasPrefix(args, "fileTree(") || strings.HasPrefix(args, "gradleApi(") {
+		return types.Dependency{}, false
+	}
+
+	// platform(...) and enforcedPlatform(...) import BOMs
+	for _, wrapper := range []string{"platform(", "enforcedPlatform("} {
+		if inner, ok := strings.CutPrefix(args, wrapper); ok {
+			args = strings.TrimSuffix(strings.TrimSpace(inner), ")")
+		}
+	}
+
+	if kotlinMatch := gradleKotlinPattern.FindStringSubmatch(args); kotlinMatch != nil {
+		version := kotlinMatch[2]
+		if version == "" {
+			version = "UNKNOWN"
+		}
+		return s.newDependency("org.jetbrains.kotlin:kotlin-"+kotlinMatch[1], version, scope, path), true
+	}
+
+	if catalogMatch := gradleCatalogPattern.FindStringSubmatch(args); catalogMatch != nil {
+		library, ok := catalog[catalogMatch[1]]
+		if !ok {
+			return types.Dependency{}, false
+		}
+		return s.newDependency(library.Group+":"+library.Name, library.Version, scope, path), true
+	}
+
+	// Map notation: group: 'g', name: 'a', version: 'v' (Groovy) or
+	// group = "g", name = "a", version = "v" (Kotlin)
+	if named := gradleNamedArgPattern.FindAllStringSubmatch(args, -1); len(named) > 0 {
+		fields := make(map[string]string)
+		for _, m := range named {
+			fields[m[1]] = m[2] + m[3]
+		}
+		if fields["group"] == "" || fields["name"] == "" {
+			return types.Dependency{}, false
+		}
+		return s.newDependency(fields["group"]+":"+fields["name"], interpolateGradle(fields["version"], variables), scope, path), true
+	}
+
+	// String notation: "group:name:version[:classifier][@type]"
+	stringMatch := gradleStringPattern.FindStringSubmatch(args)
+	if stringMatch == nil {
+		return types.Dependency{}, false
+	}
+	notation := strings.SplitN(stringMatch[1]+stringMatch[2], "@", 2)[0]
+	parts := strings.Split(notation, ":")
+	if len(parts) < 2 {
+		return types.Dependency{}, false
+	}
+
+	version := ""
+	if len(parts) > 2 {
+		version = parts[2]
+	}
+	return s.newDependency(parts[0]+":"+parts[1], interpolateGradle(version, variables), scope, path), true
+}
+
+// interpolateGradle substitutes $name and ${name} references to known
+// variables. Versions that still reference unknown values are UNKNOWN.
+func interpolateGradle(value string, variables map[string]string) string {
+	value = gradleVariablePattern.ReplaceAllStringFunc(value, func(ref string) string {
+		name := strings.Trim(ref, "${}")
+		name = strings.TrimPrefix(strings.TrimPrefix(name, "project."), "rootProject.")
+		if resolved, ok := variables[name]; ok {
+			return resolved
+		}
+		return ref
+	})
+
+	if value == "" || strings.Contains(value, "$") {
+		return "UNKNOWN"
+	}
+	return value
+}
+
+// gradleProperties reads key=value pairs from gradle.properties.
+func gradleProperties(path string) map[string]string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return make(map[string]string)
+	}
+	return parseProperties(data)
+}
+
+// stripGradleComments removes // and /* */ comments outside of strings.
+func stripGradleComments(source string) string {
+	var sb strings.Builder
+	var quote byte
+
+	for i := 0; i < len(source); i++ {
+		c := source[i]
+
+		if quote != 0 {
+			sb.WriteByte(c)
+			if c == '\\' && i+1 < len(source) {
+				i++
+				sb.WriteByte(source[i])
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+
+		switch {
+		case c == '"' || c == '\'':
+			quote = c
+			sb.WriteByte(c)
+		case c == '/' && i+1 < len(source) && source[i+1] == '/':
+			for i < len(source) && source[i] != '\n' {
+				i++
+			}
+			sb.WriteByte('\n')
+		case c == '/' && i+1 < len(source) && source[i+1] == '*':
+			end := strings.Index(source[i+2:], "*/")
+			if end == -1 {
+				return sb.String()
+			}
+			// Keep line breaks so statements stay separated
+			sb.WriteString(strings.Repeat("\n", strings.Count(source[i:i+2+end], "\n")))
+			i += end + 3
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	return sb.String()
+}
+
+type gradleBlock struct {
+	body        string
+	buildscript bool
+}
+
+// gradleDependencyBlocks finds the bodies of all dependencies { } blocks,
+// noting those nested in buildscript { }.
+func gradleDependencyBlocks(source string) []gradleBlock {
+	var blocks []gradleBlock
+	pattern := regexp.MustCompile(`\b(buildscript|dependencies)\s*\{`)
+
+	var scan func(text string, buildscript bool)
+	scan = func(text string, buildscript bool) {
+		offset := 0
+		for {
+			loc := pattern.FindStringSubmatchIndex(text[offset:])
+			if loc == nil {
+				return
+			}
+			keyword := text[offset+loc[2] : offset+loc[3]]
+			open := offset + loc[1] - 1
+			end := matchingBrace(text, open)
+			body := text[open+1 : end]
+
+			if keyword == "buildscript" {
+				scan(body, true)
+			} else {
+				blocks = append(blocks, gradleBlock{body: body, buildscript: buildscript})
+			}
+
+			if end >= len(text) {
+				return
+			}
+			offset = end + 1
+		}
+	}
+	scan(source, false)
+
+	return blocks
+}
+
+// matchingBrace returns the index of the brace closing the one at open,
+// or len(text) when it is unbalanced.
+func matchingBrace(text string, open int) int {
+	depth := 0
+	var quote byte
+
+	for i := open; i < len(text); i++ {
+		c := text[i]
+		if quote != 0 {
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+
+		switch c {
+		case '"', '\'':
+			quote = c
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return len(text)
+}
+
+// gradleStatements splits a block body into top-level statements, joining
+// calls that span lines and dropping trailing configuration closures.
+func gradleStatements(body string) []string {
+	var statements []string
+	var current strings.Builder
+	parens, braces := 0, 0
+	var quote byte
+
+	flush := func() {
+		// Collapse calls that were split across lines onto one line
+		if statement := strings.Join(strings.Fields(current.String()), " "); statement != "" {
+			statements = append(statements, statement)
+		}
+		current.Reset()
+	}
+
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+
+		if quote != 0 {
+			if braces == 0 {
+				current.WriteByte(c)
+			}
+			if c == '\\' && i+1 < len(body) {
+				i++
+				if braces == 0 {
+					current.WriteByte(body[i])
+				}
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+
+		switch {
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '{':
+			braces++
+			continue
+		case c == '}':
+			braces--
+			continue
+		case braces > 0:
+			continue
+		case c == '(':
+			parens++
+		case c == ')':
+			parens--
+		case (c == '\n' || c == ';') && parens == 0:
+			flush()
+			continue
+		}
+
+		if braces == 0 {
+			current.WriteByte(c)
+		}
+	}
+	flush()
+
+	return statements
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -1,31 +1,21 @@
 package java
 
 import (
-	"encoding/xml"
 	"fmt"
-	"os"
 	"path/filepath"
 	"strings"
 
 	"license-audit/pkg/types"
 )
 
-type Scanner struct{}
+type Scanner struct {
+	// MavenRepository is the local Maven repository used to resolve parent
+	// POMs, BOM imports and license metadata. It defaults to ~/.m2/repository.
+	MavenRepository string
 
-type POM struct {
-	XMLName      xml.Name     `xml:"project"`
-	Dependencies Dependencies `xml:"dependencies"`
-}
-
-type Dependencies struct {
-	Dependency []Dependency `xml:"dependency"`
-}
-
-type Dependency struct {
-	GroupID    string `xml:"groupId"`
-	ArtifactID string `xml:"artifactId"`
-	Version    string `xml:"version"`
-	Scope      string `xml:"scope"`
+	pomCache       map[string]*POM
+	effectiveCache map[string]*EffectivePOM
+	licenseCache   map[string]ArtifactLicense
 }
 
 func NewScanner() *Scanner {
@@ -38,59 +28,53 @@ func (s *Scanner) Name() string {
 
 func (s *Scanner) Detect(path string) bool {
 	fileName := filepath.Base(path)
-	return fileName == "pom.xml" || fileName == "build.gradle" ||
-		fileName == "build.gradle.kts"
+	switch fileName {
+	case "pom.xml", "build.gradle", "build.gradle.kts", "gradle.lockfile", "buildscript-gradle.lockfile":
+		return true
+	}
+
+	// Version catalogs live in the gradle/ directory of the root project
+	return strings.HasSuffix(fileName, ".versions.toml") && filepath.Base(filepath.Dir(path)) == "gradle"
 }
 
 func (s *Scanner) Scan(path string) ([]types.Dependency, error) {
+	dependencies, err := s.scanFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s.applyRepositoryLicenses(dependencies)
+	return dependencies, nil
+}
+
+func (s *Scanner) scanFile(path string) ([]types.Dependency, error) {
 	fileName := filepath.Base(path)
 
 	switch {
 	case fileName == "pom.xml":
 		return s.scanPOM(path)
 	case strings.HasPrefix(fileName, "build.gradle"):
-		return s.scanGradle(path)
+		return s.scanGradleBuild(path)
+	case strings.HasSuffix(fileName, "gradle.lockfile"):
+		return s.scanGradleLockfile(path)
+	case strings.HasSuffix(fileName, ".versions.toml"):
+		return s.scanVersionCatalog(path)
 	default:
 		return nil, fmt.Errorf("unsupported Java file: %s", fileName)
 	}
 }
 
-func (s *Scanner) scanPOM(path string) ([]types.Dependency, error) {
-	file, err := os.Open(path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open pom.xml: %w", err)
+func (s *Scanner) newDependency(name, version, scope, filePath string) types.Dependency {
+	if version == "" {
+		version = "UNKNOWN"
 	}
-	defer file.Close()
-
-	var pom POM
-	if err := xml.NewDecoder(file).Decode(&pom); err != nil {
-		return nil, fmt.Errorf("failed to parse pom.xml: %w", err)
-	}
-
-	var dependencies []types.Dependency
-	for _, dep := range pom.Dependencies.Dependency {
-		name := dep.GroupID + ":" + dep.ArtifactID
-		version := dep.Version
-		if version == "" {
-			version = "UNKNOWN"
-		}
 
-		dependency := types.Dependency{
-			Name:        name,
-			Version:     version,
-			LicenseType: "UNKNOWN",
-			PackageType: "maven",
-			FilePath:    path,
-		}
-
-		dependencies = append(dependencies, dependency)
+	return types.Dependency{
+		Name:        name,
+		Version:     version,
+		LicenseType: "UNKNOWN",
+		PackageType: "maven",
+		FilePath:    filePath,
+		Scope:       scope,
 	}
-
-	return dependencies, nil
-}
-
-func (s *Scanner) scanGradle(path string) ([]types.Dependency, error) {
-	// Basic implementation for Gradle files
-	// This could be enhanced to actually parse Gradle build files
-	return []types.Dependency{}, nil
 }
@@ -0,0 +1,417 @@
+package java
+
+import (
+	"archive/zip"
+	"bytes"
+	"path/filepath"
+	"testing"
+)
+
+const fixturesDir = "../../../test/fixtures/java"
+
+func TestDetect(t *testing.T) {
+	scanner := NewScanner()
+
+	testCases := []struct {
+		path     string
+		expected bool
+	}{
+		{"pom.xml", true},
+		{"build.gradle", true},
+		{"build.gradle.kts", true},
+		{"gradle.lockfile", true},
+		{"buildscript-gradle.lockfile", true},
+		{"gradle/libs.versions.toml", true},
+		{"libs.versions.toml", false},
+		{"package.json", false},
+		{"", false},
+	}
+
+	for _, tc := range testCases {
+		result := scanner.Detect(tc.path)
+		if result != tc.expected {
+			t.Errorf("Detect(%s) = %v, expected %v", tc.path, result, tc.expected)
+		}
+	}
+}
+
+func TestScanGradle(t *testing.T) {
+	scanner := NewScanner()
+
+	type expectation struct {
+		version string
+		scope   string
+	}
+
+	testCases := []struct {
+		fileName string
+		expected map[string]expectation
+	}{
+		{
+			fileName: "build.gradle",
+			expected: map[string]expectation{
+				"org.springframework.boot:spring-boot-gradle-plugin": {"3.1.4", "build"},
+				"org.apache.commons:commons-lang3":                   {"3.13.0", ""},
+				"org.slf4j:slf4j-api":                                {"2.0.9", ""},
+				"com.squareup.okhttp3:okhttp":                        {"4.11.0", ""},
+				"org.springframework.boot:spring-boot-dependencies":  {"3.1.4", ""},
+				"org.projectlombok:lombok":                           {"1.18.30", "provided"},
+				"junit:junit":                                        {"4.13.2", "test"},
+			},
+		},
+		{
+			fileName: "app/build.gradle.kts",
+			expected: map[string]expectation{
+				"com.fasterxml.jackson.core:jackson-databind":   {"2.15.2", ""},
+				"com.google.guava:guava":                        {"32.1.2-jre", ""},
+				"org.jetbrains.kotlin:kotlin-stdlib":            {"UNKNOWN", ""},
+				"org.jetbrains.kotlinx:kotlinx-coroutines-core": {"1.7.3", ""},
+				"io.ktor:ktor-client-core":                      {"2.3.5", ""},
+				"org.postgresql:postgresql":                     {"UNKNOWN", ""},
+				"org.junit.jupiter:junit-jupiter":               {"5.10.0", "test"},
+				"com.google.dagger:dagger-compiler":             {"2.48", "build"},
+			},
+		},
+		{
+			fileName: "gradle.lockfile",
+			expected: map[string]expectation{
+				"com.google.guava:guava":            {"32.1.2-jre", ""},
+				"junit:junit":                       {"4.13.2", "test"},
+				"org.hamcrest:hamcrest-core":        {"1.3", "test"},
+				"org.projectlombok:lombok":          {"1.18.30", "provided"},
+				"com.google.dagger:dagger-compiler": {"2.48", "build"},
+			},
+		},
+		{
+			fileName: "buildscript-gradle.lockfile",
+			expected: map[string]expectation{
+				"org.springframework.boot:spring-boot-gradle-plugin": {"3.1.4", "build"},
+			},
+		},
+		{
+			// Libraries the build scripts use are reported from there
+			fileName: "gradle/libs.versions.toml",
+			expected: map[string]expectation{
+				"commons-io:commons-io": {"2.14.0", ""},
+			},
+		},
+		{
+			// A separate build does not see the catalog of the one around it
+			fileName: "standalone/build.gradle",
+			expected: map[string]expectation{
+				"commons-codec:commons-codec": {"1.16.0", ""},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		dependencies, err := scanner.Scan(filepath.Join(fixturesDir, "gradle", tc.fileName))
+		if err != nil {
+			t.Fatalf("Scan(%s) returned error: %v", tc.fileName, err)
+		}
+
+		if len(dependencies) != len(tc.expected) {
+			t.Errorf("%s: expected %d dependencies, got %d", tc.fileName, len(tc.expected), len(dependencies))
+		}
+
+		for _, dep := range dependencies {
+			want, ok := tc.expected[dep.Name]
+			if !ok {
+				t.Errorf("%s: unexpected dependency '%s'", tc.fileName, dep.Name)
+				continue
+			}
+
+			got := expectation{dep.Version, dep.Scope}
+			if got != want {
+				t.Errorf("%s: dependency '%s' = %+v, expected %+v", tc.fileName, dep.Name, got, want)
+			}
+
+			if dep.PackageType != "maven" {
+				t.Errorf("Expected package type 'maven', got '%s'", dep.PackageType)
+			}
+		}
+	}
+}
+
+func TestScanPOM(t *testing.T) {
+	scanner := NewScanner()
+	scanner.MavenRepository = filepath.Join(fixturesDir, "maven", "repository")
+
+	type expectation struct {
+		version string
+		scope   string
+	}
+
+	testCases := []struct {
+		fileName string
+		expected map[string]expectation
+	}{
+		{
+			fileName: "pom.xml",
+			expected: map[string]expectation{
+				"org.slf4j:slf4j-api": {"2.0.9", ""},
+			},
+		},
+		{
+			fileName: "core/pom.xml",
+			expected: map[string]expectation{
+				// Redeclared over the parent's compile dependency
+				"org.slf4j:slf4j-api":                         {"2.0.9", "test"},
+				"com.fasterxml.jackson.core:jackson-databind": {"2.15.2", ""},
+				"com.google.guava:guava":                      {"32.1.2-jre", ""},
+				"org.junit.jupiter:junit-jupiter":             {"5.10.0", "test"},
+			},
+		},
+		{
+			fileName: "app/pom.xml",
+			expected: map[string]expectation{
+				"org.slf4j:slf4j-api":                         {"2.0.9", ""},
+				"com.fasterxml.jackson.core:jackson-databind": {"2.16.0", ""},
+				"org.apache.commons:commons-lang3":            {"3.13.0", ""},
+				"jakarta.servlet:jakarta.servlet-api":         {"6.0.0", "provided"},
+				"org.postgresql:postgresql":                   {"42.6.0", "optional"},
+				"com.example.unknown:unmanaged":               {"UNKNOWN", ""},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		dependencies, err := scanner.Scan(filepath.Join(fixturesDir, "maven", tc.fileName))
+		if err != nil {
+			t.Fatalf("Scan(%s) returned error: %v", tc.fileName, err)
+		}
+
+		if len(dependencies) != len(tc.expected) {
+			t.Errorf("%s: expected %d dependencies, got %d", tc.fileName, len(tc.expected), len(dependencies))
+		}
+
+		for _, dep := range dependencies {
+			want, ok := tc.expected[dep.Name]
+			if !ok {
+				t.Errorf("%s: unexpected dependency '%s'", tc.fileName, dep.Name)
+				continue
+			}
+
+			got := expectation{dep.Version, dep.Scope}
+			if got != want {
+				t.Errorf("%s: dependency '%s' = %+v, expected %+v", tc.fileName, dep.Name, got, want)
+			}
+		}
+	}
+}
+
+func TestInterpolatePOM(t *testing.T) {
+	properties := map[string]string{
+		"a":       "${b}",
+		"b":       "1.0",
+		"cycle":   "${cycle}",
+		"version": "${a}-SNAPSHOT",
+	}
+
+	testCases := map[string]string{
+		"${a}":               "1.0",
+		"${version}":         "1.0-SNAPSHOT",
+		"prefix-${b}-suffix": "prefix-1.0-suffix",
+		"${missing}":         "${missing}",
+		"${cycle}":           "${cycle}",
+	}
+
+	for value, expected := range testCases {
+		if result := interpolatePOM(value, properties); result != expected {
+			t.Errorf("interpolatePOM(%s) = %s, expected %s", value, result, expected)
+		}
+	}
+}
+
+func TestRepositoryLicenses(t *testing.T) {
+	scanner := NewScanner()
+	scanner.MavenRepository = filepath.Join(fixturesDir, "maven", "repository")
+
+	expected := map[string]string{
+		"org.slf4j:slf4j-api":                 "MIT",        // META-INF/LICENSE.txt
+		"com.google.guava:guava":              "Apache-2.0", // parent POM
+		"jakarta.servlet:jakarta.servlet-api": "EPL-2.0 OR GPL-2.0-with-classpath-exception",
+		"org.postgresql:postgresql":           "BSD-2-Clause", // embedded META-INF/maven POM
+		"org.apache.commons:commons-lang3":    "UNKNOWN",      // not in the repository
+	}
+
+	found := make(map[string]bool)
+	for _, fileName := range []string{"core/pom.xml", "app/pom.xml"} {
+		dependencies, err := scanner.Scan(filepath.Join(fixturesDir, "maven", fileName))
+		if err != nil {
+			t.Fatalf("Scan(%s) returned error: %v", fileName, err)
+		}
+
+		for _, dep := range dependencies {
+			license, ok := expected[dep.Name]
+			if !ok {
+				continue
+			}
+			found[dep.Name] = true
+			if dep.LicenseType != license {
+				t.Errorf("%s license = %s, expected %s", dep.Name, dep.LicenseType, license)
+			}
+			if dep.Name == "org.slf4j:slf4j-api" && dep.LicenseText == "" {
+				t.Errorf("Expected license text from the JAR for %s", dep.Name)
+			}
+		}
+	}
+
+	for name := range expected {
+		if !found[name] {
+			t.Errorf("Expected to find dependency '%s'", name)
+		}
+	}
+}
+
+func TestMavenLicense(t *testing.T) {
+	testCases := []struct {
+		license  License
+		expected string
+	}{
+		{License{Name: "The Apache Software License, Version 2.0"}, "Apache-2.0"},
+		{License{Name: "Apache 2", URL: "http://www.apache.org/licenses/LICENSE-2.0/"}, "Apache-2.0"},
+		{License{Name: "Whatever", URL: "https://opensource.org/licenses/MIT"}, "MIT"},
+		{License{URL: "https://spdx.org/licenses/BSD-3-Clause.html"}, "BSD-3-Clause"},
+		{License{Name: "Eclipse Public License - v 1.0"}, "EPL-1.0"},
+		{License{Name: "Custom Corp License"}, "Custom Corp License"},
+		{License{}, "UNKNOWN"},
+	}
+
+	for _, tc := range testCases {
+		if result := mavenLicense(tc.license); result != tc.expected {
+			t.Errorf("mavenLicense(%+v) = %s, expected %s", tc.license, result, tc.expected)
+		}
+	}
+}
+
+func TestArchiveDetect(t *testing.T) {
+	scanner := NewArchiveScanner()
+
+	testCases := []struct {
+		path     string
+		expected bool
+	}{
+		{"app.jar", true},
+		{"shop.war", true},
+		{"legacy.EAR", true},
+		{"app-sources.jar", false},
+		{"app-javadoc.jar", false},
+		{"pom.xml", false},
+	}
+
+	for _, tc := range testCases {
+		result := scanner.Detect(tc.path)
+		if result != tc.expected {
+			t.Errorf("Detect(%s) = %v, expected %v", tc.path, result, tc.expected)
+		}
+	}
+}
+
+func TestScanArchives(t *testing.T) {
+	scanner := NewArchiveScanner()
+
+	type expectation struct {
+		version   string
+		license   string
+		scope     string
+		indirect  bool
+		sourceURL string
+	}
+
+	testCases := []struct {
+		fileName string
+		expected map[string]expectation
+	}{
+		{
+			fileName: "app.jar",
+			expected: map[string]expectation{
+				"com.example:app": {"1.0.0", "UNKNOWN", "project", false, ""},
+				"com.fasterxml.jackson.core:jackson-databind": {"2.15.2", "Apache-2.0", "", true, "BOOT-INF/lib/jackson-databind-2.15.2.jar"},
+				"org.example.osgi":       {"1.0.0", "EPL-2.0", "", true, "BOOT-INF/lib/osgi-thing-1.0.jar"},
+				"plain-lib":              {"2.1", "MIT", "", true, "BOOT-INF/lib/plain-lib-2.1.jar"},
+				"com.example:shaded-all": {"1.0", "Apache-2.0", "", true, "BOOT-INF/lib/shaded-all-1.0.jar"},
+				"com.google.guava:guava": {"32.1.2-jre", "Apache-2.0", "", true, "BOOT-INF/lib/shaded-all-1.0.jar"},
+				"org.example:inner":      {"0.1", "UNKNOWN", "", true, "BOOT-INF/lib/shaded-all-1.0.jar!/lib/inner-0.1.jar"},
+			},
+		},
+		{
+			fileName: "shop.war",
+			expected: map[string]expectation{
+				"shop":          {"UNKNOWN", "UNKNOWN", "project", false, ""},
+				"commons-lang3": {"3.13.0", "UNKNOWN", "", true, "WEB-INF/lib/commons-lang3-3.13.0.jar"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		dependencies, err := scanner.Scan(filepath.Join(fixturesDir, "archives", tc.fileName))
+		if err != nil {
+			t.Fatalf("Scan(%s) returned error: %v", tc.fileName, err)
+		}
+
+		if len(dependencies) != len(tc.expected) {
+			t.Errorf("%s: expected %d dependencies, got %d", tc.fileName, len(tc.expected), len(dependencies))
+		}
+
+		for _, dep := range dependencies {
+			want, ok := tc.expected[dep.Name]
+			if !ok {
+				t.Errorf("%s: unexpected dependency '%s'", tc.fileName, dep.Name)
+				continue
+			}
+
+			got := expectation{dep.Version, dep.LicenseType, dep.Scope, dep.Indirect, dep.SourceURL}
+			if got != want {
+				t.Errorf("%s: dependency '%s' = %+v, expected %+v", tc.fileName, dep.Name, got, want)
+			}
+		}
+	}
+}
+
+func TestBundleLicense(t *testing.T) {
+	testCases := map[string]string{
+		"Apache-2.0": "Apache-2.0",
+		`"Apache License, Version 2.0";link="https://www.apache.org/licenses/LICENSE-2.0.txt"`: "Apache-2.0",
+		"http://www.opensource.org/licenses/mit-license.php":                                   "MIT",
+		"EPL-2.0, GPL-2.0-with-classpath-exception":                                            "EPL-2.0 OR GPL-2.0-with-classpath-exception",
+	}
+
+	for value, expected := range testCases {
+		if result := bundleLicense(value); result != expected {
+			t.Errorf("bundleLicense(%s) = %s, expected %s", value, result, expected)
+		}
+	}
+}
+
+func TestReadNestedArchive(t *testing.T) {
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	entry, err := writer.Create("WEB-INF/lib/large.jar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry.Write(make([]byte, 4096))
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := reader.File[0]
+
+	if data, err := readNestedArchive(file, 4096); err != nil || len(data) != 4096 {
+		t.Errorf("readNestedArchive(4096) = %d bytes, %v, expected 4096 bytes", len(data), err)
+	}
+	if _, err := readNestedArchive(file, 1024); err == nil {
+		t.Errorf("readNestedArchive(1024) returned no error, expected the entry to be skipped")
+	}
+
+	// A header that understates the size does not lift the limit
+	file.UncompressedSize64 = 512
+	if _, err := readNestedArchive(file, 1024); err == nil {
+		t.Errorf("readNestedArchive(1024) with a short header returned no error")
+	}
+}
@@ -0,0 +1,260 @@
+package java
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"license-audit/internal/license"
+	"license-audit/pkg/types"
+)
+
+// ArtifactLicense is the license metadata found for a Maven artifact.
+type ArtifactLicense struct {
+	LicenseType string
+	LicenseText string
+	LicenseURL  string
+	Homepage    string
+}
+
+// licenseNames maps license names commonly found in POMs to SPDX
+// identifiers. Keys are lower-cased.
+var licenseNames = map[string]string{
+	"apache 2":                                 "Apache-2.0",
+	"apache 2.0":                               "Apache-2.0",
+	"apache-2.0":                               "Apache-2.0",
+	"apache license 2.0":                       "Apache-2.0",
+	"apache license, version 2.0":              "Apache-2.0",
+	"apache license version 2.0":               "Apache-2.0",
+	"apache software license - version 2.0":    "Apache-2.0",
+	"the apache license, version 2.0":          "Apache-2.0",
+	"the apache software license, version 2.0": "Apache-2.0",
+	"asf 2.0":                                  "Apache-2.0",
+	"mit":                                      "MIT",
+	"mit license":                              "MIT",
+	"the mit license":                          "MIT",
+	"the mit license (mit)":                    "MIT",
+	"bouncy castle licence":                    "MIT",
+	"bsd":                                      "BSD-3-Clause",
+	"bsd license":                              "BSD-3-Clause",
+	"the bsd license":                          "BSD-3-Clause",
+	"new bsd license":                          "BSD-3-Clause",
+	"bsd 3-clause":                             "BSD-3-Clause",
+	"bsd-3-clause":                             "BSD-3-Clause",
+	"revised bsd":                              "BSD-3-Clause",
+	"eclipse distribution license - v 1.0":     "BSD-3-Clause",
+	"edl 1.0":                                  "BSD-3-Clause",
+	"bsd 2-clause":                             "BSD-2-Clause",
+	"bsd-2-clause":                             "BSD-2-Clause",
+	"eclipse public license - v 1.0":           "EPL-1.0",
+	"eclipse public license 1.0":               "EPL-1.0",
+	"eclipse public license v1.0":              "EPL-1.0",
+	"epl 1.0":                                  "EPL-1.0",
+	"eclipse public license - v 2.0":           "EPL-2.0",
+	"eclipse public license 2.0":               "EPL-2.0",
+	"eclipse public license v2.0":              "EPL-2.0",
+	"epl 2.0":                                  "EPL-2.0",
+	"epl-2.0":                                  "EPL-2.0",
+	"gnu lesser general public license":        "LGPL-2.1",
+	"gnu lesser general public license, version 2.1": "LGPL-2.1",
+	"lgpl 2.1": "LGPL-2.1",
+	"lgpl-2.1": "LGPL-2.1",
+	"gnu general public license, version 2 with the classpath exception": "GPL-2.0-with-classpath-exception",
+	"gpl2 w/ cpe":                                 "GPL-2.0-with-classpath-exception",
+	"cddl + gplv2 with classpath exception":       "CDDL-1.1 OR GPL-2.0-with-classpath-exception",
+	"cddl/gplv2+ce":                               "CDDL-1.1 OR GPL-2.0-with-classpath-exception",
+	"common development and distribution license": "CDDL-1.0",
+	"cddl 1.1":                                    "CDDL-1.1",
+	"mozilla public license 2.0":                  "MPL-2.0",
+	"mozilla public license version 2.0":          "MPL-2.0",
+	"mpl 2.0":                                     "MPL-2.0",
+	"mpl-2.0":                                     "MPL-2.0",
+	"go license":                                  "BSD-3-Clause",
+	"public domain":                               "Public-Domain",
+	"cc0":                                         "CC0-1.0",
+	"universal permissive license, version 1.0": "UPL-1.0",
+}
+
+// licenseURLs maps license URLs commonly found in POMs to SPDX
+// identifiers. Keys are normalized by normalizeLicenseURL.
+var licenseURLs = map[string]string{
+	"apache.org/licenses/license-2.0":             "Apache-2.0",
+	"apache.org/licenses/license-2.0.txt":         "Apache-2.0",
+	"apache.org/licenses/license-2.0.html":        "Apache-2.0",
+	"opensource.org/licenses/mit":                 "MIT",
+	"opensource.org/licenses/mit-license.php":     "MIT",
+	"opensource.org/licenses/bsd-license.php":     "BSD-3-Clause",
+	"opensource.org/licenses/bsd-3-clause":        "BSD-3-Clause",
+	"opensource.org/licenses/bsd-2-clause":        "BSD-2-Clause",
+	"eclipse.org/legal/epl-v10.html":              "EPL-1.0",
+	"eclipse.org/legal/epl-2.0":                   "EPL-2.0",
+	"eclipse.org/legal/epl-v20.html":              "EPL-2.0",
+	"eclipse.org/org/documents/edl-v10.php":       "BSD-3-Clause",
+	"gnu.org/licenses/old-licenses/lgpl-2.1.html": "LGPL-2.1",
+	"gnu.org/licenses/lgpl.html":                  "LGPL-3.0",
+	"gnu.org/licenses/lgpl-3.0.html":              "LGPL-3.0",
+	"gnu.org/licenses/gpl-3.0.html":               "GPL-3.0",
+	"openjdk.java.net/legal/gplv2+ce.html":        "GPL-2.0-with-classpath-exception",
+	"mozilla.org/mpl/2.0":                         "MPL-2.0",
+	"creativecommons.org/publicdomain/zero/1.0":   "CC0-1.0",
+}
+
+// applyRepositoryLicenses fills in licenses for dependencies whose
+// artifacts are in the local Maven repository.
+func (s *Scanner) applyRepositoryLicenses(dependencies []types.Dependency) {
+	for i := range dependencies {
+		dep := &dependencies[i]
+		if dep.LicenseType != "UNKNOWN" && dep.LicenseType != "" {
+			continue
+		}
+
+		groupID, artifactID, ok := strings.Cut(dep.Name, ":")
+		if !ok || dep.Version == "UNKNOWN" {
+			continue
+		}
+
+		metadata := s.artifactLicense(groupID, artifactID, dep.Version)
+		dep.LicenseType = metadata.LicenseType
+		if dep.LicenseText == "" {
+			dep.LicenseText = metadata.LicenseText
+		}
+		if dep.LicenseURL == "" {
+			dep.LicenseURL = metadata.LicenseURL
+		}
+		if dep.Homepage == "" {
+			dep.Homepage = metadata.Homepage
+		}
+	}
+}
+
+// artifactLicense reads an artifact's license from its POM and parent
+// chain in the local repository, then from the JAR itself.
+func (s *Scanner) artifactLicense(groupID, artifactID, version string) ArtifactLicense {
+	key := groupID + ":" + artifactID + ":" + version
+	if metadata, ok := s.licenseCache[key]; ok {
+		return metadata
+	}
+
+	metadata := ArtifactLicense{LicenseType: "UNKNOWN"}
+
+	if pomPath := s.repositoryPOM(groupID, artifactID, version); pomPath != "" {
+		s.pomChainLicense(pomPath, &metadata)
+	}
+
+	if jar, err := ReadJarFile(s.repositoryArtifact(groupID, artifactID, version, "jar")); err == nil {
+		applyJarLicense(jar, &metadata)
+	}
+
+	if s.licenseCache == nil {
+		s.licenseCache = make(map[string]ArtifactLicense)
+	}
+	s.licenseCache[key] = metadata
+	return metadata
+}
+
+// pomChainLicense takes the licenses declared by the POM at pomPath or,
+// when it declares none, by the nearest ancestor that does.
+func (s *Scanner) pomChainLicense(pomPath string, metadata *ArtifactLicense) {
+	for depth := 0; pomPath != "" && depth <= maxPOMDepth; depth++ {
+		pom, err := s.readPOM(pomPath)
+		if err != nil {
+			return
+		}
+
+		if metadata.Homepage == "" {
+			metadata.Homepage = strings.TrimSpace(pom.URL)
+		}
+		if len(pom.Licenses) > 0 {
+			metadata.LicenseType = pomLicenseType(pom.Licenses)
+			metadata.LicenseURL = strings.TrimSpace(pom.Licenses[0].URL)
+			return
+		}
+
+		if pom.Parent == nil {
+			return
+		}
+		pomPath = s.repositoryPOM(pom.Parent.GroupID, pom.Parent.ArtifactID, pom.Parent.Version)
+	}
+}
+
+// applyJarLicense fills in what the POM chain left unknown from the
+// JAR's embedded POM and its META-INF license and notice files.
+func applyJarLicense(jar *JarMetadata, metadata *ArtifactLicense) {
+	if metadata.LicenseType == "UNKNOWN" {
+		for _, artifact := range jar.Artifacts {
+			if artifact.POM != nil && len(artifact.POM.Licenses) > 0 {
+				metadata.LicenseType = pomLicenseType(artifact.POM.Licenses)
+				metadata.LicenseURL = strings.TrimSpace(artifact.POM.Licenses[0].URL)
+				break
+			}
+		}
+	}
+
+	text := jar.LicenseText
+	if text == "" {
+		text = jar.NoticeText
+	}
+	if metadata.LicenseText == "" {
+		metadata.LicenseText = text
+	}
+	if metadata.LicenseType == "UNKNOWN" && text != "" {
+		metadata.LicenseType = license.Detect(text)
+	}
+}
+
+// pomLicenseType maps the <licenses> of a POM to an SPDX expression.
+// Several licenses are offered as alternatives.
+func pomLicenseType(licenses []License) string {
+	var identifiers []string
+	seen := make(map[string]bool)
+
+	for _, l := range licenses {
+		spdx := mavenLicense(l)
+		if spdx == "UNKNOWN" || seen[spdx] {
+			continue
+		}
+		seen[spdx] = true
+		identifiers = append(identifiers, spdx)
+	}
+
+	if len(identifiers) == 0 {
+		return "UNKNOWN"
+	}
+	sort.Strings(identifiers)
+	return strings.Join(identifiers, " OR ")
+}
+
+// mavenLicense maps a single <license> to an SPDX identifier, trying its
+// URL, then its name. Unrecognized names are returned as written.
+func mavenLicense(l License) string {
+	url := normalizeLicenseURL(l.URL)
+	if spdx, ok := licenseURLs[url]; ok {
+		return spdx
+	}
+
+	// spdx.org/licenses/<id>.html links carry the identifier itself
+	if strings.HasPrefix(url, "spdx.org/licenses/") {
+		return strings.TrimSuffix(path.Base(strings.TrimSpace(l.URL)), ".html")
+	}
+
+	name := strings.Join(strings.Fields(l.Name), " ")
+	if name == "" {
+		return "UNKNOWN"
+	}
+	if spdx, ok := licenseNames[strings.ToLower(name)]; ok {
+		return spdx
+	}
+	if spdx := license.Detect(name); spdx != "UNKNOWN" {
+		return spdx
+	}
+	return name
+}
+
+// normalizeLicenseURL drops the scheme, "www." and trailing slashes and
+// lower-cases the URL so equivalent links compare equal.
+func normalizeLicenseURL(url string) string {
+	url = strings.ToLower(strings.TrimSpace(url))
+	url = strings.TrimPrefix(strings.TrimPrefix(url, "https://"), "http://")
+	url = strings.TrimPrefix(url, "www.")
+	return strings.TrimSuffix(url, "/")
+}
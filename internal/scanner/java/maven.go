@@ -0,0 +1,454 @@
+package java
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"license-audit/pkg/types"
+)
+
+// maxPOMDepth bounds parent and import chains so cyclic POMs terminate.
+const maxPOMDepth = 16
+
+type POM struct {
+	XMLName              xml.Name             `xml:"project"`
+	GroupID              string               `xml:"groupId"`
+	ArtifactID           string               `xml:"artifactId"`
+	Version              string               `xml:"version"`
+	Packaging            string               `xml:"packaging"`
+	URL                  string               `xml:"url"`
+	Licenses             []License            `xml:"licenses>license"`
+	Parent               *Parent              `xml:"parent"`
+	Properties           Properties           `xml:"properties"`
+	Dependencies         Dependencies         `xml:"dependencies"`
+	DependencyManagement DependencyManagement `xml:"dependencyManagement"`
+	Modules              []string             `xml:"modules>module"`
+}
+
+type Parent struct {
+	GroupID      string  `xml:"groupId"`
+	ArtifactID   string  `xml:"artifactId"`
+	Version      string  `xml:"version"`
+	RelativePath *string `xml:"relativePath"` // an empty element disables the local lookup
+}
+
+type License struct {
+	Name string `xml:"name"`
+	URL  string `xml:"url"`
+}
+
+type Properties struct {
+	Entries []Property `xml:",any"`
+}
+
+type Property struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+type DependencyManagement struct {
+	Dependencies Dependencies `xml:"dependencies"`
+}
+
+type Dependencies struct {
+	Dependency []Dependency `xml:"dependency"`
+}
+
+type Dependency struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+	Type       string `xml:"type"`
+	Classifier string `xml:"classifier"`
+	Scope      string `xml:"scope"`
+	Optional   string `xml:"optional"`
+}
+
+// EffectivePOM is a POM with its parent chain merged in, properties
+// interpolated and managed versions applied to its dependencies.
+type EffectivePOM struct {
+	GroupID    string
+	ArtifactID string
+	Version    string
+	Properties map[string]string
+	Managed    map[string]Dependency // keyed by groupId:artifactId
+	// Dependencies are fully resolved, with defaulted versions and scopes
+	Dependencies []Dependency
+
+	rawManaged      []Dependency
+	rawDependencies []Dependency
+}
+
+var pomPropertyPattern = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+func (s *Scanner) scanPOM(path string) ([]types.Dependency, error) {
+	effective, err := s.effectivePOM(path, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	reactor := s.reactorModules(path)
+
+	var dependencies []types.Dependency
+	for _, dep := range effective.Dependencies {
+		name := dep.GroupID + ":" + dep.ArtifactID
+
+		// Sibling modules of a multi-module build are not third-party
+		if _, ok := reactor[name]; ok {
+			continue
+		}
+
+		dependencies = append(dependencies, s.newDependency(name, dep.Version, mavenScope(dep.Scope, dep.Optional), path))
+	}
+
+	return dependencies, nil
+}
+
+// mavenScope maps a Maven dependency scope to a dependency scope.
+func mavenScope(scope, optional string) string {
+	switch scope {
+	case "test":
+		return "test"
+	case "provided", "system":
+		return "provided"
+	}
+	if optional == "true" {
+		return "optional"
+	}
+	return ""
+}
+
+func (s *Scanner) readPOM(path string) (*POM, error) {
+	if pom, ok := s.pomCache[path]; ok {
+		return pom, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", filepath.Base(path), err)
+	}
+	defer file.Close()
+
+	var pom POM
+	if err := xml.NewDecoder(file).Decode(&pom); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Base(path), err)
+	}
+
+	if s.pomCache == nil {
+		s.pomCache = make(map[string]*POM)
+	}
+	s.pomCache[path] = &pom
+	return &pom, nil
+}
+
+// effectivePOM builds the effective model of the POM at path, following
+// its parent chain and BOM imports.
+func (s *Scanner) effectivePOM(path string, depth int) (*EffectivePOM, error) {
+	if effective, ok := s.effectiveCache[path]; ok {
+		return effective, nil
+	}
+	if depth > maxPOMDepth {
+		return nil, fmt.Errorf("failed to resolve %s: parent or import chain too deep", path)
+	}
+
+	pom, err := s.readPOM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	effective := &EffectivePOM{
+		GroupID:    pom.GroupID,
+		ArtifactID: pom.ArtifactID,
+		Version:    pom.Version,
+		Properties: make(map[string]string),
+		Managed:    make(map[string]Dependency),
+	}
+
+	var inherited []Dependency
+	if pom.Parent != nil {
+		// A parent that cannot be found leaves its versions unresolved
+		if parentPath := s.findParent(pom.Parent, path); parentPath != "" {
+			if parent, err := s.effectivePOM(parentPath, depth+1); err == nil {
+				for key, value := range parent.Properties {
+					effective.Properties[key] = value
+				}
+				effective.rawManaged = append(effective.rawManaged, parent.rawManaged...)
+				inherited = parent.rawDependencies
+			}
+		}
+
+		if effective.GroupID == "" {
+			effective.GroupID = pom.Parent.GroupID
+		}
+		if effective.Version == "" {
+			effective.Version = pom.Parent.Version
+		}
+		effective.Properties["project.parent.groupId"] = pom.Parent.GroupID
+		effective.Properties["project.parent.artifactId"] = pom.Parent.ArtifactID
+		effective.Properties["project.parent.version"] = pom.Parent.Version
+		effective.Properties["parent.version"] = pom.Parent.Version
+	}
+
+	for _, property := range pom.Properties.Entries {
+		effective.Properties[property.XMLName.Local] = strings.TrimSpace(property.Value)
+	}
+	for _, prefix := range []string{"project.", "pom.", ""} {
+		effective.Properties[prefix+"groupId"] = effective.GroupID
+		effective.Properties[prefix+"artifactId"] = effective.ArtifactID
+		effective.Properties[prefix+"version"] = effective.Version
+	}
+
+	effective.GroupID = interpolatePOM(effective.GroupID, effective.Properties)
+	effective.Version = interpolatePOM(effective.Version, effective.Properties)
+
+	// Inherited declarations are interpolated with this POM's properties,
+	// so a child can override the versions its parent manages
+	effective.rawManaged = append(effective.rawManaged, pom.DependencyManagement.Dependencies.Dependency...)
+	effective.rawDependencies = mergeDependencies(inherited, pom.Dependencies.Dependency, effective.Properties)
+
+	var imports []Dependency
+	for _, raw := range effective.rawManaged {
+		dep := interpolateDependency(raw, effective.Properties)
+		if dep.Scope == "import" && dep.Type == "pom" {
+			imports = append(imports, dep)
+			continue
+		}
+		effective.Managed[dep.GroupID+":"+dep.ArtifactID] = dep
+	}
+
+	// Versions declared directly win over those imported from BOMs, and
+	// earlier imports win over later ones
+	for _, imported := range imports {
+		bomPath := s.findArtifactPOM(imported.GroupID, imported.ArtifactID, imported.Version, path)
+		if bomPath == "" {
+			continue
+		}
+		bom, err := s.effectivePOM(bomPath, depth+1)
+		if err != nil {
+			continue
+		}
+		for key, dep := range bom.Managed {
+			if _, ok := effective.Managed[key]; !ok {
+				effective.Managed[key] = dep
+			}
+		}
+	}
+
+	for _, raw := range effective.rawDependencies {
+		dep := interpolateDependency(raw, effective.Properties)
+		managed, ok := effective.Managed[dep.GroupID+":"+dep.ArtifactID]
+		if ok {
+			if dep.Version == "" {
+				dep.Version = managed.Version
+			}
+			if dep.Scope == "" {
+				dep.Scope = managed.Scope
+			}
+			if dep.Optional == "" {
+				dep.Optional = managed.Optional
+			}
+		}
+		if dep.Scope == "" {
+			dep.Scope = "compile"
+		}
+		if dep.Version == "" || strings.Contains(dep.Version, "${") {
+			dep.Version = "UNKNOWN"
+		}
+		effective.Dependencies = append(effective.Dependencies, dep)
+	}
+
+	if s.effectiveCache == nil {
+		s.effectiveCache = make(map[string]*EffectivePOM)
+	}
+	s.effectiveCache[path] = effective
+	return effective, nil
+}
+
+// mergeDependencies returns the inherited dependencies followed by those
+// declared, where a declared dependency replaces the inherited one with
+// the same groupId:artifactId.
+func mergeDependencies(inherited, declared []Dependency, properties map[string]string) []Dependency {
+	key := func(dep Dependency) string {
+		return interpolatePOM(dep.GroupID, properties) + ":" + interpolatePOM(dep.ArtifactID, properties)
+	}
+
+	overridden := make(map[string]bool)
+	for _, dep := range declared {
+		overridden[key(dep)] = true
+	}
+
+	var merged []Dependency
+	for _, dep := range inherited {
+		if !overridden[key(dep)] {
+			merged = append(merged, dep)
+		}
+	}
+	return append(merged, declared...)
+}
+
+// findParent locates a parent POM through its relativePath (../pom.xml by
+// default), falling back to the local repository.
+func (s *Scanner) findParent(parent *Parent, childPath string) string {
+	if candidate := relativeParentPath(parent, childPath); candidate != "" {
+		if pom, err := s.readPOM(candidate); err == nil && pom.ArtifactID == parent.ArtifactID {
+			return candidate
+		}
+	}
+	return s.repositoryPOM(parent.GroupID, parent.ArtifactID, parent.Version)
+}
+
+func relativeParentPath(parent *Parent, childPath string) string {
+	relativePath := "../pom.xml"
+	if parent.RelativePath != nil {
+		relativePath = strings.TrimSpace(*parent.RelativePath)
+	}
+	if relativePath == "" {
+		return ""
+	}
+
+	candidate := filepath.Join(filepath.Dir(childPath), filepath.FromSlash(relativePath))
+	if info, err := os.Stat(candidate); err != nil {
+		return ""
+	} else if info.IsDir() {
+		candidate = filepath.Join(candidate, "pom.xml")
+	}
+	return candidate
+}
+
+// findArtifactPOM looks up a POM among the reactor modules of the build
+// containing fromPath, then in the local repository.
+func (s *Scanner) findArtifactPOM(groupID, artifactID, version, fromPath string) string {
+	if path, ok := s.reactorModules(fromPath)[groupID+":"+artifactID]; ok {
+		return path
+	}
+	return s.repositoryPOM(groupID, artifactID, version)
+}
+
+// repositoryPOM returns the path of an artifact's POM in the local
+// repository, or "" when it has not been downloaded.
+func (s *Scanner) repositoryPOM(groupID, artifactID, version string) string {
+	path := s.repositoryArtifact(groupID, artifactID, version, "pom")
+	if path == "" {
+		return ""
+	}
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
+}
+
+// repositoryArtifact returns where an artifact with the given extension
+// is stored in the local repository, whether or not it exists.
+func (s *Scanner) repositoryArtifact(groupID, artifactID, version, extension string) string {
+	repository := s.mavenRepository()
+	if repository == "" || groupID == "" || artifactID == "" || version == "" {
+		return ""
+	}
+
+	return filepath.Join(repository, filepath.FromSlash(strings.ReplaceAll(groupID, ".", "/")),
+		artifactID, version, artifactID+"-"+version+"."+extension)
+}
+
+func (s *Scanner) mavenRepository() string {
+	if s.MavenRepository != "" {
+		return s.MavenRepository
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".m2", "repository")
+}
+
+// reactorModules maps groupId:artifactId to the POM path of every module
+// in the multi-module build containing path, found by following parent
+// relativePaths up to the top-level POM and then its <modules>.
+func (s *Scanner) reactorModules(path string) map[string]string {
+	root := path
+	for i := 0; i < maxPOMDepth; i++ {
+		pom, err := s.readPOM(root)
+		if err != nil || pom.Parent == nil {
+			break
+		}
+		parentPath := relativeParentPath(pom.Parent, root)
+		if parentPath == "" {
+			break
+		}
+		if _, err := s.readPOM(parentPath); err != nil {
+			break
+		}
+		root = parentPath
+	}
+
+	modules := make(map[string]string)
+	s.collectModules(root, modules, make(map[string]bool))
+	return modules
+}
+
+func (s *Scanner) collectModules(path string, modules map[string]string, seen map[string]bool) {
+	pom, err := s.readPOM(path)
+	if err != nil || len(pom.Modules) == 0 || seen[path] {
+		return
+	}
+	seen[path] = true
+
+	for _, module := range pom.Modules {
+		modulePath := filepath.Join(filepath.Dir(path), filepath.FromSlash(strings.TrimSpace(module)))
+		if info, err := os.Stat(modulePath); err == nil && info.IsDir() {
+			modulePath = filepath.Join(modulePath, "pom.xml")
+		}
+
+		child, err := s.readPOM(modulePath)
+		if err != nil {
+			continue
+		}
+
+		groupID := child.GroupID
+		if groupID == "" && child.Parent != nil {
+			groupID = child.Parent.GroupID
+		}
+		modules[groupID+":"+child.ArtifactID] = modulePath
+		s.collectModules(modulePath, modules, seen)
+	}
+}
+
+func interpolateDependency(dep Dependency, properties map[string]string) Dependency {
+	dep.GroupID = interpolatePOM(dep.GroupID, properties)
+	dep.ArtifactID = interpolatePOM(dep.ArtifactID, properties)
+	dep.Version = interpolatePOM(dep.Version, properties)
+	dep.Type = interpolatePOM(dep.Type, properties)
+	dep.Scope = interpolatePOM(dep.Scope, properties)
+	dep.Optional = interpolatePOM(dep.Optional, properties)
+	return dep
+}
+
+// interpolatePOM expands ${property} references, including properties
+// that refer to other properties. Unknown references are left in place.
+func interpolatePOM(value string, properties map[string]string) string {
+	value = strings.TrimSpace(value)
+
+	for i := 0; i < maxPOMDepth && strings.Contains(value, "${"); i++ {
+		expanded := pomPropertyPattern.ReplaceAllStringFunc(value, func(ref string) string {
+			name := ref[2 : len(ref)-1]
+			if resolved, ok := properties[name]; ok && resolved != ref {
+				return resolved
+			}
+			if env, ok := strings.CutPrefix(name, "env."); ok {
+				if resolved, ok := os.LookupEnv(env); ok {
+					return resolved
+				}
+			}
+			return ref
+		})
+		if expanded == value {
+			break
+		}
+		value = expanded
+	}
+
+	return value
+}
@@ -0,0 +1,154 @@
+package nodejs
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"license-audit/pkg/types"
+)
+
+// lockFileNames are the files that already describe the full dependency
+// tree, making a walk of the installed tree unnecessary.
+var lockFileNames = []string{"package-lock.json", "npm-shrinkwrap.json", "pnpm-lock.yaml", "yarn.lock"}
+
+// InstalledPackage is a package found on disk under a node_modules directory.
+type InstalledPackage struct {
+	InstallPath string // relative to the project root, e.g. node_modules/a/node_modules/b
+	Name        string
+	Version     string
+	LicenseType string
+	LicenseText string
+}
+
+// readInstalledTree walks rootDir/node_modules, including scoped @org
+// directories and nested node_modules of each package, and returns every
+// installed package keyed by its install path.
+func (s *Scanner) readInstalledTree(rootDir string) map[string]InstalledPackage {
+	tree := make(map[string]InstalledPackage)
+	s.walkNodeModules(rootDir, "node_modules", tree, make(map[string]bool))
+	return tree
+}
+
+func (s *Scanner) walkNodeModules(rootDir, relDir string, tree map[string]InstalledPackage, seen map[string]bool) {
+	// Symlinked installs (npm link, pnpm) may point back into the tree
+	realDir, err := filepath.EvalSymlinks(filepath.Join(rootDir, relDir))
+	if err != nil || seen[realDir] {
+		return
+	}
+	seen[realDir] = true
+
+	entries, err := os.ReadDir(realDir)
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+
+		// Skip .bin, .pnpm, .package-lock.json and other tool metadata
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		if strings.HasPrefix(name, "@") {
+			scoped, err := os.ReadDir(filepath.Join(realDir, name))
+			if err != nil {
+				continue
+			}
+			for _, scopedEntry := range scoped {
+				s.readInstalledPackage(rootDir, filepath.Join(relDir, name, scopedEntry.Name()), tree, seen)
+			}
+			continue
+		}
+
+		s.readInstalledPackage(rootDir, filepath.Join(relDir, name), tree, seen)
+	}
+}
+
+func (s *Scanner) readInstalledPackage(rootDir, relPath string, tree map[string]InstalledPackage, seen map[string]bool) {
+	pkgPath := filepath.Join(rootDir, relPath)
+
+	pkg, err := s.readPackageJSON(filepath.Join(pkgPath, "package.json"))
+	if err != nil {
+		return
+	}
+
+	installPath := filepath.ToSlash(relPath)
+	name := pkg.Name
+	if name == "" {
+		name = installPath[strings.LastIndex(installPath, "node_modules/")+len("node_modules/"):]
+	}
+
+	licenseText, licenseType := s.packageLicense(pkg, pkgPath)
+	tree[installPath] = InstalledPackage{
+		InstallPath: installPath,
+		Name:        name,
+		Version:     pkg.Version,
+		LicenseType: licenseType,
+		LicenseText: licenseText,
+	}
+
+	// Nested duplicates that could not be hoisted
+	s.walkNodeModules(rootDir, filepath.Join(relPath, "node_modules"), tree, seen)
+}
+
+// scanInstalledTree reports the transitive packages of an installed tree
+// that are not declared directly in package.json. It is only used when no
+// lockfile sits next to package.json, and only for a project's own
+// package.json: the installed packages under node_modules are part of the
+// project's tree already.
+func (s *Scanner) scanInstalledTree(path string, direct map[string]bool) []types.Dependency {
+	rootDir := filepath.Dir(path)
+
+	for _, part := range strings.Split(filepath.ToSlash(rootDir), "/") {
+		if part == "node_modules" {
+			return nil
+		}
+	}
+
+	for _, lockFileName := range lockFileNames {
+		if _, err := os.Stat(filepath.Join(rootDir, lockFileName)); err == nil {
+			return nil
+		}
+	}
+
+	tree := s.readInstalledTree(rootDir)
+
+	// Sort install paths so the output is stable between runs
+	installPaths := make([]string, 0, len(tree))
+	for installPath := range tree {
+		installPaths = append(installPaths, installPath)
+	}
+	sort.Strings(installPaths)
+
+	var dependencies []types.Dependency
+	reported := make(map[string]bool)
+
+	for _, installPath := range installPaths {
+		pkg := tree[installPath]
+		if installPath == "node_modules/"+pkg.Name && direct[pkg.Name] {
+			continue
+		}
+
+		// The same version may be installed in several nested locations
+		key := pkg.Name + "@" + pkg.Version
+		if reported[key] {
+			continue
+		}
+		reported[key] = true
+
+		dependencies = append(dependencies, types.Dependency{
+			Name:        pkg.Name,
+			Version:     pkg.Version,
+			LicenseType: pkg.LicenseType,
+			LicenseText: pkg.LicenseText,
+			PackageType: "npm",
+			FilePath:    path,
+			Indirect:    true,
+		})
+	}
+
+	return dependencies
+}
@@ -8,20 +8,22 @@ import (
 	"path/filepath"
 	"strings"
 
+	"license-audit/internal/license"
 	"license-audit/pkg/types"
 )
 
 type Scanner struct{}
 
 type PackageJSON struct {
-	Name            string            `json:"name"`
-	Version         string            `json:"version"`
-	License         interface{}       `json:"license"`
-	Licenses        []LicenseInfo     `json:"licenses"`
-	Repository      interface{}       `json:"repository"`
-	Homepage        string            `json:"homepage"`
-	Dependencies    map[string]string `json:"dependencies"`
-	DevDependencies map[string]string `json:"devDependencies"`
+	Name                 string            `json:"name"`
+	Version              string            `json:"version"`
+	License              interface{}       `json:"license"`
+	Licenses             interface{}       `json:"licenses"` // deprecated array of LicenseInfo
+	Repository           interface{}       `json:"repository"`
+	Homepage             string            `json:"homepage"`
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
 }
 
 type PackageLockJSON struct {
@@ -32,15 +34,28 @@ type PackageLockJSON struct {
 }
 
 type PackageLockEntry struct {
-	Version  string `json:"version"`
-	Resolved string `json:"resolved"`
+	Version      string                      `json:"version"`
+	Resolved     string                      `json:"resolved"`
+	Dev          bool                        `json:"dev"`
+	Optional     bool                        `json:"optional"`
+	Requires     map[string]string           `json:"requires"`
+	Dependencies map[string]PackageLockEntry `json:"dependencies"` // nested installs
 }
 
+// PackageLockPackage is an entry of the packages map of a lockfile v2 or
+// v3, keyed by install path. The root package is keyed by "" and
+// workspace packages by their directory; both list what they declare.
 type PackageLockPackage struct {
-	Name     string      `json:"name"`
-	Version  string      `json:"version"`
-	License  interface{} `json:"license"`
-	Resolved string      `json:"resolved"`
+	Name                 string            `json:"name"`
+	Version              string            `json:"version"`
+	License              interface{}       `json:"license"`
+	Resolved             string            `json:"resolved"`
+	Dev                  bool              `json:"dev"`
+	Optional             bool              `json:"optional"`
+	Link                 bool              `json:"link"`
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
 }
 
 type LicenseInfo struct {
@@ -58,7 +73,8 @@ func (s *Scanner) Name() string {
 
 func (s *Scanner) Detect(path string) bool {
 	fileName := filepath.Base(path)
-	return fileName == "package.json" || fileName == "package-lock.json"
+	return fileName == "package.json" || fileName == "package-lock.json" ||
+		fileName == "pnpm-lock.yaml"
 }
 
 func (s *Scanner) Scan(path string) ([]types.Dependency, error) {
@@ -69,45 +85,64 @@ func (s *Scanner) Scan(path string) ([]types.Dependency, error) {
 		return s.scanPackageJSON(path)
 	case "package-lock.json":
 		return s.scanPackageLock(path)
+	case "pnpm-lock.yaml":
+		return s.scanPnpmLock(path)
 	default:
 		return nil, fmt.Errorf("unsupported Node.js file: %s", fileName)
 	}
 }
 
 func (s *Scanner) scanPackageJSON(path string) ([]types.Dependency, error) {
-	file, err := os.Open(path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open package.json: %w", err)
-	}
-	defer file.Close()
-
-	data, err := io.ReadAll(file)
+	pkg, err := s.readPackageJSON(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read package.json: %w", err)
-	}
-
-	var pkg PackageJSON
-	if err := json.Unmarshal(data, &pkg); err != nil {
-		return nil, fmt.Errorf("failed to parse package.json: %w", err)
+		return nil, err
 	}
 
 	var dependencies []types.Dependency
+	direct := make(map[string]bool)
 
 	// Process main dependencies
 	for name, version := range pkg.Dependencies {
 		dep := s.createDependency(name, version, path)
 		dependencies = append(dependencies, dep)
+		direct[name] = true
 	}
 
 	// Process dev dependencies
 	for name, version := range pkg.DevDependencies {
 		dep := s.createDependency(name, version, path)
+		dep.Scope = "dev"
 		dependencies = append(dependencies, dep)
+		direct[name] = true
 	}
 
+	// Without a lockfile the installed tree is the only record of
+	// transitive dependencies
+	dependencies = append(dependencies, s.scanInstalledTree(path, direct)...)
+
 	return dependencies, nil
 }
 
+func (s *Scanner) readPackageJSON(path string) (*PackageJSON, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open package.json: %w", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read package.json: %w", err)
+	}
+
+	var pkg PackageJSON
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, fmt.Errorf("failed to parse package.json: %w", err)
+	}
+
+	return &pkg, nil
+}
+
 func (s *Scanner) scanPackageLock(path string) ([]types.Dependency, error) {
 	file, err := os.Open(path)
 	if err != nil {
@@ -126,12 +161,16 @@ func (s *Scanner) scanPackageLock(path string) ([]types.Dependency, error) {
 	}
 
 	var dependencies []types.Dependency
+	rootDir := filepath.Dir(path)
 
 	// Handle npm v7+ format (packages)
 	if len(lockFile.Packages) > 0 {
+		declared := newLockDeclarations(lockFile.Packages)
+
 		for pkgPath, pkg := range lockFile.Packages {
-			// Skip root package (empty string key)
-			if pkgPath == "" {
+			// Skip root package (empty string key) and workspace links,
+			// whose targets are listed under their own path
+			if pkgPath == "" || pkg.Link {
 				continue
 			}
 
@@ -150,26 +189,145 @@ func (s *Scanner) scanPackageLock(path string) ([]types.Dependency, error) {
 				LicenseType: s.parseLicense(pkg.License),
 				PackageType: "npm",
 				FilePath:    path,
+				Scope:       lockScope(pkg.Dev, pkg.Optional),
+				Indirect:    !declared.isDirect(pkgPath, name),
+			}
+
+			// Backfill from the installed copy at the same install path
+			if dep.LicenseType == "UNKNOWN" {
+				dep.LicenseText, dep.LicenseType = s.readLicenseFromNodeModules(filepath.Join(rootDir, filepath.FromSlash(pkgPath)))
 			}
 
 			dependencies = append(dependencies, dep)
 		}
 	} else {
 		// Handle older npm format (dependencies)
-		for name, entry := range lockFile.Dependencies {
-			dep := types.Dependency{
-				Name:        name,
-				Version:     entry.Version,
-				LicenseType: "UNKNOWN", // License info not available in old format
-				PackageType: "npm",
-				FilePath:    path,
+		direct := s.lockV1Direct(lockFile.Dependencies, rootDir)
+		dependencies = s.collectLockEntries(lockFile.Dependencies, "node_modules", rootDir, path, direct, dependencies)
+	}
+
+	return dependencies, nil
+}
+
+// lockDeclarations records what the root and workspace packages of a
+// lockfile v2 or v3 declare, by the directory of each.
+type lockDeclarations map[string]map[string]bool
+
+func newLockDeclarations(packages map[string]PackageLockPackage) lockDeclarations {
+	declared := make(lockDeclarations)
+	for pkgPath, pkg := range packages {
+		if pkgPath != "" && (pkg.Link || strings.Contains(pkgPath, "node_modules/")) {
+			continue
+		}
+		names := make(map[string]bool)
+		for _, group := range []map[string]string{pkg.Dependencies, pkg.DevDependencies, pkg.OptionalDependencies} {
+			for name := range group {
+				names[name] = true
 			}
+		}
+		declared[pkgPath] = names
+	}
+	return declared
+}
 
-			dependencies = append(dependencies, dep)
+// isDirect reports whether the package name installed at pkgPath is one
+// the project declares, rather than one npm hoisted next to them. A
+// package is direct when the root or workspace package whose node_modules
+// holds it declares it; the top-level node_modules serves the workspaces
+// too. Nesting depth says nothing, as npm hoists most indirect packages to
+// the top level.
+func (d lockDeclarations) isDirect(pkgPath, name string) bool {
+	i := strings.LastIndex(pkgPath, "node_modules/")
+	if i < 0 {
+		// A workspace package itself
+		return true
+	}
+
+	owner := strings.TrimSuffix(pkgPath[:i], "/")
+	if owner != "" {
+		return d[owner][name]
+	}
+	for _, names := range d {
+		if names[name] {
+			return true
 		}
 	}
+	return false
+}
 
-	return dependencies, nil
+// lockV1Direct returns the packages the project declares in the
+// package.json next to a lockfile v1. Without one, the top-level packages
+// that no other package requires are taken as direct.
+func (s *Scanner) lockV1Direct(entries map[string]PackageLockEntry, rootDir string) map[string]bool {
+	direct := make(map[string]bool)
+
+	if pkg, err := s.readPackageJSON(filepath.Join(rootDir, "package.json")); err == nil {
+		for _, group := range []map[string]string{pkg.Dependencies, pkg.DevDependencies, pkg.OptionalDependencies} {
+			for name := range group {
+				direct[name] = true
+			}
+		}
+		return direct
+	}
+
+	required := make(map[string]bool)
+	var collect func(map[string]PackageLockEntry)
+	collect = func(entries map[string]PackageLockEntry) {
+		for _, entry := range entries {
+			for name := range entry.Requires {
+				required[name] = true
+			}
+			collect(entry.Dependencies)
+		}
+	}
+	collect(entries)
+
+	for name := range entries {
+		if !required[name] {
+			direct[name] = true
+		}
+	}
+	return direct
+}
+
+// collectLockEntries flattens the nested dependencies of a lockfile v1,
+// where each level mirrors a node_modules directory on disk. Only the
+// top-level copy of a declared package is direct.
+func (s *Scanner) collectLockEntries(entries map[string]PackageLockEntry, installDir, rootDir, path string, direct map[string]bool, dependencies []types.Dependency) []types.Dependency {
+	for name, entry := range entries {
+		installPath := installDir + "/" + name
+
+		// License info is not available in the old format, so it can only
+		// come from the installed copy
+		licenseText, licenseType := s.readLicenseFromNodeModules(filepath.Join(rootDir, filepath.FromSlash(installPath)))
+
+		dep := types.Dependency{
+			Name:        name,
+			Version:     entry.Version,
+			LicenseType: licenseType,
+			LicenseText: licenseText,
+			PackageType: "npm",
+			FilePath:    path,
+			Scope:       lockScope(entry.Dev, entry.Optional),
+			Indirect:    installDir != "node_modules" || !direct[name],
+		}
+
+		dependencies = append(dependencies, dep)
+		dependencies = s.collectLockEntries(entry.Dependencies, installPath+"/node_modules", rootDir, path, direct, dependencies)
+	}
+
+	return dependencies
+}
+
+func lockScope(dev, optional bool) string {
+	switch {
+	case dev:
+		return "dev"
+	case optional:
+		return "optional"
+	default:
+		return ""
+	}
 }
 
 func (s *Scanner) createDependency(name, version, filePath string) types.Dependency {
@@ -193,29 +351,42 @@ func (s *Scanner) createDependency(name, version, filePath string) types.Depende
 
 func (s *Scanner) readLicenseFromNodeModules(pkgPath string) (string, string) {
 	// Try to read package.json from node_modules
-	pkgJSONPath := filepath.Join(pkgPath, "package.json")
-	if file, err := os.Open(pkgJSONPath); err == nil {
-		defer file.Close()
-
-		data, err := io.ReadAll(file)
-		if err == nil {
-			var pkg PackageJSON
-			if err := json.Unmarshal(data, &pkg); err == nil {
-				licenseType := s.parseLicense(pkg.License)
-				if licenseType != "UNKNOWN" {
-					// Try to read license file
-					licenseText := s.readLicenseFile(pkgPath)
-					return licenseText, licenseType
-				}
-			}
+	pkg, err := s.readPackageJSON(filepath.Join(pkgPath, "package.json"))
+	if err != nil {
+		return "", "UNKNOWN"
+	}
+
+	return s.packageLicense(pkg, pkgPath)
+}
+
+// packageLicense resolves the license of an installed package from the
+// license field, the deprecated licenses array, and finally its LICENSE file.
+func (s *Scanner) packageLicense(pkg *PackageJSON, pkgPath string) (string, string) {
+	licenseType := s.parseLicense(pkg.License)
+	if licenseType == "UNKNOWN" {
+		licenseType = s.parseLicense(pkg.Licenses)
+	}
+
+	// "SEE LICENSE IN <file>" points at a custom license shipped with the package
+	if fileName, ok := strings.CutPrefix(licenseType, "SEE LICENSE IN "); ok {
+		if data, err := os.ReadFile(filepath.Join(pkgPath, fileName)); err == nil {
+			return string(data), licenseType
 		}
 	}
 
-	return "", "UNKNOWN"
+	licenseText := s.readLicenseFile(pkgPath)
+	if licenseType == "UNKNOWN" && licenseText != "" {
+		licenseType = license.Detect(licenseText)
+	}
+
+	return licenseText, licenseType
 }
 
 func (s *Scanner) readLicenseFile(pkgPath string) string {
-	licenseFiles := []string{"LICENSE", "LICENSE.txt", "LICENSE.md", "license", "license.txt", "license.md"}
+	licenseFiles := []string{
+		"LICENSE", "LICENSE.txt", "LICENSE.md", "LICENCE", "LICENCE.md",
+		"license", "license.txt", "license.md", "LICENSE-MIT", "COPYING",
+	}
 
 	for _, fileName := range licenseFiles {
 		licensePath := filepath.Join(pkgPath, fileName)
@@ -249,6 +420,9 @@ func (s *Scanner) parseLicense(license interface{}) string {
 					return licType
 				}
 			}
+			if licType, ok := v[0].(string); ok && licType != "" {
+				return licType
+			}
 		}
 	}
 
@@ -1,6 +1,7 @@
 package nodejs
 
 import (
+	"io/fs"
 	"path/filepath"
 	"testing"
 )
@@ -14,6 +15,7 @@ func TestDetect(t *testing.T) {
 	}{
 		{"package.json", true},
 		{"package-lock.json", true},
+		{"pnpm-lock.yaml", true},
 		{"node_modules/package.json", true},
 		{"go.mod", false},
 		{"requirements.txt", false},
@@ -88,3 +90,240 @@ func TestParseLicense(t *testing.T) {
 		}
 	}
 }
+
+func TestScanPnpmLock(t *testing.T) {
+	scanner := NewScanner()
+
+	type expectation struct {
+		version   string
+		scope     string
+		workspace string
+		indirect  bool
+		license   string
+	}
+
+	testCases := []struct {
+		fixture  string
+		expected map[string]expectation
+	}{
+		{
+			fixture: "pnpm/v5/pnpm-lock.yaml",
+			expected: map[string]expectation{
+				"express":    {"4.18.2", "", ".", false, "UNKNOWN"},
+				"accepts":    {"1.3.8", "", ".", true, "UNKNOWN"},
+				"jest":       {"29.0.0", "dev", ".", false, "UNKNOWN"},
+				"@jest/core": {"29.0.0", "dev", ".", true, "UNKNOWN"},
+			},
+		},
+		{
+			fixture: "pnpm/v6/pnpm-lock.yaml",
+			expected: map[string]expectation{
+				"typescript":                {"5.0.4", "dev", ".", false, "UNKNOWN"},
+				"react-dom":                 {"18.2.0", "", "packages/app", false, "UNKNOWN"},
+				"packages/app/react":        {"18.2.0", "", "packages/app", true, "UNKNOWN"},
+				"packages/lib/react":        {"18.2.0", "", "packages/lib", false, "UNKNOWN"},
+				"packages/lib/loose-envify": {"1.4.0", "", "packages/lib", true, "UNKNOWN"},
+				"fsevents":                  {"2.3.2", "optional", "packages/lib", false, "UNKNOWN"},
+			},
+		},
+		{
+			fixture: "pnpm/v9/pnpm-lock.yaml",
+			expected: map[string]expectation{
+				"react-dom":    {"18.2.0", "", ".", false, "MIT"},
+				"react":        {"18.2.0", "", ".", true, "UNKNOWN"},
+				"loose-envify": {"1.4.0", "", ".", true, "UNKNOWN"},
+				"@types/node":  {"20.1.0", "dev", ".", false, "MIT"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		path := filepath.Join("../../../test/fixtures", tc.fixture)
+
+		dependencies, err := scanner.Scan(path)
+		if err != nil {
+			t.Fatalf("Scan(%s) returned error: %v", tc.fixture, err)
+		}
+
+		found := make(map[string]expectation)
+		for _, dep := range dependencies {
+			e := expectation{dep.Version, dep.Scope, dep.Workspace, dep.Indirect, dep.LicenseType}
+			found[dep.Name] = e
+			found[dep.Workspace+"/"+dep.Name] = e
+		}
+
+		for key, want := range tc.expected {
+			got, ok := found[key]
+			if !ok {
+				t.Errorf("%s: expected to find dependency '%s'", tc.fixture, key)
+				continue
+			}
+			if got != want {
+				t.Errorf("%s: dependency '%s' = %+v, expected %+v", tc.fixture, key, got, want)
+			}
+		}
+
+		if _, ok := found["lib"]; ok {
+			t.Errorf("%s: workspace links should not be reported as dependencies", tc.fixture)
+		}
+	}
+}
+
+func TestScanInstalledTree(t *testing.T) {
+	scanner := NewScanner()
+
+	path := filepath.Join("../../../test/fixtures/node-installed", "package.json")
+	dependencies, err := scanner.Scan(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	testCases := []struct {
+		key      string // name@version
+		license  string
+		scope    string
+		indirect bool
+	}{
+		{"express@^4.18.0", "MIT", "", false},
+		{"@babel/core@^7.22.0", "MIT", "dev", false},
+		{"debug@4.3.4", "MIT", "", true},
+		{"debug@2.6.9", "MIT", "", true},
+		{"ms@2.1.2", "MIT", "", true},
+	}
+
+	found := make(map[string]int)
+	for _, dep := range dependencies {
+		found[dep.Name+"@"+dep.Version]++
+
+		for _, tc := range testCases {
+			if dep.Name+"@"+dep.Version != tc.key {
+				continue
+			}
+			if dep.LicenseType != tc.license {
+				t.Errorf("%s: license = %s, expected %s", tc.key, dep.LicenseType, tc.license)
+			}
+			if dep.Scope != tc.scope {
+				t.Errorf("%s: scope = %q, expected %q", tc.key, dep.Scope, tc.scope)
+			}
+			if dep.Indirect != tc.indirect {
+				t.Errorf("%s: indirect = %v, expected %v", tc.key, dep.Indirect, tc.indirect)
+			}
+		}
+	}
+
+	for _, tc := range testCases {
+		if found[tc.key] != 1 {
+			t.Errorf("Expected to find dependency '%s' once, found %d times", tc.key, found[tc.key])
+		}
+	}
+}
+
+func TestScanInstalledTreeOnce(t *testing.T) {
+	scanner := NewScanner()
+
+	// Every package.json of the tree is scanned, as in a directory scan
+	found := make(map[string]int)
+	err := filepath.WalkDir("../../../test/fixtures/node-installed", func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || !scanner.Detect(path) {
+			return err
+		}
+		dependencies, err := scanner.Scan(path)
+		if err != nil {
+			return err
+		}
+		for _, dep := range dependencies {
+			if dep.Indirect {
+				found[dep.Name+"@"+dep.Version]++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for key, count := range found {
+		if count != 1 {
+			t.Errorf("Expected to find dependency '%s' once, found %d times", key, count)
+		}
+	}
+}
+
+func TestScanPackageLockBackfill(t *testing.T) {
+	scanner := NewScanner()
+
+	path := filepath.Join("../../../test/fixtures/node-lock-v1", "package-lock.json")
+	dependencies, err := scanner.Scan(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := map[string]struct {
+		license  string
+		scope    string
+		indirect bool
+	}{
+		"express": {"MIT", "", false},
+		"debug":   {"MIT", "", true},
+		"jest":    {"UNKNOWN", "dev", false},
+		// Hoisted, but only required by express
+		"ms": {"UNKNOWN", "", true},
+	}
+
+	if len(dependencies) != len(expected) {
+		t.Errorf("Expected %d dependencies, got %d", len(expected), len(dependencies))
+	}
+
+	for _, dep := range dependencies {
+		want, ok := expected[dep.Name]
+		if !ok {
+			t.Errorf("Unexpected dependency '%s'", dep.Name)
+			continue
+		}
+		if dep.LicenseType != want.license || dep.Scope != want.scope || dep.Indirect != want.indirect {
+			t.Errorf("%s = (%s, %q, %v), expected (%s, %q, %v)", dep.Name, dep.LicenseType, dep.Scope, dep.Indirect,
+				want.license, want.scope, want.indirect)
+		}
+	}
+}
+
+func TestScanPackageLockDirectness(t *testing.T) {
+	scanner := NewScanner()
+
+	path := filepath.Join("../../../test/fixtures/node-lock-v3", "package-lock.json")
+	dependencies, err := scanner.Scan(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := map[string]struct {
+		scope    string
+		indirect bool
+	}{
+		"express":  {"", false},
+		"jest":     {"dev", false},
+		"fsevents": {"optional", false},
+		// Hoisted to the top level, though only express requires it
+		"debug": {"", true},
+		"ms":    {"", true},
+		// The workspace package and what it declares
+		"app":    {"", false},
+		"lodash": {"", false},
+		"semver": {"", false},
+	}
+
+	if len(dependencies) != len(expected) {
+		t.Errorf("Expected %d dependencies, got %d", len(expected), len(dependencies))
+	}
+
+	for _, dep := range dependencies {
+		want, ok := expected[dep.Name]
+		if !ok {
+			t.Errorf("Unexpected dependency '%s'", dep.Name)
+			continue
+		}
+		if dep.Scope != want.scope || dep.Indirect != want.indirect {
+			t.Errorf("%s = (%q, %v), expected (%q, %v)", dep.Name, dep.Scope, dep.Indirect, want.scope, want.indirect)
+		}
+	}
+}
@@ -0,0 +1,331 @@
+package nodejs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"license-audit/pkg/types"
+)
+
+// PnpmLock covers pnpm-lock.yaml lockfile versions 5.x, 6.x and 9.x.
+type PnpmLock struct {
+	LockfileVersion interface{}             `yaml:"lockfileVersion"` // number in 5.x, string afterwards
+	Importers       map[string]PnpmImporter `yaml:"importers"`
+	Packages        map[string]PnpmPackage  `yaml:"packages"`
+	Snapshots       map[string]PnpmSnapshot `yaml:"snapshots"`
+
+	// Lockfiles without workspaces keep the root importer at the top level
+	PnpmImporter `yaml:",inline"`
+}
+
+type PnpmImporter struct {
+	Dependencies         map[string]PnpmImporterDep `yaml:"dependencies"`
+	DevDependencies      map[string]PnpmImporterDep `yaml:"devDependencies"`
+	OptionalDependencies map[string]PnpmImporterDep `yaml:"optionalDependencies"`
+}
+
+// PnpmImporterDep is a plain version string in 5.x and a
+// {specifier, version} mapping in 6.x and later.
+type PnpmImporterDep struct {
+	Specifier string `yaml:"specifier"`
+	Version   string `yaml:"version"`
+}
+
+// PnpmPackage is an entry of the packages section. The dev flag of 5.x and
+// 6.x is not read: it spans all importers, while the scope of a package is
+// taken from the importer group it is reached from.
+type PnpmPackage struct {
+	Name                 string            `yaml:"name"`
+	Version              string            `yaml:"version"`
+	Optional             bool              `yaml:"optional"`
+	Dependencies         map[string]string `yaml:"dependencies"`
+	OptionalDependencies map[string]string `yaml:"optionalDependencies"`
+}
+
+type PnpmSnapshot struct {
+	Dependencies         map[string]string `yaml:"dependencies"`
+	OptionalDependencies map[string]string `yaml:"optionalDependencies"`
+}
+
+func (d *PnpmImporterDep) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		d.Version = node.Value
+		return nil
+	}
+	type plain PnpmImporterDep
+	return node.Decode((*plain)(d))
+}
+
+func (s *Scanner) scanPnpmLock(path string) ([]types.Dependency, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pnpm-lock.yaml: %w", err)
+	}
+
+	var lockFile PnpmLock
+	if err := yaml.Unmarshal(data, &lockFile); err != nil {
+		return nil, fmt.Errorf("failed to parse pnpm-lock.yaml: %w", err)
+	}
+
+	major := pnpmMajorVersion(lockFile.LockfileVersion)
+	if major == 0 {
+		return nil, fmt.Errorf("unsupported pnpm lockfile version: %v", lockFile.LockfileVersion)
+	}
+
+	importers := lockFile.Importers
+	if len(importers) == 0 {
+		importers = map[string]PnpmImporter{".": lockFile.PnpmImporter}
+	}
+
+	// Sort importers so the output is stable between runs
+	importerPaths := make([]string, 0, len(importers))
+	for importerPath := range importers {
+		importerPaths = append(importerPaths, importerPath)
+	}
+	sort.Strings(importerPaths)
+
+	rootDir := filepath.Dir(path)
+	var dependencies []types.Dependency
+
+	for _, importerPath := range importerPaths {
+		importer := importers[importerPath]
+
+		groups := []struct {
+			deps  map[string]PnpmImporterDep
+			scope string
+		}{
+			{importer.Dependencies, ""},
+			{importer.OptionalDependencies, "optional"},
+			{importer.DevDependencies, "dev"},
+		}
+
+		// Direct dependencies are reported from their own root even when
+		// another direct dependency pulls them in as well
+		walk := &pnpmWalk{
+			lockFile:     &lockFile,
+			major:        major,
+			importerPath: importerPath,
+			rootDir:      rootDir,
+			filePath:     path,
+			direct:       make(map[string]bool),
+			visited:      make(map[string]bool),
+		}
+		for _, group := range groups {
+			for name, dep := range group.deps {
+				if !isPnpmLocalRef(dep.Version) {
+					walk.direct[pnpmDepPath(major, name, dep.Version)] = true
+				}
+			}
+		}
+
+		// Walk production roots first so packages shared with dev
+		// dependencies are reported as production ones
+		for _, group := range groups {
+			for _, name := range sortedKeys(group.deps) {
+				ref := group.deps[name].Version
+				if isPnpmLocalRef(ref) {
+					continue
+				}
+				dependencies = append(dependencies, s.walkPnpmPackage(walk, pnpmDepPath(major, name, ref), group.scope, false)...)
+			}
+		}
+	}
+
+	return dependencies, nil
+}
+
+// pnpmWalk holds the state of a dependency graph walk for one importer.
+type pnpmWalk struct {
+	lockFile     *PnpmLock
+	major        int
+	importerPath string
+	rootDir      string
+	filePath     string
+	direct       map[string]bool
+	visited      map[string]bool
+}
+
+func (s *Scanner) walkPnpmPackage(w *pnpmWalk, key, scope string, indirect bool) []types.Dependency {
+	if w.visited[key] || (indirect && w.direct[key]) {
+		return nil
+	}
+	w.visited[key] = true
+
+	lockFile, major := w.lockFile, w.major
+
+	name, version := parsePnpmKey(major, key)
+	pkgKey := key
+	if major >= 9 {
+		// 9.x stores metadata without the peer suffix and the graph in snapshots
+		pkgKey = stripPnpmPeerSuffix(key)
+	}
+	pkg := lockFile.Packages[pkgKey]
+	if pkg.Name != "" {
+		name = pkg.Name
+	}
+	if pkg.Version != "" {
+		version = pkg.Version
+	}
+
+	depScope := scope
+	if depScope == "" && pkg.Optional {
+		depScope = "optional"
+	}
+
+	dep := types.Dependency{
+		Name:        name,
+		Version:     version,
+		LicenseType: "UNKNOWN",
+		PackageType: "npm",
+		FilePath:    w.filePath,
+		Scope:       depScope,
+		Workspace:   w.importerPath,
+		Indirect:    indirect,
+	}
+
+	if licenseText, licenseType := s.readLicenseFromNodeModules(pnpmStorePath(w.rootDir, major, key, name, version)); licenseType != "UNKNOWN" {
+		dep.LicenseType = licenseType
+		dep.LicenseText = licenseText
+	}
+
+	dependencies := []types.Dependency{dep}
+
+	children, optionalChildren := pkg.Dependencies, pkg.OptionalDependencies
+	if major >= 9 {
+		snapshot := lockFile.Snapshots[key]
+		children, optionalChildren = snapshot.Dependencies, snapshot.OptionalDependencies
+	}
+
+	for _, childDeps := range []map[string]string{children, optionalChildren} {
+		for _, childName := range sortedKeys(childDeps) {
+			ref := childDeps[childName]
+			if isPnpmLocalRef(ref) {
+				continue
+			}
+			dependencies = append(dependencies, s.walkPnpmPackage(w, pnpmDepPath(major, childName, ref), scope, true)...)
+		}
+	}
+
+	return dependencies
+}
+
+func pnpmMajorVersion(version interface{}) int {
+	var raw string
+	switch v := version.(type) {
+	case string:
+		raw = v
+	case int:
+		return v
+	case float64:
+		return int(v)
+	default:
+		return 0
+	}
+
+	major, err := strconv.Atoi(strings.SplitN(raw, ".", 2)[0])
+	if err != nil {
+		return 0
+	}
+	return major
+}
+
+// pnpmDepPath turns a dependency reference into the key used in the
+// packages (or, for 9.x, snapshots) section of the lockfile.
+func pnpmDepPath(major int, name, ref string) string {
+	if major >= 9 {
+		// Aliased dependencies reference the real package as name@version
+		if at := strings.LastIndex(stripPnpmPeerSuffix(ref), "@"); at > 0 {
+			return ref
+		}
+		return name + "@" + ref
+	}
+
+	if strings.HasPrefix(ref, "/") {
+		return ref
+	}
+	if major >= 6 {
+		return "/" + name + "@" + ref
+	}
+	return "/" + name + "/" + ref
+}
+
+// parsePnpmKey extracts the package name and version from a packages key
+// such as "/@scope/pkg/1.0.0_react@18.2.0" (5.x), "/pkg@1.0.0(react@18.2.0)"
+// (6.x) or "pkg@1.0.0(react@18.2.0)" (9.x).
+func parsePnpmKey(major int, key string) (string, string) {
+	key = strings.TrimPrefix(key, "/")
+
+	if major < 6 {
+		slash := strings.LastIndex(key, "/")
+		if slash <= 0 {
+			return key, "UNKNOWN"
+		}
+		version := key[slash+1:]
+		if idx := strings.Index(version, "_"); idx != -1 {
+			version = version[:idx]
+		}
+		return key[:slash], version
+	}
+
+	key = stripPnpmPeerSuffix(key)
+	at := strings.LastIndex(key, "@")
+	if at <= 0 {
+		return key, "UNKNOWN"
+	}
+	return key[:at], key[at+1:]
+}
+
+func stripPnpmPeerSuffix(key string) string {
+	if idx := strings.Index(key, "("); idx != -1 {
+		return key[:idx]
+	}
+	return key
+}
+
+func isPnpmLocalRef(ref string) bool {
+	return ref == "" || strings.HasPrefix(ref, "link:") || strings.HasPrefix(ref, "file:") ||
+		strings.HasPrefix(ref, "workspace:")
+}
+
+// pnpmStorePath locates a package inside the node_modules/.pnpm virtual
+// store, falling back to the hoisted node_modules/<name> link.
+func pnpmStorePath(rootDir string, major int, key, name, version string) string {
+	storeDir := filepath.Join(rootDir, "node_modules", ".pnpm")
+
+	dirName := strings.TrimPrefix(key, "/")
+	if major < 6 && strings.HasPrefix(dirName, name+"/") {
+		// 5.x keys separate name and version with a slash
+		dirName = name + "@" + dirName[len(name)+1:]
+	}
+	dirName = strings.ReplaceAll(dirName, "/", "+")
+	dirName = strings.ReplaceAll(dirName, ")(", "_")
+	dirName = strings.ReplaceAll(dirName, "(", "_")
+	dirName = strings.ReplaceAll(dirName, ")", "")
+
+	candidate := filepath.Join(storeDir, dirName, "node_modules", name)
+	if _, err := os.Stat(candidate); err == nil {
+		return candidate
+	}
+
+	// Long directory names are hashed by pnpm, so match on the prefix
+	prefix := strings.ReplaceAll(name, "/", "+") + "@" + version
+	if matches, _ := filepath.Glob(filepath.Join(storeDir, prefix+"*", "node_modules", name)); len(matches) > 0 {
+		return matches[0]
+	}
+
+	return filepath.Join(rootDir, "node_modules", name)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,92 @@
+package nuget
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"license-audit/pkg/types"
+)
+
+// PackagesLock is a packages.lock.json file, which lists the resolved
+// packages of each target framework.
+type PackagesLock struct {
+	Version      int                                 `json:"version"`
+	Dependencies map[string]map[string]LockedPackage `json:"dependencies"`
+}
+
+type LockedPackage struct {
+	Type         string            `json:"type"` // Direct, Transitive, CentralTransitive or Project
+	Requested    string            `json:"requested"`
+	Resolved     string            `json:"resolved"`
+	ContentHash  string            `json:"contentHash"`
+	Dependencies map[string]string `json:"dependencies"`
+}
+
+func (s *Scanner) scanPackagesLock(path string) ([]types.Dependency, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read packages.lock.json: %w", err)
+	}
+
+	var lockFile PackagesLock
+	if err := json.Unmarshal(data, &lockFile); err != nil {
+		return nil, fmt.Errorf("failed to parse packages.lock.json: %w", err)
+	}
+
+	var dependencies []types.Dependency
+	index := make(map[string]int) // lowercase id@version -> position in dependencies
+
+	// A package resolved for several target frameworks is reported once
+	for _, framework := range sortedKeys(lockFile.Dependencies) {
+		packages := lockFile.Dependencies[framework]
+
+		for _, name := range sortedKeys(packages) {
+			pkg := packages[name]
+
+			// Project references are the solution's own projects, which
+			// are reported from their project files
+			if pkg.Type == "Project" {
+				continue
+			}
+
+			key := strings.ToLower(name) + "@" + pkg.Resolved
+			if i, ok := index[key]; ok {
+				// Direct for any framework makes it direct
+				if pkg.Type == "Direct" {
+					dependencies[i].Indirect = false
+				}
+				continue
+			}
+
+			dep := s.newDependency(name, pkg.Resolved, path)
+			if pkg.Type == "Transitive" || pkg.Type == "CentralTransitive" {
+				dep.Indirect = true
+			}
+
+			dep.Requires = sortedKeys(pkg.Dependencies)
+			// contentHash is the base64 SHA-512 of the .nupkg
+			if hash, err := base64.StdEncoding.DecodeString(pkg.ContentHash); err == nil && len(hash) > 0 {
+				dep.Hashes = []string{"sha512:" + hex.EncodeToString(hash)}
+			}
+
+			index[key] = len(dependencies)
+			dependencies = append(dependencies, dep)
+		}
+	}
+
+	return dependencies, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
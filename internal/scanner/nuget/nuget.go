@@ -0,0 +1,118 @@
+package nuget
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"license-audit/pkg/types"
+)
+
+type Scanner struct {
+	// PackagesPath is the global packages folder restore extracts packages
+	// to. It defaults to $NUGET_PACKAGES, then ~/.nuget/packages.
+	PackagesPath string
+
+	nuspecCache map[string]*NuspecMetadata
+}
+
+func NewScanner() *Scanner {
+	return &Scanner{}
+}
+
+func (s *Scanner) Name() string {
+	return "nuget"
+}
+
+func (s *Scanner) Detect(path string) bool {
+	fileName := filepath.Base(path)
+
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csproj", ".fsproj", ".vbproj":
+		return true
+	}
+	return fileName == "Directory.Packages.props" || fileName == "packages.lock.json" ||
+		fileName == "packages.config"
+}
+
+func (s *Scanner) Scan(path string) ([]types.Dependency, error) {
+	dependencies, err := s.scanFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s.applyNuspecLicenses(filepath.Dir(path), dependencies)
+	return dependencies, nil
+}
+
+func (s *Scanner) scanFile(path string) ([]types.Dependency, error) {
+	fileName := filepath.Base(path)
+
+	switch {
+	case fileName == "Directory.Packages.props":
+		return s.scanPackagesProps(path)
+	case fileName == "packages.lock.json":
+		return s.scanPackagesLock(path)
+	case fileName == "packages.config":
+		return s.scanPackagesConfig(path)
+	case s.Detect(path):
+		return s.scanProject(path)
+	default:
+		return nil, fmt.Errorf("unsupported NuGet file: %s", fileName)
+	}
+}
+
+func (s *Scanner) newDependency(name, version, filePath string) types.Dependency {
+	if version == "" {
+		version = "UNKNOWN"
+	}
+
+	return types.Dependency{
+		Name:        name,
+		Version:     version,
+		LicenseType: "UNKNOWN",
+		PackageType: "nuget",
+		FilePath:    filePath,
+	}
+}
+
+// exactVersion returns the version a requirement pins. Restore picks the
+// lowest version a range allows, so "1.2.0" (meaning >= 1.2.0) and
+// "[1.2.0]" both resolve to 1.2.0; open ranges resolve to nothing.
+func exactVersion(requirement string) string {
+	requirement = strings.TrimSpace(requirement)
+	if requirement == "" || strings.ContainsAny(requirement, "*$") {
+		return ""
+	}
+
+	if strings.HasPrefix(requirement, "[") {
+		lower, _, _ := strings.Cut(strings.Trim(requirement, "[]()"), ",")
+		return strings.TrimSpace(lower)
+	}
+	if strings.HasPrefix(requirement, "(") {
+		return ""
+	}
+	return requirement
+}
+
+// normalizeVersion converts a version to the form NuGet uses for folder
+// names: lowercase, at least three parts and no build metadata.
+func normalizeVersion(version string) string {
+	version, _, _ = strings.Cut(strings.ToLower(version), "+")
+	release, prerelease, hasPrerelease := strings.Cut(version, "-")
+
+	parts := strings.Split(release, ".")
+	for len(parts) < 3 {
+		parts = append(parts, "0")
+	}
+	// A fourth part of zero is dropped: 1.2.3.0 is stored as 1.2.3
+	if len(parts) == 4 && parts[3] == "0" {
+		parts = parts[:3]
+	}
+
+	version = strings.Join(parts, ".")
+	if hasPrerelease {
+		version += "-" + prerelease
+	}
+	return version
+}
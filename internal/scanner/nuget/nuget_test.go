@@ -0,0 +1,228 @@
+package nuget
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const fixturesDir = "../../../test/fixtures/nuget"
+
+func newTestScanner() *Scanner {
+	scanner := NewScanner()
+	scanner.PackagesPath = filepath.Join(fixturesDir, "nuget-packages")
+	return scanner
+}
+
+func TestDetect(t *testing.T) {
+	scanner := NewScanner()
+
+	testCases := []struct {
+		path     string
+		expected bool
+	}{
+		{"App.csproj", true},
+		{"Lib.fsproj", true},
+		{"Legacy.vbproj", true},
+		{"Directory.Packages.props", true},
+		{"packages.lock.json", true},
+		{"packages.config", true},
+		{"Directory.Build.props", false},
+		{"package.json", false},
+		{"", false},
+	}
+
+	for _, tc := range testCases {
+		result := scanner.Detect(tc.path)
+		if result != tc.expected {
+			t.Errorf("Detect(%s) = %v, expected %v", tc.path, result, tc.expected)
+		}
+	}
+}
+
+func TestScanProject(t *testing.T) {
+	scanner := newTestScanner()
+
+	dependencies, err := scanner.Scan(filepath.Join(fixturesDir, "src", "App", "App.csproj"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	type expectation struct {
+		version string
+		scope   string
+		license string
+	}
+
+	expected := map[string]expectation{
+		"Acme.App":                     {"1.4.0", "project", "MIT"},
+		"Newtonsoft.Json":              {"13.0.3", "", "MIT"},
+		"Serilog":                      {"3.0.1", "", "Apache-2.0"}, // license file, version from a central property
+		"Microsoft.Extensions.Logging": {"8.0.1", "", "MIT"},        // VersionOverride
+		"coverlet.collector":           {"6.0.0", "build", "UNKNOWN"},
+	}
+
+	if len(dependencies) != len(expected) {
+		t.Errorf("Expected %d dependencies, got %d", len(expected), len(dependencies))
+	}
+
+	for _, dep := range dependencies {
+		want, ok := expected[dep.Name]
+		if !ok {
+			t.Errorf("Unexpected dependency '%s'", dep.Name)
+			continue
+		}
+		got := expectation{dep.Version, dep.Scope, dep.LicenseType}
+		if got != want {
+			t.Errorf("%s = %+v, expected %+v", dep.Name, got, want)
+		}
+
+		switch dep.Name {
+		case "Newtonsoft.Json":
+			if dep.Repository != "https://github.com/JamesNK/Newtonsoft.Json" {
+				t.Errorf("Unexpected repository for Newtonsoft.Json: %s", dep.Repository)
+			}
+		case "Serilog":
+			if dep.LicenseURL != "" {
+				t.Errorf("Expected the deprecated license URL to be dropped, got %s", dep.LicenseURL)
+			}
+		}
+	}
+}
+
+func TestScanPackagesProps(t *testing.T) {
+	scanner := newTestScanner()
+
+	dependencies, err := scanner.Scan(filepath.Join(fixturesDir, "Directory.Packages.props"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(dependencies) != 1 {
+		t.Fatalf("Expected 1 dependency, got %d", len(dependencies))
+	}
+	if dep := dependencies[0]; dep.Name != "StyleCop.Analyzers" || dep.Version != "1.2.0-beta.556" || dep.Scope != "build" {
+		t.Errorf("Unexpected global package reference %+v", dep)
+	}
+}
+
+func TestScanPackagesLock(t *testing.T) {
+	scanner := newTestScanner()
+
+	dependencies, err := scanner.Scan(filepath.Join(fixturesDir, "src", "App", "packages.lock.json"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	type expectation struct {
+		version  string
+		scope    string
+		indirect bool
+		license  string
+	}
+
+	expected := map[string]expectation{
+		"Newtonsoft.Json":              {"13.0.3", "", false, "MIT"}, // direct for net8.0
+		"Microsoft.Extensions.Logging": {"8.0.1", "", false, "MIT"},
+		"System.Text.Json":             {"8.0.0", "", true, "MIT"}, // from licenseUrl
+	}
+
+	if len(dependencies) != len(expected) {
+		t.Errorf("Expected %d dependencies, got %d", len(expected), len(dependencies))
+	}
+
+	for _, dep := range dependencies {
+		want, ok := expected[dep.Name]
+		if !ok {
+			t.Errorf("Unexpected dependency '%s'", dep.Name)
+			continue
+		}
+		got := expectation{dep.Version, dep.Scope, dep.Indirect, dep.LicenseType}
+		if got != want {
+			t.Errorf("%s = %+v, expected %+v", dep.Name, got, want)
+		}
+		if len(dep.Hashes) != 1 || !strings.HasPrefix(dep.Hashes[0], "sha512:") {
+			t.Errorf("Expected a content hash for %s, got %v", dep.Name, dep.Hashes)
+		}
+	}
+}
+
+func TestScanPackagesConfig(t *testing.T) {
+	scanner := newTestScanner()
+
+	dependencies, err := scanner.Scan(filepath.Join(fixturesDir, "legacy", "packages.config"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	type expectation struct {
+		version string
+		scope   string
+		license string
+	}
+
+	expected := map[string]expectation{
+		"Dapper": {"2.0.123", "", "Apache-2.0"}, // licenses.nuget.org URL in packages/
+		"NUnit":  {"3.13.3", "dev", "UNKNOWN"},
+	}
+
+	if len(dependencies) != len(expected) {
+		t.Errorf("Expected %d dependencies, got %d", len(expected), len(dependencies))
+	}
+
+	for _, dep := range dependencies {
+		want, ok := expected[dep.Name]
+		if !ok {
+			t.Errorf("Unexpected dependency '%s'", dep.Name)
+			continue
+		}
+		got := expectation{dep.Version, dep.Scope, dep.LicenseType}
+		if got != want {
+			t.Errorf("%s = %+v, expected %+v", dep.Name, got, want)
+		}
+	}
+}
+
+func TestExactVersion(t *testing.T) {
+	testCases := []struct {
+		requirement string
+		expected    string
+	}{
+		{"13.0.3", "13.0.3"},
+		{"[13.0.3]", "13.0.3"},
+		{"[13.0.3, )", "13.0.3"},
+		{"[1.0,2.0)", "1.0"},
+		{"(1.0,)", ""},
+		{"6.*", ""},
+		{"$(SerilogVersion)", ""},
+		{"", ""},
+	}
+
+	for _, tc := range testCases {
+		result := exactVersion(tc.requirement)
+		if result != tc.expected {
+			t.Errorf("exactVersion(%s) = %s, expected %s", tc.requirement, result, tc.expected)
+		}
+	}
+}
+
+func TestNormalizeVersion(t *testing.T) {
+	testCases := []struct {
+		version  string
+		expected string
+	}{
+		{"13.0.3", "13.0.3"},
+		{"1.0", "1.0.0"},
+		{"4.5.0.0", "4.5.0"},
+		{"4.5.0.1", "4.5.0.1"},
+		{"1.2.0-Beta.556", "1.2.0-beta.556"},
+		{"2.0.0+build.7", "2.0.0"},
+	}
+
+	for _, tc := range testCases {
+		result := normalizeVersion(tc.version)
+		if result != tc.expected {
+			t.Errorf("normalizeVersion(%s) = %s, expected %s", tc.version, result, tc.expected)
+		}
+	}
+}
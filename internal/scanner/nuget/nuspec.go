@@ -0,0 +1,198 @@
+package nuget
+
+import (
+	"encoding/xml"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"license-audit/internal/license"
+	"license-audit/pkg/types"
+)
+
+// Nuspec is the manifest restore extracts next to each package.
+type Nuspec struct {
+	Metadata NuspecMetadata `xml:"metadata"`
+}
+
+type NuspecMetadata struct {
+	ID         string        `xml:"id"`
+	Version    string        `xml:"version"`
+	License    NuspecLicense `xml:"license"`
+	LicenseURL string        `xml:"licenseUrl"`
+	ProjectURL string        `xml:"projectUrl"`
+	Repository struct {
+		URL string `xml:"url,attr"`
+	} `xml:"repository"`
+
+	// Set when the nuspec is read from disk
+	LicenseType string `xml:"-"`
+	LicenseText string `xml:"-"`
+}
+
+type NuspecLicense struct {
+	Type  string `xml:"type,attr"` // expression or file
+	Value string `xml:",chardata"`
+}
+
+// licenseURLs maps license URLs commonly found in nuspecs to SPDX
+// identifiers. Keys are normalized by normalizeLicenseURL.
+var licenseURLs = map[string]string{
+	"opensource.org/licenses/mit":             "MIT",
+	"opensource.org/licenses/mit-license.php": "MIT",
+	"opensource.org/licenses/apache-2.0":      "Apache-2.0",
+	"apache.org/licenses/license-2.0":         "Apache-2.0",
+	"apache.org/licenses/license-2.0.txt":     "Apache-2.0",
+	"apache.org/licenses/license-2.0.html":    "Apache-2.0",
+	"opensource.org/licenses/bsd-3-clause":    "BSD-3-Clause",
+	"opensource.org/licenses/bsd-2-clause":    "BSD-2-Clause",
+	"opensource.org/licenses/ms-pl":           "MS-PL",
+}
+
+// deprecatedLicenseURL is the placeholder licenseUrl written into packages
+// that carry a license expression or file.
+const deprecatedLicenseURL = "aka.ms/deprecatelicenseurl"
+
+// applyNuspecLicenses fills in licenses for dependencies whose packages are
+// in the global packages folder, or in the packages/ folder a
+// packages.config restore writes next to the solution.
+func (s *Scanner) applyNuspecLicenses(dir string, dependencies []types.Dependency) {
+	for i := range dependencies {
+		dep := &dependencies[i]
+		if dep.Scope == "project" || (dep.LicenseType != "UNKNOWN" && dep.LicenseType != "") {
+			continue
+		}
+
+		version := exactVersion(dep.Version)
+		if version == "" {
+			continue
+		}
+
+		nuspec := s.findNuspec(dir, dep.Name, version)
+		if nuspec == nil {
+			continue
+		}
+
+		dep.LicenseType = nuspec.LicenseType
+		dep.LicenseText = nuspec.LicenseText
+		if nuspec.LicenseURL != "" && !strings.Contains(normalizeLicenseURL(nuspec.LicenseURL), deprecatedLicenseURL) {
+			dep.LicenseURL = nuspec.LicenseURL
+		}
+		if dep.Homepage == "" {
+			dep.Homepage = nuspec.ProjectURL
+		}
+		if dep.Repository == "" {
+			dep.Repository = nuspec.Repository.URL
+		}
+	}
+}
+
+func (s *Scanner) findNuspec(dir, id, version string) *NuspecMetadata {
+	lowerID := strings.ToLower(id)
+
+	var candidates []string
+	if packagesPath := s.packagesPath(); packagesPath != "" {
+		candidates = append(candidates, filepath.Join(packagesPath, lowerID, normalizeVersion(version), lowerID+".nuspec"))
+	}
+	for _, packagesDir := range []string{filepath.Join(dir, "packages"), filepath.Join(filepath.Dir(dir), "packages")} {
+		candidates = append(candidates, filepath.Join(packagesDir, id+"."+version, id+".nuspec"))
+	}
+
+	for _, candidate := range candidates {
+		if nuspec := s.readNuspec(candidate); nuspec != nil {
+			return nuspec
+		}
+	}
+	return nil
+}
+
+func (s *Scanner) packagesPath() string {
+	if s.PackagesPath != "" {
+		return s.PackagesPath
+	}
+	if packagesPath := os.Getenv("NUGET_PACKAGES"); packagesPath != "" {
+		return packagesPath
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".nuget", "packages")
+}
+
+// readNuspec reads a nuspec and resolves its license from, in order, a
+// license expression, a license file in the package, the licenseUrl and
+// any license file next to the nuspec.
+func (s *Scanner) readNuspec(path string) *NuspecMetadata {
+	if cached, ok := s.nuspecCache[path]; ok {
+		return cached
+	}
+
+	var metadata *NuspecMetadata
+	if data, err := os.ReadFile(path); err == nil {
+		var nuspec Nuspec
+		if err := xml.Unmarshal(data, &nuspec); err == nil {
+			metadata = &nuspec.Metadata
+			packageDir := filepath.Dir(path)
+			value := strings.TrimSpace(metadata.License.Value)
+
+			metadata.LicenseType = "UNKNOWN"
+			switch {
+			case metadata.License.Type == "expression" && value != "":
+				metadata.LicenseType = value
+			case metadata.License.Type == "file" && value != "":
+				if text, err := os.ReadFile(filepath.Join(packageDir, filepath.FromSlash(value))); err == nil {
+					metadata.LicenseText = string(text)
+					metadata.LicenseType = license.Detect(metadata.LicenseText)
+				}
+			default:
+				metadata.LicenseType = licenseURLType(metadata.LicenseURL)
+			}
+
+			if metadata.LicenseText == "" {
+				metadata.LicenseText = license.ReadFile(packageDir)
+			}
+			if metadata.LicenseType == "UNKNOWN" && metadata.LicenseText != "" {
+				metadata.LicenseType = license.Detect(metadata.LicenseText)
+			}
+		}
+	}
+
+	if s.nuspecCache == nil {
+		s.nuspecCache = make(map[string]*NuspecMetadata)
+	}
+	s.nuspecCache[path] = metadata
+	return metadata
+}
+
+// licenseURLType maps a licenseUrl to a license. licenses.nuget.org URLs
+// carry the expression itself.
+func licenseURLType(licenseURL string) string {
+	normalized := normalizeLicenseURL(licenseURL)
+	if normalized == "" || strings.Contains(normalized, deprecatedLicenseURL) {
+		return "UNKNOWN"
+	}
+
+	if expression, ok := strings.CutPrefix(normalized, "licenses.nuget.org/"); ok {
+		// Use the original casing, which the lowercase key has lost
+		original := strings.TrimSuffix(strings.TrimSpace(licenseURL), "/")
+		expression = original[len(original)-len(expression):]
+		if unescaped, err := url.PathUnescape(expression); err == nil {
+			expression = unescaped
+		}
+		return expression
+	}
+
+	if spdx, ok := licenseURLs[normalized]; ok {
+		return spdx
+	}
+	return "UNKNOWN"
+}
+
+func normalizeLicenseURL(licenseURL string) string {
+	licenseURL = strings.ToLower(strings.TrimSpace(licenseURL))
+	licenseURL = strings.TrimPrefix(strings.TrimPrefix(licenseURL, "https://"), "http://")
+	licenseURL = strings.TrimPrefix(licenseURL, "www.")
+	return strings.TrimSuffix(licenseURL, "/")
+}
@@ -0,0 +1,249 @@
+package nuget
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"license-audit/pkg/types"
+)
+
+// MSBuildProject is an SDK-style project file or a .props file imported
+// into one.
+type MSBuildProject struct {
+	XMLName        xml.Name        `xml:"Project"`
+	PropertyGroups []PropertyGroup `xml:"PropertyGroup"`
+	ItemGroups     []ItemGroup     `xml:"ItemGroup"`
+}
+
+type PropertyGroup struct {
+	Entries []Property `xml:",any"`
+}
+
+type Property struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+type ItemGroup struct {
+	PackageReferences       []PackageItem `xml:"PackageReference"`
+	PackageVersions         []PackageItem `xml:"PackageVersion"`
+	GlobalPackageReferences []PackageItem `xml:"GlobalPackageReference"`
+}
+
+// PackageItem is a PackageReference, PackageVersion or
+// GlobalPackageReference. Metadata may be given as attributes or as child
+// elements.
+type PackageItem struct {
+	Include              string `xml:"Include,attr"`
+	Version              string `xml:"Version,attr"`
+	VersionElement       string `xml:"Version"`
+	VersionOverride      string `xml:"VersionOverride,attr"`
+	PrivateAssets        string `xml:"PrivateAssets,attr"`
+	PrivateAssetsElement string `xml:"PrivateAssets"`
+}
+
+var propertyPattern = regexp.MustCompile(`\$\(([A-Za-z_][\w.-]*)\)`)
+
+func readMSBuildProject(path string) (*MSBuildProject, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
+	}
+
+	var project MSBuildProject
+	if err := xml.Unmarshal(data, &project); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Base(path), err)
+	}
+
+	return &project, nil
+}
+
+// properties returns the project's properties keyed by lowercase name,
+// as MSBuild property names are case-insensitive.
+func (p *MSBuildProject) properties() map[string]string {
+	properties := make(map[string]string)
+	for _, group := range p.PropertyGroups {
+		for _, entry := range group.Entries {
+			properties[strings.ToLower(entry.XMLName.Local)] = strings.TrimSpace(entry.Value)
+		}
+	}
+	return properties
+}
+
+// interpolate replaces $(Property) references with their values; unknown
+// properties are left in place.
+func interpolate(value string, properties map[string]string) string {
+	return propertyPattern.ReplaceAllStringFunc(value, func(ref string) string {
+		if resolved, ok := properties[strings.ToLower(ref[2:len(ref)-1])]; ok {
+			return resolved
+		}
+		return ref
+	})
+}
+
+func (item PackageItem) version() string {
+	for _, version := range []string{item.VersionOverride, item.Version, item.VersionElement} {
+		if version = strings.TrimSpace(version); version != "" {
+			return version
+		}
+	}
+	return ""
+}
+
+// scope maps PrivateAssets="all", which keeps a package (typically an
+// analyzer or build tool) from flowing to consumers, to "build".
+func (item PackageItem) scope() string {
+	for _, assets := range []string{item.PrivateAssets, item.PrivateAssetsElement} {
+		for _, asset := range strings.Split(assets, ";") {
+			if strings.EqualFold(strings.TrimSpace(asset), "all") {
+				return "build"
+			}
+		}
+	}
+	return ""
+}
+
+// scanProject reports the project, with the license it declares for its
+// own package, followed by its PackageReference items. References without
+// a version take it from the nearest Directory.Packages.props.
+func (s *Scanner) scanProject(path string) ([]types.Dependency, error) {
+	project, err := readMSBuildProject(path)
+	if err != nil {
+		return nil, err
+	}
+
+	properties := project.properties()
+	central := centralPackageVersions(filepath.Dir(path))
+
+	var dependencies []types.Dependency
+
+	name := properties["packageid"]
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	self := s.newDependency(name, interpolate(properties["version"], properties), path)
+	self.Scope = "project"
+	if license := properties["packagelicenseexpression"]; license != "" {
+		self.LicenseType = license
+	}
+	self.Homepage = properties["packageprojecturl"]
+	self.Repository = properties["repositoryurl"]
+	dependencies = append(dependencies, self)
+
+	for _, group := range project.ItemGroups {
+		for _, item := range group.PackageReferences {
+			id := strings.TrimSpace(item.Include)
+			if id == "" {
+				// Update items only change metadata of an existing reference
+				continue
+			}
+
+			version := item.version()
+			if version == "" {
+				version = central[strings.ToLower(id)]
+			}
+
+			dep := s.newDependency(id, interpolate(version, properties), path)
+			dep.Scope = item.scope()
+			dependencies = append(dependencies, dep)
+		}
+	}
+
+	return dependencies, nil
+}
+
+// scanPackagesProps reports the GlobalPackageReference items of a central
+// package management file. Its PackageVersion items only pin versions for
+// the projects that reference them and are reported with those projects.
+func (s *Scanner) scanPackagesProps(path string) ([]types.Dependency, error) {
+	project, err := readMSBuildProject(path)
+	if err != nil {
+		return nil, err
+	}
+
+	properties := project.properties()
+
+	var dependencies []types.Dependency
+	for _, group := range project.ItemGroups {
+		for _, item := range group.GlobalPackageReferences {
+			if id := strings.TrimSpace(item.Include); id != "" {
+				// Global references are always private assets
+				dep := s.newDependency(id, interpolate(item.version(), properties), path)
+				dep.Scope = "build"
+				dependencies = append(dependencies, dep)
+			}
+		}
+	}
+
+	return dependencies, nil
+}
+
+// centralPackageVersions reads the PackageVersion items of the nearest
+// Directory.Packages.props at or above dir, keyed by lowercase package id.
+func centralPackageVersions(dir string) map[string]string {
+	versions := make(map[string]string)
+
+	for {
+		propsPath := filepath.Join(dir, "Directory.Packages.props")
+		if props, err := readMSBuildProject(propsPath); err == nil {
+			properties := props.properties()
+			for _, group := range props.ItemGroups {
+				for _, item := range group.PackageVersions {
+					if id := strings.TrimSpace(item.Include); id != "" {
+						versions[strings.ToLower(id)] = interpolate(item.version(), properties)
+					}
+				}
+			}
+			return versions
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return versions
+		}
+		dir = parent
+	}
+}
+
+// PackagesConfig is a legacy packages.config file.
+type PackagesConfig struct {
+	XMLName  xml.Name        `xml:"packages"`
+	Packages []ConfigPackage `xml:"package"`
+}
+
+type ConfigPackage struct {
+	ID                    string `xml:"id,attr"`
+	Version               string `xml:"version,attr"`
+	TargetFramework       string `xml:"targetFramework,attr"`
+	DevelopmentDependency bool   `xml:"developmentDependency,attr"`
+}
+
+func (s *Scanner) scanPackagesConfig(path string) ([]types.Dependency, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read packages.config: %w", err)
+	}
+
+	var config PackagesConfig
+	if err := xml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse packages.config: %w", err)
+	}
+
+	var dependencies []types.Dependency
+	for _, pkg := range config.Packages {
+		if pkg.ID == "" {
+			continue
+		}
+		dep := s.newDependency(pkg.ID, pkg.Version, path)
+		if pkg.DevelopmentDependency {
+			dep.Scope = "dev"
+		}
+		dependencies = append(dependencies, dep)
+	}
+
+	return dependencies, nil
+}
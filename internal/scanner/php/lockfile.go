@@ -0,0 +1,110 @@
+package php
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"license-audit/pkg/types"
+)
+
+// ComposerLock is a composer.lock file.
+type ComposerLock struct {
+	Packages    []ComposerPackage `json:"packages"`
+	PackagesDev []ComposerPackage `json:"packages-dev"`
+}
+
+// ComposerPackage is a locked package, which carries the license and
+// metadata Composer read from the package's own composer.json.
+type ComposerPackage struct {
+	Name     string            `json:"name"`
+	Version  string            `json:"version"`
+	License  []string          `json:"license"`
+	Homepage string            `json:"homepage"`
+	Require  map[string]string `json:"require"`
+	Source   *ComposerSource   `json:"source"`
+	Dist     *ComposerSource   `json:"dist"`
+}
+
+type ComposerSource struct {
+	Type      string `json:"type"`
+	URL       string `json:"url"`
+	Reference string `json:"reference"`
+	Shasum    string `json:"shasum"`
+}
+
+func (s *Scanner) scanComposerLock(path string) ([]types.Dependency, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read composer.lock: %w", err)
+	}
+
+	var lockFile ComposerLock
+	if err := json.Unmarshal(data, &lockFile); err != nil {
+		return nil, fmt.Errorf("failed to parse composer.lock: %w", err)
+	}
+
+	// The lock does not say which packages were required directly; the
+	// composer.json next to it does
+	var direct map[string]bool
+	if manifest, err := readComposerJSON(filepath.Join(filepath.Dir(path), "composer.json")); err == nil {
+		direct = make(map[string]bool)
+		for name := range manifest.Require {
+			direct[name] = true
+		}
+		for name := range manifest.RequireDev {
+			direct[name] = true
+		}
+	}
+
+	var dependencies []types.Dependency
+
+	for _, group := range []struct {
+		packages []ComposerPackage
+		scope    string
+	}{
+		{lockFile.Packages, ""},
+		{lockFile.PackagesDev, "dev"},
+	} {
+		for _, pkg := range group.packages {
+			dep := s.newDependency(pkg.Name, pkg.Version, path)
+			dep.Scope = group.scope
+			dep.LicenseType = composerLicense(pkg.License)
+			dep.Homepage = pkg.Homepage
+			if direct != nil {
+				dep.Indirect = !direct[pkg.Name]
+			}
+
+			for _, name := range sortedKeys(pkg.Require) {
+				if !isPlatformPackage(name) {
+					dep.Requires = append(dep.Requires, name)
+				}
+			}
+
+			if pkg.Source != nil {
+				dep.Repository = pkg.Source.URL
+			}
+			if pkg.Dist != nil {
+				if pkg.Dist.Type == "path" {
+					dep.Source = "path"
+					dep.SourceURL = pkg.Dist.URL
+				}
+				if pkg.Dist.Shasum != "" {
+					dep.Hashes = []string{"sha1:" + pkg.Dist.Shasum}
+				}
+			} else if pkg.Source != nil && pkg.Source.Type == "git" {
+				// Installed from a VCS repository rather than a package archive
+				dep.Source = "git"
+				dep.SourceURL = pkg.Source.URL
+				if pkg.Source.Reference != "" {
+					dep.SourceURL += "#" + pkg.Source.Reference
+				}
+			}
+
+			dependencies = append(dependencies, dep)
+		}
+	}
+
+	return dependencies, nil
+}
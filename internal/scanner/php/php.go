@@ -0,0 +1,196 @@
+package php
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"license-audit/pkg/types"
+)
+
+type Scanner struct {
+	// ScanPaths are the roots the scan walks. Projects above them are not
+	// consulted when deciding whether a manifest lives under vendor/.
+	ScanPaths []string
+
+	vendorCache map[string]*VendorPackage
+}
+
+// ComposerJSON is a composer.json file, either the project's own or that of
+// an installed package under vendor/.
+type ComposerJSON struct {
+	Name       string            `json:"name"`
+	Version    string            `json:"version"`
+	License    interface{}       `json:"license"` // a string or an array of alternatives
+	Homepage   string            `json:"homepage"`
+	Require    map[string]string `json:"require"`
+	RequireDev map[string]string `json:"require-dev"`
+	Config     struct {
+		VendorDir string `json:"vendor-dir"`
+	} `json:"config"`
+}
+
+func NewScanner() *Scanner {
+	return &Scanner{}
+}
+
+func (s *Scanner) Name() string {
+	return "php"
+}
+
+func (s *Scanner) Detect(path string) bool {
+	fileName := filepath.Base(path)
+	if fileName != "composer.json" && fileName != "composer.lock" {
+		return false
+	}
+	return !s.isVendorPackage(path)
+}
+
+func (s *Scanner) Scan(path string) ([]types.Dependency, error) {
+	dependencies, err := s.scanFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s.applyVendorLicenses(filepath.Dir(path), dependencies)
+	return dependencies, nil
+}
+
+func (s *Scanner) scanFile(path string) ([]types.Dependency, error) {
+	fileName := filepath.Base(path)
+
+	switch fileName {
+	case "composer.json":
+		return s.scanComposerJSON(path)
+	case "composer.lock":
+		return s.scanComposerLock(path)
+	default:
+		return nil, fmt.Errorf("unsupported PHP file: %s", fileName)
+	}
+}
+
+func readComposerJSON(path string) (*ComposerJSON, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read composer.json: %w", err)
+	}
+
+	var manifest ComposerJSON
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse composer.json: %w", err)
+	}
+
+	return &manifest, nil
+}
+
+// scanComposerJSON reports the project, with its license, followed by its
+// require and require-dev packages. Platform requirements such as php and
+// ext-json are not packages and are skipped.
+func (s *Scanner) scanComposerJSON(path string) ([]types.Dependency, error) {
+	manifest, err := readComposerJSON(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var dependencies []types.Dependency
+
+	if manifest.Name != "" {
+		project := s.newDependency(manifest.Name, manifest.Version, path)
+		project.Scope = "project"
+		project.Homepage = manifest.Homepage
+		project.LicenseType = composerLicense(manifest.License)
+		dependencies = append(dependencies, project)
+	}
+
+	for _, group := range []struct {
+		requirements map[string]string
+		scope        string
+	}{
+		{manifest.Require, ""},
+		{manifest.RequireDev, "dev"},
+	} {
+		for _, name := range sortedKeys(group.requirements) {
+			if isPlatformPackage(name) {
+				continue
+			}
+			dep := s.newDependency(name, group.requirements[name], path)
+			dep.Scope = group.scope
+			dependencies = append(dependencies, dep)
+		}
+	}
+
+	return dependencies, nil
+}
+
+// composerLicense converts a license field to an expression. Composer
+// reads an array of licenses as a choice between them.
+func composerLicense(license interface{}) string {
+	var licenses []string
+
+	switch v := license.(type) {
+	case string:
+		licenses = append(licenses, v)
+	case []interface{}:
+		for _, item := range v {
+			if name, ok := item.(string); ok {
+				licenses = append(licenses, name)
+			}
+		}
+	case []string:
+		licenses = v
+	}
+
+	var identifiers []string
+	for _, name := range licenses {
+		if name = strings.TrimSpace(name); name != "" {
+			identifiers = append(identifiers, name)
+		}
+	}
+	if len(identifiers) == 0 {
+		return "UNKNOWN"
+	}
+	return strings.Join(identifiers, " OR ")
+}
+
+// isPlatformPackage reports whether a requirement names the PHP runtime,
+// an extension or a library provided by the platform rather than a package.
+func isPlatformPackage(name string) bool {
+	name = strings.ToLower(name)
+	switch {
+	case name == "php" || strings.HasPrefix(name, "php-"):
+		return true
+	case strings.HasPrefix(name, "ext-") || strings.HasPrefix(name, "lib-"):
+		return true
+	case name == "composer" || name == "composer-plugin-api" || name == "composer-runtime-api":
+		return true
+	case name == "hhvm":
+		return true
+	}
+	return false
+}
+
+func (s *Scanner) newDependency(name, version, filePath string) types.Dependency {
+	if version == "" {
+		version = "UNKNOWN"
+	}
+
+	return types.Dependency{
+		Name:        name,
+		Version:     version,
+		LicenseType: "UNKNOWN",
+		PackageType: "composer",
+		FilePath:    filePath,
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,194 @@
+package php
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+const fixturesDir = "../../../test/fixtures/php"
+
+func TestDetect(t *testing.T) {
+	scanner := NewScanner()
+	scanner.ScanPaths = []string{fixturesDir}
+
+	testCases := []struct {
+		path     string
+		expected bool
+	}{
+		{"composer.json", true},
+		{"composer.lock", true},
+		{"vendor/monolog/monolog/composer.json", true},
+		{"package.json", false},
+		{"", false},
+		// Packages installed into a project's vendor directory
+		{filepath.Join(fixturesDir, "vendor", "monolog", "monolog", "composer.json"), false},
+		{filepath.Join(fixturesDir, "vendor", "acme", "legacy", "composer.json"), false},
+		{filepath.Join(fixturesDir, "custom", "composer.json"), true},
+	}
+
+	for _, tc := range testCases {
+		result := scanner.Detect(tc.path)
+		if result != tc.expected {
+			t.Errorf("Detect(%s) = %v, expected %v", tc.path, result, tc.expected)
+		}
+	}
+}
+
+func TestDetectScanRoot(t *testing.T) {
+	// A scan rooted inside vendor/ does not consult the project above it
+	scanner := NewScanner()
+	scanner.ScanPaths = []string{filepath.Join(fixturesDir, "vendor", "monolog")}
+
+	path := filepath.Join(fixturesDir, "vendor", "monolog", "monolog", "composer.json")
+	if !scanner.Detect(path) {
+		t.Errorf("Detect(%s) = false, expected true", path)
+	}
+}
+
+func TestScanComposerLock(t *testing.T) {
+	scanner := NewScanner()
+
+	dependencies, err := scanner.Scan(filepath.Join(fixturesDir, "composer.lock"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	type expectation struct {
+		version  string
+		scope    string
+		source   string
+		indirect bool
+		license  string
+	}
+
+	expected := map[string]expectation{
+		"acme/legacy":     {"dev-main", "", "git", false, "LGPL-2.1-or-later"}, // from vendor/
+		"monolog/monolog": {"3.4.0", "", "", false, "MIT"},
+		"psr/log":         {"3.0.0", "", "", true, "MIT"},
+		"symfony/console": {"v6.3.4", "", "", false, "MIT"},
+		"symfony/string":  {"v6.3.2", "", "path", true, "MIT OR GPL-2.0-or-later"},
+		"phpunit/phpunit": {"10.3.5", "dev", "", false, "BSD-3-Clause"},
+	}
+
+	if len(dependencies) != len(expected) {
+		t.Errorf("Expected %d dependencies, got %d", len(expected), len(dependencies))
+	}
+
+	for _, dep := range dependencies {
+		want, ok := expected[dep.Name]
+		if !ok {
+			t.Errorf("Unexpected dependency '%s'", dep.Name)
+			continue
+		}
+
+		got := expectation{dep.Version, dep.Scope, dep.Source, dep.Indirect, dep.LicenseType}
+		if got != want {
+			t.Errorf("%s = %+v, expected %+v", dep.Name, got, want)
+		}
+
+		switch dep.Name {
+		case "monolog/monolog":
+			if len(dep.Requires) != 1 || dep.Requires[0] != "psr/log" {
+				t.Errorf("Expected monolog to require psr/log only, got %v", dep.Requires)
+			}
+			if dep.LicenseText == "" {
+				t.Errorf("Expected the license text of monolog from vendor/")
+			}
+		case "psr/log":
+			if len(dep.Hashes) != 1 || dep.Hashes[0] != "sha1:d2c0b3e4a1f6e7d8c9b0a1f2e3d4c5b6a7f8e9d0" {
+				t.Errorf("Expected the dist shasum for psr/log, got %v", dep.Hashes)
+			}
+		case "acme/legacy":
+			if dep.SourceURL != "https://git.example.com/acme/legacy.git#4f2c9e1" {
+				t.Errorf("Unexpected source URL for acme/legacy: %s", dep.SourceURL)
+			}
+		}
+	}
+}
+
+func TestScanComposerJSON(t *testing.T) {
+	scanner := NewScanner()
+
+	dependencies, err := scanner.Scan(filepath.Join(fixturesDir, "composer.json"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	type expectation struct {
+		version string
+		scope   string
+		license string
+	}
+
+	expected := map[string]expectation{
+		"acme/shop":       {"UNKNOWN", "project", "proprietary"},
+		"acme/legacy":     {"*", "", "LGPL-2.1-or-later"},
+		"monolog/monolog": {"^3.4", "", "MIT"},
+		"symfony/console": {"^6.3", "", "UNKNOWN"},
+		"phpunit/phpunit": {"^10.3", "dev", "UNKNOWN"},
+	}
+
+	if len(dependencies) != len(expected) {
+		t.Errorf("Expected %d dependencies, got %d", len(expected), len(dependencies))
+	}
+
+	for _, dep := range dependencies {
+		want, ok := expected[dep.Name]
+		if !ok {
+			t.Errorf("Unexpected dependency '%s'", dep.Name)
+			continue
+		}
+		got := expectation{dep.Version, dep.Scope, dep.LicenseType}
+		if got != want {
+			t.Errorf("%s = %+v, expected %+v", dep.Name, got, want)
+		}
+	}
+}
+
+func TestCustomVendorDir(t *testing.T) {
+	scanner := NewScanner()
+
+	dependencies, err := scanner.Scan(filepath.Join(fixturesDir, "custom", "composer.json"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := map[string]string{
+		"acme/tools":    "MIT OR Apache-2.0",
+		"untagged/tool": "Apache-2.0", // from LICENSE.md under config.vendor-dir
+	}
+
+	if len(dependencies) != len(expected) {
+		t.Errorf("Expected %d dependencies, got %d", len(expected), len(dependencies))
+	}
+
+	for _, dep := range dependencies {
+		if license, ok := expected[dep.Name]; !ok {
+			t.Errorf("Unexpected dependency '%s'", dep.Name)
+		} else if dep.LicenseType != license {
+			t.Errorf("%s license = %s, expected %s", dep.Name, dep.LicenseType, license)
+		}
+	}
+}
+
+func TestIsPlatformPackage(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected bool
+	}{
+		{"php", true},
+		{"php-64bit", true},
+		{"ext-json", true},
+		{"lib-libxml", true},
+		{"composer-plugin-api", true},
+		{"psr/log", false},
+		{"phpunit/phpunit", false},
+	}
+
+	for _, tc := range testCases {
+		result := isPlatformPackage(tc.name)
+		if result != tc.expected {
+			t.Errorf("isPlatformPackage(%s) = %v, expected %v", tc.name, result, tc.expected)
+		}
+	}
+}
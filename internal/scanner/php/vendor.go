@@ -0,0 +1,126 @@
+package php
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"license-audit/internal/license"
+	"license-audit/pkg/types"
+)
+
+// VendorPackage is the license metadata of a package installed under the
+// project's vendor directory.
+type VendorPackage struct {
+	LicenseType string
+	LicenseText string
+	Homepage    string
+}
+
+// applyVendorLicenses fills in licenses the lock or composer.json did not
+// record from the packages installed for the project in dir.
+func (s *Scanner) applyVendorLicenses(dir string, dependencies []types.Dependency) {
+	vendorDir := projectVendorDir(dir)
+
+	for i := range dependencies {
+		dep := &dependencies[i]
+		if dep.Scope == "project" {
+			continue
+		}
+
+		installed := s.readVendorPackage(filepath.Join(vendorDir, filepath.FromSlash(dep.Name)))
+		if installed == nil {
+			continue
+		}
+
+		if dep.LicenseType == "UNKNOWN" || dep.LicenseType == "" {
+			dep.LicenseType = installed.LicenseType
+		}
+		if dep.LicenseText == "" {
+			dep.LicenseText = installed.LicenseText
+		}
+		if dep.Homepage == "" {
+			dep.Homepage = installed.Homepage
+		}
+	}
+}
+
+// projectVendorDir returns the vendor directory of the project in dir,
+// honouring config.vendor-dir in its composer.json.
+func projectVendorDir(dir string) string {
+	if manifest, err := readComposerJSON(filepath.Join(dir, "composer.json")); err == nil && manifest.Config.VendorDir != "" {
+		vendorDir := filepath.FromSlash(manifest.Config.VendorDir)
+		if !filepath.IsAbs(vendorDir) {
+			vendorDir = filepath.Join(dir, vendorDir)
+		}
+		return vendorDir
+	}
+	return filepath.Join(dir, "vendor")
+}
+
+// isVendorPackage reports whether a manifest belongs to a package that
+// Composer installed into the vendor directory of a project above it, within
+// the scan path. Those packages only fill in the licenses of the project's
+// own dependencies, through applyVendorLicenses.
+func (s *Scanner) isVendorPackage(path string) bool {
+	path = filepath.Clean(path)
+	root, ok := s.scanRoot(path)
+	if !ok {
+		return false
+	}
+
+	for dir := filepath.Dir(path); dir != root; {
+		next := filepath.Dir(dir)
+		if next == dir {
+			return false
+		}
+		dir = next
+
+		if _, err := os.Stat(filepath.Join(dir, "composer.json")); err == nil {
+			if isWithin(projectVendorDir(dir), path) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// scanRoot returns the scan path that path was found under.
+func (s *Scanner) scanRoot(path string) (string, bool) {
+	for _, root := range s.ScanPaths {
+		root = filepath.Clean(root)
+		if isWithin(root, path) {
+			return root, true
+		}
+	}
+	return "", false
+}
+
+func isWithin(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func (s *Scanner) readVendorPackage(packageDir string) *VendorPackage {
+	if cached, ok := s.vendorCache[packageDir]; ok {
+		return cached
+	}
+
+	var installed *VendorPackage
+	if manifest, err := readComposerJSON(filepath.Join(packageDir, "composer.json")); err == nil {
+		installed = &VendorPackage{
+			LicenseType: composerLicense(manifest.License),
+			LicenseText: license.ReadFile(packageDir),
+			Homepage:    manifest.Homepage,
+		}
+		if installed.LicenseType == "UNKNOWN" && installed.LicenseText != "" {
+			installed.LicenseType = license.Detect(installed.LicenseText)
+		}
+	}
+
+	if s.vendorCache == nil {
+		s.vendorCache = make(map[string]*VendorPackage)
+	}
+	s.vendorCache[packageDir] = installed
+	return installed
+}
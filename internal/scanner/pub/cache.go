@@ -0,0 +1,129 @@
+package pub
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"license-audit/internal/license"
+	"license-audit/pkg/types"
+)
+
+// CachedPackage is the license of a package whose sources are on disk,
+// in the pub cache or at a local path.
+type CachedPackage struct {
+	Name        string
+	LicenseType string
+	LicenseText string
+	Homepage    string
+	Repository  string
+}
+
+var exactVersionPattern = regexp.MustCompile(`^\d+\.\d+\.\d+(?:[-+][0-9A-Za-z.+-]*)?$`)
+
+// applyCacheLicenses fills in licenses from the LICENSE files of the
+// packages downloaded to the pub cache, or of path dependencies. Pub has
+// no license metadata, so the license file is the only source.
+func (s *Scanner) applyCacheLicenses(dir string, dependencies []types.Dependency) {
+	for i := range dependencies {
+		dep := &dependencies[i]
+		if dep.Scope == "project" || dep.Source == "sdk" {
+			continue
+		}
+
+		var candidates []string
+		switch dep.Source {
+		case "path":
+			candidates = []string{filepath.Join(dir, filepath.FromSlash(dep.SourceURL))}
+		case "git":
+			candidates = s.gitCandidates(dep.SourceURL)
+		default:
+			if pubCache := s.pubCache(); pubCache != "" && exactVersionPattern.MatchString(dep.Version) {
+				candidates, _ = filepath.Glob(filepath.Join(pubCache, "hosted", "*", dep.Name+"-"+dep.Version))
+			}
+		}
+
+		for _, candidate := range candidates {
+			cached := s.readPackage(candidate)
+			if cached == nil || cached.Name != dep.Name {
+				continue
+			}
+			if dep.LicenseType == "UNKNOWN" || dep.LicenseType == "" {
+				dep.LicenseType = cached.LicenseType
+			}
+			if dep.LicenseText == "" {
+				dep.LicenseText = cached.LicenseText
+			}
+			if dep.Homepage == "" {
+				dep.Homepage = cached.Homepage
+			}
+			if dep.Repository == "" {
+				dep.Repository = cached.Repository
+			}
+			break
+		}
+	}
+}
+
+// gitCandidates returns the checkouts of a git dependency locked to a
+// revision: git/<repository>-<revision> in the pub cache, where the
+// package may sit in a subdirectory.
+func (s *Scanner) gitCandidates(sourceURL string) []string {
+	_, ref, ok := strings.Cut(sourceURL, "#")
+	pubCache := s.pubCache()
+	if !ok || ref == "" || pubCache == "" {
+		return nil
+	}
+
+	var candidates []string
+	for _, pattern := range []string{
+		filepath.Join(pubCache, "git", "*-"+ref),
+		filepath.Join(pubCache, "git", "*-"+ref, "*"),
+		filepath.Join(pubCache, "git", "*-"+ref, "packages", "*"),
+	} {
+		matches, _ := filepath.Glob(pattern)
+		candidates = append(candidates, matches...)
+	}
+	return candidates
+}
+
+func (s *Scanner) pubCache() string {
+	if s.PubCache != "" {
+		return s.PubCache
+	}
+	if pubCache := os.Getenv("PUB_CACHE"); pubCache != "" {
+		return pubCache
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".pub-cache")
+}
+
+func (s *Scanner) readPackage(packageDir string) *CachedPackage {
+	if cached, ok := s.licenseCache[packageDir]; ok {
+		return cached
+	}
+
+	var cached *CachedPackage
+	if pubspec, err := readPubspec(filepath.Join(packageDir, "pubspec.yaml")); err == nil {
+		cached = &CachedPackage{
+			Name:        pubspec.Name,
+			LicenseType: "UNKNOWN",
+			LicenseText: license.ReadFile(packageDir),
+			Homepage:    pubspec.Homepage,
+			Repository:  pubspec.Repository,
+		}
+		if cached.LicenseText != "" {
+			cached.LicenseType = license.Detect(cached.LicenseText)
+		}
+	}
+
+	if s.licenseCache == nil {
+		s.licenseCache = make(map[string]*CachedPackage)
+	}
+	s.licenseCache[packageDir] = cached
+	return cached
+}
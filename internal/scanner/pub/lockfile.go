@@ -0,0 +1,105 @@
+package pub
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+	"license-audit/pkg/types"
+)
+
+// PubspecLock is a pubspec.lock file.
+type PubspecLock struct {
+	Packages map[string]LockedPackage `yaml:"packages"`
+}
+
+type LockedPackage struct {
+	Dependency  string             `yaml:"dependency"` // direct main, direct dev, direct overridden or transitive
+	Description PackageDescription `yaml:"description"`
+	Source      string             `yaml:"source"` // hosted, git, path or sdk
+	Version     string             `yaml:"version"`
+}
+
+// PackageDescription locates a locked package. It is a plain SDK name for
+// sdk packages.
+type PackageDescription struct {
+	Name        string `yaml:"name"`
+	URL         string `yaml:"url"`
+	SHA256      string `yaml:"sha256"`
+	Path        string `yaml:"path"`
+	Ref         string `yaml:"ref"`
+	ResolvedRef string `yaml:"resolved-ref"`
+	SDK         string `yaml:"-"`
+}
+
+func (d *PackageDescription) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		d.SDK = node.Value
+		return nil
+	}
+	type plain PackageDescription
+	return node.Decode((*plain)(d))
+}
+
+func (s *Scanner) scanPubspecLock(path string) ([]types.Dependency, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pubspec.lock: %w", err)
+	}
+
+	var lockFile PubspecLock
+	if err := yaml.Unmarshal(data, &lockFile); err != nil {
+		return nil, fmt.Errorf("failed to parse pubspec.lock: %w", err)
+	}
+
+	var dependencies []types.Dependency
+	for _, name := range sortedKeys(lockFile.Packages) {
+		pkg := lockFile.Packages[name]
+
+		dep := s.newDependency(name, pkg.Version, path)
+		switch pkg.Dependency {
+		case "direct dev":
+			dep.Scope = "dev"
+		case "transitive":
+			dep.Indirect = true
+		}
+
+		switch pkg.Source {
+		case "sdk":
+			dep.Source = "sdk"
+			dep.SourceURL = pkg.Description.SDK
+		case "path":
+			dep.Source = "path"
+			dep.SourceURL = pkg.Description.Path
+		case "git":
+			dep.Source = "git"
+			dep.SourceURL = pkg.Description.URL
+			if pkg.Description.ResolvedRef != "" {
+				dep.SourceURL += "#" + pkg.Description.ResolvedRef
+			}
+		case "hosted":
+			if !isDefaultHost(pkg.Description.URL) {
+				dep.Source = "registry"
+				dep.SourceURL = pkg.Description.URL
+			}
+		}
+
+		if pkg.Description.SHA256 != "" {
+			dep.Hashes = []string{"sha256:" + pkg.Description.SHA256}
+		}
+
+		dependencies = append(dependencies, dep)
+	}
+
+	return dependencies, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
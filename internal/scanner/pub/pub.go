@@ -0,0 +1,74 @@
+package pub
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"license-audit/pkg/types"
+)
+
+type Scanner struct {
+	// PubCache is the pub cache holding downloaded packages. It defaults to
+	// $PUB_CACHE, then ~/.pub-cache.
+	PubCache string
+
+	licenseCache map[string]*CachedPackage
+}
+
+func NewScanner() *Scanner {
+	return &Scanner{}
+}
+
+func (s *Scanner) Name() string {
+	return "pub"
+}
+
+func (s *Scanner) Detect(path string) bool {
+	fileName := filepath.Base(path)
+	return fileName == "pubspec.yaml" || fileName == "pubspec.lock"
+}
+
+func (s *Scanner) Scan(path string) ([]types.Dependency, error) {
+	dependencies, err := s.scanFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s.applyCacheLicenses(filepath.Dir(path), dependencies)
+	return dependencies, nil
+}
+
+func (s *Scanner) scanFile(path string) ([]types.Dependency, error) {
+	fileName := filepath.Base(path)
+
+	switch fileName {
+	case "pubspec.yaml":
+		return s.scanPubspec(path)
+	case "pubspec.lock":
+		return s.scanPubspecLock(path)
+	default:
+		return nil, fmt.Errorf("unsupported pub file: %s", fileName)
+	}
+}
+
+func (s *Scanner) newDependency(name, version, filePath string) types.Dependency {
+	if version == "" {
+		version = "UNKNOWN"
+	}
+
+	return types.Dependency{
+		Name:        name,
+		Version:     version,
+		LicenseType: "UNKNOWN",
+		PackageType: "pub",
+		FilePath:    filePath,
+	}
+}
+
+// isDefaultHost reports whether a hosted URL is the public pub.dev
+// repository, whose packages need no source annotation.
+func isDefaultHost(url string) bool {
+	url = strings.TrimSuffix(url, "/")
+	return url == "" || url == "https://pub.dev" || url == "https://pub.dartlang.org"
+}
@@ -0,0 +1,145 @@
+package pub
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+const fixturesDir = "../../../test/fixtures/pub"
+
+func newTestScanner() *Scanner {
+	scanner := NewScanner()
+	scanner.PubCache = filepath.Join(fixturesDir, "pub-cache")
+	return scanner
+}
+
+func TestDetect(t *testing.T) {
+	scanner := NewScanner()
+
+	testCases := []struct {
+		path     string
+		expected bool
+	}{
+		{"pubspec.yaml", true},
+		{"pubspec.lock", true},
+		{"packages/core/pubspec.yaml", true},
+		{"pubspec.yml", false},
+		{"analysis_options.yaml", false},
+		{"", false},
+	}
+
+	for _, tc := range testCases {
+		result := scanner.Detect(tc.path)
+		if result != tc.expected {
+			t.Errorf("Detect(%s) = %v, expected %v", tc.path, result, tc.expected)
+		}
+	}
+}
+
+func TestScanPubspec(t *testing.T) {
+	scanner := newTestScanner()
+
+	dependencies, err := scanner.Scan(filepath.Join(fixturesDir, "app", "pubspec.yaml"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	type expectation struct {
+		version  string
+		scope    string
+		source   string
+		indirect bool
+		license  string
+	}
+
+	expected := map[string]expectation{
+		"acme_app":      {"2.3.0+41", "project", "", false, "BSD-2-Clause"},
+		"acme_auth":     {"^3.0.0", "", "registry", false, "UNKNOWN"},
+		"fancy_widgets": {"UNKNOWN", "", "git", false, "UNKNOWN"},
+		"flutter":       {"UNKNOWN", "", "sdk", false, "UNKNOWN"},
+		"http":          {"^1.1.0", "", "", false, "UNKNOWN"}, // a constraint matches no single cached version
+		"local_utils":   {"UNKNOWN", "", "path", false, "MIT"},
+		"provider":      {"6.1.1", "", "", false, "UNKNOWN"}, // overridden
+		"flutter_test":  {"UNKNOWN", "dev", "sdk", false, "UNKNOWN"},
+		"flutter_lints": {"^3.0.0", "dev", "", false, "UNKNOWN"},
+		"collection":    {"1.18.0", "", "", true, "BSD-3-Clause"}, // override of a transitive package
+	}
+
+	if len(dependencies) != len(expected) {
+		t.Errorf("Expected %d dependencies, got %d", len(expected), len(dependencies))
+	}
+
+	for _, dep := range dependencies {
+		want, ok := expected[dep.Name]
+		if !ok {
+			t.Errorf("Unexpected dependency '%s'", dep.Name)
+			continue
+		}
+		got := expectation{dep.Version, dep.Scope, dep.Source, dep.Indirect, dep.LicenseType}
+		if got != want {
+			t.Errorf("%s = %+v, expected %+v", dep.Name, got, want)
+		}
+
+		if dep.Name == "fancy_widgets" && dep.SourceURL != "https://github.com/acme/flutter_widgets.git#main" {
+			t.Errorf("Unexpected source URL for fancy_widgets: %s", dep.SourceURL)
+		}
+	}
+}
+
+func TestScanPubspecLock(t *testing.T) {
+	scanner := newTestScanner()
+
+	dependencies, err := scanner.Scan(filepath.Join(fixturesDir, "app", "pubspec.lock"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	type expectation struct {
+		version  string
+		scope    string
+		source   string
+		indirect bool
+		license  string
+	}
+
+	expected := map[string]expectation{
+		"acme_auth":     {"3.2.0", "", "registry", false, "UNKNOWN"},
+		"collection":    {"1.18.0", "", "", false, "BSD-3-Clause"},
+		"fancy_widgets": {"0.9.0", "", "git", false, "Apache-2.0"}, // git checkout in the pub cache
+		"flutter":       {"0.0.0", "", "sdk", false, "UNKNOWN"},
+		"flutter_lints": {"3.0.1", "dev", "", false, "UNKNOWN"},
+		"http":          {"1.1.0", "", "", false, "BSD-3-Clause"},
+		"http_parser":   {"4.0.2", "", "", true, "UNKNOWN"},
+		"local_utils":   {"0.1.0", "", "path", false, "MIT"},
+	}
+
+	if len(dependencies) != len(expected) {
+		t.Errorf("Expected %d dependencies, got %d", len(expected), len(dependencies))
+	}
+
+	for _, dep := range dependencies {
+		want, ok := expected[dep.Name]
+		if !ok {
+			t.Errorf("Unexpected dependency '%s'", dep.Name)
+			continue
+		}
+		got := expectation{dep.Version, dep.Scope, dep.Source, dep.Indirect, dep.LicenseType}
+		if got != want {
+			t.Errorf("%s = %+v, expected %+v", dep.Name, got, want)
+		}
+
+		switch dep.Name {
+		case "http":
+			if len(dep.Hashes) != 1 || dep.Hashes[0] != "sha256:759d1a329847dd0f39226c688d3e06a6b8679668e350e2891a6474f8b4bb8525" {
+				t.Errorf("Expected the sha256 of http, got %v", dep.Hashes)
+			}
+			if dep.Repository != "https://github.com/dart-lang/http/tree/master/pkgs/http" {
+				t.Errorf("Expected the repository from the cached pubspec, got %s", dep.Repository)
+			}
+		case "flutter":
+			if dep.SourceURL != "flutter" {
+				t.Errorf("Expected the flutter SDK as source, got %s", dep.SourceURL)
+			}
+		}
+	}
+}
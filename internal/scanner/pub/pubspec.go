@@ -0,0 +1,147 @@
+package pub
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+	"license-audit/internal/license"
+	"license-audit/pkg/types"
+)
+
+// Pubspec is a pubspec.yaml file.
+type Pubspec struct {
+	Name                string                       `yaml:"name"`
+	Version             string                       `yaml:"version"`
+	Homepage            string                       `yaml:"homepage"`
+	Repository          string                       `yaml:"repository"`
+	Dependencies        map[string]PubspecDependency `yaml:"dependencies"`
+	DevDependencies     map[string]PubspecDependency `yaml:"dev_dependencies"`
+	DependencyOverrides map[string]PubspecDependency `yaml:"dependency_overrides"`
+}
+
+// PubspecDependency is a version constraint string, or a mapping with a
+// hosted, git, path or sdk source.
+type PubspecDependency struct {
+	Version string      `yaml:"version"`
+	Hosted  interface{} `yaml:"hosted"` // a URL or {name, url}
+	Git     interface{} `yaml:"git"`    // a URL or {url, ref, path}
+	Path    string      `yaml:"path"`
+	SDK     string      `yaml:"sdk"`
+}
+
+func (d *PubspecDependency) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		d.Version = node.Value
+		return nil
+	}
+	type plain PubspecDependency
+	return node.Decode((*plain)(d))
+}
+
+func readPubspec(path string) (*Pubspec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pubspec.yaml: %w", err)
+	}
+
+	var pubspec Pubspec
+	if err := yaml.Unmarshal(data, &pubspec); err != nil {
+		return nil, fmt.Errorf("failed to parse pubspec.yaml: %w", err)
+	}
+
+	return &pubspec, nil
+}
+
+// scanPubspec reports the package, with the license file next to the
+// pubspec, followed by its dependencies and dev_dependencies. A
+// dependency_overrides entry replaces the constraint and source of the
+// dependency it overrides, or adds the transitive package it pins.
+func (s *Scanner) scanPubspec(path string) ([]types.Dependency, error) {
+	pubspec, err := readPubspec(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var dependencies []types.Dependency
+
+	if pubspec.Name != "" {
+		project := s.newDependency(pubspec.Name, pubspec.Version, path)
+		project.Scope = "project"
+		project.Homepage = pubspec.Homepage
+		project.Repository = pubspec.Repository
+		if text := license.ReadFile(filepath.Dir(path)); text != "" {
+			project.LicenseText = text
+			project.LicenseType = license.Detect(text)
+		}
+		dependencies = append(dependencies, project)
+	}
+
+	index := make(map[string]int)
+	for _, group := range []struct {
+		entries map[string]PubspecDependency
+		scope   string
+	}{
+		{pubspec.Dependencies, ""},
+		{pubspec.DevDependencies, "dev"},
+	} {
+		for _, name := range sortedKeys(group.entries) {
+			dep := s.pubspecDependency(name, group.entries[name], path)
+			dep.Scope = group.scope
+			index[name] = len(dependencies)
+			dependencies = append(dependencies, dep)
+		}
+	}
+
+	for _, name := range sortedKeys(pubspec.DependencyOverrides) {
+		override := s.pubspecDependency(name, pubspec.DependencyOverrides[name], path)
+		if i, ok := index[name]; ok {
+			override.Scope = dependencies[i].Scope
+			dependencies[i] = override
+			continue
+		}
+		override.Indirect = true
+		dependencies = append(dependencies, override)
+	}
+
+	return dependencies, nil
+}
+
+func (s *Scanner) pubspecDependency(name string, declaration PubspecDependency, path string) types.Dependency {
+	dep := s.newDependency(name, declaration.Version, path)
+
+	switch {
+	case declaration.SDK != "":
+		dep.Source = "sdk"
+		dep.SourceURL = declaration.SDK
+	case declaration.Path != "":
+		dep.Source = "path"
+		dep.SourceURL = declaration.Path
+	case declaration.Git != nil:
+		dep.Source = "git"
+		switch git := declaration.Git.(type) {
+		case string:
+			dep.SourceURL = git
+		case map[string]interface{}:
+			dep.SourceURL, _ = git["url"].(string)
+			if ref, ok := git["ref"].(string); ok && ref != "" {
+				dep.SourceURL += "#" + ref
+			}
+		}
+	case declaration.Hosted != nil:
+		var url string
+		switch hosted := declaration.Hosted.(type) {
+		case string:
+			url = hosted
+		case map[string]interface{}:
+			url, _ = hosted["url"].(string)
+		}
+		if !isDefaultHost(url) {
+			dep.Source = "registry"
+			dep.SourceURL = url
+		}
+	}
+
+	return dep
+}
@@ -0,0 +1,302 @@
+package python
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"license-audit/pkg/types"
+)
+
+// FileHash is an artifact entry shared by the poetry, uv and pdm lockfiles.
+type FileHash struct {
+	File string `toml:"file"`
+	URL  string `toml:"url"`
+	Hash string `toml:"hash"`
+}
+
+type PoetryLock struct {
+	Package  []PoetryPackage `toml:"package"`
+	Metadata struct {
+		Files map[string][]FileHash `toml:"files"` // lock format 1.x
+	} `toml:"metadata"`
+}
+
+type PoetryPackage struct {
+	Name     string     `toml:"name"`
+	Version  string     `toml:"version"`
+	Category string     `toml:"category"` // main or dev, lock format 1.x
+	Optional bool       `toml:"optional"`
+	Groups   []string   `toml:"groups"` // lock format 2.1+
+	Files    []FileHash `toml:"files"`
+}
+
+type PipfileLock struct {
+	Default map[string]PipfileLockEntry `json:"default"`
+	Develop map[string]PipfileLockEntry `json:"develop"`
+}
+
+type PipfileLockEntry struct {
+	Version string   `json:"version"`
+	Hashes  []string `json:"hashes"`
+	Markers string   `json:"markers"`
+	Git     string   `json:"git"`
+	Ref     string   `json:"ref"`
+	Path    string   `json:"path"`
+}
+
+type UvLock struct {
+	Package []UvPackage `toml:"package"`
+}
+
+type UvPackage struct {
+	Name                 string                       `toml:"name"`
+	Version              string                       `toml:"version"`
+	Source               map[string]string            `toml:"source"` // registry, git, path, editable or virtual
+	Dependencies         []UvDependencyRef            `toml:"dependencies"`
+	OptionalDependencies map[string][]UvDependencyRef `toml:"optional-dependencies"`
+	DevDependencies      map[string][]UvDependencyRef `toml:"dev-dependencies"`
+	Sdist                *FileHash                    `toml:"sdist"`
+	Wheels               []FileHash                   `toml:"wheels"`
+}
+
+type UvDependencyRef struct {
+	Name    string `toml:"name"`
+	Version string `toml:"version"`
+	Marker  string `toml:"marker"`
+}
+
+type PdmLock struct {
+	Package  []PdmPackage `toml:"package"`
+	Metadata struct {
+		Files map[string][]FileHash `toml:"files"` // "name version" keys in older locks
+	} `toml:"metadata"`
+}
+
+type PdmPackage struct {
+	Name    string     `toml:"name"`
+	Version string     `toml:"version"`
+	Groups  []string   `toml:"groups"`
+	Files   []FileHash `toml:"files"`
+}
+
+func (s *Scanner) scanPoetryLock(path string) ([]types.Dependency, error) {
+	var lockFile PoetryLock
+	if _, err := toml.DecodeFile(path, &lockFile); err != nil {
+		return nil, fmt.Errorf("failed to parse poetry.lock: %w", err)
+	}
+
+	var dependencies []types.Dependency
+	for _, pkg := range lockFile.Package {
+		scope := ""
+		switch {
+		case pkg.Optional:
+			scope = "optional"
+		case pkg.Category == "dev":
+			scope = "dev"
+		case len(pkg.Groups) > 0 && !containsString(pkg.Groups, "main"):
+			scope = "dev"
+		}
+
+		files := pkg.Files
+		if len(files) == 0 {
+			files = lockFile.Metadata.Files[pkg.Name]
+		}
+
+		dep := s.newDependency(pkg.Name, pkg.Version, path)
+		dep.Scope = scope
+		dep.Hashes = fileHashes(files)
+		dependencies = append(dependencies, dep)
+	}
+
+	return dependencies, nil
+}
+
+func (s *Scanner) scanPipfileLock(path string) ([]types.Dependency, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Pipfile.lock: %w", err)
+	}
+
+	var lockFile PipfileLock
+	if err := json.Unmarshal(data, &lockFile); err != nil {
+		return nil, fmt.Errorf("failed to parse Pipfile.lock: %w", err)
+	}
+
+	var dependencies []types.Dependency
+	for _, section := range []struct {
+		entries map[string]PipfileLockEntry
+		scope   string
+	}{
+		{lockFile.Default, ""},
+		{lockFile.Develop, "dev"},
+	} {
+		for _, name := range sortedKeys(section.entries) {
+			// Packages needed at runtime are listed in both sections
+			if _, inDefault := lockFile.Default[name]; inDefault && section.scope == "dev" {
+				continue
+			}
+
+			entry := section.entries[name]
+			version := strings.TrimPrefix(entry.Version, "==")
+			if version == "" {
+				version = "UNKNOWN"
+			}
+
+			dep := s.newDependency(name, version, path)
+			dep.Scope = section.scope
+			dep.Hashes = entry.Hashes
+			dep.Markers = entry.Markers
+			if entry.Git != "" {
+				dep.Source = "git"
+				dep.SourceURL = entry.Git
+			} else if entry.Path != "" {
+				dep.Source = "path"
+				dep.SourceURL = entry.Path
+			}
+			dependencies = append(dependencies, dep)
+		}
+	}
+
+	return dependencies, nil
+}
+
+func (s *Scanner) scanUvLock(path string) ([]types.Dependency, error) {
+	var lockFile UvLock
+	if _, err := toml.DecodeFile(path, &lockFile); err != nil {
+		return nil, fmt.Errorf("failed to parse uv.lock: %w", err)
+	}
+
+	packages := make(map[string][]UvPackage)
+	for _, pkg := range lockFile.Package {
+		packages[pkg.Name] = append(packages[pkg.Name], pkg)
+	}
+
+	// The project itself (and its workspace members) are editable or
+	// virtual sources; their dependency lists decide each package's scope.
+	scopes := make(map[string]string)
+	var mark func(refs []UvDependencyRef, scope string)
+	mark = func(refs []UvDependencyRef, scope string) {
+		for _, ref := range refs {
+			for _, pkg := range packages[ref.Name] {
+				if ref.Version != "" && ref.Version != pkg.Version {
+					continue
+				}
+				key := pkg.Name + "@" + pkg.Version
+				if _, seen := scopes[key]; seen {
+					continue
+				}
+				scopes[key] = scope
+				mark(pkg.Dependencies, scope)
+				for _, extra := range sortedKeys(pkg.OptionalDependencies) {
+					mark(pkg.OptionalDependencies[extra], scope)
+				}
+			}
+		}
+	}
+
+	var roots []UvPackage
+	for _, pkg := range lockFile.Package {
+		if isUvProject(pkg) {
+			roots = append(roots, pkg)
+		}
+	}
+	for _, root := range roots {
+		mark(root.Dependencies, "")
+	}
+	for _, root := range roots {
+		for _, extra := range sortedKeys(root.OptionalDependencies) {
+			mark(root.OptionalDependencies[extra], "optional")
+		}
+	}
+	for _, root := range roots {
+		for _, group := range sortedKeys(root.DevDependencies) {
+			mark(root.DevDependencies[group], "dev")
+		}
+	}
+
+	var dependencies []types.Dependency
+	for _, pkg := range lockFile.Package {
+		if isUvProject(pkg) {
+			continue
+		}
+
+		files := pkg.Wheels
+		if pkg.Sdist != nil {
+			files = append([]FileHash{*pkg.Sdist}, files...)
+		}
+
+		version := pkg.Version
+		if version == "" {
+			version = "UNKNOWN"
+		}
+
+		dep := s.newDependency(pkg.Name, version, path)
+		dep.Scope = scopes[pkg.Name+"@"+pkg.Version]
+		dep.Hashes = fileHashes(files)
+		dependencies = append(dependencies, dep)
+	}
+
+	return dependencies, nil
+}
+
+func isUvProject(pkg UvPackage) bool {
+	_, editable := pkg.Source["editable"]
+	_, virtual := pkg.Source["virtual"]
+	return editable || virtual
+}
+
+func (s *Scanner) scanPdmLock(path string) ([]types.Dependency, error) {
+	var lockFile PdmLock
+	if _, err := toml.DecodeFile(path, &lockFile); err != nil {
+		return nil, fmt.Errorf("failed to parse pdm.lock: %w", err)
+	}
+
+	var dependencies []types.Dependency
+	for _, pkg := range lockFile.Package {
+		files := pkg.Files
+		if len(files) == 0 {
+			files = lockFile.Metadata.Files[pkg.Name+" "+pkg.Version]
+		}
+
+		dep := s.newDependency(pkg.Name, pkg.Version, path)
+		if len(pkg.Groups) > 0 && !containsString(pkg.Groups, "default") {
+			dep.Scope = "dev"
+		}
+		dep.Hashes = fileHashes(files)
+		dependencies = append(dependencies, dep)
+	}
+
+	return dependencies, nil
+}
+
+func fileHashes(files []FileHash) []string {
+	var hashes []string
+	for _, file := range files {
+		if file.Hash != "" {
+			hashes = append(hashes, file.Hash)
+		}
+	}
+	return hashes
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
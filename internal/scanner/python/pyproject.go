@@ -0,0 +1,119 @@
+package python
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"license-audit/pkg/types"
+)
+
+type PyProject struct {
+	Project struct {
+		Dependencies         []string            `toml:"dependencies"`
+		OptionalDependencies map[string][]string `toml:"optional-dependencies"`
+	} `toml:"project"`
+	Tool struct {
+		Poetry struct {
+			Dependencies    map[string]interface{} `toml:"dependencies"`
+			DevDependencies map[string]interface{} `toml:"dev-dependencies"` // pre-1.2 dev group
+			Group           map[string]struct {
+				Dependencies map[string]interface{} `toml:"dependencies"`
+			} `toml:"group"`
+		} `toml:"poetry"`
+	} `toml:"tool"`
+}
+
+type Pipfile struct {
+	Packages    map[string]interface{} `toml:"packages"`
+	DevPackages map[string]interface{} `toml:"dev-packages"`
+}
+
+func (s *Scanner) scanPyProject(path string) ([]types.Dependency, error) {
+	var project PyProject
+	if _, err := toml.DecodeFile(path, &project); err != nil {
+		return nil, fmt.Errorf("failed to parse pyproject.toml: %w", err)
+	}
+
+	var dependencies []types.Dependency
+
+	// PEP 621 metadata
+	for _, requirement := range project.Project.Dependencies {
+		if dep := s.parseRequirement(requirement, path); dep.Name != "" {
+			dependencies = append(dependencies, dep)
+		}
+	}
+	for _, extra := range sortedKeys(project.Project.OptionalDependencies) {
+		for _, requirement := range project.Project.OptionalDependencies[extra] {
+			if dep := s.parseRequirement(requirement, path); dep.Name != "" {
+				dep.Scope = "optional"
+				dependencies = append(dependencies, dep)
+			}
+		}
+	}
+
+	// Poetry keeps its own tables under [tool.poetry]
+	poetry := project.Tool.Poetry
+	dependencies = append(dependencies, s.poetryDependencies(poetry.Dependencies, "", path)...)
+	dependencies = append(dependencies, s.poetryDependencies(poetry.DevDependencies, "dev", path)...)
+	for _, groupName := range sortedKeys(poetry.Group) {
+		scope := "dev"
+		if groupName == "main" {
+			scope = ""
+		}
+		dependencies = append(dependencies, s.poetryDependencies(poetry.Group[groupName].Dependencies, scope, path)...)
+	}
+
+	return dependencies, nil
+}
+
+func (s *Scanner) scanPipfile(path string) ([]types.Dependency, error) {
+	var pipfile Pipfile
+	if _, err := toml.DecodeFile(path, &pipfile); err != nil {
+		return nil, fmt.Errorf("failed to parse Pipfile: %w", err)
+	}
+
+	var dependencies []types.Dependency
+	dependencies = append(dependencies, s.poetryDependencies(pipfile.Packages, "", path)...)
+	dependencies = append(dependencies, s.poetryDependencies(pipfile.DevPackages, "dev", path)...)
+
+	return dependencies, nil
+}
+
+// poetryDependencies converts a name -> constraint table, as used by both
+// Poetry and Pipfile, where the constraint is either a version string or an
+// inline table with a version key.
+func (s *Scanner) poetryDependencies(table map[string]interface{}, scope, path string) []types.Dependency {
+	var dependencies []types.Dependency
+
+	for _, name := range sortedKeys(table) {
+		// The interpreter constraint is not a package
+		if strings.EqualFold(name, "python") {
+			continue
+		}
+
+		version := "UNKNOWN"
+		depScope := scope
+
+		switch v := table[name].(type) {
+		case string:
+			version = v
+		case map[string]interface{}:
+			if constraint, ok := v["version"].(string); ok {
+				version = constraint
+			}
+			if optional, ok := v["optional"].(bool); ok && optional && depScope == "" {
+				depScope = "optional"
+			}
+		}
+		if version == "*" || version == "" {
+			version = "UNKNOWN"
+		}
+
+		dep := s.newDependency(name, version, path)
+		dep.Scope = depScope
+		dependencies = append(dependencies, dep)
+	}
+
+	return dependencies
+}
@@ -1,16 +1,19 @@
 package python
 
 import (
-	"bufio"
 	"fmt"
-	"os"
 	"path/filepath"
-	"strings"
 
 	"license-audit/pkg/types"
 )
 
-type Scanner struct{}
+type Scanner struct {
+	// VirtualenvPaths lists extra virtualenvs or site-packages directories
+	// to read installed licenses from, relative to the scanned file.
+	VirtualenvPaths []string
+
+	sitePackagesCache map[string]map[string]Distribution
+}
 
 func NewScanner() *Scanner {
 	return &Scanner{}
@@ -23,87 +26,65 @@ func (s *Scanner) Name() string {
 func (s *Scanner) Detect(path string) bool {
 	fileName := filepath.Base(path)
 	return fileName == "requirements.txt" || fileName == "setup.py" ||
-		fileName == "pyproject.toml" || fileName == "Pipfile"
+		fileName == "pyproject.toml" || fileName == "Pipfile" ||
+		fileName == "Pipfile.lock" || fileName == "poetry.lock" ||
+		fileName == "uv.lock" || fileName == "pdm.lock" || fileName == "setup.cfg"
 }
 
 func (s *Scanner) Scan(path string) ([]types.Dependency, error) {
+	dependencies, err := s.scanFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s.applyInstalledLicenses(filepath.Dir(path), dependencies)
+
+	return dependencies, nil
+}
+
+func (s *Scanner) scanFile(path string) ([]types.Dependency, error) {
 	fileName := filepath.Base(path)
 
 	switch fileName {
 	case "requirements.txt":
 		return s.scanRequirements(path)
-	case "setup.py", "pyproject.toml", "Pipfile":
-		return s.scanOtherFormats(path)
+	case "pyproject.toml":
+		return s.scanPyProject(path)
+	case "Pipfile":
+		return s.scanPipfile(path)
+	case "Pipfile.lock":
+		return s.scanPipfileLock(path)
+	case "poetry.lock":
+		return s.scanPoetryLock(path)
+	case "uv.lock":
+		return s.scanUvLock(path)
+	case "pdm.lock":
+		return s.scanPdmLock(path)
+	case "setup.py":
+		return s.scanSetupPy(path)
+	case "setup.cfg":
+		return s.scanSetupCfg(path)
 	default:
 		return nil, fmt.Errorf("unsupported Python file: %s", fileName)
 	}
 }
 
-func (s *Scanner) scanRequirements(path string) ([]types.Dependency, error) {
-	file, err := os.Open(path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open requirements.txt: %w", err)
-	}
-	defer file.Close()
-
-	var dependencies []types.Dependency
-	scanner := bufio.NewScanner(file)
-
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-
-		// Skip empty lines and comments
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-
-		dep := s.parseRequirement(line, path)
-		if dep.Name != "" {
-			dependencies = append(dependencies, dep)
-		}
-	}
-
-	return dependencies, scanner.Err()
-}
-
+// parseRequirement converts a PEP 508 requirement string into a dependency,
+// returning an empty dependency when the string cannot be parsed.
 func (s *Scanner) parseRequirement(line, filePath string) types.Dependency {
-	// Remove options like -e, --editable
-	if strings.HasPrefix(line, "-e ") || strings.HasPrefix(line, "--editable ") {
-		return types.Dependency{} // Skip editable installs
-	}
-
-	// Parse package==version format
-	name := line
-	version := "UNKNOWN"
-
-	for _, sep := range []string{"==", ">=", "<=", "~=", ">", "<"} {
-		if strings.Contains(line, sep) {
-			parts := strings.SplitN(line, sep, 2)
-			name = strings.TrimSpace(parts[0])
-			if len(parts) > 1 {
-				version = strings.TrimSpace(parts[1])
-			}
-			break
-		}
-	}
-
-	// Clean package name
-	name = strings.Trim(name, "\"'")
-	if name == "" {
+	req, err := ParseRequirement(line)
+	if err != nil {
 		return types.Dependency{}
 	}
+	return s.requirementDependency(req, filePath)
+}
 
+func (s *Scanner) newDependency(name, version, filePath string) types.Dependency {
 	return types.Dependency{
-		Name:        name,
+		Name:        NormalizeName(name),
 		Version:     version,
 		LicenseType: "UNKNOWN",
 		PackageType: "python",
 		FilePath:    filePath,
 	}
 }
-
-func (s *Scanner) scanOtherFormats(path string) ([]types.Dependency, error) {
-	// Basic implementation - just return empty for now
-	// Could be enhanced to parse setup.py, pyproject.toml, etc.
-	return []types.Dependency{}, nil
-}
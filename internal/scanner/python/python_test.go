@@ -0,0 +1,390 @@
+package python
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const fixturesDir = "../../../test/fixtures/python"
+
+func TestDetect(t *testing.T) {
+	scanner := NewScanner()
+
+	testCases := []struct {
+		path     string
+		expected bool
+	}{
+		{"requirements.txt", true},
+		{"pyproject.toml", true},
+		{"Pipfile", true},
+		{"Pipfile.lock", true},
+		{"poetry.lock", true},
+		{"uv.lock", true},
+		{"pdm.lock", true},
+		{"package.json", false},
+		{"", false},
+	}
+
+	for _, tc := range testCases {
+		result := scanner.Detect(tc.path)
+		if result != tc.expected {
+			t.Errorf("Detect(%s) = %v, expected %v", tc.path, result, tc.expected)
+		}
+	}
+}
+
+func TestScanLockfiles(t *testing.T) {
+	scanner := NewScanner()
+
+	type expectation struct {
+		version string
+		scope   string
+		hashes  int
+	}
+
+	testCases := []struct {
+		fileName string
+		expected map[string]expectation
+	}{
+		{
+			fileName: "poetry.lock",
+			expected: map[string]expectation{
+				"requests": {"2.31.0", "", 2},
+				"pysocks":  {"1.7.1", "optional", 0},
+				"pytest":   {"7.4.0", "dev", 1},
+			},
+		},
+		{
+			fileName: "Pipfile.lock",
+			expected: map[string]expectation{
+				"flask": {"2.3.3", "", 1},
+				"six":   {"1.16.0", "", 0},
+				"black": {"23.7.0", "dev", 1},
+			},
+		},
+		{
+			fileName: "uv.lock",
+			expected: map[string]expectation{
+				"httpx": {"0.25.0", "", 1},
+				"anyio": {"4.0.0", "", 2},
+				"click": {"8.1.7", "optional", 1},
+				"ruff":  {"0.1.0", "dev", 0},
+			},
+		},
+		{
+			fileName: "pdm.lock",
+			expected: map[string]expectation{
+				"attrs":  {"23.1.0", "", 1},
+				"pytest": {"7.4.0", "dev", 1},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		dependencies, err := scanner.Scan(filepath.Join(fixturesDir, tc.fileName))
+		if err != nil {
+			t.Fatalf("Scan(%s) returned error: %v", tc.fileName, err)
+		}
+
+		if len(dependencies) != len(tc.expected) {
+			t.Errorf("%s: expected %d dependencies, got %d", tc.fileName, len(tc.expected), len(dependencies))
+		}
+
+		for _, dep := range dependencies {
+			want, ok := tc.expected[dep.Name]
+			if !ok {
+				t.Errorf("%s: unexpected dependency '%s'", tc.fileName, dep.Name)
+				continue
+			}
+
+			got := expectation{dep.Version, dep.Scope, len(dep.Hashes)}
+			if got != want {
+				t.Errorf("%s: dependency '%s' = %+v, expected %+v", tc.fileName, dep.Name, got, want)
+			}
+
+			if dep.PackageType != "python" {
+				t.Errorf("Expected package type 'python', got '%s'", dep.PackageType)
+			}
+		}
+	}
+}
+
+func TestScanPyProject(t *testing.T) {
+	scanner := NewScanner()
+
+	testCases := []struct {
+		fileName string
+		expected map[string]string // name -> scope
+	}{
+		{
+			fileName: "pyproject.toml",
+			expected: map[string]string{
+				"httpx":    "",
+				"attrs":    "",
+				"click":    "optional",
+				"requests": "",
+				"pysocks":  "optional",
+				"pytest":   "dev",
+			},
+		},
+		{
+			fileName: "Pipfile",
+			expected: map[string]string{
+				"flask": "",
+				"six":   "",
+				"black": "dev",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		dependencies, err := scanner.Scan(filepath.Join(fixturesDir, tc.fileName))
+		if err != nil {
+			t.Fatalf("Scan(%s) returned error: %v", tc.fileName, err)
+		}
+
+		if len(dependencies) != len(tc.expected) {
+			t.Errorf("%s: expected %d dependencies, got %d", tc.fileName, len(tc.expected), len(dependencies))
+		}
+
+		for _, dep := range dependencies {
+			scope, ok := tc.expected[dep.Name]
+			if !ok {
+				t.Errorf("%s: unexpected dependency '%s'", tc.fileName, dep.Name)
+				continue
+			}
+			if dep.Scope != scope {
+				t.Errorf("%s: dependency '%s' scope = %q, expected %q", tc.fileName, dep.Name, dep.Scope, scope)
+			}
+		}
+	}
+}
+
+func TestParseRequirement(t *testing.T) {
+	testCases := []struct {
+		line      string
+		name      string
+		extras    int
+		specifier string
+		url       string
+		markers   string
+		wantErr   bool
+	}{
+		{"requests", "requests", 0, "", "", "", false},
+		{"requests[socks,security] >= 2.0", "requests", 2, ">=2.0", "", "", false},
+		{`numpy==1.24.3; python_version < "3.9"`, "numpy", 0, "==1.24.3", "", `python_version < "3.9"`, false},
+		{"pkg (>=1.0, !=1.5)", "pkg", 0, ">=1.0,!=1.5", "", "", false},
+		{"legacy===1.0-custom", "legacy", 0, "===1.0-custom", "", "", false},
+		{`pkg @ https://host/pkg.whl?a=1;b=2 ; os_name == "nt"`, "pkg", 0, "", "https://host/pkg.whl?a=1;b=2", `os_name == "nt"`, false},
+		{"./local/path", "", 0, "", "", "", true},
+		{"pkg 1.0", "", 0, "", "", "", true},
+	}
+
+	for _, tc := range testCases {
+		req, err := ParseRequirement(tc.line)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("ParseRequirement(%q) expected an error", tc.line)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseRequirement(%q) returned error: %v", tc.line, err)
+			continue
+		}
+
+		if req.Name != tc.name || len(req.Extras) != tc.extras || req.Specifier != tc.specifier ||
+			req.URL != tc.url || req.Markers != tc.markers {
+			t.Errorf("ParseRequirement(%q) = %+v", tc.line, req)
+		}
+	}
+}
+
+func TestNormalizeName(t *testing.T) {
+	testCases := map[string]string{
+		"Flask":              "flask",
+		"importlib_metadata": "importlib-metadata",
+		"zope.interface":     "zope-interface",
+		"Some__Weird-.Name":  "some-weird-name",
+	}
+
+	for name, expected := range testCases {
+		if result := NormalizeName(name); result != expected {
+			t.Errorf("NormalizeName(%s) = %s, expected %s", name, result, expected)
+		}
+	}
+}
+
+func TestScanRequirements(t *testing.T) {
+	scanner := NewScanner()
+
+	dependencies, err := scanner.Scan(filepath.Join(fixturesDir, "requirements", "requirements.txt"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := map[string]struct {
+		version string
+		source  string
+		markers string
+		hashes  int
+	}{
+		"flask":              {"2.3.3", "", "", 0},
+		"requests":           {">=2.28,<3", "", `python_version >= "3.8"`, 0},
+		"django":             {"4.2.4", "", "", 0},
+		"importlib-metadata": {"!=6.0.0", "", `python_version < "3.10"`, 0},
+		"urllib3":            {"2.0.4", "", "", 2},
+		"private-lib":        {"UNKNOWN", "url", "", 0},
+		"example-tool":       {"UNKNOWN", "git", "", 0},
+		"helper":             {"UNKNOWN", "path", "", 0},
+		"some-package":       {"UNKNOWN", "url", "", 0},
+	}
+
+	if len(dependencies) != len(expected) {
+		t.Errorf("Expected %d dependencies, got %d", len(expected), len(dependencies))
+	}
+
+	for _, dep := range dependencies {
+		want, ok := expected[dep.Name]
+		if !ok {
+			t.Errorf("Unexpected dependency '%s'", dep.Name)
+			continue
+		}
+		if dep.Version != want.version || dep.Source != want.source || dep.Markers != want.markers || len(dep.Hashes) != want.hashes {
+			t.Errorf("%s = (%s, %q, %q, %d hashes), expected %+v", dep.Name, dep.Version, dep.Source, dep.Markers, len(dep.Hashes), want)
+		}
+	}
+}
+
+func TestInstalledLicenses(t *testing.T) {
+	scanner := NewScanner()
+
+	dependencies, err := scanner.Scan(filepath.Join(fixturesDir, "installed", "requirements.txt"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := map[string]string{
+		"requests":   "Apache-2.0",
+		"flask":      "BSD-3-Clause",
+		"modern-pkg": "MIT OR Apache-2.0",
+		"legacy-pkg": "GPL-3.0",
+		"dual":       "GPL-2.0 OR MIT",
+		"missing":    "UNKNOWN",
+	}
+
+	for _, dep := range dependencies {
+		license, ok := expected[dep.Name]
+		if !ok {
+			t.Errorf("Unexpected dependency '%s'", dep.Name)
+			continue
+		}
+		if dep.LicenseType != license {
+			t.Errorf("%s license = %s, expected %s", dep.Name, dep.LicenseType, license)
+		}
+		if dep.Name == "modern-pkg" && dep.LicenseText == "" {
+			t.Errorf("Expected license text from the PEP 639 licenses directory for %s", dep.Name)
+		}
+	}
+}
+
+func TestTroveClassifierMapping(t *testing.T) {
+	testCases := []struct {
+		classifier string
+		expected   string
+	}{
+		{"License :: OSI Approved :: MIT License", "MIT"},
+		{"License :: OSI Approved :: Apache Software License", "Apache-2.0"},
+		{"License :: OSI Approved :: GNU Lesser General Public License v3 (LGPLv3)", "LGPL-3.0"},
+		{"License :: OSI Approved :: Mozilla Public License 2.0 (MPL 2.0)", "MPL-2.0"},
+	}
+
+	for _, tc := range testCases {
+		if result := troveLicenses[tc.classifier]; result != tc.expected {
+			t.Errorf("troveLicenses[%s] = %s, expected %s", tc.classifier, result, tc.expected)
+		}
+	}
+}
+
+func TestScanSetupFiles(t *testing.T) {
+	scanner := NewScanner()
+
+	type expectation struct {
+		version string
+		scope   string
+		license string
+	}
+
+	testCases := []struct {
+		fileName string
+		expected map[string]expectation
+	}{
+		{
+			fileName: "setup.py",
+			expected: map[string]expectation{
+				"legacy-lib": {"1.4.2", "project", "BSD-3-Clause"},
+				"requests":   {">=2.28,<3", "", "UNKNOWN"},
+				"click":      {"~=8.1", "", "UNKNOWN"},
+				"six":        {"UNKNOWN", "", "UNKNOWN"},
+				"pyyaml":     {">=6.0", "optional", "UNKNOWN"},
+			},
+		},
+		{
+			fileName: "setup.cfg",
+			expected: map[string]expectation{
+				"cfg-lib":            {"UNKNOWN", "project", "Apache-2.0"},
+				"attrs":              {">=22.1", "", "UNKNOWN"},
+				"importlib-metadata": {"UNKNOWN", "", "UNKNOWN"},
+				"pytest":             {">=7,<8", "optional", "UNKNOWN"},
+				"sphinx":             {"UNKNOWN", "optional", "UNKNOWN"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		dependencies, err := scanner.Scan(filepath.Join(fixturesDir, "setuptools", tc.fileName))
+		if err != nil {
+			t.Fatalf("Scan(%s) returned error: %v", tc.fileName, err)
+		}
+
+		if len(dependencies) != len(tc.expected) {
+			t.Errorf("%s: expected %d dependencies, got %d", tc.fileName, len(tc.expected), len(dependencies))
+		}
+
+		for _, dep := range dependencies {
+			want, ok := tc.expected[dep.Name]
+			if !ok {
+				t.Errorf("%s: unexpected dependency '%s'", tc.fileName, dep.Name)
+				continue
+			}
+			got := expectation{dep.Version, dep.Scope, dep.LicenseType}
+			if got != want {
+				t.Errorf("%s: dependency '%s' = %+v, expected %+v", tc.fileName, dep.Name, got, want)
+			}
+		}
+	}
+}
+
+func TestParseSetupPyUnbalanced(t *testing.T) {
+	// A malformed setup() call must not stop the scan
+	testCases := []string{
+		"setup(name='x', a=b])",
+		"setup(name='x', ]",
+		"setup(\n name='x'\n}\n)",
+	}
+
+	for _, source := range testCases {
+		done := make(chan SetupMetadata, 1)
+		go func() { done <- ParseSetupPy(source) }()
+
+		select {
+		case metadata := <-done:
+			if metadata.Name != "x" {
+				t.Errorf("ParseSetupPy(%q).Name = %q, expected x", source, metadata.Name)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("ParseSetupPy(%q) did not return", source)
+		}
+	}
+}
@@ -0,0 +1,377 @@
+package python
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"license-audit/pkg/types"
+)
+
+// Requirement is a parsed PEP 508 dependency specifier.
+type Requirement struct {
+	Name      string
+	Extras    []string
+	Specifier string // e.g. ">=2.0,<3"; empty when unconstrained
+	URL       string // direct reference after "@"
+	Markers   string
+}
+
+var (
+	requirementNamePattern = regexp.MustCompile(`^([A-Za-z0-9](?:[A-Za-z0-9._-]*[A-Za-z0-9])?)`)
+	nameSeparatorPattern   = regexp.MustCompile(`[-_.]+`)
+	eggFragmentPattern     = regexp.MustCompile(`[#&]egg=([A-Za-z0-9._-]+)`)
+	optionStartPattern     = regexp.MustCompile(`(^|\s)--?[A-Za-z]`)
+)
+
+// ParseRequirement parses a single PEP 508 requirement such as
+// `requests[socks]>=2.0; python_version < "3.9"` or `pkg @ https://...`.
+func ParseRequirement(line string) (Requirement, error) {
+	var req Requirement
+	rest := strings.TrimSpace(line)
+
+	match := requirementNamePattern.FindString(rest)
+	if match == "" {
+		return req, fmt.Errorf("invalid requirement: %q", line)
+	}
+	req.Name = match
+	rest = strings.TrimSpace(rest[len(match):])
+
+	if strings.HasPrefix(rest, "[") {
+		end := strings.Index(rest, "]")
+		if end == -1 {
+			return req, fmt.Errorf("unterminated extras in requirement: %q", line)
+		}
+		for _, extra := range strings.Split(rest[1:end], ",") {
+			if extra = strings.TrimSpace(extra); extra != "" {
+				req.Extras = append(req.Extras, extra)
+			}
+		}
+		rest = strings.TrimSpace(rest[end+1:])
+	}
+
+	if strings.HasPrefix(rest, "@") {
+		// A URL may itself contain ";", so markers must follow whitespace
+		rest = strings.TrimSpace(rest[1:])
+		if idx := strings.Index(rest, " ;"); idx != -1 {
+			req.Markers = strings.TrimSpace(rest[idx+2:])
+			rest = rest[:idx]
+		}
+		req.URL = strings.TrimSpace(rest)
+		if req.URL == "" {
+			return req, fmt.Errorf("missing URL in requirement: %q", line)
+		}
+		return req, nil
+	}
+
+	if idx := strings.Index(rest, ";"); idx != -1 {
+		req.Markers = strings.TrimSpace(rest[idx+1:])
+		rest = rest[:idx]
+	}
+
+	specifier := strings.TrimSpace(rest)
+	if strings.HasPrefix(specifier, "(") && strings.HasSuffix(specifier, ")") {
+		specifier = specifier[1 : len(specifier)-1]
+	}
+	req.Specifier = strings.Join(strings.Fields(specifier), "")
+
+	if req.Specifier != "" && !strings.ContainsAny(req.Specifier[:1], "=<>!~") {
+		return req, fmt.Errorf("invalid version specifier in requirement: %q", line)
+	}
+
+	return req, nil
+}
+
+// PinnedVersion returns the exact version for "==" and "===" pins.
+func (r Requirement) PinnedVersion() (string, bool) {
+	if strings.Contains(r.Specifier, ",") || strings.HasSuffix(r.Specifier, "*") {
+		return "", false
+	}
+	if version, ok := strings.CutPrefix(r.Specifier, "==="); ok {
+		return version, true
+	}
+	if version, ok := strings.CutPrefix(r.Specifier, "=="); ok {
+		return version, true
+	}
+	return "", false
+}
+
+// NormalizeName normalizes a distribution name per PEP 503.
+func NormalizeName(name string) string {
+	return strings.ToLower(nameSeparatorPattern.ReplaceAllString(name, "-"))
+}
+
+func (s *Scanner) scanRequirements(path string) ([]types.Dependency, error) {
+	constraints := make(map[string]Requirement)
+	dependencies, err := s.readRequirementsFile(path, constraints, make(map[string]bool))
+	if err != nil {
+		return nil, err
+	}
+
+	// Constraints files only pin versions of packages required elsewhere
+	for i := range dependencies {
+		if dependencies[i].Version != "UNKNOWN" {
+			continue
+		}
+		if constraint, ok := constraints[dependencies[i].Name]; ok {
+			dependencies[i].Version = requirementVersion(constraint)
+		}
+	}
+
+	return dependencies, nil
+}
+
+func (s *Scanner) readRequirementsFile(path string, constraints map[string]Requirement, visited map[string]bool) ([]types.Dependency, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		absPath = path
+	}
+	if visited[absPath] {
+		return nil, nil
+	}
+	visited[absPath] = true
+
+	lines, err := readLogicalLines(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var dependencies []types.Dependency
+	baseDir := filepath.Dir(path)
+
+	for _, line := range lines {
+		option, value := splitOption(line)
+
+		switch option {
+		case "-r", "--requirement":
+			included, err := s.readRequirementsFile(filepath.Join(baseDir, value), constraints, visited)
+			if err != nil {
+				return nil, err
+			}
+			dependencies = append(dependencies, included...)
+		case "-c", "--constraint":
+			constrained, err := s.readRequirementsFile(filepath.Join(baseDir, value), constraints, visited)
+			if err != nil {
+				return nil, err
+			}
+			for _, dep := range constrained {
+				if req, err := ParseRequirement(dep.Name + pinSpecifier(dep.Version)); err == nil {
+					constraints[dep.Name] = req
+				}
+			}
+		case "-e", "--editable":
+			if dep := s.parseDirectReference(value, baseDir, path); dep.Name != "" {
+				dependencies = append(dependencies, dep)
+			}
+		case "":
+			if dep := s.parseRequirementLine(line, baseDir, path); dep.Name != "" {
+				dependencies = append(dependencies, dep)
+			}
+		default:
+			// Global options such as --index-url or --find-links
+		}
+	}
+
+	return dependencies, nil
+}
+
+// readLogicalLines joins backslash continuations and strips comments.
+func readLogicalLines(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", filepath.Base(path), err)
+	}
+	defer file.Close()
+
+	var lines []string
+	var current strings.Builder
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		// Comments start at "#" preceded by whitespace, so URL fragments survive
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			line = ""
+		} else if idx := strings.Index(line, " #"); idx != -1 {
+			line = line[:idx]
+		} else if idx := strings.Index(line, "\t#"); idx != -1 {
+			line = line[:idx]
+		}
+
+		if strings.HasSuffix(line, "\\") {
+			current.WriteString(strings.TrimSuffix(line, "\\"))
+			current.WriteString(" ")
+			continue
+		}
+
+		current.WriteString(line)
+		if joined := strings.TrimSpace(current.String()); joined != "" {
+			lines = append(lines, joined)
+		}
+		current.Reset()
+	}
+
+	if joined := strings.TrimSpace(current.String()); joined != "" {
+		lines = append(lines, joined)
+	}
+
+	return lines, scanner.Err()
+}
+
+// splitOption splits lines like "-r base.txt" or "--constraint=c.txt" into
+// the option and its value. Requirement lines return an empty option.
+func splitOption(line string) (string, string) {
+	if !strings.HasPrefix(line, "-") {
+		return "", ""
+	}
+
+	option, value := line, ""
+	if idx := strings.IndexAny(line, " \t="); idx != -1 {
+		option, value = line[:idx], strings.TrimSpace(line[idx+1:])
+	}
+	return option, value
+}
+
+func (s *Scanner) parseRequirementLine(line, baseDir, filePath string) types.Dependency {
+	// Per-requirement options such as --hash follow the specifier
+	var hashes []string
+	if loc := optionStartPattern.FindStringIndex(line); loc != nil {
+		options := strings.Fields(line[loc[0]:])
+		line = line[:loc[0]]
+
+		for i := 0; i < len(options); i++ {
+			if hash, ok := strings.CutPrefix(options[i], "--hash="); ok {
+				hashes = append(hashes, hash)
+			} else if options[i] == "--hash" && i+1 < len(options) {
+				hashes = append(hashes, options[i+1])
+				i++
+			}
+		}
+	}
+
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return types.Dependency{}
+	}
+
+	// Bare URLs, archives and local paths are not PEP 508 requirements
+	var dep types.Dependency
+	if req, err := ParseRequirement(line); err != nil || (req.URL == "" && distributionName(line) != "") {
+		dep = s.parseDirectReference(line, baseDir, filePath)
+	} else {
+		dep = s.requirementDependency(req, filePath)
+	}
+
+	if dep.Name != "" {
+		dep.Hashes = hashes
+	}
+	return dep
+}
+
+// parseDirectReference handles editable installs and bare URL or path
+// requirements, e.g. "git+https://host/repo.git@v1#egg=name" or "./libs/pkg".
+func (s *Scanner) parseDirectReference(ref, baseDir, filePath string) types.Dependency {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return types.Dependency{}
+	}
+
+	// "-e name @ url" and "-e name[extra] @ url" are PEP 508 requirements
+	if req, err := ParseRequirement(ref); err == nil && req.URL != "" {
+		return s.requirementDependency(req, filePath)
+	}
+
+	source := "url"
+	switch {
+	case strings.HasPrefix(ref, "git+"), strings.HasPrefix(ref, "hg+"),
+		strings.HasPrefix(ref, "svn+"), strings.HasPrefix(ref, "bzr+"):
+		source = strings.SplitN(ref, "+", 2)[0]
+	case strings.HasPrefix(ref, "file:"), !strings.Contains(ref, "://"):
+		source = "path"
+	}
+
+	name := ""
+	if match := eggFragmentPattern.FindStringSubmatch(ref); match != nil {
+		name = match[1]
+	} else if source == "path" {
+		localPath := strings.TrimPrefix(ref, "file://")
+		if !filepath.IsAbs(localPath) {
+			localPath = filepath.Join(baseDir, localPath)
+		}
+		// The project itself, as in "-e ."
+		if filepath.Clean(localPath) == filepath.Clean(baseDir) {
+			return types.Dependency{}
+		}
+		name = filepath.Base(localPath)
+	} else if parsed, err := url.Parse(ref); err == nil {
+		name = distributionName(filepath.Base(parsed.Path))
+	}
+
+	if name == "" {
+		return types.Dependency{}
+	}
+
+	dep := s.newDependency(name, "UNKNOWN", filePath)
+	dep.Source = source
+	dep.SourceURL = ref
+	return dep
+}
+
+// distributionName extracts the project name from a wheel or sdist file name.
+func distributionName(fileName string) string {
+	for _, suffix := range []string{".whl", ".tar.gz", ".zip", ".tar.bz2"} {
+		if base, ok := strings.CutSuffix(fileName, suffix); ok {
+			if idx := strings.Index(base, "-"); idx > 0 {
+				return base[:idx]
+			}
+			return base
+		}
+	}
+	return ""
+}
+
+func (s *Scanner) requirementDependency(req Requirement, filePath string) types.Dependency {
+	dep := s.newDependency(req.Name, requirementVersion(req), filePath)
+	dep.Markers = req.Markers
+
+	if req.URL != "" {
+		dep.SourceURL = req.URL
+		switch {
+		case strings.HasPrefix(req.URL, "git+"):
+			dep.Source = "git"
+		case strings.HasPrefix(req.URL, "file:"):
+			dep.Source = "path"
+		default:
+			dep.Source = "url"
+		}
+	}
+
+	return dep
+}
+
+// requirementVersion reports the exact pin when there is one and the full
+// specifier otherwise.
+func requirementVersion(req Requirement) string {
+	if version, ok := req.PinnedVersion(); ok {
+		return version
+	}
+	if req.Specifier != "" {
+		return req.Specifier
+	}
+	return "UNKNOWN"
+}
+
+func pinSpecifier(version string) string {
+	if version == "UNKNOWN" {
+		return ""
+	}
+	if strings.ContainsAny(version[:1], "=<>!~") {
+		return version
+	}
+	return "==" + version
+}
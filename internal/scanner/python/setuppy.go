@@ -0,0 +1,656 @@
+package python
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"license-audit/internal/license"
+	"license-audit/pkg/types"
+)
+
+// SetupMetadata is the subset of setuptools metadata the scanner reads
+// from setup.py and setup.cfg.
+type SetupMetadata struct {
+	Name            string
+	Version         string
+	License         string
+	LicenseFiles    []string
+	Classifiers     []string
+	InstallRequires []string
+	ExtrasRequire   map[string][]string
+}
+
+func (s *Scanner) scanSetupPy(path string) ([]types.Dependency, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read setup.py: %w", err)
+	}
+
+	return s.setupDependencies(ParseSetupPy(string(data)), path), nil
+}
+
+func (s *Scanner) scanSetupCfg(path string) ([]types.Dependency, error) {
+	metadata, err := ParseSetupCfg(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.setupDependencies(metadata, path), nil
+}
+
+// setupDependencies reports the requirements of a setuptools project along
+// with the project itself, carrying its own declared license.
+func (s *Scanner) setupDependencies(metadata SetupMetadata, path string) []types.Dependency {
+	var dependencies []types.Dependency
+
+	if metadata.Name != "" {
+		version := metadata.Version
+		if version == "" {
+			version = "UNKNOWN"
+		}
+
+		project := s.newDependency(metadata.Name, version, path)
+		project.Scope = "project"
+		project.LicenseType = setupLicense(metadata)
+		project.LicenseText = readDistributionLicenseFiles(filepath.Dir(path), metadata.LicenseFiles)
+		if project.LicenseType == "UNKNOWN" && project.LicenseText != "" {
+			project.LicenseType = license.Detect(project.LicenseText)
+		}
+		dependencies = append(dependencies, project)
+	}
+
+	for _, requirement := range metadata.InstallRequires {
+		if dep := s.parseRequirement(requirement, path); dep.Name != "" {
+			dependencies = append(dependencies, dep)
+		}
+	}
+
+	for _, extra := range sortedKeys(metadata.ExtrasRequire) {
+		for _, requirement := range metadata.ExtrasRequire[extra] {
+			if dep := s.parseRequirement(requirement, path); dep.Name != "" {
+				dep.Scope = "optional"
+				dependencies = append(dependencies, dep)
+			}
+		}
+	}
+
+	return dependencies
+}
+
+// setupLicense prefers license trove classifiers over the free-form
+// license argument, mirroring how installed metadata is resolved.
+func setupLicense(metadata SetupMetadata) string {
+	var classified []string
+	for _, classifier := range metadata.Classifiers {
+		if spdx, ok := troveLicenses[strings.TrimSpace(classifier)]; ok {
+			classified = append(classified, spdx)
+		}
+	}
+	if len(classified) > 0 {
+		return strings.Join(classified, " OR ")
+	}
+
+	return normalizeLicenseField(metadata.License)
+}
+
+// ParseSetupCfg reads the [metadata], [options] and
+// [options.extras_require] sections of a setup.cfg file.
+func ParseSetupCfg(path string) (SetupMetadata, error) {
+	var metadata SetupMetadata
+
+	file, err := os.Open(path)
+	if err != nil {
+		return metadata, fmt.Errorf("failed to open setup.cfg: %w", err)
+	}
+	defer file.Close()
+
+	sections := make(map[string]map[string]string)
+	var section, key string
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, ";") {
+			continue
+		}
+
+		// Indented lines continue the previous value
+		if (line[0] == ' ' || line[0] == '\t') && key != "" {
+			sections[section][key] += "\n" + trimmed
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+			section = strings.TrimSpace(trimmed[1 : len(trimmed)-1])
+			if sections[section] == nil {
+				sections[section] = make(map[string]string)
+			}
+			key = ""
+			continue
+		}
+
+		name, value, ok := strings.Cut(trimmed, "=")
+		if !ok {
+			name, value, ok = strings.Cut(trimmed, ":")
+		}
+		if !ok || section == "" {
+			continue
+		}
+		key = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), "-", "_")
+		sections[section][key] = strings.TrimSpace(value)
+	}
+	if err := scanner.Err(); err != nil {
+		return metadata, fmt.Errorf("failed to read setup.cfg: %w", err)
+	}
+
+	meta := sections["metadata"]
+	metadata.Name = meta["name"]
+	metadata.Version = literalCfgValue(meta["version"])
+	metadata.License = literalCfgValue(meta["license"])
+	metadata.LicenseFiles = cfgList(meta["license_files"] + "\n" + meta["license_file"])
+	metadata.Classifiers = cfgList(meta["classifiers"])
+	metadata.InstallRequires = cfgList(sections["options"]["install_requires"])
+
+	if extras := sections["options.extras_require"]; len(extras) > 0 {
+		metadata.ExtrasRequire = make(map[string][]string)
+		for extra, value := range extras {
+			metadata.ExtrasRequire[extra] = cfgList(value)
+		}
+	}
+
+	return metadata, nil
+}
+
+// literalCfgValue drops "attr:" and "file:" directives, which would
+// require importing or reading other files to resolve.
+func literalCfgValue(value string) string {
+	if strings.HasPrefix(value, "attr:") || strings.HasPrefix(value, "file:") {
+		return ""
+	}
+	return value
+}
+
+// cfgList splits a dangling list (one item per line) or a comma list.
+func cfgList(value string) []string {
+	var items []string
+	for _, line := range strings.Split(value, "\n") {
+		parts := []string{line}
+		// Commas also appear in version specifiers, so only lines of plain
+		// names are treated as comma lists
+		if !strings.ContainsAny(line, "<>=!~;@") {
+			parts = strings.Split(line, ",")
+		}
+		for _, item := range parts {
+			if item = strings.TrimSpace(item); item != "" && !strings.HasPrefix(item, "#") {
+				items = append(items, item)
+			}
+		}
+	}
+	return items
+}
+
+// ParseSetupPy statically extracts literal keyword arguments of the
+// setup() call in a setup.py file. The file is never executed: arguments
+// built from anything other than literals and module-level assignments of
+// literals are ignored.
+func ParseSetupPy(source string) SetupMetadata {
+	var metadata SetupMetadata
+
+	p := &pyParser{tokens: tokenizePython(source), names: make(map[string]interface{})}
+	args := p.setupArguments()
+
+	metadata.Name, _ = args["name"].(string)
+	metadata.Version, _ = args["version"].(string)
+	metadata.License, _ = args["license"].(string)
+	metadata.LicenseFiles = stringList(args["license_files"])
+	metadata.Classifiers = stringList(args["classifiers"])
+	metadata.InstallRequires = stringList(args["install_requires"])
+
+	if extras, ok := args["extras_require"].(map[string]interface{}); ok {
+		metadata.ExtrasRequire = make(map[string][]string)
+		for extra, value := range extras {
+			metadata.ExtrasRequire[extra] = stringList(value)
+		}
+	}
+
+	return metadata
+}
+
+func stringList(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		// setuptools accepts newline separated requirement strings
+		var items []string
+		for _, line := range strings.Split(v, "\n") {
+			if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+				items = append(items, line)
+			}
+		}
+		return items
+	case []interface{}:
+		var items []string
+		for _, item := range v {
+			if str, ok := item.(string); ok {
+				items = append(items, str)
+			}
+		}
+		return items
+	}
+	return nil
+}
+
+type pyTokenKind int
+
+const (
+	pyName pyTokenKind = iota
+	pyString
+	pyNumber
+	pyOp
+	pyNewline
+	pyOther // f-strings and anything else that is not a literal
+)
+
+type pyToken struct {
+	kind  pyTokenKind
+	value string
+}
+
+// tokenizePython splits Python source into the tokens needed to read
+// literals. Newlines inside brackets are dropped, like Python's own
+// implicit line joining.
+func tokenizePython(source string) []pyToken {
+	var tokens []pyToken
+	depth := 0
+	i := 0
+
+	for i < len(source) {
+		c := source[i]
+
+		switch {
+		case c == '#':
+			for i < len(source) && source[i] != '\n' {
+				i++
+			}
+		case c == '\\' && i+1 < len(source) && source[i+1] == '\n':
+			i += 2
+		case c == '\n':
+			if depth == 0 {
+				tokens = append(tokens, pyToken{pyNewline, "\n"})
+			}
+			i++
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case isPyNameStart(c):
+			start := i
+			for i < len(source) && isPyNameChar(source[i]) {
+				i++
+			}
+			word := source[start:i]
+
+			// String prefixes such as r"", b'' or f""
+			if i < len(source) && (source[i] == '"' || source[i] == '\'') && len(word) <= 2 &&
+				strings.Trim(strings.ToLower(word), "rbuf") == "" {
+				value, end := readPyString(source, i, strings.ContainsAny(word, "rR"))
+				kind := pyString
+				if strings.ContainsAny(word, "fF") {
+					kind = pyOther
+				}
+				tokens = append(tokens, pyToken{kind, value})
+				i = end
+				continue
+			}
+			tokens = append(tokens, pyToken{pyName, word})
+		case c == '"' || c == '\'':
+			value, end := readPyString(source, i, false)
+			tokens = append(tokens, pyToken{pyString, value})
+			i = end
+		case c >= '0' && c <= '9':
+			start := i
+			for i < len(source) && (isPyNameChar(source[i]) || source[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, pyToken{pyNumber, source[start:i]})
+		default:
+			op := string(c)
+			if i+1 < len(source) && strings.Contains("=!<>*/", string(c)) && source[i+1] == '=' {
+				op = source[i : i+2]
+			} else if i+1 < len(source) && c == '*' && source[i+1] == '*' {
+				op = "**"
+			}
+			switch op {
+			case "(", "[", "{":
+				depth++
+			case ")", "]", "}":
+				if depth > 0 {
+					depth--
+				}
+			}
+			tokens = append(tokens, pyToken{pyOp, op})
+			i += len(op)
+		}
+	}
+
+	return tokens
+}
+
+func isPyNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isPyNameChar(c byte) bool {
+	return isPyNameStart(c) || (c >= '0' && c <= '9')
+}
+
+// readPyString reads a (possibly triple-quoted) string literal starting at
+// the opening quote and returns its value and the index after it.
+func readPyString(source string, start int, raw bool) (string, int) {
+	quote := source[start : start+1]
+	if strings.HasPrefix(source[start:], strings.Repeat(quote, 3)) {
+		quote = strings.Repeat(quote, 3)
+	}
+
+	var sb strings.Builder
+	i := start + len(quote)
+	for i < len(source) {
+		if strings.HasPrefix(source[i:], quote) {
+			return sb.String(), i + len(quote)
+		}
+		if source[i] == '\\' && i+1 < len(source) {
+			if raw {
+				sb.WriteByte(source[i])
+				sb.WriteByte(source[i+1])
+			} else {
+				switch source[i+1] {
+				case 'n':
+					sb.WriteByte('\n')
+				case 't':
+					sb.WriteByte('\t')
+				case '\n':
+					// Escaped newline continues the string
+				default:
+					sb.WriteByte(source[i+1])
+				}
+			}
+			i += 2
+			continue
+		}
+		if len(quote) == 1 && source[i] == '\n' {
+			break
+		}
+		sb.WriteByte(source[i])
+		i++
+	}
+
+	return sb.String(), i
+}
+
+// pyParser evaluates Python literals from a token stream.
+type pyParser struct {
+	tokens []pyToken
+	pos    int
+	names  map[string]interface{} // module-level assignments of literals
+}
+
+func (p *pyParser) peek(offset int) pyToken {
+	if p.pos+offset < len(p.tokens) {
+		return p.tokens[p.pos+offset]
+	}
+	return pyToken{kind: pyNewline}
+}
+
+func (p *pyParser) isOp(offset int, op string) bool {
+	t := p.peek(offset)
+	return t.kind == pyOp && t.value == op
+}
+
+// setupArguments records module-level literal assignments and returns the
+// keyword arguments of the first setup() call.
+func (p *pyParser) setupArguments() map[string]interface{} {
+	lineStart := true
+
+	for p.pos < len(p.tokens) {
+		t := p.peek(0)
+
+		switch {
+		case t.kind == pyNewline:
+			lineStart = true
+			p.pos++
+			continue
+		case lineStart && t.kind == pyName && p.isOp(1, "="):
+			p.pos += 2
+			if value, ok := p.parseExpression(); ok {
+				p.names[t.value] = value
+			}
+		case t.kind == pyName && t.value == "setup" && p.isOp(1, "(") && (p.pos == 0 || p.tokens[p.pos-1].value != "def"):
+			p.pos += 2
+			return p.parseCallArguments()
+		default:
+			p.pos++
+		}
+		lineStart = false
+	}
+
+	return map[string]interface{}{}
+}
+
+// parseCallArguments reads keyword arguments up to the closing parenthesis.
+// Positional arguments and **kwargs expansions are skipped. An unbalanced
+// call ends the arguments at the stray bracket or line break.
+func (p *pyParser) parseCallArguments() map[string]interface{} {
+	args := make(map[string]interface{})
+
+	for p.pos < len(p.tokens) && !p.isOp(0, ")") {
+		start := p.pos
+		if t := p.peek(0); t.kind == pyName && p.isOp(1, "=") {
+			p.pos += 2
+			if value, ok := p.parseExpression(); ok {
+				args[t.value] = value
+			}
+		} else {
+			p.skipExpression()
+		}
+
+		if p.isOp(0, ",") {
+			p.pos++
+		} else if p.pos == start {
+			return args
+		}
+	}
+	p.pos++
+
+	return args
+}
+
+// parseExpression parses a literal, optionally joined with "+". On failure
+// the rest of the expression is skipped.
+func (p *pyParser) parseExpression() (interface{}, bool) {
+	value, ok := p.parseLiteral()
+	for ok && p.isOp(0, "+") {
+		p.pos++
+		var next interface{}
+		next, ok = p.parseLiteral()
+		if !ok {
+			break
+		}
+		value, ok = addPyValues(value, next)
+	}
+
+	// Method calls, attribute access or comprehensions after the literal
+	if ok && !p.atExpressionEnd() {
+		ok = false
+	}
+	if !ok {
+		p.skipExpression()
+	}
+	return value, ok
+}
+
+func (p *pyParser) atExpressionEnd() bool {
+	t := p.peek(0)
+	if t.kind == pyNewline {
+		return true
+	}
+	return t.kind == pyOp && (t.value == "," || t.value == ")" || t.value == "]" || t.value == "}" || t.value == ":")
+}
+
+func (p *pyParser) parseLiteral() (interface{}, bool) {
+	t := p.peek(0)
+
+	switch t.kind {
+	case pyString:
+		// Adjacent string literals are concatenated
+		var sb strings.Builder
+		for p.peek(0).kind == pyString {
+			sb.WriteString(p.peek(0).value)
+			p.pos++
+		}
+		return sb.String(), true
+	case pyNumber:
+		p.pos++
+		return t.value, true
+	case pyName:
+		switch t.value {
+		case "True":
+			p.pos++
+			return true, true
+		case "False":
+			p.pos++
+			return false, true
+		case "None":
+			p.pos++
+			return nil, true
+		case "dict":
+			if p.isOp(1, "(") {
+				p.pos += 2
+				return p.parseCallArguments(), true
+			}
+		}
+		if value, ok := p.names[t.value]; ok && !p.isOp(1, "(") && !p.isOp(1, ".") && !p.isOp(1, "[") {
+			p.pos++
+			return value, true
+		}
+		return nil, false
+	case pyOp:
+		switch t.value {
+		case "[":
+			return p.parseSequence("]")
+		case "(":
+			return p.parseSequence(")")
+		case "{":
+			return p.parseDict()
+		}
+	}
+
+	return nil, false
+}
+
+func (p *pyParser) parseSequence(closing string) (interface{}, bool) {
+	p.pos++
+	items := []interface{}{}
+
+	for !p.isOp(0, closing) {
+		if p.pos >= len(p.tokens) {
+			return nil, false
+		}
+		item, ok := p.parseExpression()
+		if !ok {
+			p.skipTo(closing)
+			return nil, false
+		}
+		items = append(items, item)
+		if p.isOp(0, ",") {
+			p.pos++
+		} else if !p.isOp(0, closing) {
+			p.skipTo(closing)
+			return nil, false
+		}
+	}
+	p.pos++
+
+	return items, true
+}
+
+func (p *pyParser) parseDict() (interface{}, bool) {
+	p.pos++
+	dict := make(map[string]interface{})
+
+	for !p.isOp(0, "}") {
+		if p.pos >= len(p.tokens) {
+			return nil, false
+		}
+		key, ok := p.parseLiteral()
+		keyStr, isString := key.(string)
+		if !ok || !isString || !p.isOp(0, ":") {
+			p.skipTo("}")
+			return nil, false
+		}
+		p.pos++
+
+		value, ok := p.parseExpression()
+		if !ok {
+			p.skipTo("}")
+			return nil, false
+		}
+		dict[keyStr] = value
+
+		if p.isOp(0, ",") {
+			p.pos++
+		}
+	}
+	p.pos++
+
+	return dict, true
+}
+
+// skipExpression advances past the current expression, stopping before a
+// comma or closing bracket at the same nesting level.
+func (p *pyParser) skipExpression() {
+	depth := 0
+	for p.pos < len(p.tokens) {
+		t := p.peek(0)
+		if depth == 0 && (t.kind == pyNewline || (t.kind == pyOp && (t.value == "," || t.value == ")" || t.value == "]" || t.value == "}"))) {
+			return
+		}
+		if t.kind == pyOp {
+			switch t.value {
+			case "(", "[", "{":
+				depth++
+			case ")", "]", "}":
+				depth--
+			}
+		}
+		p.pos++
+	}
+}
+
+// skipTo advances past the closing bracket of the current sequence.
+func (p *pyParser) skipTo(closing string) {
+	for p.pos < len(p.tokens) && !p.isOp(0, closing) {
+		p.skipExpression()
+		if p.isOp(0, ",") {
+			p.pos++
+		} else if !p.isOp(0, closing) {
+			p.pos++
+		}
+	}
+	p.pos++
+}
+
+func addPyValues(left, right interface{}) (interface{}, bool) {
+	switch l := left.(type) {
+	case string:
+		if r, ok := right.(string); ok {
+			return l + r, true
+		}
+	case []interface{}:
+		if r, ok := right.([]interface{}); ok {
+			return append(append([]interface{}{}, l...), r...), true
+		}
+	}
+	return nil, false
+}
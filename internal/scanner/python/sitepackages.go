@@ -0,0 +1,339 @@
+package python
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"license-audit/internal/license"
+	"license-audit/pkg/types"
+)
+
+// Distribution is an installed package read from a *.dist-info (or legacy
+// *.egg-info) metadata directory.
+type Distribution struct {
+	Name        string
+	Version     string
+	LicenseType string
+	LicenseText string
+	Homepage    string
+}
+
+// troveLicenses maps "License ::" trove classifiers to SPDX identifiers.
+var troveLicenses = map[string]string{
+	"License :: OSI Approved :: MIT License":                                                "MIT",
+	"License :: OSI Approved :: MIT No Attribution License (MIT-0)":                         "MIT-0",
+	"License :: OSI Approved :: Apache Software License":                                    "Apache-2.0",
+	"License :: OSI Approved :: BSD License":                                                "BSD-3-Clause",
+	"License :: OSI Approved :: ISC License (ISCL)":                                         "ISC",
+	"License :: OSI Approved :: Python Software Foundation License":                         "PSF-2.0",
+	"License :: OSI Approved :: Mozilla Public License 1.1 (MPL 1.1)":                       "MPL-1.1",
+	"License :: OSI Approved :: Mozilla Public License 2.0 (MPL 2.0)":                       "MPL-2.0",
+	"License :: OSI Approved :: GNU General Public License v2 (GPLv2)":                      "GPL-2.0",
+	"License :: OSI Approved :: GNU General Public License v2 or later (GPLv2+)":            "GPL-2.0-or-later",
+	"License :: OSI Approved :: GNU General Public License v3 (GPLv3)":                      "GPL-3.0",
+	"License :: OSI Approved :: GNU General Public License v3 or later (GPLv3+)":            "GPL-3.0-or-later",
+	"License :: OSI Approved :: GNU Lesser General Public License v2 (LGPLv2)":              "LGPL-2.0",
+	"License :: OSI Approved :: GNU Lesser General Public License v2 or later (LGPLv2+)":    "LGPL-2.0-or-later",
+	"License :: OSI Approved :: GNU Lesser General Public License v3 (LGPLv3)":              "LGPL-3.0",
+	"License :: OSI Approved :: GNU Lesser General Public License v3 or later (LGPLv3+)":    "LGPL-3.0-or-later",
+	"License :: OSI Approved :: GNU Library or Lesser General Public License (LGPL)":        "LGPL-2.0-or-later",
+	"License :: OSI Approved :: GNU Affero General Public License v3":                       "AGPL-3.0",
+	"License :: OSI Approved :: GNU Affero General Public License v3 or later (AGPLv3+)":    "AGPL-3.0-or-later",
+	"License :: OSI Approved :: Eclipse Public License 1.0 (EPL-1.0)":                       "EPL-1.0",
+	"License :: OSI Approved :: Eclipse Public License 2.0 (EPL-2.0)":                       "EPL-2.0",
+	"License :: OSI Approved :: Common Development and Distribution License 1.0 (CDDL-1.0)": "CDDL-1.0",
+	"License :: OSI Approved :: Artistic License":                                           "Artistic-2.0",
+	"License :: OSI Approved :: Zope Public License":                                        "ZPL-2.1",
+	"License :: OSI Approved :: zlib/libpng License":                                        "Zlib",
+	"License :: OSI Approved :: The Unlicense (Unlicense)":                                  "Unlicense",
+	"License :: OSI Approved :: Boost Software License 1.0 (BSL-1.0)":                       "BSL-1.0",
+	"License :: OSI Approved :: Academic Free License (AFL)":                                "AFL-3.0",
+	"License :: OSI Approved :: Historical Permission Notice and Disclaimer (HPND)":         "HPND",
+	"License :: OSI Approved :: Universal Permissive License (UPL)":                         "UPL-1.0",
+	"License :: CC0 1.0 Universal (CC0 1.0) Public Domain Dedication":                       "CC0-1.0",
+	"License :: Public Domain":                                                              "Public-Domain",
+	"License :: Other/Proprietary License":                                                  "PROPRIETARY",
+}
+
+// licenseAliases normalizes free-form values of the License metadata field.
+var licenseAliases = map[string]string{
+	"mit":                     "MIT",
+	"mit license":             "MIT",
+	"apache":                  "Apache-2.0",
+	"apache 2":                "Apache-2.0",
+	"apache 2.0":              "Apache-2.0",
+	"apache-2":                "Apache-2.0",
+	"apache-2.0":              "Apache-2.0",
+	"apache license 2.0":      "Apache-2.0",
+	"apache software license": "Apache-2.0",
+	"bsd":                     "BSD-3-Clause",
+	"bsd license":             "BSD-3-Clause",
+	"new bsd":                 "BSD-3-Clause",
+	"bsd-3-clause":            "BSD-3-Clause",
+	"3-clause bsd":            "BSD-3-Clause",
+	"bsd-2-clause":            "BSD-2-Clause",
+	"isc":                     "ISC",
+	"psf":                     "PSF-2.0",
+	"mpl-2.0":                 "MPL-2.0",
+	"mpl 2.0":                 "MPL-2.0",
+	"lgpl":                    "LGPL-2.0-or-later",
+	"gpl":                     "GPL-2.0-or-later",
+	"gplv2":                   "GPL-2.0",
+	"gplv3":                   "GPL-3.0",
+	"public domain":           "Public-Domain",
+}
+
+// virtualenvPaths returns the virtualenvs that may hold the installed
+// packages for a project in dir: configured paths, .venv, venv and the
+// active $VIRTUAL_ENV.
+func (s *Scanner) virtualenvPaths(dir string) []string {
+	var paths []string
+	for _, configured := range s.VirtualenvPaths {
+		if !filepath.IsAbs(configured) {
+			configured = filepath.Join(dir, configured)
+		}
+		paths = append(paths, configured)
+	}
+
+	paths = append(paths, filepath.Join(dir, ".venv"), filepath.Join(dir, "venv"))
+
+	if active := os.Getenv("VIRTUAL_ENV"); active != "" {
+		paths = append(paths, active)
+	}
+
+	return paths
+}
+
+// sitePackagesDirs expands a virtualenv (or a site-packages directory
+// given directly) into the site-packages directories it contains.
+func sitePackagesDirs(venv string) []string {
+	var dirs []string
+
+	for _, pattern := range []string{
+		filepath.Join(venv, "lib", "python*", "site-packages"),
+		filepath.Join(venv, "lib64", "python*", "site-packages"),
+		filepath.Join(venv, "Lib", "site-packages"),
+	} {
+		matches, _ := filepath.Glob(pattern)
+		dirs = append(dirs, matches...)
+	}
+
+	if len(dirs) == 0 {
+		if matches, _ := filepath.Glob(filepath.Join(venv, "*.dist-info")); len(matches) > 0 {
+			dirs = append(dirs, venv)
+		}
+	}
+
+	return dirs
+}
+
+// installedDistributions indexes the distributions installed in every
+// virtualenv found for dir by their normalized name.
+func (s *Scanner) installedDistributions(dir string) map[string]Distribution {
+	distributions := make(map[string]Distribution)
+
+	for _, venv := range s.virtualenvPaths(dir) {
+		for _, sitePackages := range sitePackagesDirs(venv) {
+			for name, dist := range s.readSitePackages(sitePackages) {
+				if _, exists := distributions[name]; !exists {
+					distributions[name] = dist
+				}
+			}
+		}
+	}
+
+	return distributions
+}
+
+func (s *Scanner) readSitePackages(sitePackages string) map[string]Distribution {
+	if cached, ok := s.sitePackagesCache[sitePackages]; ok {
+		return cached
+	}
+
+	distributions := make(map[string]Distribution)
+
+	var metadataFiles []string
+	distInfos, _ := filepath.Glob(filepath.Join(sitePackages, "*.dist-info", "METADATA"))
+	eggInfos, _ := filepath.Glob(filepath.Join(sitePackages, "*.egg-info", "PKG-INFO"))
+	metadataFiles = append(metadataFiles, distInfos...)
+	metadataFiles = append(metadataFiles, eggInfos...)
+
+	for _, metadataFile := range metadataFiles {
+		dist, ok := s.readDistribution(metadataFile)
+		if !ok {
+			continue
+		}
+		name := NormalizeName(dist.Name)
+		if _, exists := distributions[name]; !exists {
+			distributions[name] = dist
+		}
+	}
+
+	if s.sitePackagesCache == nil {
+		s.sitePackagesCache = make(map[string]map[string]Distribution)
+	}
+	s.sitePackagesCache[sitePackages] = distributions
+
+	return distributions
+}
+
+// readDistribution parses the core metadata file of an installed
+// distribution, resolving its license from License-Expression, trove
+// classifiers, the License field and bundled license files in that order.
+func (s *Scanner) readDistribution(metadataFile string) (Distribution, bool) {
+	headers, err := readMetadataHeaders(metadataFile)
+	if err != nil || len(headers["name"]) == 0 {
+		return Distribution{}, false
+	}
+
+	dist := Distribution{
+		Name:        headers["name"][0],
+		LicenseType: "UNKNOWN",
+	}
+	if versions := headers["version"]; len(versions) > 0 {
+		dist.Version = versions[0]
+	}
+	if homepages := headers["home-page"]; len(homepages) > 0 {
+		dist.Homepage = homepages[0]
+	}
+
+	infoDir := filepath.Dir(metadataFile)
+	dist.LicenseText = readDistributionLicenseFiles(infoDir, headers["license-file"])
+
+	var classified []string
+	for _, classifier := range headers["classifier"] {
+		if spdx, ok := troveLicenses[classifier]; ok {
+			classified = append(classified, spdx)
+		}
+	}
+
+	switch {
+	case len(headers["license-expression"]) > 0:
+		dist.LicenseType = headers["license-expression"][0]
+	case len(classified) == 1:
+		dist.LicenseType = classified[0]
+	case len(classified) > 1:
+		sort.Strings(classified)
+		dist.LicenseType = strings.Join(classified, " OR ")
+	case len(headers["license"]) > 0:
+		dist.LicenseType = normalizeLicenseField(headers["license"][0])
+	}
+
+	if dist.LicenseType == "UNKNOWN" && dist.LicenseText != "" {
+		dist.LicenseType = license.Detect(dist.LicenseText)
+	}
+
+	return dist, true
+}
+
+// readMetadataHeaders reads the RFC 822 style header block of a METADATA
+// or PKG-INFO file. Header names are lower-cased and may repeat.
+func readMetadataHeaders(path string) (map[string][]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	headers := make(map[string][]string)
+	var lastKey string
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		// The description body follows the first blank line
+		if line == "" {
+			break
+		}
+
+		// Continuation lines (e.g. a multi-line License field) are indented
+		if (line[0] == ' ' || line[0] == '\t') && lastKey != "" {
+			values := headers[lastKey]
+			values[len(values)-1] += "\n" + strings.TrimSpace(line)
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		lastKey = strings.ToLower(strings.TrimSpace(key))
+		headers[lastKey] = append(headers[lastKey], strings.TrimSpace(value))
+	}
+
+	return headers, scanner.Err()
+}
+
+// readDistributionLicenseFiles reads the PEP 639 licenses/ directory, the
+// License-File entries, or license files placed directly in the info dir.
+func readDistributionLicenseFiles(infoDir string, licenseFiles []string) string {
+	var paths []string
+	for _, licenseFile := range licenseFiles {
+		paths = append(paths, filepath.Join(infoDir, "licenses", licenseFile), filepath.Join(infoDir, licenseFile))
+	}
+	if matches, _ := filepath.Glob(filepath.Join(infoDir, "licenses", "*")); len(matches) > 0 {
+		paths = append(paths, matches...)
+	}
+
+	for _, path := range paths {
+		if info, err := os.Stat(path); err != nil || info.IsDir() {
+			continue
+		}
+		if data, err := os.ReadFile(path); err == nil {
+			return string(data)
+		}
+	}
+
+	return license.ReadFile(infoDir)
+}
+
+// normalizeLicenseField maps the free-form License field to an SPDX
+// identifier where possible. Fields holding a full license text are
+// classified by content.
+func normalizeLicenseField(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" || strings.EqualFold(value, "UNKNOWN") {
+		return "UNKNOWN"
+	}
+
+	if strings.Contains(value, "\n") || len(value) > 80 {
+		return license.Detect(value)
+	}
+
+	if spdx, ok := licenseAliases[strings.ToLower(value)]; ok {
+		return spdx
+	}
+
+	return value
+}
+
+// applyInstalledLicenses fills in licenses for dependencies that are
+// installed in a virtualenv next to the scanned file.
+func (s *Scanner) applyInstalledLicenses(dir string, dependencies []types.Dependency) {
+	distributions := s.installedDistributions(dir)
+	if len(distributions) == 0 {
+		return
+	}
+
+	for i := range dependencies {
+		dist, ok := distributions[NormalizeName(dependencies[i].Name)]
+		if !ok {
+			continue
+		}
+
+		if dependencies[i].LicenseType == "UNKNOWN" || dependencies[i].LicenseType == "" {
+			dependencies[i].LicenseType = dist.LicenseType
+		}
+		if dependencies[i].LicenseText == "" {
+			dependencies[i].LicenseText = dist.LicenseText
+		}
+		if dependencies[i].Homepage == "" {
+			dependencies[i].Homepage = dist.Homepage
+		}
+	}
+}
@@ -0,0 +1,248 @@
+package ruby
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"license-audit/pkg/types"
+)
+
+// Gemspec is what can be read statically from a .gemspec file.
+type Gemspec struct {
+	Name                    string
+	Version                 string
+	Licenses                []string
+	Homepage                string
+	Dependencies            []GemRequirement
+	DevelopmentDependencies []GemRequirement
+}
+
+// GemRequirement is a dependency declared with add_dependency and friends.
+type GemRequirement struct {
+	Name         string
+	Requirements []string
+}
+
+var (
+	specBlockPattern   = regexp.MustCompile(`Gem::Specification\.new\s*(?:do|\{)\s*\|\s*(\w+)\s*\|`)
+	rubyStringPattern  = regexp.MustCompile(`"((?:[^"\\]|\\.)*)"|'((?:[^'\\]|\\.)*)'|%q([<{(\[])(.*?)[>})\]]|%w[\[(]([^\])]*)[\])]`)
+	versionConstant    = regexp.MustCompile(`^([A-Z]\w*(?:::[A-Z]\w*)*)$`)
+	versionAssignRegex = regexp.MustCompile(`\bVERSION\s*=\s*["']([^"']+)["']`)
+)
+
+// scanGemspec reports the gem a .gemspec describes, with its license,
+// followed by its runtime and development dependencies.
+func (s *Scanner) scanGemspec(path string) ([]types.Dependency, error) {
+	spec, err := ParseGemspec(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var dependencies []types.Dependency
+
+	if spec.Name != "" {
+		project := s.newDependency(spec.Name, spec.Version, path)
+		project.Scope = "project"
+		project.Homepage = spec.Homepage
+		if len(spec.Licenses) > 0 {
+			project.LicenseType = strings.Join(spec.Licenses, " OR ")
+		}
+		dependencies = append(dependencies, project)
+	}
+
+	for _, group := range []struct {
+		requirements []GemRequirement
+		scope        string
+	}{
+		{spec.Dependencies, ""},
+		{spec.DevelopmentDependencies, "dev"},
+	} {
+		for _, req := range group.requirements {
+			dep := s.newDependency(req.Name, strings.Join(req.Requirements, ", "), path)
+			dep.Scope = group.scope
+			dependencies = append(dependencies, dep)
+		}
+	}
+
+	return dependencies, nil
+}
+
+// ParseGemspec reads a .gemspec without evaluating it. Only literal
+// values are understood; a version given as a constant such as
+// Acme::VERSION is looked up in the gem's lib/**/version.rb.
+func ParseGemspec(path string) (*Gemspec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
+	}
+
+	source := string(data)
+	match := specBlockPattern.FindStringSubmatch(source)
+	if match == nil {
+		return nil, fmt.Errorf("failed to parse %s: no Gem::Specification block", filepath.Base(path))
+	}
+	receiver := match[1] + "."
+
+	spec := &Gemspec{}
+	for _, statement := range rubyStatements(source) {
+		rest, ok := strings.CutPrefix(statement, receiver)
+		if !ok {
+			continue
+		}
+
+		attribute, value, isAssignment := strings.Cut(rest, "=")
+		attribute = strings.TrimSpace(attribute)
+
+		if isAssignment && !strings.ContainsAny(attribute, " (") {
+			values := rubyStrings(value)
+			switch attribute {
+			case "name":
+				spec.Name = first(values)
+			case "version":
+				spec.Version = first(values)
+				if spec.Version == "" {
+					spec.Version = resolveVersionConstant(filepath.Dir(path), value)
+				}
+			case "license", "licenses":
+				spec.Licenses = append(spec.Licenses, values...)
+			case "homepage":
+				spec.Homepage = first(values)
+			}
+			continue
+		}
+
+		method, args := splitRubyCall(rest)
+		values := rubyStrings(args)
+		if len(values) == 0 {
+			continue
+		}
+		req := GemRequirement{Name: values[0], Requirements: values[1:]}
+
+		switch method {
+		case "add_dependency", "add_runtime_dependency":
+			spec.Dependencies = append(spec.Dependencies, req)
+		case "add_development_dependency":
+			spec.DevelopmentDependencies = append(spec.DevelopmentDependencies, req)
+		}
+	}
+
+	return spec, nil
+}
+
+// rubyStatements splits Ruby source into logical lines, dropping comments
+// and joining lines while brackets are open or a line ends with a comma.
+func rubyStatements(source string) []string {
+	var statements []string
+	var current strings.Builder
+	depth := 0
+
+	for _, line := range strings.Split(source, "\n") {
+		line = strings.TrimSpace(stripRubyComment(line))
+		if line == "" {
+			continue
+		}
+
+		if current.Len() > 0 {
+			current.WriteByte(' ')
+		}
+		current.WriteString(line)
+		depth += strings.Count(line, "[") + strings.Count(line, "(") -
+			strings.Count(line, "]") - strings.Count(line, ")")
+
+		if depth <= 0 && !strings.HasSuffix(line, ",") {
+			statements = append(statements, current.String())
+			current.Reset()
+			depth = 0
+		}
+	}
+
+	if current.Len() > 0 {
+		statements = append(statements, current.String())
+	}
+	return statements
+}
+
+// stripRubyComment removes a trailing # comment outside of strings.
+func stripRubyComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			// "#{" only occurs inside strings, which are handled above
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// splitRubyCall splits `add_dependency "x", "~> 1"` and
+// `add_dependency("x", "~> 1")` into the method name and its arguments.
+func splitRubyCall(call string) (string, string) {
+	end := strings.IndexAny(call, " (")
+	if end == -1 {
+		return call, ""
+	}
+	return call[:end], call[end:]
+}
+
+// rubyStrings returns the literal strings in an expression: quoted
+// strings, %q<> literals and the words of %w[] arrays.
+func rubyStrings(expression string) []string {
+	var values []string
+	for _, match := range rubyStringPattern.FindAllStringSubmatch(expression, -1) {
+		switch {
+		case match[5] != "":
+			values = append(values, strings.Fields(match[5])...)
+		case match[3] != "":
+			values = append(values, match[4])
+		default:
+			values = append(values, match[1]+match[2])
+		}
+	}
+	return values
+}
+
+// resolveVersionConstant finds the value of a constant like Acme::VERSION
+// in the version.rb files under the gem's lib directory.
+func resolveVersionConstant(dir, expression string) string {
+	expression = strings.TrimSuffix(strings.TrimSpace(expression), ".freeze")
+	if !versionConstant.MatchString(expression) || !strings.HasSuffix(expression, "VERSION") {
+		return ""
+	}
+
+	var version string
+	filepath.WalkDir(filepath.Join(dir, "lib"), func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() || d.Name() != "version.rb" || version != "" {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		if match := versionAssignRegex.FindStringSubmatch(string(data)); match != nil {
+			version = match[1]
+		}
+		return nil
+	})
+
+	return version
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
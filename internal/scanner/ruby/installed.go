@@ -0,0 +1,225 @@
+package ruby
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"license-audit/internal/license"
+	"license-audit/pkg/types"
+)
+
+// InstalledGem is a gem found in a specifications directory.
+type InstalledGem struct {
+	Name        string
+	Version     string
+	LicenseType string
+	LicenseText string
+	Homepage    string
+}
+
+// gemHomes returns the directories that may hold the installed gems for a
+// project in dir: configured paths, the BUNDLE_PATH from .bundle/config,
+// vendor/bundle and $GEM_HOME.
+func (s *Scanner) gemHomes(dir string) []string {
+	var paths []string
+	for _, configured := range s.GemPaths {
+		if !filepath.IsAbs(configured) {
+			configured = filepath.Join(dir, configured)
+		}
+		paths = append(paths, configured)
+	}
+
+	if bundlePath := bundleConfigPath(dir); bundlePath != "" {
+		paths = append(paths, bundlePath)
+	}
+	paths = append(paths, filepath.Join(dir, "vendor", "bundle"))
+
+	if gemHome := os.Getenv("GEM_HOME"); gemHome != "" {
+		paths = append(paths, gemHome)
+	}
+
+	return paths
+}
+
+// isInstalledGemspec reports whether a .gemspec belongs to an installed
+// gem rather than to a project: it is in a gem home's specifications
+// directory (one with gems/ or cache/ beside it), inside one of its gems,
+// or under a configured gem home.
+// Installed gems are read through the project that requires them.
+func (s *Scanner) isInstalledGemspec(path string) bool {
+	path = filepath.Clean(path)
+	for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+		switch filepath.Base(dir) {
+		case "specifications":
+			if isGemHome(filepath.Dir(dir)) {
+				return true
+			}
+		case "gems":
+			if isDir(filepath.Join(filepath.Dir(dir), "specifications")) {
+				return true
+			}
+		}
+		if next := filepath.Dir(dir); next == dir {
+			break
+		}
+	}
+
+	gemHomes := s.GemPaths
+	if gemHome := os.Getenv("GEM_HOME"); gemHome != "" {
+		gemHomes = append(gemHomes[:len(gemHomes):len(gemHomes)], gemHome)
+	}
+	for _, gemHome := range gemHomes {
+		// Relative gem paths depend on the project, which is unknown here
+		if !filepath.IsAbs(gemHome) {
+			continue
+		}
+		if rel, err := filepath.Rel(gemHome, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return true
+		}
+	}
+	return false
+}
+
+// isGemHome reports whether dir is laid out like a gem home, as opposed to
+// a project that happens to have a specifications directory.
+func isGemHome(dir string) bool {
+	return isDir(filepath.Join(dir, "gems")) || isDir(filepath.Join(dir, "cache"))
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// bundleConfigPath reads BUNDLE_PATH from the project's .bundle/config.
+func bundleConfigPath(dir string) string {
+	data, err := os.ReadFile(filepath.Join(dir, ".bundle", "config"))
+	if err != nil {
+		return ""
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(key) != "BUNDLE_PATH" {
+			continue
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+		if value == "" {
+			return ""
+		}
+		if !filepath.IsAbs(value) {
+			value = filepath.Join(dir, value)
+		}
+		return value
+	}
+
+	return ""
+}
+
+// specificationDirs expands a gem home (or a bundle path, which nests gem
+// homes under ruby/<version>) into its specifications directories.
+func specificationDirs(gemHome string) []string {
+	var dirs []string
+
+	for _, pattern := range []string{
+		filepath.Join(gemHome, "specifications"),
+		filepath.Join(gemHome, "ruby", "*", "specifications"),
+	} {
+		matches, _ := filepath.Glob(pattern)
+		dirs = append(dirs, matches...)
+	}
+
+	return dirs
+}
+
+// installedGems indexes the gems installed for dir by name and version.
+func (s *Scanner) installedGems(dir string) map[string][]InstalledGem {
+	gems := make(map[string][]InstalledGem)
+
+	for _, gemHome := range s.gemHomes(dir) {
+		for _, specifications := range specificationDirs(gemHome) {
+			for name, installed := range s.readSpecifications(specifications) {
+				gems[name] = append(gems[name], installed...)
+			}
+		}
+	}
+
+	return gems
+}
+
+func (s *Scanner) readSpecifications(specifications string) map[string][]InstalledGem {
+	if cached, ok := s.specificationsCache[specifications]; ok {
+		return cached
+	}
+
+	gems := make(map[string][]InstalledGem)
+
+	specFiles, _ := filepath.Glob(filepath.Join(specifications, "*.gemspec"))
+	for _, specFile := range specFiles {
+		spec, err := ParseGemspec(specFile)
+		if err != nil || spec.Name == "" {
+			continue
+		}
+
+		installed := InstalledGem{
+			Name:        spec.Name,
+			Version:     spec.Version,
+			LicenseType: "UNKNOWN",
+			Homepage:    spec.Homepage,
+		}
+		if len(spec.Licenses) > 0 {
+			installed.LicenseType = strings.Join(spec.Licenses, " OR ")
+		}
+
+		// The unpacked gem sits in ../gems/<name>-<version>[-<platform>]
+		gemDir := filepath.Join(filepath.Dir(specifications), "gems", strings.TrimSuffix(filepath.Base(specFile), ".gemspec"))
+		installed.LicenseText = license.ReadFile(gemDir)
+		if installed.LicenseType == "UNKNOWN" && installed.LicenseText != "" {
+			installed.LicenseType = license.Detect(installed.LicenseText)
+		}
+
+		gems[spec.Name] = append(gems[spec.Name], installed)
+	}
+
+	if s.specificationsCache == nil {
+		s.specificationsCache = make(map[string]map[string][]InstalledGem)
+	}
+	s.specificationsCache[specifications] = gems
+
+	return gems
+}
+
+// applyInstalledLicenses fills in licenses for dependencies whose gems are
+// installed for the project in dir, preferring the locked version.
+func (s *Scanner) applyInstalledLicenses(dir string, dependencies []types.Dependency) {
+	gems := s.installedGems(dir)
+	if len(gems) == 0 {
+		return
+	}
+
+	for i := range dependencies {
+		candidates := gems[dependencies[i].Name]
+		if len(candidates) == 0 {
+			continue
+		}
+
+		installed := candidates[0]
+		for _, candidate := range candidates {
+			if candidate.Version == dependencies[i].Version {
+				installed = candidate
+				break
+			}
+		}
+
+		if dependencies[i].LicenseType == "UNKNOWN" || dependencies[i].LicenseType == "" {
+			dependencies[i].LicenseType = installed.LicenseType
+		}
+		if dependencies[i].LicenseText == "" {
+			dependencies[i].LicenseText = installed.LicenseText
+		}
+		if dependencies[i].Homepage == "" {
+			dependencies[i].Homepage = installed.Homepage
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package ruby
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"license-audit/pkg/types"
+)
+
+// defaultGemSource is the remote of gems that need no source annotation.
+const defaultGemSource = "https://rubygems.org/"
+
+// LockedGem is a spec from a GEM, GIT or PATH section of Gemfile.lock.
+type LockedGem struct {
+	Name     string
+	Version  string
+	Platform string
+	Source   string // rubygems, git or path
+	Remote   string
+	Revision string
+	Requires []string
+}
+
+// Lockfile is a parsed Gemfile.lock.
+type Lockfile struct {
+	Gems         []LockedGem
+	Platforms    []string
+	Dependencies map[string]string // direct dependency name -> requirement
+	Checksums    map[string]string // name (version[-platform]) -> checksum
+	BundledWith  string
+}
+
+// specPattern matches "name (version)" and "name (= requirement)" lines.
+var specPattern = regexp.MustCompile(`^([^\s(]+)(?:\s+\(([^)]*)\))?!?$`)
+
+func (s *Scanner) scanGemfileLock(path string) ([]types.Dependency, error) {
+	lockfile, err := ParseGemfileLock(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var dependencies []types.Dependency
+	locked := make(map[string]bool)
+
+	for _, gem := range lockfile.Gems {
+		locked[gem.Name] = true
+
+		dep := s.newDependency(gem.Name, gem.Version, path)
+		dep.Platform = gem.Platform
+		dep.Requires = gem.Requires
+		dep.Indirect = !hasKey(lockfile.Dependencies, gem.Name)
+
+		switch {
+		case gem.Source == "path" && gem.Remote == ".":
+			// The gem whose gemspec the Gemfile loads
+			dep.Scope = "project"
+		case gem.Source == "git":
+			dep.Source = "git"
+			dep.SourceURL = gem.Remote
+			if gem.Revision != "" {
+				dep.SourceURL += "#" + gem.Revision
+			}
+		case gem.Source == "path":
+			dep.Source = "path"
+			dep.SourceURL = gem.Remote
+		case gem.Remote != defaultGemSource && gem.Remote != "":
+			dep.Source = "rubygems"
+			dep.SourceURL = gem.Remote
+		}
+
+		key := gem.Name + " (" + gem.Version
+		if gem.Platform != "" {
+			key += "-" + gem.Platform
+		}
+		if checksum := lockfile.Checksums[key+")"]; checksum != "" {
+			dep.Hashes = []string{checksum}
+		}
+
+		dependencies = append(dependencies, dep)
+	}
+
+	// Bundler itself loads the bundle at runtime
+	if lockfile.BundledWith != "" && !locked["bundler"] {
+		dependencies = append(dependencies, s.newDependency("bundler", lockfile.BundledWith, path))
+	}
+
+	return dependencies, nil
+}
+
+// ParseGemfileLock reads every section of a Gemfile.lock.
+func ParseGemfileLock(path string) (*Lockfile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open Gemfile.lock: %w", err)
+	}
+	defer file.Close()
+
+	lockfile := &Lockfile{
+		Dependencies: make(map[string]string),
+		Checksums:    make(map[string]string),
+	}
+
+	var section string
+	var source LockedGem // source attributes shared by the section's specs
+	var current *LockedGem
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+
+		if indent == 0 {
+			section = trimmed
+			current = nil
+			source = LockedGem{}
+			switch section {
+			case "GEM":
+				source.Source = "rubygems"
+			case "GIT":
+				source.Source = "git"
+			case "PATH":
+				source.Source = "path"
+			}
+			continue
+		}
+
+		switch section {
+		case "GEM", "GIT", "PATH":
+			switch {
+			case indent == 2:
+				key, value, _ := strings.Cut(trimmed, ":")
+				switch key {
+				case "remote":
+					source.Remote = strings.TrimSpace(value)
+				case "revision":
+					source.Revision = strings.TrimSpace(value)
+				}
+			case indent == 4:
+				name, version := parseSpec(trimmed)
+				if name == "" {
+					continue
+				}
+				gem := source
+				gem.Name = name
+				gem.Version, gem.Platform = splitPlatform(version)
+				gem.Requires = nil
+				lockfile.Gems = append(lockfile.Gems, gem)
+				current = &lockfile.Gems[len(lockfile.Gems)-1]
+			case indent >= 6 && current != nil:
+				if name, _ := parseSpec(trimmed); name != "" {
+					current.Requires = append(current.Requires, name)
+				}
+			}
+		case "PLATFORMS":
+			lockfile.Platforms = append(lockfile.Platforms, trimmed)
+		case "DEPENDENCIES":
+			if name, requirement := parseSpec(trimmed); name != "" {
+				lockfile.Dependencies[name] = requirement
+			}
+		case "CHECKSUMS":
+			if spec, checksum, ok := strings.Cut(trimmed, " sha"); ok {
+				// sha256=<hex>, written as sha256:<hex> like other lockfiles
+				algorithm, value, _ := strings.Cut(checksum, "=")
+				lockfile.Checksums[spec] = "sha" + algorithm + ":" + value
+			}
+		case "BUNDLED WITH":
+			lockfile.BundledWith = trimmed
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read Gemfile.lock: %w", err)
+	}
+
+	return lockfile, nil
+}
+
+// parseSpec splits "name (version)" into its parts. A trailing "!" marks
+// a dependency pinned to a non-default source and is dropped.
+func parseSpec(spec string) (string, string) {
+	match := specPattern.FindStringSubmatch(spec)
+	if match == nil {
+		return "", ""
+	}
+	return strings.TrimSuffix(match[1], "!"), match[2]
+}
+
+// splitPlatform separates the platform from a locked version such as
+// 1.15.0-x86_64-linux. Gem versions never contain a hyphen.
+func splitPlatform(version string) (string, string) {
+	version, platform, _ := strings.Cut(version, "-")
+	return version, platform
+}
+
+func hasKey(m map[string]string, key string) bool {
+	_, ok := m[key]
+	return ok
+}
@@ -11,7 +11,13 @@ import (
 	"license-audit/pkg/types"
 )
 
-type Scanner struct{}
+type Scanner struct {
+	// GemPaths lists extra gem homes or bundle paths to read installed
+	// licenses from, relative to the scanned file.
+	GemPaths []string
+
+	specificationsCache map[string]map[string][]InstalledGem
+}
 
 func NewScanner() *Scanner {
 	return &Scanner{}
@@ -23,10 +29,23 @@ func (s *Scanner) Name() string {
 
 func (s *Scanner) Detect(path string) bool {
 	fileName := filepath.Base(path)
-	return fileName == "Gemfile" || fileName == "Gemfile.lock" || strings.HasSuffix(fileName, ".gemspec")
+	if strings.HasSuffix(fileName, ".gemspec") {
+		return !s.isInstalledGemspec(path)
+	}
+	return fileName == "Gemfile" || fileName == "Gemfile.lock"
 }
 
 func (s *Scanner) Scan(path string) ([]types.Dependency, error) {
+	dependencies, err := s.scanFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s.applyInstalledLicenses(filepath.Dir(path), dependencies)
+	return dependencies, nil
+}
+
+func (s *Scanner) scanFile(path string) ([]types.Dependency, error) {
 	fileName := filepath.Base(path)
 
 	switch {
@@ -85,61 +104,16 @@ func (s *Scanner) scanGemfile(path string) ([]types.Dependency, error) {
 	return dependencies, scanner.Err()
 }
 
-func (s *Scanner) scanGemfileLock(path string) ([]types.Dependency, error) {
-	file, err := os.Open(path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open Gemfile.lock: %w", err)
+func (s *Scanner) newDependency(name, version, filePath string) types.Dependency {
+	if version == "" {
+		version = "UNKNOWN"
 	}
-	defer file.Close()
-
-	var dependencies []types.Dependency
-	scanner := bufio.NewScanner(file)
-	inSpecsSection := false
-
-	for scanner.Scan() {
-		line := scanner.Text()
-		trimmed := strings.TrimSpace(line)
-
-		if trimmed == "GEM" {
-			continue
-		}
 
-		if strings.HasPrefix(trimmed, "specs:") {
-			inSpecsSection = true
-			continue
-		}
-
-		if inSpecsSection {
-			if strings.HasPrefix(line, "  ") && !strings.HasPrefix(line, "    ") {
-				// This is a direct dependency line
-				parts := strings.Fields(trimmed)
-				if len(parts) >= 2 {
-					name := parts[0]
-					version := strings.Trim(parts[1], "()")
-
-					dep := types.Dependency{
-						Name:        name,
-						Version:     version,
-						LicenseType: "UNKNOWN",
-						PackageType: "ruby",
-						FilePath:    path,
-					}
-
-					dependencies = append(dependencies, dep)
-				}
-			}
-
-			if !strings.HasPrefix(line, " ") && trimmed != "" {
-				// End of specs section
-				break
-			}
-		}
+	return types.Dependency{
+		Name:        name,
+		Version:     version,
+		LicenseType: "UNKNOWN",
+		PackageType: "ruby",
+		FilePath:    filePath,
 	}
-
-	return dependencies, scanner.Err()
-}
-
-func (s *Scanner) scanGemspec(path string) ([]types.Dependency, error) {
-	// Basic implementation for .gemspec files
-	return []types.Dependency{}, nil
 }
@@ -0,0 +1,199 @@
+package ruby
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const fixturesDir = "../../../test/fixtures/ruby"
+
+func TestDetect(t *testing.T) {
+	scanner := NewScanner()
+
+	testCases := []struct {
+		path     string
+		expected bool
+	}{
+		{"Gemfile", true},
+		{"Gemfile.lock", true},
+		{"acme.gemspec", true},
+		// Installed gems are read from the project that requires them
+		{filepath.Join(fixturesDir, "installed", "vendor", "gems", "ruby", "3.2.0", "specifications", "rack-3.0.8.gemspec"), false},
+		{filepath.Join(fixturesDir, "installed", "vendor", "gems", "ruby", "3.2.0", "gems", "oldgem-0.1.0", "oldgem.gemspec"), false},
+		{"package.json", false},
+		{"", false},
+	}
+
+	for _, tc := range testCases {
+		result := scanner.Detect(tc.path)
+		if result != tc.expected {
+			t.Errorf("Detect(%s) = %v, expected %v", tc.path, result, tc.expected)
+		}
+	}
+}
+
+func TestDetectProjectSpecifications(t *testing.T) {
+	// A project's own specifications directory is not a gem home
+	dir := t.TempDir()
+	path := filepath.Join(dir, "specifications", "acme.gemspec")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	scanner := NewScanner()
+	if !scanner.Detect(path) {
+		t.Errorf("Detect(%s) = false, expected true", path)
+	}
+}
+
+func TestDetectGemHome(t *testing.T) {
+	gemHome := t.TempDir()
+	t.Setenv("GEM_HOME", gemHome)
+
+	scanner := NewScanner()
+	scanner.GemPaths = []string{"/opt/gems"}
+
+	for _, path := range []string{
+		filepath.Join(gemHome, "bundler", "gems", "acme-1a2b3c", "acme.gemspec"),
+		filepath.Join("/opt/gems", "cache", "acme.gemspec"),
+	} {
+		if scanner.Detect(path) {
+			t.Errorf("Detect(%s) = true, expected false", path)
+		}
+	}
+}
+
+func TestScanGemfileLock(t *testing.T) {
+	scanner := NewScanner()
+
+	dependencies, err := scanner.Scan(filepath.Join(fixturesDir, "Gemfile.lock"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	type expectation struct {
+		version  string
+		platform string
+		scope    string
+		source   string
+		indirect bool
+		requires int
+	}
+
+	expected := map[string]expectation{
+		"activesupport (git)":     {"7.1.0.alpha", "", "", "git", false, 2},
+		"acme-widgets":            {"0.3.0", "", "project", "", false, 2},
+		"acme-shared":             {"1.0.0", "", "", "path", false, 0},
+		"concurrent-ruby":         {"1.2.2", "", "", "", true, 0},
+		"i18n":                    {"1.14.1", "", "", "", true, 1},
+		"nokogiri (arm64-darwin)": {"1.15.4", "arm64-darwin", "", "", true, 1},
+		"nokogiri (x86_64-linux)": {"1.15.4", "x86_64-linux", "", "", true, 1},
+		"racc":                    {"1.7.1", "", "", "", true, 0},
+		"rspec":                   {"3.12.0", "", "", "", false, 0},
+		"acme-private":            {"2.1.0", "", "", "rubygems", false, 0},
+		"bundler":                 {"2.4.19", "", "", "", false, 0},
+	}
+
+	if len(dependencies) != len(expected) {
+		t.Errorf("Expected %d dependencies, got %d", len(expected), len(dependencies))
+	}
+
+	for _, dep := range dependencies {
+		key := dep.Name
+		switch {
+		case dep.Platform != "":
+			key += " (" + dep.Platform + ")"
+		case dep.Source == "git":
+			key += " (git)"
+		}
+
+		want, ok := expected[key]
+		if !ok {
+			t.Errorf("Unexpected dependency '%s'", key)
+			continue
+		}
+
+		got := expectation{dep.Version, dep.Platform, dep.Scope, dep.Source, dep.Indirect, len(dep.Requires)}
+		if got != want {
+			t.Errorf("%s = %+v, expected %+v", key, got, want)
+		}
+
+		if dep.Name == "racc" && (len(dep.Hashes) != 1 || dep.Hashes[0] != "sha256:af64124836fdd3c00e830703d7f873ea5deabde923f37006a39f5a5e0da16387") {
+			t.Errorf("Expected a checksum for racc, got %v", dep.Hashes)
+		}
+		if dep.Name == "activesupport" && dep.SourceURL != "https://github.com/rails/rails.git#6f0e9d0c1b4d5a0e0c4f1d2a3b4c5d6e7f8a9b0c" {
+			t.Errorf("Unexpected source URL for activesupport: %s", dep.SourceURL)
+		}
+	}
+}
+
+func TestScanGemspec(t *testing.T) {
+	t.Setenv("GEM_HOME", "")
+	scanner := NewScanner()
+
+	dependencies, err := scanner.Scan(filepath.Join(fixturesDir, "gemspec", "acme-widgets.gemspec"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	type expectation struct {
+		version string
+		scope   string
+		license string
+	}
+
+	expected := map[string]expectation{
+		"acme-widgets":  {"0.3.0", "project", "MIT OR Apache-2.0"},
+		"activesupport": {">= 6.1, < 8", "", "UNKNOWN"},
+		"nokogiri":      {"~> 1.15", "", "UNKNOWN"},
+		"rack":          {"UNKNOWN", "", "UNKNOWN"},
+		"rspec":         {"~> 3.12", "dev", "UNKNOWN"},
+	}
+
+	if len(dependencies) != len(expected) {
+		t.Errorf("Expected %d dependencies, got %d", len(expected), len(dependencies))
+	}
+
+	for _, dep := range dependencies {
+		want, ok := expected[dep.Name]
+		if !ok {
+			t.Errorf("Unexpected dependency '%s'", dep.Name)
+			continue
+		}
+		got := expectation{dep.Version, dep.Scope, dep.LicenseType}
+		if got != want {
+			t.Errorf("%s = %+v, expected %+v", dep.Name, got, want)
+		}
+	}
+}
+
+func TestInstalledLicenses(t *testing.T) {
+	t.Setenv("GEM_HOME", "")
+	scanner := NewScanner()
+
+	dependencies, err := scanner.Scan(filepath.Join(fixturesDir, "installed", "Gemfile.lock"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := map[string]string{
+		"json":     "Ruby OR BSD-2-Clause",
+		"missing":  "UNKNOWN",
+		"nokogiri": "MIT",
+		"oldgem":   "MIT", // from the gem's MIT-LICENSE file
+		"rack":     "MIT", // the locked 3.0.8, not the also installed 2.2.8
+		"bundler":  "UNKNOWN",
+	}
+
+	for _, dep := range dependencies {
+		license, ok := expected[dep.Name]
+		if !ok {
+			t.Errorf("Unexpected dependency '%s'", dep.Name)
+			continue
+		}
+		if dep.LicenseType != license {
+			t.Errorf("%s license = %s, expected %s", dep.Name, dep.LicenseType, license)
+		}
+	}
+}
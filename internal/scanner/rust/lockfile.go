@@ -0,0 +1,103 @@
+package rust
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"license-audit/pkg/types"
+)
+
+// CargoLock is a Cargo.lock file. Format 1 keeps checksums in [metadata];
+// formats 2 to 4 put them on each package.
+type CargoLock struct {
+	Version  int                `toml:"version"`
+	Package  []CargoLockPackage `toml:"package"`
+	Metadata map[string]string  `toml:"metadata"`
+}
+
+type CargoLockPackage struct {
+	Name         string   `toml:"name"`
+	Version      string   `toml:"version"`
+	Source       string   `toml:"source"`
+	Checksum     string   `toml:"checksum"`
+	Dependencies []string `toml:"dependencies"` // "name", "name version" or "name version (source)"
+}
+
+// crates.io sources, which need no source annotation
+var defaultRegistries = map[string]bool{
+	"registry+https://github.com/rust-lang/crates.io-index": true,
+	"sparse+https://index.crates.io/":                       true,
+}
+
+func (s *Scanner) scanCargoLock(path string) ([]types.Dependency, error) {
+	var lockFile CargoLock
+	if _, err := toml.DecodeFile(path, &lockFile); err != nil {
+		return nil, fmt.Errorf("failed to parse Cargo.lock: %w", err)
+	}
+
+	// Packages without a source are local crates: the workspace members
+	// the root manifest lists, and path dependencies. Without a manifest
+	// to tell them apart, all of them are taken for members. What the
+	// members depend on directly is not indirect.
+	members := workspaceMembers(filepath.Dir(path))
+	isMember := func(pkg CargoLockPackage) bool {
+		return pkg.Source == "" && (members == nil || members[pkg.Name])
+	}
+
+	direct := make(map[string]bool)
+	for _, pkg := range lockFile.Package {
+		if !isMember(pkg) {
+			continue
+		}
+		for _, ref := range pkg.Dependencies {
+			direct[lockDependencyName(ref)] = true
+		}
+	}
+
+	var dependencies []types.Dependency
+	for _, pkg := range lockFile.Package {
+		dep := s.newDependency(pkg.Name, pkg.Version, path)
+
+		for _, ref := range pkg.Dependencies {
+			dep.Requires = append(dep.Requires, lockDependencyName(ref))
+		}
+
+		checksum := pkg.Checksum
+		if checksum == "" {
+			checksum = lockFile.Metadata[fmt.Sprintf("checksum %s %s (%s)", pkg.Name, pkg.Version, pkg.Source)]
+		}
+		if checksum != "" && checksum != "<none>" {
+			dep.Hashes = []string{"sha256:" + checksum}
+		}
+
+		switch {
+		case isMember(pkg):
+			dep.Scope = "project"
+		case pkg.Source == "":
+			dep.Source = "path"
+			dep.Indirect = !direct[pkg.Name]
+		case strings.HasPrefix(pkg.Source, "git+"):
+			dep.Source = "git"
+			dep.SourceURL = strings.TrimPrefix(pkg.Source, "git+")
+			dep.Indirect = !direct[pkg.Name]
+		case !defaultRegistries[pkg.Source]:
+			dep.Source = "registry"
+			dep.SourceURL = pkg.Source
+			dep.Indirect = !direct[pkg.Name]
+		default:
+			dep.Indirect = !direct[pkg.Name]
+		}
+
+		dependencies = append(dependencies, dep)
+	}
+
+	return dependencies, nil
+}
+
+// lockDependencyName returns the crate name of a dependency reference.
+func lockDependencyName(ref string) string {
+	name, _, _ := strings.Cut(ref, " ")
+	return name
+}
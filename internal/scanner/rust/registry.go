@@ -0,0 +1,147 @@
+package rust
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"license-audit/internal/license"
+	"license-audit/pkg/types"
+)
+
+// Crate is the license metadata of a crate whose sources are on disk.
+type Crate struct {
+	Name        string
+	Version     string
+	LicenseType string
+	LicenseText string
+	Homepage    string
+	Repository  string
+}
+
+// applyCrateLicenses fills in licenses for dependencies whose crate
+// sources are vendored next to the project in dir or unpacked in the
+// cargo registry.
+func (s *Scanner) applyCrateLicenses(dir string, dependencies []types.Dependency) {
+	for i := range dependencies {
+		dep := &dependencies[i]
+		if dep.LicenseType != "UNKNOWN" && dep.LicenseType != "" {
+			continue
+		}
+		if dep.Source == "path" || dep.Scope == "project" {
+			continue
+		}
+
+		crate := s.findCrate(dir, dep.Name, dep.Version)
+		if crate == nil {
+			continue
+		}
+
+		dep.LicenseType = crate.LicenseType
+		if dep.LicenseText == "" {
+			dep.LicenseText = crate.LicenseText
+		}
+		if dep.Homepage == "" {
+			dep.Homepage = crate.Homepage
+		}
+		if dep.Repository == "" {
+			dep.Repository = crate.Repository
+		}
+	}
+}
+
+// findCrate looks for a crate's sources in vendor/ (as written by cargo
+// vendor, next to the project or its workspace root) and then in the
+// registry sources under the cargo home.
+func (s *Scanner) findCrate(dir, name, version string) *Crate {
+	var candidates []string
+
+	vendorDirs := []string{filepath.Join(dir, "vendor")}
+	if root := findWorkspaceRoot(dir); root != "" {
+		vendorDirs = append(vendorDirs, filepath.Join(filepath.Dir(root), "vendor"))
+	}
+	for _, vendor := range vendorDirs {
+		candidates = append(candidates, filepath.Join(vendor, name+"-"+version), filepath.Join(vendor, name))
+	}
+
+	if cargoHome := s.cargoHome(); cargoHome != "" {
+		matches, _ := filepath.Glob(filepath.Join(cargoHome, "registry", "src", "*", name+"-"+version))
+		candidates = append(candidates, matches...)
+	}
+
+	for _, candidate := range candidates {
+		crate := s.readCrate(candidate)
+		if crate == nil || crate.Name != name {
+			continue
+		}
+		// Unversioned vendor directories match any requirement from a
+		// Cargo.toml, but a locked version must be exact
+		if crate.Version == version || !isExactVersion(version) {
+			return crate
+		}
+	}
+
+	return nil
+}
+
+func (s *Scanner) cargoHome() string {
+	if s.CargoHome != "" {
+		return s.CargoHome
+	}
+	if cargoHome := os.Getenv("CARGO_HOME"); cargoHome != "" {
+		return cargoHome
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".cargo")
+}
+
+// readCrate reads the manifest and license files of an unpacked crate.
+func (s *Scanner) readCrate(crateDir string) *Crate {
+	if crate, ok := s.crateCache[crateDir]; ok {
+		return crate
+	}
+
+	var crate *Crate
+	if manifest, err := readCargoManifest(filepath.Join(crateDir, "Cargo.toml")); err == nil && manifest.Package.Name != "" {
+		crate = &Crate{
+			Name:        manifest.Package.Name,
+			Version:     manifestField(manifest.Package.Version, nil, "version"),
+			LicenseType: "UNKNOWN",
+			Homepage:    manifestField(manifest.Package.Homepage, nil, "homepage"),
+			Repository:  manifestField(manifest.Package.Repository, nil, "repository"),
+		}
+
+		if licenseFile := manifestField(manifest.Package.LicenseFile, nil, "license-file"); licenseFile != "" {
+			if data, err := os.ReadFile(filepath.Join(crateDir, licenseFile)); err == nil {
+				crate.LicenseText = string(data)
+			}
+		}
+		if crate.LicenseText == "" {
+			crate.LicenseText = license.ReadFile(crateDir)
+		}
+
+		if expression := manifestField(manifest.Package.License, nil, "license"); expression != "" {
+			crate.LicenseType = normalizeLicenseExpression(expression)
+		} else if crate.LicenseText != "" {
+			crate.LicenseType = license.Detect(crate.LicenseText)
+		}
+	}
+
+	if s.crateCache == nil {
+		s.crateCache = make(map[string]*Crate)
+	}
+	s.crateCache[crateDir] = crate
+	return crate
+}
+
+// isExactVersion reports whether a version is a locked version rather
+// than a requirement such as "1.0", "^1.2" or ">=0.4, <0.6".
+func isExactVersion(version string) bool {
+	if strings.ContainsAny(version, "^~<>=*, ") {
+		return false
+	}
+	return strings.Count(version, ".") >= 2
+}
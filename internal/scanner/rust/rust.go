@@ -0,0 +1,361 @@
+package rust
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"license-audit/internal/license"
+	"license-audit/pkg/types"
+)
+
+type Scanner struct {
+	// CargoHome is where cargo keeps its registry sources. It defaults to
+	// $CARGO_HOME, then ~/.cargo.
+	CargoHome string
+
+	crateCache map[string]*Crate
+}
+
+// CargoManifest is a Cargo.toml file.
+type CargoManifest struct {
+	Package           CargoPackage                  `toml:"package"`
+	Dependencies      map[string]interface{}        `toml:"dependencies"`
+	DevDependencies   map[string]interface{}        `toml:"dev-dependencies"`
+	BuildDependencies map[string]interface{}        `toml:"build-dependencies"`
+	Target            map[string]CargoTargetSection `toml:"target"`
+	Workspace         *CargoWorkspace               `toml:"workspace"`
+}
+
+type CargoPackage struct {
+	Name        string      `toml:"name"`
+	Version     interface{} `toml:"version"` // a string or {workspace = true}
+	License     interface{} `toml:"license"`
+	LicenseFile interface{} `toml:"license-file"`
+	Homepage    interface{} `toml:"homepage"`
+	Repository  interface{} `toml:"repository"`
+}
+
+// CargoTargetSection holds the dependencies of a [target.'cfg(...)'] table.
+type CargoTargetSection struct {
+	Dependencies      map[string]interface{} `toml:"dependencies"`
+	DevDependencies   map[string]interface{} `toml:"dev-dependencies"`
+	BuildDependencies map[string]interface{} `toml:"build-dependencies"`
+}
+
+type dependencySection struct {
+	entries map[string]interface{}
+	scope   string
+}
+
+type CargoWorkspace struct {
+	Members      []string               `toml:"members"`
+	Exclude      []string               `toml:"exclude"`
+	Package      map[string]interface{} `toml:"package"`
+	Dependencies map[string]interface{} `toml:"dependencies"`
+}
+
+func NewScanner() *Scanner {
+	return &Scanner{}
+}
+
+func (s *Scanner) Name() string {
+	return "rust"
+}
+
+func (s *Scanner) Detect(path string) bool {
+	fileName := filepath.Base(path)
+	switch fileName {
+	case "Cargo.toml":
+		return !isVendoredCrate(filepath.Dir(path))
+	case "Cargo.lock":
+		return true
+	}
+	return false
+}
+
+// isVendoredCrate reports whether dir holds a crate copied by cargo
+// vendor: it has the .cargo-checksum.json cargo vendor writes, or sits in
+// the vendor directory of a project. Vendored crates are read through the
+// lockfile of that project.
+func isVendoredCrate(dir string) bool {
+	if _, err := os.Stat(filepath.Join(dir, ".cargo-checksum.json")); err == nil {
+		return true
+	}
+
+	vendorDir := filepath.Dir(dir)
+	if filepath.Base(vendorDir) != "vendor" {
+		return false
+	}
+	for _, name := range []string{"Cargo.toml", "Cargo.lock"} {
+		if _, err := os.Stat(filepath.Join(filepath.Dir(vendorDir), name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Scanner) Scan(path string) ([]types.Dependency, error) {
+	dependencies, err := s.scanFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s.applyCrateLicenses(filepath.Dir(path), dependencies)
+	return dependencies, nil
+}
+
+func (s *Scanner) scanFile(path string) ([]types.Dependency, error) {
+	fileName := filepath.Base(path)
+
+	switch fileName {
+	case "Cargo.toml":
+		return s.scanCargoToml(path)
+	case "Cargo.lock":
+		return s.scanCargoLock(path)
+	default:
+		return nil, fmt.Errorf("unsupported Rust file: %s", fileName)
+	}
+}
+
+func readCargoManifest(path string) (*CargoManifest, error) {
+	var manifest CargoManifest
+	if _, err := toml.DecodeFile(path, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Base(path), err)
+	}
+	return &manifest, nil
+}
+
+// scanCargoToml reports the crate a manifest describes, with its license,
+// followed by its dependencies. Fields and dependencies declared with
+// workspace = true are inherited from the workspace root manifest.
+func (s *Scanner) scanCargoToml(path string) ([]types.Dependency, error) {
+	manifest, err := readCargoManifest(path)
+	if err != nil {
+		return nil, err
+	}
+
+	workspace := manifest.Workspace
+	if workspace == nil {
+		if rootPath := findWorkspaceRoot(filepath.Dir(path)); rootPath != "" {
+			if root, err := readCargoManifest(rootPath); err == nil {
+				workspace = root.Workspace
+			}
+		}
+	}
+
+	var dependencies []types.Dependency
+
+	if manifest.Package.Name != "" {
+		project := s.newDependency(manifest.Package.Name, manifestField(manifest.Package.Version, workspace, "version"), path)
+		project.Scope = "project"
+		if workspace != nil {
+			project.Workspace = manifest.Package.Name
+		}
+		project.Homepage = manifestField(manifest.Package.Homepage, workspace, "homepage")
+		project.Repository = manifestField(manifest.Package.Repository, workspace, "repository")
+		if expression := manifestField(manifest.Package.License, workspace, "license"); expression != "" {
+			project.LicenseType = normalizeLicenseExpression(expression)
+		} else if licenseFile := manifestField(manifest.Package.LicenseFile, workspace, "license-file"); licenseFile != "" {
+			if data, err := os.ReadFile(filepath.Join(filepath.Dir(path), licenseFile)); err == nil {
+				project.LicenseText = string(data)
+				project.LicenseType = license.Detect(project.LicenseText)
+			}
+		}
+		dependencies = append(dependencies, project)
+	}
+
+	sections := []dependencySection{
+		{manifest.Dependencies, ""},
+		{manifest.DevDependencies, "dev"},
+		{manifest.BuildDependencies, "build"},
+	}
+	for _, target := range sortedKeys(manifest.Target) {
+		section := manifest.Target[target]
+		sections = append(sections,
+			dependencySection{section.Dependencies, ""},
+			dependencySection{section.DevDependencies, "dev"},
+			dependencySection{section.BuildDependencies, "build"},
+		)
+	}
+
+	for _, section := range sections {
+		for _, key := range sortedKeys(section.entries) {
+			dep := s.cargoDependency(key, section.entries[key], workspace, path)
+			if dep.Scope == "" {
+				dep.Scope = section.scope
+			}
+			if workspace != nil {
+				dep.Workspace = manifest.Package.Name
+			}
+			dependencies = append(dependencies, dep)
+		}
+	}
+
+	return dependencies, nil
+}
+
+// cargoDependency converts a dependency declaration, either a version
+// requirement string or a table, into a dependency.
+func (s *Scanner) cargoDependency(key string, value interface{}, workspace *CargoWorkspace, path string) types.Dependency {
+	table, ok := value.(map[string]interface{})
+	if !ok {
+		version, _ := value.(string)
+		return s.newDependency(key, version, path)
+	}
+
+	optional, _ := table["optional"].(bool)
+
+	// { workspace = true } takes the declaration from [workspace.dependencies];
+	// only optional (read above) and features may be set locally
+	if inherited, _ := table["workspace"].(bool); inherited && workspace != nil {
+		if declaration, ok := workspace.Dependencies[key]; ok {
+			if declared, ok := declaration.(map[string]interface{}); ok {
+				table = declared
+			} else {
+				table = map[string]interface{}{"version": declaration}
+			}
+		}
+	}
+
+	name := key
+	if pkg, ok := table["package"].(string); ok {
+		// Renamed dependencies: foo = { package = "real-name" }
+		name = pkg
+	}
+
+	version, _ := table["version"].(string)
+	dep := s.newDependency(name, version, path)
+
+	if git, ok := table["git"].(string); ok {
+		dep.Source = "git"
+		dep.SourceURL = git
+		for _, ref := range []string{"rev", "tag", "branch"} {
+			if value, ok := table[ref].(string); ok {
+				dep.SourceURL += "#" + value
+				break
+			}
+		}
+	} else if localPath, ok := table["path"].(string); ok {
+		dep.Source = "path"
+		dep.SourceURL = localPath
+	} else if registry, ok := table["registry"].(string); ok {
+		dep.Source = "registry"
+		dep.SourceURL = registry
+	}
+
+	if optional {
+		dep.Scope = "optional"
+	}
+	return dep
+}
+
+// manifestField returns a string package field, resolving
+// { workspace = true } against [workspace.package].
+func manifestField(value interface{}, workspace *CargoWorkspace, key string) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case map[string]interface{}:
+		if inherited, _ := v["workspace"].(bool); inherited && workspace != nil {
+			field, _ := workspace.Package[key].(string)
+			return field
+		}
+	}
+	return ""
+}
+
+// workspaceMembers returns the names of the crates the manifest in dir
+// builds: its own package and the members of its [workspace], less those
+// excluded. It returns nil if dir has no manifest.
+func workspaceMembers(dir string) map[string]bool {
+	manifest, err := readCargoManifest(filepath.Join(dir, "Cargo.toml"))
+	if err != nil {
+		return nil
+	}
+
+	members := make(map[string]bool)
+	if manifest.Package.Name != "" {
+		members[manifest.Package.Name] = true
+	}
+	if manifest.Workspace == nil {
+		return members
+	}
+
+	excluded := make(map[string]bool)
+	for _, pattern := range manifest.Workspace.Exclude {
+		excluded[filepath.Join(dir, filepath.FromSlash(pattern))] = true
+	}
+	for _, pattern := range manifest.Workspace.Members {
+		matches, _ := filepath.Glob(filepath.Join(dir, filepath.FromSlash(pattern)))
+		for _, memberDir := range matches {
+			if excluded[memberDir] {
+				continue
+			}
+			if member, err := readCargoManifest(filepath.Join(memberDir, "Cargo.toml")); err == nil && member.Package.Name != "" {
+				members[member.Package.Name] = true
+			}
+		}
+	}
+	return members
+}
+
+// findWorkspaceRoot looks for the nearest Cargo.toml above dir that
+// declares a [workspace].
+func findWorkspaceRoot(dir string) string {
+	for {
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+
+		candidate := filepath.Join(dir, "Cargo.toml")
+		if _, err := os.Stat(candidate); err != nil {
+			continue
+		}
+		if manifest, err := readCargoManifest(candidate); err == nil && manifest.Workspace != nil {
+			return candidate
+		}
+	}
+}
+
+// normalizeLicenseExpression converts the deprecated "MIT/Apache-2.0"
+// form to an SPDX expression.
+func normalizeLicenseExpression(license string) string {
+	license = strings.TrimSpace(license)
+	if strings.Contains(license, "/") {
+		parts := strings.Split(license, "/")
+		for i := range parts {
+			parts[i] = strings.TrimSpace(parts[i])
+		}
+		return strings.Join(parts, " OR ")
+	}
+	return license
+}
+
+func (s *Scanner) newDependency(name, version, filePath string) types.Dependency {
+	if version == "" {
+		version = "UNKNOWN"
+	}
+
+	return types.Dependency{
+		Name:        name,
+		Version:     version,
+		LicenseType: "UNKNOWN",
+		PackageType: "cargo",
+		FilePath:    filePath,
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
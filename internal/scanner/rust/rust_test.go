@@ -0,0 +1,208 @@
+package rust
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+const fixturesDir = "../../../test/fixtures/rust"
+
+func newTestScanner() *Scanner {
+	scanner := NewScanner()
+	scanner.CargoHome = filepath.Join(fixturesDir, "cargo-home")
+	return scanner
+}
+
+func TestDetect(t *testing.T) {
+	scanner := NewScanner()
+
+	testCases := []struct {
+		path     string
+		expected bool
+	}{
+		{"Cargo.toml", true},
+		{"Cargo.lock", true},
+		{"crates/api/Cargo.toml", true},
+		// Crates copied by cargo vendor
+		{filepath.Join(fixturesDir, "workspace", "vendor", "serde", "Cargo.toml"), false},
+		{filepath.Join(fixturesDir, "workspace", "vendor", "libc-0.2.148", "Cargo.toml"), false},
+		{filepath.Join(fixturesDir, "workspace", "crates", "core", "Cargo.toml"), true},
+		{"cargo.toml", false},
+		{"package.json", false},
+		{"", false},
+	}
+
+	for _, tc := range testCases {
+		result := scanner.Detect(tc.path)
+		if result != tc.expected {
+			t.Errorf("Detect(%s) = %v, expected %v", tc.path, result, tc.expected)
+		}
+	}
+}
+
+func TestScanCargoToml(t *testing.T) {
+	scanner := newTestScanner()
+
+	dependencies, err := scanner.Scan(filepath.Join(fixturesDir, "workspace", "crates", "api", "Cargo.toml"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	type expectation struct {
+		version string
+		scope   string
+		source  string
+		license string
+	}
+
+	expected := map[string]expectation{
+		"acme-api":   {"0.2.0", "project", "", "MIT OR Apache-2.0"},
+		"serde":      {"1.0", "", "", "MIT OR Apache-2.0"}, // from vendor/serde
+		"tokio":      {"1.32", "optional", "", "UNKNOWN"},
+		"serde_json": {"1", "", "", "UNKNOWN"},
+		"acme-core":  {"UNKNOWN", "", "path", "UNKNOWN"},
+		"tracing":    {"UNKNOWN", "", "git", "UNKNOWN"},
+		"criterion":  {"0.5", "dev", "", "UNKNOWN"},
+		"cc":         {"1.0.83", "build", "", "UNKNOWN"},
+		"libc":       {"0.2", "", "", "UNKNOWN"},
+	}
+
+	if len(dependencies) != len(expected) {
+		t.Errorf("Expected %d dependencies, got %d", len(expected), len(dependencies))
+	}
+
+	for _, dep := range dependencies {
+		want, ok := expected[dep.Name]
+		if !ok {
+			t.Errorf("Unexpected dependency '%s'", dep.Name)
+			continue
+		}
+
+		got := expectation{dep.Version, dep.Scope, dep.Source, dep.LicenseType}
+		if got != want {
+			t.Errorf("%s = %+v, expected %+v", dep.Name, got, want)
+		}
+		if dep.Workspace != "acme-api" {
+			t.Errorf("%s workspace = %s, expected acme-api", dep.Name, dep.Workspace)
+		}
+	}
+
+	if dependencies[0].Repository != "https://github.com/example/acme" {
+		t.Errorf("Expected the repository to be inherited from the workspace, got %s", dependencies[0].Repository)
+	}
+}
+
+func TestScanCargoTomlLicenseFile(t *testing.T) {
+	scanner := newTestScanner()
+
+	dependencies, err := scanner.Scan(filepath.Join(fixturesDir, "workspace", "crates", "core", "Cargo.toml"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(dependencies) != 1 {
+		t.Fatalf("Expected 1 dependency, got %d", len(dependencies))
+	}
+	if dependencies[0].LicenseType != "MIT" || dependencies[0].LicenseText == "" {
+		t.Errorf("Expected the MIT license from license-file, got %s", dependencies[0].LicenseType)
+	}
+}
+
+func TestScanCargoLock(t *testing.T) {
+	scanner := newTestScanner()
+
+	dependencies, err := scanner.Scan(filepath.Join(fixturesDir, "workspace", "Cargo.lock"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	type expectation struct {
+		version  string
+		scope    string
+		source   string
+		indirect bool
+		license  string
+	}
+
+	expected := map[string]expectation{
+		"acme-api":      {"0.2.0", "project", "", false, "UNKNOWN"},
+		"acme-core":     {"0.2.0", "project", "", false, "UNKNOWN"},
+		"acme-macros":   {"0.1.0", "", "path", false, "UNKNOWN"}, // path dependency, not a member
+		"internal-auth": {"1.4.0", "", "registry", true, "UNKNOWN"},
+		"libc":          {"0.2.148", "", "", false, "MIT OR Apache-2.0"}, // vendor/libc-0.2.148, "MIT/Apache-2.0"
+		"serde":         {"1.0.188", "", "", false, "MIT OR Apache-2.0"},
+		"serde_derive":  {"1.0.188", "", "", true, "MIT"}, // license-file in the cargo registry
+		"tracing":       {"0.1.40", "", "git", false, "UNKNOWN"},
+	}
+
+	if len(dependencies) != len(expected) {
+		t.Errorf("Expected %d dependencies, got %d", len(expected), len(dependencies))
+	}
+
+	for _, dep := range dependencies {
+		want, ok := expected[dep.Name]
+		if !ok {
+			t.Errorf("Unexpected dependency '%s'", dep.Name)
+			continue
+		}
+
+		got := expectation{dep.Version, dep.Scope, dep.Source, dep.Indirect, dep.LicenseType}
+		if got != want {
+			t.Errorf("%s = %+v, expected %+v", dep.Name, got, want)
+		}
+
+		if dep.Source == "" && dep.Scope != "project" && len(dep.Hashes) != 1 {
+			t.Errorf("Expected a checksum for %s, got %v", dep.Name, dep.Hashes)
+		}
+	}
+}
+
+func TestScanCargoLockV1(t *testing.T) {
+	scanner := newTestScanner()
+
+	dependencies, err := scanner.Scan(filepath.Join(fixturesDir, "v1", "Cargo.lock"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(dependencies) != 2 {
+		t.Fatalf("Expected 2 dependencies, got %d", len(dependencies))
+	}
+
+	for _, dep := range dependencies {
+		switch dep.Name {
+		case "legacy":
+			if dep.Scope != "project" || len(dep.Requires) != 1 || dep.Requires[0] != "itoa" {
+				t.Errorf("legacy = %+v", dep)
+			}
+		case "itoa":
+			if dep.Indirect {
+				t.Errorf("Expected itoa to be a direct dependency")
+			}
+			if len(dep.Hashes) != 1 || dep.Hashes[0] != "sha256:b71991ff56294aa922b450139ee08b3bfc70982c6b2c7562771375cf73542dd4" {
+				t.Errorf("Expected the [metadata] checksum for itoa, got %v", dep.Hashes)
+			}
+		default:
+			t.Errorf("Unexpected dependency '%s'", dep.Name)
+		}
+	}
+}
+
+func TestNormalizeLicenseExpression(t *testing.T) {
+	testCases := []struct {
+		license  string
+		expected string
+	}{
+		{"MIT", "MIT"},
+		{"MIT OR Apache-2.0", "MIT OR Apache-2.0"},
+		{"MIT/Apache-2.0", "MIT OR Apache-2.0"},
+		{"MIT / Apache-2.0 / Zlib", "MIT OR Apache-2.0 OR Zlib"},
+	}
+
+	for _, tc := range testCases {
+		result := normalizeLicenseExpression(tc.license)
+		if result != tc.expected {
+			t.Errorf("normalizeLicenseExpression(%s) = %s, expected %s", tc.license, result, tc.expected)
+		}
+	}
+}
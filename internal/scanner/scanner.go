@@ -7,12 +7,25 @@ import (
 	"time"
 
 	"license-audit/internal/ignore"
+	"license-audit/internal/scanner/cocoapods"
+	"license-audit/internal/scanner/conan"
+	"license-audit/internal/scanner/deb"
 	"license-audit/internal/scanner/docker"
 	"license-audit/internal/scanner/golang"
+	"license-audit/internal/scanner/headers"
+	"license-audit/internal/scanner/hex"
+	"license-audit/internal/scanner/image"
 	"license-audit/internal/scanner/java"
 	"license-audit/internal/scanner/nodejs"
+	"license-audit/internal/scanner/nuget"
+	"license-audit/internal/scanner/php"
+	"license-audit/internal/scanner/pub"
 	"license-audit/internal/scanner/python"
 	"license-audit/internal/scanner/ruby"
+	"license-audit/internal/scanner/rust"
+	"license-audit/internal/scanner/swift"
+	"license-audit/internal/scanner/vcpkg"
+	"license-audit/internal/scanner/vendored"
 	"license-audit/pkg/types"
 )
 
@@ -28,6 +41,19 @@ type PackageScanner interface {
 	Scan(path string) ([]types.Dependency, error)
 }
 
+// DirectoryScanner is implemented by package scanners whose packages are
+// directories rather than manifest files. Scan is called with each
+// directory DetectDir accepts.
+type DirectoryScanner interface {
+	DetectDir(root, dir string) bool
+}
+
+// TreeScanner is implemented by package scanners that report once a scan
+// path has been walked, from what their Scan calls recorded.
+type TreeScanner interface {
+	ScanTree(root string) ([]types.Dependency, error)
+}
+
 func New(config *types.Config) *Scanner {
 	s := &Scanner{
 		config:   config,
@@ -53,13 +79,80 @@ func New(config *types.Config) *Scanner {
 		s.scanners = append(s.scanners, docker.NewScanner())
 	}
 	if config.Scanners.Python {
-		s.scanners = append(s.scanners, python.NewScanner())
+		pythonScanner := python.NewScanner()
+		pythonScanner.VirtualenvPaths = config.Scanners.PythonVirtualenvs
+		s.scanners = append(s.scanners, pythonScanner)
 	}
 	if config.Scanners.Ruby {
-		s.scanners = append(s.scanners, ruby.NewScanner())
+		rubyScanner := ruby.NewScanner()
+		rubyScanner.GemPaths = config.Scanners.RubyGemPaths
+		s.scanners = append(s.scanners, rubyScanner)
 	}
 	if config.Scanners.Java {
-		s.scanners = append(s.scanners, java.NewScanner())
+		javaScanner := java.NewScanner()
+		javaScanner.MavenRepository = config.Scanners.MavenRepository
+		s.scanners = append(s.scanners, javaScanner)
+	}
+	if config.Scanners.JavaArchives {
+		s.scanners = append(s.scanners, java.NewArchiveScanner())
+	}
+	if config.Scanners.SourceHeaders {
+		headerScanner := headers.NewScanner()
+		headerScanner.ProjectLicense = config.ProjectLicense
+		headerScanner.HeaderLines = config.Scanners.SourceHeaderLines
+		s.scanners = append(s.scanners, headerScanner)
+	}
+	if config.Scanners.Rust {
+		s.scanners = append(s.scanners, rust.NewScanner())
+	}
+	if config.Scanners.PHP {
+		phpScanner := php.NewScanner()
+		phpScanner.ScanPaths = config.ScanPaths
+		s.scanners = append(s.scanners, phpScanner)
+	}
+	if config.Scanners.NuGet {
+		s.scanners = append(s.scanners, nuget.NewScanner())
+	}
+	if config.Scanners.Swift {
+		s.scanners = append(s.scanners, swift.NewScanner())
+	}
+	if config.Scanners.CocoaPods {
+		s.scanners = append(s.scanners, cocoapods.NewScanner())
+	}
+	if config.Scanners.Pub {
+		s.scanners = append(s.scanners, pub.NewScanner())
+	}
+	if config.Scanners.Elixir {
+		s.scanners = append(s.scanners, hex.NewScanner())
+	}
+	if config.Scanners.Erlang {
+		s.scanners = append(s.scanners, hex.NewRebarScanner())
+	}
+	if config.Scanners.Conan {
+		s.scanners = append(s.scanners, conan.NewScanner())
+	}
+	if config.Scanners.Vcpkg {
+		s.scanners = append(s.scanners, vcpkg.NewScanner())
+	}
+	if config.Scanners.Vendored {
+		vendoredScanner := vendored.NewScanner()
+		vendoredScanner.Dirs = config.Scanners.VendoredDirs
+		s.scanners = append(s.scanners, vendoredScanner)
+	}
+	if config.Scanners.Compose {
+		s.scanners = append(s.scanners, docker.NewComposeScanner())
+	}
+	if config.Scanners.Kubernetes {
+		s.scanners = append(s.scanners, docker.NewKubernetesScanner())
+	}
+	if config.Scanners.Helm {
+		s.scanners = append(s.scanners, docker.NewHelmScanner())
+	}
+	if config.Scanners.Deb {
+		s.scanners = append(s.scanners, deb.NewScanner())
+	}
+	if config.Scanners.ContainerImages {
+		s.scanners = append(s.scanners, image.NewScanner())
 	}
 
 	return s
@@ -94,7 +187,7 @@ func (s *Scanner) Scan() (*types.ScanResult, error) {
 }
 
 func (s *Scanner) scanPath(scanPath string, result *types.ScanResult) error {
-	return filepath.WalkDir(scanPath, func(path string, d fs.DirEntry, err error) error {
+	err := filepath.WalkDir(scanPath, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
@@ -108,31 +201,60 @@ func (s *Scanner) scanPath(scanPath string, result *types.ScanResult) error {
 			return nil
 		}
 
-		// Skip directories for file-based scanning
+		// Directories are only packages to directory scanners
 		if d.IsDir() {
+			if path != scanPath {
+				for _, scanner := range s.scanners {
+					if dirScanner, ok := scanner.(DirectoryScanner); ok && dirScanner.DetectDir(scanPath, path) {
+						s.scanPackage(scanner, path, result)
+					}
+				}
+			}
 			return nil
 		}
 
 		// Try each scanner
 		for _, scanner := range s.scanners {
 			if scanner.Detect(path) {
-				deps, err := scanner.Scan(path)
-				if err != nil {
-					fmt.Printf("Warning: %s scanner failed for %s: %v\n", scanner.Name(), path, err)
-					continue
-				}
-
-				// Set file path for each dependency
-				for i := range deps {
-					deps[i].FilePath = path
-				}
-
-				result.Dependencies = append(result.Dependencies, deps...)
+				s.scanPackage(scanner, path, result)
 			}
 		}
 
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	for _, scanner := range s.scanners {
+		treeScanner, ok := scanner.(TreeScanner)
+		if !ok {
+			continue
+		}
+		deps, err := treeScanner.ScanTree(scanPath)
+		if err != nil {
+			fmt.Printf("Warning: %s scanner failed for %s: %v\n", scanner.Name(), scanPath, err)
+			continue
+		}
+		result.Dependencies = append(result.Dependencies, deps...)
+	}
+
+	return nil
+}
+
+func (s *Scanner) scanPackage(scanner PackageScanner, path string, result *types.ScanResult) {
+	deps, err := scanner.Scan(path)
+	if err != nil {
+		fmt.Printf("Warning: %s scanner failed for %s: %v\n", scanner.Name(), path, err)
+		return
+	}
+
+	// Set file path for each dependency
+	for i := range deps {
+		deps[i].FilePath = path
+	}
+
+	result.Dependencies = append(result.Dependencies, deps...)
 }
 
 func (s *Scanner) applyLicenseOverrides(dependencies []types.Dependency) {
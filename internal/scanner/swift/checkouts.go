@@ -0,0 +1,117 @@
+package swift
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"license-audit/internal/license"
+	"license-audit/pkg/types"
+)
+
+// Checkout is the license of a package whose sources SwiftPM or Xcode
+// have cloned.
+type Checkout struct {
+	LicenseType string
+	LicenseText string
+}
+
+// applyCheckoutLicenses fills in licenses from the LICENSE files of the
+// checked-out packages of the project in dir. SwiftPM has no license
+// metadata, so the license file is the only source.
+func (s *Scanner) applyCheckoutLicenses(dir string, dependencies []types.Dependency) {
+	checkoutDirs := checkoutDirs(dir)
+	if len(checkoutDirs) == 0 {
+		return
+	}
+
+	for i := range dependencies {
+		dep := &dependencies[i]
+		if dep.Scope == "project" || dep.Source == "path" || dep.Source == "registry" {
+			continue
+		}
+
+		name := dep.Name
+		if dep.Repository != "" {
+			name = repositoryName(dep.Repository)
+		}
+
+		for _, checkouts := range checkoutDirs {
+			checkout := s.readCheckout(filepath.Join(checkouts, name))
+			if checkout == nil {
+				continue
+			}
+			dep.LicenseType = checkout.LicenseType
+			dep.LicenseText = checkout.LicenseText
+			break
+		}
+	}
+}
+
+// checkoutDirs returns the existing checkout directories for the project
+// in dir: .build/checkouts for SwiftPM and SourcePackages/checkouts for
+// Xcode, either next to the project or in Xcode's DerivedData.
+func checkoutDirs(dir string) []string {
+	root, xcodeProject := projectRoot(dir)
+
+	candidates := []string{
+		filepath.Join(root, ".build", "checkouts"),
+		filepath.Join(root, "SourcePackages", "checkouts"),
+	}
+	if xcodeProject != "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			pattern := filepath.Join(home, "Library", "Developer", "Xcode", "DerivedData", xcodeProject+"-*", "SourcePackages", "checkouts")
+			matches, _ := filepath.Glob(pattern)
+			candidates = append(candidates, matches...)
+		}
+	}
+
+	var dirs []string
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && info.IsDir() {
+			dirs = append(dirs, candidate)
+		}
+	}
+	return dirs
+}
+
+// projectRoot returns the directory holding the project for a manifest
+// or Package.resolved in dir. Xcode keeps Package.resolved inside the
+// .xcodeproj or .xcworkspace bundle, whose name is also returned. A
+// project bundle embeds a project.xcworkspace, so the outermost bundle wins.
+func projectRoot(dir string) (string, string) {
+	root, name := dir, ""
+
+	for current := dir; ; {
+		ext := filepath.Ext(current)
+		if ext == ".xcodeproj" || ext == ".xcworkspace" {
+			root, name = filepath.Dir(current), strings.TrimSuffix(filepath.Base(current), ext)
+		}
+
+		parent := filepath.Dir(current)
+		if parent == current {
+			return root, name
+		}
+		current = parent
+	}
+}
+
+func (s *Scanner) readCheckout(checkoutDir string) *Checkout {
+	if cached, ok := s.checkoutCache[checkoutDir]; ok {
+		return cached
+	}
+
+	var checkout *Checkout
+	if text := license.ReadFile(checkoutDir); text != "" {
+		checkout = &Checkout{
+			LicenseType: license.Detect(text),
+			LicenseText: text,
+		}
+	}
+
+	if s.checkoutCache == nil {
+		s.checkoutCache = make(map[string]*Checkout)
+	}
+	s.checkoutCache[checkoutDir] = checkout
+	return checkout
+}
@@ -0,0 +1,210 @@
+package swift
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"license-audit/internal/license"
+	"license-audit/pkg/types"
+)
+
+// PackageManifest is what can be read statically from a Package.swift.
+type PackageManifest struct {
+	Name         string
+	Dependencies []PackageRequirement
+}
+
+// PackageRequirement is a .package(...) entry of the manifest's
+// dependencies.
+type PackageRequirement struct {
+	Name        string
+	Location    string // URL, local path or registry identity
+	Kind        string // remoteSourceControl, localSourceControl or registry
+	Requirement string // a version, a range such as ^1.2.0, a branch or a revision
+	Pinned      bool   // a branch or revision rather than a version
+}
+
+var (
+	packageNamePattern = regexp.MustCompile(`\bPackage\s*\(\s*name:\s*"([^"]+)"`)
+	argumentPattern    = regexp.MustCompile(`\b(url|path|id|name):\s*"([^"]+)"`)
+
+	rangePattern      = regexp.MustCompile(`"([^"]+)"\s*(\.\.<|\.\.\.)\s*"([^"]+)"`)
+	nextMinorPattern  = regexp.MustCompile(`\.upToNextMinor\s*\(\s*from:\s*"([^"]+)"`)
+	nextMajorPattern  = regexp.MustCompile(`(?:\.upToNextMajor\s*\(\s*)?\bfrom:\s*"([^"]+)"`)
+	exactPattern      = regexp.MustCompile(`(?:\bexact:\s*|\.exact\s*\(\s*)"([^"]+)"`)
+	branchPattern     = regexp.MustCompile(`(?:\bbranch:\s*|\.branch\s*\(\s*)"([^"]+)"`)
+	revisionPattern   = regexp.MustCompile(`(?:\brevision:\s*|\.revision\s*\(\s*)"([^"]+)"`)
+	blockCommentRegex = regexp.MustCompile(`(?s)/\*.*?\*/`)
+)
+
+// scanPackageSwift reports the package a manifest describes, with the
+// license file in its directory, followed by its direct dependencies.
+func (s *Scanner) scanPackageSwift(path string) ([]types.Dependency, error) {
+	manifest, err := ParsePackageSwift(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var dependencies []types.Dependency
+
+	if manifest.Name != "" {
+		project := s.newDependency(manifest.Name, "", path)
+		project.Scope = "project"
+		if text := license.ReadFile(filepath.Dir(path)); text != "" {
+			project.LicenseText = text
+			project.LicenseType = license.Detect(text)
+		}
+		dependencies = append(dependencies, project)
+	}
+
+	for _, requirement := range manifest.Dependencies {
+		dep := s.newDependency(requirement.Name, requirement.Requirement, path)
+		switch requirement.Kind {
+		case "localSourceControl":
+			dep.Source = "path"
+			dep.SourceURL = requirement.Location
+		case "registry":
+			dep.Source = "registry"
+		default:
+			dep.Repository = requirement.Location
+			if requirement.Pinned {
+				dep.Source = "git"
+				dep.SourceURL = requirement.Location + "#" + requirement.Requirement
+			}
+		}
+		dependencies = append(dependencies, dep)
+	}
+
+	return dependencies, nil
+}
+
+// ParsePackageSwift reads a Package.swift without evaluating it. Only
+// literal package declarations are understood.
+func ParsePackageSwift(path string) (*PackageManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Package.swift: %w", err)
+	}
+
+	source := stripSwiftComments(string(data))
+	manifest := &PackageManifest{}
+
+	if match := packageNamePattern.FindStringSubmatch(source); match != nil {
+		manifest.Name = match[1]
+	}
+
+	for _, call := range packageCalls(source) {
+		if requirement, ok := parsePackageCall(call); ok {
+			manifest.Dependencies = append(manifest.Dependencies, requirement)
+		}
+	}
+
+	return manifest, nil
+}
+
+// packageCalls returns the arguments of each .package(...) call.
+func packageCalls(source string) []string {
+	var calls []string
+
+	for {
+		start := strings.Index(source, ".package(")
+		if start == -1 {
+			return calls
+		}
+		source = source[start+len(".package("):]
+
+		depth := 1
+		end := 0
+		for ; end < len(source) && depth > 0; end++ {
+			switch source[end] {
+			case '(':
+				depth++
+			case ')':
+				depth--
+			}
+		}
+		calls = append(calls, source[:end])
+		source = source[end:]
+	}
+}
+
+func parsePackageCall(call string) (PackageRequirement, bool) {
+	arguments := make(map[string]string)
+	for _, match := range argumentPattern.FindAllStringSubmatch(call, -1) {
+		if _, ok := arguments[match[1]]; !ok {
+			arguments[match[1]] = match[2]
+		}
+	}
+
+	var requirement PackageRequirement
+	switch {
+	case arguments["url"] != "":
+		requirement.Kind = "remoteSourceControl"
+		requirement.Location = arguments["url"]
+		requirement.Name = repositoryName(requirement.Location)
+	case arguments["path"] != "":
+		requirement.Kind = "localSourceControl"
+		requirement.Location = arguments["path"]
+		requirement.Name = filepath.Base(filepath.FromSlash(requirement.Location))
+	case arguments["id"] != "":
+		requirement.Kind = "registry"
+		requirement.Location = arguments["id"]
+		requirement.Name = requirement.Location
+	default:
+		return requirement, false
+	}
+	if name := arguments["name"]; name != "" {
+		// Manifests before tools version 5.6 could name the package
+		requirement.Name = name
+	}
+
+	switch {
+	case rangePattern.MatchString(call):
+		match := rangePattern.FindStringSubmatch(call)
+		upper := "< "
+		if match[2] == "..." {
+			upper = "<= "
+		}
+		requirement.Requirement = ">= " + match[1] + ", " + upper + match[3]
+	case nextMinorPattern.MatchString(call):
+		requirement.Requirement = "~" + nextMinorPattern.FindStringSubmatch(call)[1]
+	case nextMajorPattern.MatchString(call):
+		requirement.Requirement = "^" + nextMajorPattern.FindStringSubmatch(call)[1]
+	case exactPattern.MatchString(call):
+		requirement.Requirement = exactPattern.FindStringSubmatch(call)[1]
+	case branchPattern.MatchString(call):
+		requirement.Requirement = branchPattern.FindStringSubmatch(call)[1]
+		requirement.Pinned = true
+	case revisionPattern.MatchString(call):
+		requirement.Requirement = revisionPattern.FindStringSubmatch(call)[1]
+		requirement.Pinned = true
+	}
+
+	return requirement, true
+}
+
+// stripSwiftComments removes // and /* */ comments outside of strings.
+func stripSwiftComments(source string) string {
+	source = blockCommentRegex.ReplaceAllString(source, "")
+
+	lines := strings.Split(source, "\n")
+	for i, line := range lines {
+		inString := false
+		for j := 0; j < len(line); j++ {
+			switch {
+			case line[j] == '\\' && inString:
+				j++
+			case line[j] == '"':
+				inString = !inString
+			case !inString && strings.HasPrefix(line[j:], "//"):
+				lines[i] = line[:j]
+				j = len(line)
+			}
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,114 @@
+package swift
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"license-audit/pkg/types"
+)
+
+// PackageResolved is a Package.resolved file. Version 1 nests the pins
+// under "object"; versions 2 and 3 keep them at the top level.
+type PackageResolved struct {
+	Version int `json:"version"`
+	Object  struct {
+		Pins []ResolvedPin `json:"pins"`
+	} `json:"object"`
+	Pins []ResolvedPin `json:"pins"`
+}
+
+type ResolvedPin struct {
+	// Version 1
+	Package       string `json:"package"`
+	RepositoryURL string `json:"repositoryURL"`
+
+	// Versions 2 and 3
+	Identity string `json:"identity"`
+	Kind     string `json:"kind"` // remoteSourceControl, localSourceControl or registry
+	Location string `json:"location"`
+
+	State struct {
+		Version  string `json:"version"`
+		Branch   string `json:"branch"`
+		Revision string `json:"revision"`
+	} `json:"state"`
+}
+
+func (s *Scanner) scanPackageResolved(path string) ([]types.Dependency, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Package.resolved: %w", err)
+	}
+
+	var resolved PackageResolved
+	if err := json.Unmarshal(data, &resolved); err != nil {
+		return nil, fmt.Errorf("failed to parse Package.resolved: %w", err)
+	}
+
+	pins := resolved.Pins
+	if resolved.Version == 1 {
+		pins = resolved.Object.Pins
+	}
+
+	// Package.resolved pins the whole graph; the manifest next to it, if
+	// any, tells which packages are direct
+	var direct map[string]bool
+	if manifest, err := ParsePackageSwift(filepath.Join(filepath.Dir(path), "Package.swift")); err == nil {
+		direct = make(map[string]bool)
+		for _, requirement := range manifest.Dependencies {
+			direct[strings.ToLower(requirement.Name)] = true
+		}
+	}
+
+	var dependencies []types.Dependency
+	for _, pin := range pins {
+		location := pin.Location
+		if location == "" {
+			location = pin.RepositoryURL
+		}
+
+		name := pin.Package
+		if name == "" {
+			if pin.Kind == "registry" {
+				name = pin.Identity
+			} else {
+				name = repositoryName(location)
+			}
+		}
+
+		version := pin.State.Version
+		if version == "" {
+			version = pin.State.Branch
+		}
+		if version == "" {
+			version = pin.State.Revision
+		}
+
+		dep := s.newDependency(name, version, path)
+		switch pin.Kind {
+		case "localSourceControl":
+			dep.Source = "path"
+			dep.SourceURL = location
+		case "registry":
+			dep.Source = "registry"
+		default:
+			dep.Repository = location
+			if pin.State.Version == "" {
+				// Pinned to a branch or revision rather than a release
+				dep.Source = "git"
+				dep.SourceURL = location + "#" + pin.State.Revision
+			}
+		}
+
+		if direct != nil {
+			dep.Indirect = !direct[strings.ToLower(name)] && !direct[strings.ToLower(pin.Identity)]
+		}
+
+		dependencies = append(dependencies, dep)
+	}
+
+	return dependencies, nil
+}
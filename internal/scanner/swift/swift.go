@@ -0,0 +1,71 @@
+package swift
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"license-audit/pkg/types"
+)
+
+type Scanner struct {
+	checkoutCache map[string]*Checkout
+}
+
+func NewScanner() *Scanner {
+	return &Scanner{}
+}
+
+func (s *Scanner) Name() string {
+	return "swift"
+}
+
+func (s *Scanner) Detect(path string) bool {
+	fileName := filepath.Base(path)
+	return fileName == "Package.resolved" || fileName == "Package.swift"
+}
+
+func (s *Scanner) Scan(path string) ([]types.Dependency, error) {
+	dependencies, err := s.scanFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s.applyCheckoutLicenses(filepath.Dir(path), dependencies)
+	return dependencies, nil
+}
+
+func (s *Scanner) scanFile(path string) ([]types.Dependency, error) {
+	fileName := filepath.Base(path)
+
+	switch fileName {
+	case "Package.resolved":
+		return s.scanPackageResolved(path)
+	case "Package.swift":
+		return s.scanPackageSwift(path)
+	default:
+		return nil, fmt.Errorf("unsupported Swift file: %s", fileName)
+	}
+}
+
+// repositoryName returns the name SwiftPM gives a package cloned from a
+// URL, which is also the name of its checkout directory.
+func repositoryName(location string) string {
+	location = strings.TrimSuffix(strings.TrimSpace(location), "/")
+	name := location[strings.LastIndexAny(location, "/:")+1:]
+	return strings.TrimSuffix(name, ".git")
+}
+
+func (s *Scanner) newDependency(name, version, filePath string) types.Dependency {
+	if version == "" {
+		version = "UNKNOWN"
+	}
+
+	return types.Dependency{
+		Name:        name,
+		Version:     version,
+		LicenseType: "UNKNOWN",
+		PackageType: "swift",
+		FilePath:    filePath,
+	}
+}
@@ -0,0 +1,171 @@
+package swift
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+const fixturesDir = "../../../test/fixtures/swift"
+
+func TestDetect(t *testing.T) {
+	scanner := NewScanner()
+
+	testCases := []struct {
+		path     string
+		expected bool
+	}{
+		{"Package.swift", true},
+		{"Package.resolved", true},
+		{"App.xcodeproj/project.xcworkspace/xcshareddata/swiftpm/Package.resolved", true},
+		{"Podfile.lock", false},
+		{"main.swift", false},
+		{"", false},
+	}
+
+	for _, tc := range testCases {
+		result := scanner.Detect(tc.path)
+		if result != tc.expected {
+			t.Errorf("Detect(%s) = %v, expected %v", tc.path, result, tc.expected)
+		}
+	}
+}
+
+func TestScanPackageSwift(t *testing.T) {
+	scanner := NewScanner()
+
+	dependencies, err := scanner.Scan(filepath.Join(fixturesDir, "package", "Package.swift"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	type expectation struct {
+		version string
+		scope   string
+		source  string
+		license string
+	}
+
+	expected := map[string]expectation{
+		"AcmeKit":                {"UNKNOWN", "project", "", "MIT"},
+		"Alamofire":              {"^5.8.0", "", "", "MIT"},
+		"swift-log":              {"~1.5.0", "", "", "Apache-2.0"},
+		"swift-snapshot-testing": {">= 1.12.0, < 2.0.0", "", "", "UNKNOWN"},
+		"networking":             {"main", "", "git", "UNKNOWN"},
+		"LocalUtils":             {"UNKNOWN", "", "path", "UNKNOWN"},
+		"acme.analytics":         {"2.1.0", "", "registry", "UNKNOWN"},
+	}
+
+	if len(dependencies) != len(expected) {
+		t.Errorf("Expected %d dependencies, got %d", len(expected), len(dependencies))
+	}
+
+	for _, dep := range dependencies {
+		want, ok := expected[dep.Name]
+		if !ok {
+			t.Errorf("Unexpected dependency '%s'", dep.Name)
+			continue
+		}
+		got := expectation{dep.Version, dep.Scope, dep.Source, dep.LicenseType}
+		if got != want {
+			t.Errorf("%s = %+v, expected %+v", dep.Name, got, want)
+		}
+	}
+}
+
+func TestScanPackageResolved(t *testing.T) {
+	scanner := NewScanner()
+
+	dependencies, err := scanner.Scan(filepath.Join(fixturesDir, "package", "Package.resolved"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	type expectation struct {
+		version  string
+		source   string
+		indirect bool
+		license  string
+	}
+
+	expected := map[string]expectation{
+		"Alamofire":              {"5.8.1", "", false, "MIT"},
+		"networking":             {"main", "git", false, "UNKNOWN"},
+		"swift-custom-dump":      {"1.1.2", "", true, "UNKNOWN"},
+		"swift-log":              {"1.5.3", "", false, "Apache-2.0"},
+		"swift-snapshot-testing": {"1.15.1", "", false, "UNKNOWN"},
+		"acme.analytics":         {"2.1.0", "registry", false, "UNKNOWN"},
+	}
+
+	if len(dependencies) != len(expected) {
+		t.Errorf("Expected %d dependencies, got %d", len(expected), len(dependencies))
+	}
+
+	for _, dep := range dependencies {
+		want, ok := expected[dep.Name]
+		if !ok {
+			t.Errorf("Unexpected dependency '%s'", dep.Name)
+			continue
+		}
+		got := expectation{dep.Version, dep.Source, dep.Indirect, dep.LicenseType}
+		if got != want {
+			t.Errorf("%s = %+v, expected %+v", dep.Name, got, want)
+		}
+
+		if dep.Name == "networking" && dep.SourceURL != "https://github.com/acme/networking.git#9f8e7d6c5b4a39281706f5e4d3c2b1a098765432" {
+			t.Errorf("Unexpected source URL for networking: %s", dep.SourceURL)
+		}
+	}
+}
+
+func TestScanXcodePackageResolved(t *testing.T) {
+	scanner := NewScanner()
+
+	// Version 1 format, inside the Xcode project bundle
+	path := filepath.Join(fixturesDir, "xcode", "App.xcodeproj", "project.xcworkspace", "xcshareddata", "swiftpm", "Package.resolved")
+	dependencies, err := scanner.Scan(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := map[string]string{
+		"Kingfisher": "MIT", // from SourcePackages/checkouts next to the project
+		"SnapKit":    "UNKNOWN",
+	}
+
+	if len(dependencies) != len(expected) {
+		t.Errorf("Expected %d dependencies, got %d", len(expected), len(dependencies))
+	}
+
+	for _, dep := range dependencies {
+		license, ok := expected[dep.Name]
+		if !ok {
+			t.Errorf("Unexpected dependency '%s'", dep.Name)
+			continue
+		}
+		if dep.LicenseType != license {
+			t.Errorf("%s license = %s, expected %s", dep.Name, dep.LicenseType, license)
+		}
+		if dep.Repository == "" || dep.Version == "UNKNOWN" {
+			t.Errorf("Expected the repository and version of %s, got %+v", dep.Name, dep)
+		}
+	}
+}
+
+func TestRepositoryName(t *testing.T) {
+	testCases := []struct {
+		location string
+		expected string
+	}{
+		{"https://github.com/apple/swift-log.git", "swift-log"},
+		{"https://github.com/SnapKit/SnapKit", "SnapKit"},
+		{"https://github.com/SnapKit/SnapKit/", "SnapKit"},
+		{"git@github.com:acme/networking.git", "networking"},
+	}
+
+	for _, tc := range testCases {
+		result := repositoryName(tc.location)
+		if result != tc.expected {
+			t.Errorf("repositoryName(%s) = %s, expected %s", tc.location, result, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package vcpkg
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Installed is what the status database of an installed tree records:
+// one paragraph per installed port and triplet.
+type Installed struct {
+	Packages map[string]InstalledPackage
+	Names    []string        // sorted names of the ports to report
+	HostOnly map[string]bool // ports only ever depended on as host tools
+}
+
+type InstalledPackage struct {
+	Version  string
+	Requires []string
+}
+
+// BaselineEntry is a port's version in versions/baseline.json.
+type BaselineEntry struct {
+	Baseline    string `json:"baseline"`
+	PortVersion int    `json:"port-version"`
+}
+
+// readInstalled reads the installed tree of a manifest project,
+// vcpkg_installed/, or else the classic-mode tree of the vcpkg root. The
+// classic tree is shared by every project, so only the versions of
+// declared ports are taken from it.
+func (s *Scanner) readInstalled(dir string) *Installed {
+	if installed := readStatus(filepath.Join(dir, "vcpkg_installed", "vcpkg")); installed != nil {
+		return installed
+	}
+
+	if root := s.root(); root != "" {
+		if installed := readStatus(filepath.Join(root, "installed", "vcpkg")); installed != nil {
+			installed.Names = nil
+			return installed
+		}
+	}
+
+	return &Installed{Packages: make(map[string]InstalledPackage), HostOnly: make(map[string]bool)}
+}
+
+// readStatus reads the status file of an installed tree and the updates
+// vcpkg appends to it before compacting them. Feature paragraphs and
+// ports that are no longer installed are skipped.
+func readStatus(vcpkgDir string) *Installed {
+	files := []string{filepath.Join(vcpkgDir, "status")}
+	if _, err := os.Stat(files[0]); err != nil {
+		return nil
+	}
+	updates, _ := filepath.Glob(filepath.Join(vcpkgDir, "updates", "*"))
+	sort.Strings(updates)
+	files = append(files, updates...)
+
+	installed := &Installed{Packages: make(map[string]InstalledPackage), HostOnly: make(map[string]bool)}
+	hostDependencies := make(map[string]bool)
+	targetDependencies := make(map[string]bool)
+
+	for _, file := range files {
+		for _, paragraph := range readParagraphs(file) {
+			name := paragraph["Package"]
+			if name == "" || paragraph["Feature"] != "" {
+				continue
+			}
+			if !strings.HasSuffix(paragraph["Status"], " installed") {
+				delete(installed.Packages, name)
+				continue
+			}
+
+			pkg := InstalledPackage{Version: paragraph["Version"]}
+			for _, dependency := range strings.Split(paragraph["Depends"], ",") {
+				dependency = strings.TrimSpace(dependency)
+				if dependency == "" {
+					continue
+				}
+				// name:triplet depends on a port built for the host
+				if depName, _, ok := strings.Cut(dependency, ":"); ok {
+					hostDependencies[depName] = true
+					dependency = depName
+				} else {
+					targetDependencies[dependency] = true
+				}
+				pkg.Requires = append(pkg.Requires, dependency)
+			}
+			installed.Packages[name] = pkg
+		}
+	}
+
+	for name := range installed.Packages {
+		installed.Names = append(installed.Names, name)
+		if hostDependencies[name] && !targetDependencies[name] {
+			installed.HostOnly[name] = true
+		}
+	}
+	sort.Strings(installed.Names)
+
+	return installed
+}
+
+// readParagraphs reads a file of blank-line separated "Field: value"
+// paragraphs, as in the status database.
+func readParagraphs(path string) []map[string]string {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var paragraphs []map[string]string
+	current := make(map[string]string)
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			if len(current) > 0 {
+				paragraphs = append(paragraphs, current)
+				current = make(map[string]string)
+			}
+			continue
+		}
+		if key, value, ok := strings.Cut(line, ":"); ok && !strings.HasPrefix(line, " ") {
+			current[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	if len(current) > 0 {
+		paragraphs = append(paragraphs, current)
+	}
+
+	return paragraphs
+}
+
+// baselineVersion returns a port's version in the baseline of the vcpkg
+// root, which is the builtin-baseline when the root is checked out at it.
+func (s *Scanner) baselineVersion(name string) string {
+	if s.baseline == nil {
+		s.baseline = make(map[string]BaselineEntry)
+		if root := s.root(); root != "" {
+			if data, err := os.ReadFile(filepath.Join(root, "versions", "baseline.json")); err == nil {
+				var baseline struct {
+					Default map[string]BaselineEntry `json:"default"`
+				}
+				if json.Unmarshal(data, &baseline) == nil && baseline.Default != nil {
+					s.baseline = baseline.Default
+				}
+			}
+		}
+	}
+
+	return s.baseline[name].Baseline
+}
+
+func (s *Scanner) root() string {
+	if s.Root != "" {
+		return s.Root
+	}
+	return os.Getenv("VCPKG_ROOT")
+}
@@ -0,0 +1,139 @@
+package vcpkg
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"license-audit/internal/license"
+	"license-audit/pkg/types"
+)
+
+// Port is the license of an installed port: the copyright file vcpkg
+// installs to share/<port>/, and the SPDX license its port manifest
+// declares.
+type Port struct {
+	LicenseType string
+	LicenseText string
+	Homepage    string
+}
+
+// SPDXDocument is the share/<port>/vcpkg.spdx.json vcpkg writes for each
+// installed port.
+type SPDXDocument struct {
+	Packages []struct {
+		SPDXID           string `json:"SPDXID"`
+		Homepage         string `json:"homepage"`
+		LicenseConcluded string `json:"licenseConcluded"`
+		LicenseDeclared  string `json:"licenseDeclared"`
+	} `json:"packages"`
+}
+
+// applyPortLicenses fills in licenses from the installed tree of the
+// project, or of the vcpkg root, and from the port manifests in the root.
+func (s *Scanner) applyPortLicenses(dir string, dependencies []types.Dependency) {
+	for i := range dependencies {
+		dep := &dependencies[i]
+		if dep.Scope == "project" {
+			continue
+		}
+
+		port := s.readPort(dir, dep.Name)
+		if port == nil {
+			continue
+		}
+		if dep.LicenseType == "UNKNOWN" || dep.LicenseType == "" {
+			dep.LicenseType = port.LicenseType
+		}
+		if dep.LicenseText == "" {
+			dep.LicenseText = port.LicenseText
+		}
+		if dep.Homepage == "" {
+			dep.Homepage = port.Homepage
+		}
+	}
+}
+
+func (s *Scanner) readPort(dir, name string) *Port {
+	key := dir + "\x00" + name
+	if port, ok := s.portCache[key]; ok {
+		return port
+	}
+
+	installedDirs := []string{filepath.Join(dir, "vcpkg_installed")}
+	root := s.root()
+	if root != "" {
+		installedDirs = append(installedDirs, filepath.Join(root, "installed"))
+	}
+
+	var port *Port
+	for _, installedDir := range installedDirs {
+		// Each triplet has its own share/ folder
+		shareDirs, _ := filepath.Glob(filepath.Join(installedDir, "*", "share", name))
+		for _, shareDir := range shareDirs {
+			data, err := os.ReadFile(filepath.Join(shareDir, "copyright"))
+			if err != nil {
+				continue
+			}
+			port = &Port{LicenseType: "UNKNOWN", LicenseText: string(data)}
+			if license, homepage := readSPDXLicense(filepath.Join(shareDir, "vcpkg.spdx.json")); license != "" {
+				port.LicenseType = license
+				port.Homepage = homepage
+			}
+			break
+		}
+		if port != nil {
+			break
+		}
+	}
+
+	if root != "" && (port == nil || port.LicenseType == "UNKNOWN") {
+		if manifest, err := readManifest(filepath.Join(root, "ports", name, "vcpkg.json")); err == nil {
+			if port == nil {
+				port = &Port{LicenseType: "UNKNOWN"}
+			}
+			if manifest.License != "" {
+				port.LicenseType = manifest.License
+			}
+			if port.Homepage == "" {
+				port.Homepage = manifest.Homepage
+			}
+		}
+	}
+
+	if port != nil && port.LicenseType == "UNKNOWN" && port.LicenseText != "" {
+		port.LicenseType = license.Detect(port.LicenseText)
+	}
+
+	if s.portCache == nil {
+		s.portCache = make(map[string]*Port)
+	}
+	s.portCache[key] = port
+	return port
+}
+
+// readSPDXLicense returns the license and homepage of the port package in
+// a vcpkg.spdx.json. NOASSERTION means the port declares none.
+func readSPDXLicense(path string) (string, string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", ""
+	}
+
+	var document SPDXDocument
+	if err := json.Unmarshal(data, &document); err != nil {
+		return "", ""
+	}
+
+	for _, pkg := range document.Packages {
+		if pkg.SPDXID != "SPDXRef-port" {
+			continue
+		}
+		for _, license := range []string{pkg.LicenseConcluded, pkg.LicenseDeclared} {
+			if license != "" && license != "NOASSERTION" {
+				return license, pkg.Homepage
+			}
+		}
+	}
+	return "", ""
+}
@@ -0,0 +1,324 @@
+package vcpkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"license-audit/pkg/types"
+)
+
+type Scanner struct {
+	// Root is the vcpkg installation whose ports, version baseline and
+	// classic-mode installed tree are read. It defaults to $VCPKG_ROOT.
+	Root string
+
+	portCache map[string]*Port
+	baseline  map[string]BaselineEntry
+}
+
+// Manifest is a vcpkg.json file.
+type Manifest struct {
+	Name            string               `json:"name"`
+	Version         string               `json:"version"`
+	VersionSemver   string               `json:"version-semver"`
+	VersionDate     string               `json:"version-date"`
+	VersionString   string               `json:"version-string"`
+	License         string               `json:"license"`
+	Homepage        string               `json:"homepage"`
+	Dependencies    []ManifestDependency `json:"dependencies"`
+	Overrides       []Override           `json:"overrides"`
+	BuiltinBaseline string               `json:"builtin-baseline"`
+	Configuration   *Configuration       `json:"vcpkg-configuration"`
+}
+
+// ManifestDependency is a port name, or an object that may set a minimum
+// version, a platform expression and whether it is a host tool.
+type ManifestDependency struct {
+	Name           string `json:"name"`
+	VersionMinimum string `json:"version>="`
+	Host           bool   `json:"host"`
+	Platform       string `json:"platform"`
+}
+
+func (d *ManifestDependency) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		return json.Unmarshal(data, &d.Name)
+	}
+	type plain ManifestDependency
+	return json.Unmarshal(data, (*plain)(d))
+}
+
+// Override pins a port to an exact version.
+type Override struct {
+	Name          string `json:"name"`
+	Version       string `json:"version"`
+	VersionSemver string `json:"version-semver"`
+	VersionDate   string `json:"version-date"`
+	VersionString string `json:"version-string"`
+}
+
+// Configuration is a vcpkg-configuration.json file, or the
+// vcpkg-configuration object of a manifest.
+type Configuration struct {
+	DefaultRegistry *Registry  `json:"default-registry"`
+	Registries      []Registry `json:"registries"`
+}
+
+// Registry is a source of ports at a baseline. The builtin registry is
+// the vcpkg root itself.
+type Registry struct {
+	Kind       string   `json:"kind"` // builtin, git, filesystem or artifact
+	Repository string   `json:"repository"`
+	Path       string   `json:"path"`
+	Baseline   string   `json:"baseline"`
+	Packages   []string `json:"packages"` // names or prefix* patterns served by this registry
+}
+
+func NewScanner() *Scanner {
+	return &Scanner{}
+}
+
+func (s *Scanner) Name() string {
+	return "vcpkg"
+}
+
+func (s *Scanner) Detect(path string) bool {
+	return filepath.Base(path) == "vcpkg.json"
+}
+
+func (s *Scanner) Scan(path string) ([]types.Dependency, error) {
+	dependencies, err := s.scanManifest(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s.applyPortLicenses(filepath.Dir(path), dependencies)
+	return dependencies, nil
+}
+
+func (s *Scanner) newDependency(name, version, filePath string) types.Dependency {
+	if version == "" {
+		version = "UNKNOWN"
+	}
+
+	return types.Dependency{
+		Name:        name,
+		Version:     version,
+		LicenseType: "UNKNOWN",
+		PackageType: "vcpkg",
+		FilePath:    filePath,
+	}
+}
+
+func readManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vcpkg.json: %w", err)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse vcpkg.json: %w", err)
+	}
+
+	return &manifest, nil
+}
+
+// readConfiguration returns the registries of a manifest: those of the
+// vcpkg-configuration.json next to it, which takes precedence, or those
+// embedded in the manifest. The builtin-baseline applies to the builtin
+// default registry.
+func readConfiguration(dir string, manifest *Manifest) *Configuration {
+	configuration := manifest.Configuration
+	if data, err := os.ReadFile(filepath.Join(dir, "vcpkg-configuration.json")); err == nil {
+		var fromFile Configuration
+		if json.Unmarshal(data, &fromFile) == nil {
+			configuration = &fromFile
+		}
+	}
+	if configuration == nil {
+		configuration = &Configuration{}
+	}
+
+	if configuration.DefaultRegistry == nil {
+		configuration.DefaultRegistry = &Registry{Kind: "builtin"}
+	}
+	if configuration.DefaultRegistry.Kind == "builtin" && configuration.DefaultRegistry.Baseline == "" {
+		configuration.DefaultRegistry.Baseline = manifest.BuiltinBaseline
+	}
+
+	return configuration
+}
+
+// registryFor returns the registry serving a port: the first registry
+// whose packages match it, or the default registry.
+func (c *Configuration) registryFor(name string) *Registry {
+	for i := range c.Registries {
+		for _, pattern := range c.Registries[i].Packages {
+			if matched, _ := path.Match(pattern, name); matched {
+				return &c.Registries[i]
+			}
+		}
+	}
+	return c.DefaultRegistry
+}
+
+// scanManifest reports the manifest's own port followed by its
+// dependencies. Versions come, in order of preference, from an override,
+// the installed tree, the registry baseline or the version>= constraint;
+// ports installed only as dependencies of these are reported as indirect.
+func (s *Scanner) scanManifest(path string) ([]types.Dependency, error) {
+	manifest, err := readManifest(path)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Dir(path)
+	configuration := readConfiguration(dir, manifest)
+	installed := s.readInstalled(dir)
+
+	var dependencies []types.Dependency
+
+	if manifest.Name != "" {
+		project := s.newDependency(manifest.Name, firstNonEmpty(manifest.Version, manifest.VersionSemver, manifest.VersionDate, manifest.VersionString), path)
+		project.Scope = "project"
+		project.Homepage = manifest.Homepage
+		if manifest.License != "" {
+			project.LicenseType = manifest.License
+		}
+		dependencies = append(dependencies, project)
+	}
+
+	overrides := make(map[string]string)
+	for _, override := range manifest.Overrides {
+		overrides[override.Name] = firstNonEmpty(override.Version, override.VersionSemver, override.VersionDate, override.VersionString)
+	}
+
+	seen := make(map[string]bool)
+	for _, declared := range manifest.Dependencies {
+		if declared.Name == "" || seen[declared.Name] {
+			continue
+		}
+		seen[declared.Name] = true
+
+		registry := configuration.registryFor(declared.Name)
+
+		version := overrides[declared.Name]
+		if version == "" {
+			if pkg, ok := installed.Packages[declared.Name]; ok {
+				version = pkg.Version
+			}
+		}
+		if version == "" && registry.Kind == "builtin" {
+			if baseline := s.baselineVersion(declared.Name); baseline != "" {
+				version = baseline
+				if declared.VersionMinimum != "" && compareVersions(declared.VersionMinimum, baseline) > 0 {
+					version = declared.VersionMinimum
+				}
+			}
+		}
+		if version == "" && declared.VersionMinimum != "" {
+			version = ">= " + declared.VersionMinimum
+		}
+
+		dep := s.newDependency(declared.Name, version, path)
+		dep.Platform = declared.Platform
+		if declared.Host || isToolPort(declared.Name) {
+			dep.Scope = "build"
+		}
+		applyRegistry(&dep, registry)
+		if pkg, ok := installed.Packages[declared.Name]; ok {
+			dep.Requires = pkg.Requires
+		}
+		dependencies = append(dependencies, dep)
+	}
+
+	for _, name := range installed.Names {
+		if seen[name] || name == manifest.Name {
+			continue
+		}
+		pkg := installed.Packages[name]
+
+		dep := s.newDependency(name, pkg.Version, path)
+		dep.Indirect = true
+		if installed.HostOnly[name] || isToolPort(name) {
+			dep.Scope = "build"
+		}
+		applyRegistry(&dep, configuration.registryFor(name))
+		dep.Requires = pkg.Requires
+		dependencies = append(dependencies, dep)
+	}
+
+	return dependencies, nil
+}
+
+// applyRegistry records where a port comes from when it is not the
+// builtin registry of the vcpkg root: repository#baseline for git
+// registries, or the registry path.
+func applyRegistry(dep *types.Dependency, registry *Registry) {
+	switch registry.Kind {
+	case "git":
+		dep.Source = "registry"
+		dep.SourceURL = registry.Repository
+		if registry.Baseline != "" {
+			dep.SourceURL += "#" + registry.Baseline
+		}
+	case "filesystem":
+		dep.Source = "registry"
+		dep.SourceURL = registry.Path
+	}
+}
+
+// isToolPort reports whether a port is one of vcpkg's build helpers, such
+// as vcpkg-cmake, which are always host dependencies.
+func isToolPort(name string) bool {
+	return strings.HasPrefix(name, "vcpkg-")
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// compareVersions compares dotted versions part by part, numerically
+// where both parts are numbers. Missing parts count as zero.
+func compareVersions(a, b string) int {
+	aParts := strings.FieldsFunc(a, isVersionSeparator)
+	bParts := strings.FieldsFunc(b, isVersionSeparator)
+
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		aPart, bPart := "0", "0"
+		if i < len(aParts) {
+			aPart = aParts[i]
+		}
+		if i < len(bParts) {
+			bPart = bParts[i]
+		}
+
+		aNumber, aErr := strconv.Atoi(aPart)
+		bNumber, bErr := strconv.Atoi(bPart)
+		switch {
+		case aErr == nil && bErr == nil && aNumber != bNumber:
+			if aNumber < bNumber {
+				return -1
+			}
+			return 1
+		case (aErr != nil || bErr != nil) && aPart != bPart:
+			return strings.Compare(aPart, bPart)
+		}
+	}
+	return 0
+}
+
+func isVersionSeparator(r rune) bool {
+	return r == '.' || r == '-' || r == '#'
+}
@@ -0,0 +1,123 @@
+package vcpkg
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const fixturesDir = "../../../test/fixtures/vcpkg"
+
+func newTestScanner() *Scanner {
+	scanner := NewScanner()
+	scanner.Root = filepath.Join(fixturesDir, "vcpkg-root")
+	return scanner
+}
+
+func TestDetect(t *testing.T) {
+	scanner := NewScanner()
+
+	testCases := []struct {
+		path     string
+		expected bool
+	}{
+		{"vcpkg.json", true},
+		{"libs/engine/vcpkg.json", true},
+		{"vcpkg-configuration.json", false},
+		{"package.json", false},
+		{"", false},
+	}
+
+	for _, tc := range testCases {
+		result := scanner.Detect(tc.path)
+		if result != tc.expected {
+			t.Errorf("Detect(%s) = %v, expected %v", tc.path, result, tc.expected)
+		}
+	}
+}
+
+func TestScanManifest(t *testing.T) {
+	scanner := newTestScanner()
+
+	dependencies, err := scanner.Scan(filepath.Join(fixturesDir, "manifest", "vcpkg.json"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	type expectation struct {
+		version  string
+		scope    string
+		source   string
+		indirect bool
+		license  string
+	}
+
+	expected := map[string]expectation{
+		"acme-renderer":      {"2.1.0", "project", "", false, "MIT"},
+		"fmt":                {"10.1.1", "", "", false, "MIT"},              // override
+		"zlib":               {"1.3", "", "", false, "Zlib"},                // installed
+		"openssl":            {"3.1.4", "", "", false, "Apache-2.0"},        // installed
+		"beicode":            {"1.0.0", "", "registry", false, "UNKNOWN"},   // git registry
+		"vcpkg-cmake":        {"2023-05-04", "build", "", false, "UNKNOWN"}, // host
+		"curl":               {"8.5.0", "", "", false, "curl AND ISC AND BSD-3-Clause"},
+		"protobuf":           {"3.21.12", "build", "", false, "UNKNOWN"}, // baseline
+		"beison":             {"1.1.0", "", "registry", true, "UNKNOWN"},
+		"vcpkg-cmake-config": {"2022-02-06", "build", "", true, "UNKNOWN"},
+	}
+
+	if len(dependencies) != len(expected) {
+		t.Errorf("Expected %d dependencies, got %d", len(expected), len(dependencies))
+	}
+
+	for _, dep := range dependencies {
+		want, ok := expected[dep.Name]
+		if !ok {
+			t.Errorf("Unexpected dependency '%s'", dep.Name)
+			continue
+		}
+		got := expectation{dep.Version, dep.Scope, dep.Source, dep.Indirect, dep.LicenseType}
+		if got != want {
+			t.Errorf("%s = %+v, expected %+v", dep.Name, got, want)
+		}
+
+		switch dep.Name {
+		case "openssl":
+			if dep.Platform != "!windows" {
+				t.Errorf("Expected the platform expression of openssl, got %s", dep.Platform)
+			}
+		case "beicode":
+			if dep.SourceURL != "https://github.com/northwindtraders/vcpkg-registry#dacf4de488094a384ca2c202b923ccc097956e0c" {
+				t.Errorf("Unexpected source URL for beicode: %s", dep.SourceURL)
+			}
+			if !reflect.DeepEqual(dep.Requires, []string{"beison", "vcpkg-cmake"}) {
+				t.Errorf("Unexpected requirements for beicode: %v", dep.Requires)
+			}
+		case "zlib":
+			if dep.LicenseText == "" || dep.Homepage != "https://www.zlib.net/" {
+				t.Errorf("Expected the zlib copyright file and SPDX homepage, got %q", dep.Homepage)
+			}
+		case "curl":
+			if dep.Homepage != "https://curl.se/" {
+				t.Errorf("Expected the homepage from the curl port, got %s", dep.Homepage)
+			}
+		}
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	testCases := []struct {
+		a, b     string
+		expected int
+	}{
+		{"8.5.0", "8.4.0", 1},
+		{"1.2", "1.2.0", 0},
+		{"1.10", "1.9", 1},
+		{"2022-02-06", "2023-05-04", -1},
+	}
+
+	for _, tc := range testCases {
+		if result := compareVersions(tc.a, tc.b); result != tc.expected {
+			t.Errorf("compareVersions(%s, %s) = %d, expected %d", tc.a, tc.b, result, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package vendored
+
+import (
+	"strings"
+
+	"license-audit/internal/license"
+)
+
+// classifyLicenses detects the license of each file. A component that
+// ships several licenses, as LICENSE-APACHE and LICENSE-MIT, offers a
+// choice between them.
+func classifyLicenses(files []license.File) string {
+	var licenses []string
+	seen := make(map[string]bool)
+	for _, file := range files {
+		detected := license.Detect(file.Text)
+		if detected == "UNKNOWN" || seen[detected] {
+			continue
+		}
+		seen[detected] = true
+		licenses = append(licenses, detected)
+	}
+
+	if len(licenses) == 0 {
+		return "UNKNOWN"
+	}
+	return strings.Join(licenses, " OR ")
+}
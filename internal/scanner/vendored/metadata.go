@@ -0,0 +1,212 @@
+package vendored
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Metadata is what a vendored component says about itself in a
+// README.chromium or an Android-style METADATA file.
+type Metadata struct {
+	Name        string
+	Version     string
+	URL         string
+	Homepage    string
+	License     string
+	LicenseFile string
+}
+
+// Submodule is a [submodule] entry of a .gitmodules file.
+type Submodule struct {
+	Path   string
+	URL    string
+	Branch string
+}
+
+var (
+	metadataNamePattern       = regexp.MustCompile(`(?m)^name:\s*"([^"]*)"`)
+	metadataVersionPattern    = regexp.MustCompile(`(?m)^\s*version:\s*"([^"]*)"`)
+	metadataHomepagePattern   = regexp.MustCompile(`(?m)^\s*homepage:\s*"([^"]*)"`)
+	metadataURLPattern        = regexp.MustCompile(`url\s*\{\s*type:\s*"?(\w+)"?\s*value:\s*"([^"]*)"\s*\}`)
+	metadataIdentifierPattern = regexp.MustCompile(`identifier\s*\{\s*type:\s*"?(\w+)"?\s*value:\s*"([^"]*)"`)
+	gitmodulesSectionPattern  = regexp.MustCompile(`^\[submodule\s+"([^"]*)"\]$`)
+)
+
+// chromiumLicenses maps the free-form License: values common in
+// README.chromium files to SPDX identifiers.
+var chromiumLicenses = map[string]string{
+	"apache 2.0":         "Apache-2.0",
+	"apache version 2.0": "Apache-2.0",
+	"apache-2.0":         "Apache-2.0",
+	"bsd":                "BSD-3-Clause",
+	"bsd 3-clause":       "BSD-3-Clause",
+	"bsd-3-clause":       "BSD-3-Clause",
+	"bsd 2-clause":       "BSD-2-Clause",
+	"mit":                "MIT",
+	"mpl 2.0":            "MPL-2.0",
+	"zlib":               "Zlib",
+	"public domain":      "Public Domain",
+}
+
+// readMetadata reads README.chromium or METADATA in dir, whichever exists.
+func readMetadata(dir string) *Metadata {
+	if fields := readChromiumFields(filepath.Join(dir, "README.chromium")); fields != nil {
+		metadata := &Metadata{
+			Name:        fields["Name"],
+			Version:     fields["Version"],
+			URL:         fields["URL"],
+			License:     fields["License"],
+			LicenseFile: strings.TrimSpace(strings.Split(fields["License File"], ",")[0]),
+		}
+		if short := fields["Short Name"]; short != "" {
+			metadata.Name = short
+		}
+		// Unversioned copies record a revision instead
+		switch strings.ToLower(metadata.Version) {
+		case "", "0", "n/a", "unknown":
+			metadata.Version = fields["Revision"]
+		}
+		if license, ok := chromiumLicenses[strings.ToLower(metadata.License)]; ok {
+			metadata.License = license
+		}
+		return metadata
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "METADATA"))
+	if err != nil {
+		return nil
+	}
+	content := string(data)
+
+	metadata := &Metadata{}
+	if match := metadataNamePattern.FindStringSubmatch(content); match != nil {
+		metadata.Name = match[1]
+	}
+	if match := metadataVersionPattern.FindStringSubmatch(content); match != nil {
+		metadata.Version = match[1]
+	}
+	if match := metadataHomepagePattern.FindStringSubmatch(content); match != nil {
+		metadata.Homepage = match[1]
+	}
+	for _, pattern := range []*regexp.Regexp{metadataURLPattern, metadataIdentifierPattern} {
+		for _, match := range pattern.FindAllStringSubmatch(content, -1) {
+			switch {
+			case strings.EqualFold(match[1], "HOMEPAGE"):
+				if metadata.Homepage == "" {
+					metadata.Homepage = match[2]
+				}
+			case metadata.URL == "":
+				metadata.URL = match[2]
+			}
+		}
+	}
+	return metadata
+}
+
+// readChromiumFields reads the "Field: value" header of a README.chromium,
+// which ends at the first blank line or the free-form Description.
+func readChromiumFields(path string) map[string]string {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	fields := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			if len(fields) > 0 {
+				break
+			}
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || key == "Description" {
+			break
+		}
+		fields[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return fields
+}
+
+// readVersionFile returns the first line of a VERSION file, which many
+// libraries ship, or UNKNOWN.
+func readVersionFile(dir string) string {
+	for _, name := range []string{"VERSION", "VERSION.txt"} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		line, _, _ := strings.Cut(string(data), "\n")
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return "UNKNOWN"
+}
+
+// submodule returns the submodule checked out at dir, as recorded by the
+// .gitmodules of the scan root or of a directory between it and dir.
+func (s *Scanner) submodule(root, dir string) *Submodule {
+	for parent := filepath.Dir(dir); ; parent = filepath.Dir(parent) {
+		if rel, err := filepath.Rel(parent, dir); err == nil {
+			if submodule := s.readGitmodules(parent)[filepath.ToSlash(rel)]; submodule != nil {
+				return submodule
+			}
+		}
+		if parent == root || filepath.Dir(parent) == parent {
+			return nil
+		}
+	}
+}
+
+func (s *Scanner) readGitmodules(dir string) map[string]*Submodule {
+	if submodules, ok := s.submodules[dir]; ok {
+		return submodules
+	}
+
+	var submodules map[string]*Submodule
+	if file, err := os.Open(filepath.Join(dir, ".gitmodules")); err == nil {
+		defer file.Close()
+
+		// The section name is the path unless a path key says otherwise
+		var entries []*Submodule
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if match := gitmodulesSectionPattern.FindStringSubmatch(line); match != nil {
+				entries = append(entries, &Submodule{Path: match[1]})
+				continue
+			}
+			key, value, ok := strings.Cut(line, "=")
+			if len(entries) == 0 || !ok {
+				continue
+			}
+			current := entries[len(entries)-1]
+			switch strings.TrimSpace(key) {
+			case "path":
+				current.Path = strings.TrimSpace(value)
+			case "url":
+				current.URL = strings.TrimSpace(value)
+			case "branch":
+				current.Branch = strings.TrimSpace(value)
+			}
+		}
+
+		submodules = make(map[string]*Submodule)
+		for _, entry := range entries {
+			submodules[strings.TrimSuffix(entry.Path, "/")] = entry
+		}
+	}
+
+	if s.submodules == nil {
+		s.submodules = make(map[string]map[string]*Submodule)
+	}
+	s.submodules[dir] = submodules
+	return submodules
+}
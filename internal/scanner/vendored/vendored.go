@@ -0,0 +1,276 @@
+package vendored
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"license-audit/internal/license"
+	"license-audit/pkg/types"
+)
+
+// DefaultDirs are the directory names that hold copied third-party code
+// when none are configured.
+var DefaultDirs = []string{"third_party", "third-party", "thirdparty", "3rdparty", "vendor", "external", "extern", "deps"}
+
+// Scanner reports copies of third-party code that no package manager
+// knows about. Each component is a directory: one found under a vendor
+// directory, a git submodule, or any directory with its own license file
+// and source code but no package manifest.
+type Scanner struct {
+	// Dirs are the names of directories holding vendored components. They
+	// default to DefaultDirs.
+	Dirs []string
+
+	components map[string]component
+	submodules map[string]map[string]*Submodule // .gitmodules directory -> submodule path -> submodule
+}
+
+// component is a directory DetectDir recognised, kept for Scan.
+type component struct {
+	submodule *Submodule
+}
+
+// skippedDirs are never vendored components themselves nor searched for
+// them: VCS metadata, build output and package manager installs, which
+// their own scanners report.
+var skippedDirs = map[string]bool{
+	".git":             true,
+	".hg":              true,
+	".svn":             true,
+	"node_modules":     true,
+	"bower_components": true,
+	"_build":           true,
+	"target":           true,
+	"Pods":             true,
+	".build":           true,
+	"vcpkg_installed":  true,
+	"site-packages":    true,
+	".venv":            true,
+}
+
+// manifestFiles mark directories that a package scanner already reports.
+var manifestFiles = []string{
+	"package.json", "go.mod", "Cargo.toml", "pom.xml", "build.gradle", "build.gradle.kts",
+	"setup.py", "pyproject.toml", "composer.json", "Gemfile", "mix.exs", "rebar.config",
+	"conanfile.txt", "conanfile.py", "vcpkg.json", "Package.swift", "pubspec.yaml",
+	"*.gemspec", "*.csproj", "*.fsproj", "*.vbproj", "*.podspec",
+}
+
+// sourceExtensions identify source code.
+var sourceExtensions = map[string]bool{
+	".c": true, ".h": true, ".cc": true, ".cpp": true, ".cxx": true, ".hh": true, ".hpp": true, ".hxx": true,
+	".inl": true, ".ipp": true, ".m": true, ".mm": true, ".s": true, ".asm": true,
+	".go": true, ".rs": true, ".zig": true, ".java": true, ".kt": true, ".scala": true, ".swift": true,
+	".py": true, ".rb": true, ".php": true, ".pl": true, ".lua": true, ".cs": true,
+	".js": true, ".mjs": true, ".ts": true, ".css": true,
+}
+
+func NewScanner() *Scanner {
+	return &Scanner{}
+}
+
+func (s *Scanner) Name() string {
+	return "vendored"
+}
+
+// Detect is false for every file; vendored components are directories,
+// recognised by DetectDir.
+func (s *Scanner) Detect(path string) bool {
+	return false
+}
+
+// DetectDir reports whether dir, below the scan root, is a vendored
+// component. Directories inside a component already reported are part
+// of it.
+func (s *Scanner) DetectDir(root, dir string) bool {
+	root = filepath.Clean(root)
+	dir = filepath.Clean(dir)
+	if dir == root || skippedDirs[filepath.Base(dir)] || s.isVendorDir(dir) {
+		return false
+	}
+
+	inVendorDir := false
+	for parent := filepath.Dir(dir); ; parent = filepath.Dir(parent) {
+		if _, ok := s.components[parent]; ok {
+			return false
+		}
+		if parent == root {
+			break
+		}
+		if skippedDirs[filepath.Base(parent)] {
+			return false
+		}
+		if isManagedVendorDir(parent, dir) {
+			return false
+		}
+		if s.isVendorDir(parent) {
+			inVendorDir = true
+		}
+		if next := filepath.Dir(parent); next == parent {
+			break
+		}
+	}
+
+	submodule := s.submodule(root, dir)
+
+	isComponent := false
+	switch {
+	case submodule != nil:
+		isComponent = true
+	case inVendorDir:
+		// Vendor directories may group components, as in
+		// vendor/github.com/owner/name; descend until one is found
+		isComponent = license.ReadFiles(dir) != nil || readMetadata(dir) != nil || hasSource(dir, 0)
+	default:
+		isComponent = license.ReadFiles(dir) != nil && hasSource(dir, 1) && !hasManifest(dir)
+	}
+
+	if isComponent {
+		if s.components == nil {
+			s.components = make(map[string]component)
+		}
+		s.components[dir] = component{submodule: submodule}
+	}
+	return isComponent
+}
+
+// Scan reports the component in dir, named and versioned by its metadata
+// file or submodule entry if it has one, or else by its directory name.
+func (s *Scanner) Scan(dir string) ([]types.Dependency, error) {
+	dir = filepath.Clean(dir)
+
+	dep := types.Dependency{
+		Name:        filepath.Base(dir),
+		Version:     "UNKNOWN",
+		LicenseType: "UNKNOWN",
+		PackageType: "vendored",
+		FilePath:    dir,
+	}
+
+	if submodule := s.components[dir].submodule; submodule != nil {
+		dep.Repository = submodule.URL
+		dep.Source = "git"
+		dep.SourceURL = submodule.URL
+	}
+
+	metadata := readMetadata(dir)
+	if metadata != nil {
+		if metadata.Name != "" {
+			dep.Name = metadata.Name
+		}
+		if metadata.Version != "" {
+			dep.Version = metadata.Version
+		}
+		if metadata.URL != "" {
+			dep.Repository = metadata.URL
+		}
+		if metadata.Homepage != "" {
+			dep.Homepage = metadata.Homepage
+		}
+	}
+	if dep.Version == "UNKNOWN" {
+		dep.Version = readVersionFile(dir)
+	}
+
+	licenses := license.ReadFiles(dir)
+	if metadata != nil && metadata.LicenseFile != "" {
+		// The metadata names the license file, which may live elsewhere
+		if data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(metadata.LicenseFile))); err == nil {
+			licenses = append([]license.File{{Name: metadata.LicenseFile, Text: string(data)}}, licenses...)
+		}
+	}
+
+	if len(licenses) > 0 {
+		dep.LicenseText = licenses[0].Text
+		dep.LicenseType = classifyLicenses(licenses)
+	}
+	if dep.LicenseType == "UNKNOWN" && metadata != nil && metadata.License != "" {
+		dep.LicenseType = metadata.License
+	}
+
+	return []types.Dependency{dep}, nil
+}
+
+func (s *Scanner) isVendorDir(dir string) bool {
+	dirs := s.Dirs
+	if len(dirs) == 0 {
+		dirs = DefaultDirs
+	}
+
+	name := filepath.Base(dir)
+	for _, vendorDir := range dirs {
+		if name == vendorDir {
+			return true
+		}
+	}
+	return false
+}
+
+// isManagedVendorDir reports whether vendorDir is where a package manager
+// installs what a lockfile lists, whether or not it is configured as a
+// vendor directory: Go's vendor/ with modules.txt, Composer's vendor/ next
+// to composer.json, the crates of cargo vendor, Bundler's vendor/bundle,
+// and Mix's or rebar's deps/. Their own scanners report those packages.
+func isManagedVendorDir(vendorDir, dir string) bool {
+	project := filepath.Dir(vendorDir)
+
+	// cargo vendor writes a checksum file into each crate it copies
+	if fileExists(filepath.Join(dir, ".cargo-checksum.json")) {
+		return true
+	}
+
+	switch filepath.Base(vendorDir) {
+	case "vendor":
+		if fileExists(filepath.Join(vendorDir, "modules.txt")) || fileExists(filepath.Join(vendorDir, "composer")) ||
+			fileExists(filepath.Join(project, "composer.json")) || fileExists(filepath.Join(project, "Cargo.toml")) {
+			return true
+		}
+		if rel, err := filepath.Rel(vendorDir, dir); err == nil {
+			first := strings.Split(rel, string(filepath.Separator))[0]
+			if first == "bundle" || first == "cache" {
+				return fileExists(filepath.Join(project, "Gemfile"))
+			}
+		}
+	case "deps":
+		return fileExists(filepath.Join(project, "mix.exs")) || fileExists(filepath.Join(project, "rebar.config"))
+	}
+	return false
+}
+
+// hasSource reports whether dir, or its subdirectories down to depth,
+// contain source code.
+func hasSource(dir string, depth int) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() && sourceExtensions[strings.ToLower(filepath.Ext(entry.Name()))] {
+			return true
+		}
+	}
+	if depth > 0 {
+		for _, entry := range entries {
+			if entry.IsDir() && !skippedDirs[entry.Name()] && hasSource(filepath.Join(dir, entry.Name()), depth-1) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func hasManifest(dir string) bool {
+	for _, pattern := range manifestFiles {
+		if matches, _ := filepath.Glob(filepath.Join(dir, pattern)); len(matches) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,149 @@
+package vendored
+
+import (
+	"io/fs"
+	"path/filepath"
+	"testing"
+
+	"license-audit/internal/license"
+	"license-audit/pkg/types"
+)
+
+const fixturesDir = "../../../test/fixtures/vendored"
+
+// scanTree walks root the way the scanner framework does, scanning each
+// directory DetectDir accepts.
+func scanTree(t *testing.T, scanner *Scanner, root string) []types.Dependency {
+	var dependencies []types.Dependency
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() || !scanner.DetectDir(root, path) {
+			return err
+		}
+		deps, err := scanner.Scan(path)
+		if err != nil {
+			t.Errorf("Scan(%s) failed: %v", path, err)
+		}
+		dependencies = append(dependencies, deps...)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return dependencies
+}
+
+func TestDetect(t *testing.T) {
+	scanner := NewScanner()
+
+	for _, path := range []string{"LICENSE", "third_party/zlib/README.chromium", "vendor/modules.txt", ""} {
+		if scanner.Detect(path) {
+			t.Errorf("Detect(%s) = true, expected false", path)
+		}
+	}
+}
+
+func TestScanProject(t *testing.T) {
+	root := filepath.Join(fixturesDir, "project")
+	dependencies := scanTree(t, NewScanner(), root)
+
+	type expectation struct {
+		version string
+		source  string
+		license string
+		path    string
+	}
+
+	expected := map[string]expectation{
+		"googletest": {"UNKNOWN", "git", "BSD-3-Clause", "external/googletest"},   // submodule
+		"zlib":       {"1.3.0.1", "", "Zlib", "third_party/zlib"},                 // README.chromium
+		"abseil-cpp": {"20240116.2", "", "Apache-2.0", "third_party/abseil"},      // METADATA
+		"ryu":        {"UNKNOWN", "", "Apache-2.0 OR BSL-1.0", "third_party/ryu"}, // dual licensed
+		"miniz":      {"1.2.1", "", "MIT", "vendor/github.com/acme/miniz"},        // nested in vendor/
+		"json":       {"3.11.2", "", "MIT", "src/lib/json"},                       // license and source
+	}
+
+	if len(dependencies) != len(expected) {
+		t.Errorf("Expected %d dependencies, got %d", len(expected), len(dependencies))
+	}
+
+	for _, dep := range dependencies {
+		want, ok := expected[dep.Name]
+		if !ok {
+			t.Errorf("Unexpected dependency '%s' at %s", dep.Name, dep.FilePath)
+			continue
+		}
+		rel, _ := filepath.Rel(root, dep.FilePath)
+		got := expectation{dep.Version, dep.Source, dep.LicenseType, filepath.ToSlash(rel)}
+		if got != want {
+			t.Errorf("%s = %+v, expected %+v", dep.Name, got, want)
+		}
+		if dep.PackageType != "vendored" {
+			t.Errorf("Expected package type vendored for %s, got %s", dep.Name, dep.PackageType)
+		}
+
+		switch dep.Name {
+		case "googletest":
+			if dep.SourceURL != "https://github.com/google/googletest.git" {
+				t.Errorf("Unexpected source URL for googletest: %s", dep.SourceURL)
+			}
+		case "zlib":
+			if dep.Repository != "http://zlib.net/" || dep.LicenseText == "" {
+				t.Errorf("Expected the zlib URL and license text, got %q", dep.Repository)
+			}
+		case "abseil-cpp":
+			if dep.Homepage != "https://abseil.io/" || dep.Repository != "https://github.com/abseil/abseil-cpp" {
+				t.Errorf("Unexpected abseil-cpp links: %s, %s", dep.Homepage, dep.Repository)
+			}
+		}
+	}
+}
+
+func TestScanConfiguredDirs(t *testing.T) {
+	scanner := NewScanner()
+	scanner.Dirs = []string{"external"}
+
+	// third_party is no longer a vendor directory, so only components
+	// with their own license file and source are found there
+	names := make(map[string]bool)
+	for _, dep := range scanTree(t, scanner, filepath.Join(fixturesDir, "project")) {
+		names[dep.Name] = true
+	}
+
+	for _, name := range []string{"googletest", "zlib", "ryu", "json"} {
+		if !names[name] {
+			t.Errorf("Expected dependency '%s'", name)
+		}
+	}
+	if names["text"] {
+		t.Errorf("Expected the managed Go vendor directory to be skipped")
+	}
+}
+
+func TestScanManagedTrees(t *testing.T) {
+	// Composer's vendor directory is reported by the php scanner, and
+	// licenses.go files in the scanners' own source are not license files
+	for _, root := range []string{filepath.Join(fixturesDir, "..", "php"), ".."} {
+		for _, dep := range scanTree(t, NewScanner(), root) {
+			t.Errorf("Unexpected dependency '%s' at %s", dep.Name, dep.FilePath)
+		}
+	}
+}
+
+func TestClassifyLicenses(t *testing.T) {
+	testCases := []struct {
+		files    []license.File
+		expected string
+	}{
+		{nil, "UNKNOWN"},
+		{[]license.File{{Name: "LICENSE", Text: "MIT License"}}, "MIT"},
+		{[]license.File{{Name: "LICENSE-MIT", Text: "MIT License"}, {Name: "LICENSE-APACHE", Text: "Apache License, Version 2.0"}}, "MIT OR Apache-2.0"},
+		{[]license.File{{Name: "COPYING", Text: "GNU GENERAL PUBLIC LICENSE"}, {Name: "COPYING.GPL", Text: "GNU General Public License"}}, "GPL-3.0"},
+		{[]license.File{{Name: "LICENSE", Text: "All rights reserved."}}, "UNKNOWN"},
+	}
+
+	for _, tc := range testCases {
+		if result := classifyLicenses(tc.files); result != tc.expected {
+			t.Errorf("classifyLicenses(%v) = %s, expected %s", tc.files, result, tc.expected)
+		}
+	}
+}
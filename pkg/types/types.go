@@ -3,15 +3,25 @@ package types
 import "time"
 
 type Dependency struct {
-	Name        string `json:"name"`
-	Version     string `json:"version"`
-	LicenseType string `json:"license_type"`
-	LicenseText string `json:"license_text,omitempty"`
-	Repository  string `json:"repository,omitempty"`
-	Homepage    string `json:"homepage,omitempty"`
-	LicenseURL  string `json:"license_url,omitempty"`
-	PackageType string `json:"package_type"` // npm, go, docker, etc.
-	FilePath    string `json:"file_path"`    // where this dependency was found
+	Name        string   `json:"name"`
+	Version     string   `json:"version"`
+	LicenseType string   `json:"license_type"`
+	LicenseText string   `json:"license_text,omitempty"`
+	Repository  string   `json:"repository,omitempty"`
+	Homepage    string   `json:"homepage,omitempty"`
+	LicenseURL  string   `json:"license_url,omitempty"`
+	PackageType string   `json:"package_type"`         // npm, go, docker, etc.
+	FilePath    string   `json:"file_path"`            // where this dependency was found
+	Scope       string   `json:"scope,omitempty"`      // dev, optional, project (the scanned package itself), etc.; empty for runtime
+	Workspace   string   `json:"workspace,omitempty"`  // workspace member that declares it
+	Indirect    bool     `json:"indirect,omitempty"`   // pulled in transitively
+	Hashes      []string `json:"hashes,omitempty"`     // pinned artifact hashes as algorithm:hex, e.g. sha256:...
+	Source      string   `json:"source,omitempty"`     // git, path, url, etc. when not the default registry
+	SourceURL   string   `json:"source_url,omitempty"` // location for non-registry sources
+	Markers     string   `json:"markers,omitempty"`    // environment markers, e.g. python_version < "3.9"
+	Platform    string   `json:"platform,omitempty"`   // platform-specific build, e.g. x86_64-linux
+	Requires    []string `json:"requires,omitempty"`   // names of the packages this one depends on
+	Files       []string `json:"files,omitempty"`      // files the dependency covers, e.g. source files sharing a license header
 }
 
 type AuditIssue struct {
@@ -46,15 +56,41 @@ type Config struct {
 	LicenseOverrides  map[string]string `toml:"license_overrides"` // package_name -> license
 	DangerousLicenses []string          `toml:"dangerous_licenses"`
 	UnclearLicenses   []string          `toml:"unclear_licenses"`
+	ProjectLicense    string            `toml:"project_license"` // license of the scanned project itself, detected from its LICENSE file if empty
 	EnableAudit       bool              `toml:"enable_audit"`
 	Scanners          ScannerConfig     `toml:"scanners"`
 }
 
 type ScannerConfig struct {
-	NodeJS bool `toml:"nodejs"`
-	Go     bool `toml:"go"`
-	Docker bool `toml:"docker"`
-	Python bool `toml:"python"`
-	Ruby   bool `toml:"ruby"`
-	Java   bool `toml:"java"`
+	NodeJS     bool `toml:"nodejs"`
+	Go         bool `toml:"go"`
+	Docker     bool `toml:"docker"`
+	Python     bool `toml:"python"`
+	Ruby       bool `toml:"ruby"`
+	Java       bool `toml:"java"`
+	Rust       bool `toml:"rust"`
+	PHP        bool `toml:"php"`
+	NuGet      bool `toml:"nuget"`
+	Swift      bool `toml:"swift"`
+	CocoaPods  bool `toml:"cocoapods"`
+	Pub        bool `toml:"pub"`
+	Elixir     bool `toml:"elixir"`
+	Erlang     bool `toml:"erlang"`
+	Conan      bool `toml:"conan"`
+	Vcpkg      bool `toml:"vcpkg"`
+	Vendored   bool `toml:"vendored"`
+	Compose    bool `toml:"compose"`
+	Kubernetes bool `toml:"kubernetes"`
+	Helm       bool `toml:"helm"`
+	Deb        bool `toml:"deb"`
+
+	JavaArchives    bool `toml:"java_archives"`    // open .jar/.war/.ear files, including nested jars
+	SourceHeaders   bool `toml:"source_headers"`   // check SPDX-License-Identifier and license headers of first-party source files
+	ContainerImages bool `toml:"container_images"` // read installed packages from docker save / OCI image tarballs and OCI layout directories
+
+	PythonVirtualenvs []string `toml:"python_virtualenvs"`  // extra virtualenvs to read installed licenses from
+	MavenRepository   string   `toml:"maven_repository"`    // local Maven repository, defaults to ~/.m2/repository
+	RubyGemPaths      []string `toml:"ruby_gem_paths"`      // extra gem homes or bundle paths to read installed licenses from
+	VendoredDirs      []string `toml:"vendored_dirs"`       // directory names holding vendored code, defaults to third_party, vendor, external, ...
+	SourceHeaderLines int      `toml:"source_header_lines"` // lines read from the top of each source file, defaults to 30
 }
@@ -0,0 +1,7 @@
+from conan import ConanFile
+
+
+class MyLibConan(ConanFile):
+    name = "mylib"
+    version = "2.0.0"
+    license = "Proprietary"
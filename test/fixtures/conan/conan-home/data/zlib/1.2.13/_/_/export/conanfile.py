@@ -0,0 +1,10 @@
+from conan import ConanFile
+
+
+class ZlibConan(ConanFile):
+    name = "zlib"
+    package_type = "library"
+    url = "https://github.com/conan-io/conan-center-index"
+    homepage = "https://zlib.net"
+    license = "Zlib"
+    description = "A Massively Spiffy Yet Delicately Unobtrusive Compression Library"
@@ -0,0 +1,9 @@
+from conan import ConanFile
+
+
+class FmtConan(ConanFile):
+    name = "fmt"
+    homepage = "https://github.com/fmtlib/fmt"
+    description = "A safe and fast alternative to printf and IOStreams."
+    url = "https://github.com/conan-io/conan-center-index"
+    license = "MIT"
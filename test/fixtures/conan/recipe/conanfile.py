@@ -0,0 +1,29 @@
+from conan import ConanFile
+from conan.tools.cmake import CMake, cmake_layout
+
+
+class AcmeEngineConan(ConanFile):
+    name = "acme-engine"
+    version = "3.2.0"
+    license = "BSL-1.0", "MIT"
+    url = "https://github.com/acme/engine"
+    homepage = "https://acme.example.com/engine"
+    description = "Acme rendering engine"
+    settings = "os", "compiler", "build_type", "arch"
+    requires = (
+        "zlib/1.2.13",
+        "fmt/10.1.1",
+    )
+    tool_requires = "ninja/1.11.1"
+
+    def requirements(self):
+        self.requires("spdlog/1.12.0")
+        if self.settings.os == "Windows":
+            self.requires("winreg/0.3.0")
+        self.requires(f"boost/{self.version}")  # computed, skipped
+
+    def build_requirements(self):
+        self.test_requires("catch2/3.4.0")
+
+    def layout(self):
+        cmake_layout(self)
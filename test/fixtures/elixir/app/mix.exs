@@ -0,0 +1,41 @@
+defmodule Acme.MixProject do
+  use Mix.Project
+
+  @version "1.4.0"
+  @source_url "https://github.com/acme/acme_web"
+
+  def project do
+    [
+      app: :acme_web,
+      version: @version,
+      elixir: "~> 1.15",
+      start_permanent: Mix.env() == :prod,
+      deps: deps(),
+      package: package(),
+      source_url: "https://github.com/acme/acme_web"
+    ]
+  end
+
+  def application do
+    [extra_applications: [:logger]]
+  end
+
+  defp package do
+    [licenses: ["Apache-2.0"], links: %{"GitHub" => @source_url}]
+  end
+
+  # Run "mix help deps" to learn about dependencies.
+  defp deps do
+    [
+      {:jason, "~> 1.4"},
+      {:plug_crypto, "~> 2.0", optional: true},
+      # {:commented_out, "~> 1.0"},
+      {:ecto_extras, git: "https://github.com/acme/ecto_extras.git", tag: "v0.3.1"},
+      {:local_helpers, path: "../local_helpers"},
+      {:billing, "~> 0.2", organization: "acme"},
+      {:credo, "~> 1.7", only: [:dev, :test], runtime: false},
+      {:mox, "~> 1.1", only: :test},
+      {:telemetry, "~> 1.2", override: true, only: Mix.env()}
+    ]
+  end
+end
@@ -0,0 +1,7 @@
+defmodule LocalHelpers.MixProject do
+  use Mix.Project
+
+  def project do
+    [app: :local_helpers, version: "0.1.0", deps: []]
+  end
+end
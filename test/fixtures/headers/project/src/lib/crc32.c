@@ -0,0 +1,10 @@
+/*
+ * crc32.c - CRC-32 checksum
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ */
+
+unsigned int crc32(const unsigned char *buf, unsigned int len);
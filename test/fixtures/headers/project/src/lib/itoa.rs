@@ -0,0 +1,3 @@
+/* SPDX-License-Identifier: MIT OR Apache-2.0 */
+
+pub fn itoa(n: u64) -> String { n.to_string() }
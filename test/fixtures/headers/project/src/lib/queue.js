@@ -0,0 +1,8 @@
+/**
+ * Copyright (c) 2019 Jane Doe
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction.
+ */
+module.exports = class Queue {};
@@ -0,0 +1,6 @@
+// Copyright 2024 Example Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+func main() {}
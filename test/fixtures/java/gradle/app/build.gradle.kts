@@ -0,0 +1,16 @@
+val coroutinesVersion = "1.7.3"
+
+dependencies {
+    implementation(libs.jackson.databind)
+    implementation(libs.guava)
+    implementation(kotlin("stdlib"))
+    implementation("org.jetbrains.kotlinx:kotlinx-coroutines-core:$coroutinesVersion")
+    implementation(
+        group = "io.ktor",
+        name = "ktor-client-core",
+        version = "2.3.5"
+    )
+    runtimeOnly("org.postgresql:postgresql") // version from a platform
+    testImplementation(libs.junit.jupiter)
+    kapt("com.google.dagger:dagger-compiler:2.48")
+}
@@ -0,0 +1,40 @@
+#!/usr/bin/env python
+import os
+from setuptools import find_packages, setup
+
+here = os.path.abspath(os.path.dirname(__file__))
+
+BASE_REQUIRES = [
+    "requests>=2.28,<3",  # HTTP client
+    'click~=8.1',
+]
+VERSION = "1.4.2"
+
+with open(os.path.join(here, "README.md")) as f:
+    long_description = f.read()
+
+
+def read_requirements(name):
+    return open(name).read().splitlines()
+
+
+setup(
+    name="legacy-lib",
+    version=VERSION,
+    description="Legacy library " "with implicit concatenation",
+    long_description=long_description,
+    license="BSD",
+    packages=find_packages(exclude=["tests"]),
+    install_requires=BASE_REQUIRES + ["six"],
+    tests_require=read_requirements("requirements-test.txt"),
+    extras_require=dict(
+        yaml=["PyYAML>=6.0"],
+        socks={"pysocks": 1}.keys(),
+    ),
+    classifiers=[
+        "Programming Language :: Python :: 3",
+        "License :: OSI Approved :: BSD License",
+    ],
+    entry_points={"console_scripts": ["legacy=legacy.cli:main"]},
+    **extra_kwargs
+)
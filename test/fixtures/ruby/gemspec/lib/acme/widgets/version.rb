@@ -0,0 +1,5 @@
+module Acme
+  module Widgets
+    VERSION = "0.3.0"
+  end
+end
@@ -0,0 +1,28 @@
+// swift-tools-version:5.9
+import PackageDescription
+
+let package = Package(
+    name: "AcmeKit",
+    platforms: [.iOS(.v15), .macOS(.v13)],
+    products: [
+        .library(name: "AcmeKit", targets: ["AcmeKit"]),
+    ],
+    dependencies: [
+        .package(url: "https://github.com/Alamofire/Alamofire.git", from: "5.8.0"),
+        .package(url: "https://github.com/apple/swift-log.git", .upToNextMinor(from: "1.5.0")),
+        .package(url: "https://github.com/pointfreeco/swift-snapshot-testing", "1.12.0"..<"2.0.0"),
+        .package(url: "https://github.com/acme/networking.git", branch: "main"),
+        // .package(url: "https://github.com/acme/unused.git", exact: "1.0.0"),
+        .package(path: "../LocalUtils"),
+        .package(id: "acme.analytics", exact: "2.1.0"),
+    ],
+    targets: [
+        .target(
+            name: "AcmeKit",
+            dependencies: [
+                "Alamofire",
+                .product(name: "Logging", package: "swift-log"),
+            ]
+        ),
+    ]
+)
@@ -0,0 +1 @@
+#include "gtest/gtest.h"
@@ -0,0 +1 @@
+#include "ryu.h"
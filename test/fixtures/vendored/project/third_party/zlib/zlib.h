@@ -0,0 +1 @@
+#define ZLIB_VERSION "1.3.0.1"